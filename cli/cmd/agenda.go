@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	agendaDay   bool
+	agendaWeek  bool
+	agendaDate  string
+	agendaHours string
+)
+
+var agendaCmd = &cobra.Command{
+	Use:   "agenda",
+	Short: "Show everything planned for a day or week",
+	Long: `Show one chronological view of a day or week: calendar events with their
+times, tasks due or scheduled to start, overdue tasks, the habits due that day
+and whether they were done, and the Pomodoros you completed.
+
+Free time between events and Pomodoros within your working hours is shown for
+today and later days, so you can see when there is room to work on tasks. The
+working hours are the ones set with 'prod plan config set'.
+
+Tasks are numbered like in 'prod task list', so the numbers can be used with
+the other task commands.
+
+Examples:
+  prod agenda                        # Today
+  prod agenda --week                 # This week, Monday to Sunday
+  prod agenda --date 2025-06-02      # A given day
+  prod agenda --week --hours 8-16    # Look for free time from 8 to 16`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		now := time.Now()
+		from := now
+		if agendaDate != "" {
+			date, err := util.ParseDate(agendaDate)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+			from = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+		}
+
+		days := 1
+		if agendaWeek {
+			from = util.StartOfWeek(from)
+			days = 7
+		}
+
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to see your agenda")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		// Free time is looked for in the working hours tasks are planned in
+		plannerService := services.NewPlannerService(queries)
+		config, err := plannerService.GetConfig(context.Background(), user.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error retrieving working hours: %v\n", err)
+			return
+		}
+		hours := config.Hours
+		if agendaHours != "" {
+			if hours, err = services.ParseWorkingHours(agendaHours); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+		}
+
+		agendaService := services.NewAgendaService(queries)
+		agenda, err := agendaService.Agenda(context.Background(), user.ID, from, days, hours, now)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error building agenda: %v\n", err)
+			return
+		}
+
+		taskMap := make(map[int]int32)
+		for i, day := range agenda {
+			if i > 0 {
+				fmt.Println()
+			}
+			printAgendaDay(day, now, taskMap)
+		}
+
+		if err := services.MakeTaskMapFile(taskMap); err != nil {
+			fmt.Fprintf(os.Stderr, "Error making task map file: %v\n", err)
+		}
+	},
+}
+
+// printAgendaDay prints a day of the agenda, numbering its tasks in taskMap
+func printAgendaDay(day services.AgendaDay, now time.Time, taskMap map[int]int32) {
+	header := TextBold + day.Date.Format("Monday, 2 January 2006") + ColorReset
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	switch {
+	case day.Date.Equal(today):
+		header += " (today)"
+	case day.Date.Equal(today.AddDate(0, 0, 1)):
+		header += " (tomorrow)"
+	}
+	if len(day.Free) > 0 {
+		var free time.Duration
+		for _, span := range day.Free {
+			free += span.Duration()
+		}
+		header += ColorGreen + " · " + formatAgendaDuration(free) + " free" + ColorReset
+	}
+	fmt.Println(header)
+
+	if len(day.Overdue) > 0 {
+		fmt.Println("  " + ColorRed + "Overdue" + ColorReset)
+		for _, task := range day.Overdue {
+			fmt.Printf("  %s %s\n", agendaTaskLine(task, taskMap),
+				ColorRed+"(due "+task.DueDate.Time.Format("2006-01-02")+")"+ColorReset)
+		}
+	}
+
+	// Untimed items come first, then the timed ones and the free time between them
+	type line struct {
+		start time.Time
+		text  string
+	}
+	var timed []line
+	untimed := 0
+	for _, item := range day.Items {
+		switch {
+		case item.Kind == services.AgendaTask:
+			var reasons []string
+			if item.Due {
+				reasons = append(reasons, "due")
+			}
+			if item.Scheduled {
+				reasons = append(reasons, "scheduled")
+			}
+			fmt.Printf("  %s %s\n", agendaTaskLine(*item.Task, taskMap),
+				ColorBrightBlack+"("+strings.Join(reasons, ", ")+")"+ColorReset)
+			untimed++
+		case !item.Timed:
+			fmt.Printf("  %-11s  %s\n", "All day", agendaEventTitle(item))
+			untimed++
+		case item.Kind == services.AgendaPomodoro:
+			timed = append(timed, line{item.Start, fmt.Sprintf("  %s  %s🍅 %s%s",
+				agendaSpan(item.Start, item.End, day.Date), ColorBrightBlack, item.Title, ColorReset)})
+		default:
+			timed = append(timed, line{item.Start, fmt.Sprintf("  %s  %s",
+				agendaSpan(item.Start, item.End, day.Date), agendaEventTitle(item))})
+		}
+	}
+	for _, span := range day.Free {
+		timed = append(timed, line{span.Start, fmt.Sprintf("  %s  %sfree %s%s",
+			agendaSpan(span.Start, span.End, day.Date), ColorGreen, formatAgendaDuration(span.Duration()), ColorReset)})
+	}
+	sort.SliceStable(timed, func(i, j int) bool {
+		return timed[i].start.Before(timed[j].start)
+	})
+	for _, l := range timed {
+		fmt.Println(l.text)
+	}
+
+	if untimed == 0 && len(timed) == 0 && len(day.Overdue) == 0 {
+		fmt.Println("  " + ColorBrightBlack + "Nothing planned" + ColorReset)
+	}
+
+	if len(day.Habits) > 0 {
+		var habits []string
+		for _, habit := range day.Habits {
+			check := ColorBrightBlack + "[ ]" + ColorReset
+			if habit.Done {
+				check = ColorGreen + "[✓]" + ColorReset
+			}
+			habits = append(habits, check+" "+habit.Habit.Name)
+		}
+		fmt.Printf("  Habits  %s\n", strings.Join(habits, "  "))
+	}
+}
+
+// agendaTaskLine formats a task with its number, status and priority. Tasks
+// shown more than once keep their number
+func agendaTaskLine(task sqlc.Task, taskMap map[int]int32) string {
+	index := 0
+	for i, id := range taskMap {
+		if id == task.ID {
+			index = i
+		}
+	}
+	if index == 0 {
+		index = len(taskMap) + 1
+		taskMap[index] = task.ID
+	}
+
+	status := ColorBrightBlack + "[ ]" + ColorReset
+	switch task.Status {
+	case "completed":
+		status = ColorGreen + "[✓]" + ColorReset
+	case "active":
+		status = ColorBlue + "[→]" + ColorReset
+	}
+
+	priority := ColorBrightBlack + "-" + ColorReset
+	switch task.Priority.String {
+	case "H":
+		priority = ColorRed + "H" + ColorReset
+	case "M":
+		priority = ColorYellow + "M" + ColorReset
+	case "L":
+		priority = ColorGreen + "L" + ColorReset
+	}
+
+	return fmt.Sprintf("%s%3d%s %s %s %s", TextBold, index, ColorReset, status, priority, task.Description)
+}
+
+func agendaEventTitle(item services.AgendaItem) string {
+	title := ColorCyan + item.Title + ColorReset
+	if item.Location != "" {
+		title += " @ " + item.Location
+	}
+	return title
+}
+
+// agendaSpan formats the times of a span on day, marking the ones on other days
+func agendaSpan(start, end, day time.Time) string {
+	format := func(t time.Time) string {
+		t = t.Local()
+		if t.Year() != day.Year() || t.YearDay() != day.YearDay() {
+			return t.Format("Jan 2 15:04")
+		}
+		return t.Format("15:04")
+	}
+	if end.Equal(start) {
+		return fmt.Sprintf("%-11s", format(start))
+	}
+	return fmt.Sprintf("%-11s", format(start)+"-"+format(end))
+}
+
+// formatAgendaDuration formats a duration in hours and minutes, like 2h30m
+func formatAgendaDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	h, m := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case h == 0:
+		return fmt.Sprintf("%dm", m)
+	case m == 0:
+		return fmt.Sprintf("%dh", h)
+	}
+	return fmt.Sprintf("%dh%02dm", h, m)
+}
+
+func init() {
+	rootCmd.AddCommand(agendaCmd)
+
+	agendaCmd.Flags().BoolVar(&agendaDay, "day", false, "Show a single day (default)")
+	agendaCmd.Flags().BoolVar(&agendaWeek, "week", false, "Show the week, Monday to Sunday")
+	agendaCmd.Flags().StringVar(&agendaDate, "date", "", "Day to show, or a day in the week to show (YYYY-MM-DD)")
+	agendaCmd.Flags().StringVar(&agendaHours, "hours", "", "Working hours to look for free time in, instead of the configured ones (e.g. 9-17)")
+	agendaCmd.MarkFlagsMutuallyExclusive("day", "week")
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgendaCommandStructure(t *testing.T) {
+	cmd := agendaCmd
+
+	// Check that the command exists
+	assert.NotNil(t, cmd)
+	assert.Equal(t, "agenda", cmd.Use)
+
+	// Check the flags
+	assert.NotNil(t, cmd.Flag("day"), "day flag should exist")
+	assert.NotNil(t, cmd.Flag("week"), "week flag should exist")
+	assert.NotNil(t, cmd.Flag("date"), "date flag should exist")
+	assert.NotNil(t, cmd.Flag("hours"), "hours flag should exist")
+}
+
+func TestFormatAgendaDuration(t *testing.T) {
+	assert.Equal(t, "45m", formatAgendaDuration(45*time.Minute))
+	assert.Equal(t, "2h", formatAgendaDuration(2*time.Hour))
+	assert.Equal(t, "2h05m", formatAgendaDuration(2*time.Hour+5*time.Minute))
+}
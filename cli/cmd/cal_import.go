@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var calImportDryRun bool
+
+var calImportCmd = &cobra.Command{
+	Use:   "import <file.ics>...",
+	Short: "Import events and to-dos from iCalendar (.ics) files",
+	Long: `Import meeting invites and other iCalendar files. Events become calendar
+events, including repeating events with their exceptions, all-day events and
+events in other time zones. To-dos become tasks with their due date, status,
+priority, categories as tags and repeat rule as recurrence.
+
+Events and to-dos are matched on their UID, so importing an updated invite
+changes the event instead of adding it twice, and importing a cancellation
+deletes the event. Imported tasks can be reverted with 'prod undo'.
+
+Examples:
+  prod cal import invite.ics             # Import a meeting invite
+  prod cal import *.ics --dry-run        # Report what would be imported`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var calendars []*services.ICalComponent
+		for _, path := range args {
+			file, err := os.Open(path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error opening %s: %v\n", path, err)
+				return
+			}
+			parsed, err := services.ParseICalendar(file)
+			file.Close()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", path, err)
+				return
+			}
+			calendars = append(calendars, parsed...)
+		}
+
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to import calendars")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		var report *services.ImportReport
+		uow := services.NewUnitOfWork(dbpool, queries)
+		err = uow.Do(context.Background(), func(tx *services.TxServices) error {
+			report, err = tx.ICal.Import(context.Background(), user.ID, calendars)
+			if err != nil {
+				return err
+			}
+			if calImportDryRun {
+				return errDryRun
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errDryRun) {
+			fmt.Fprintf(os.Stderr, "Error importing calendar: %v\n", err)
+			return
+		}
+
+		if calImportDryRun {
+			fmt.Println("Dry run, nothing was imported")
+		}
+		fmt.Printf("Events: %d created, %d updated, %d already present\n", report.EventsCreated, report.EventsUpdated, report.EventsSkipped)
+		if report.EventsDeleted > 0 {
+			fmt.Printf("Events cancelled: %d\n", report.EventsDeleted)
+		}
+		if report.TasksCreated+report.TasksUpdated+report.TasksSkipped+report.TasksTrashed > 0 {
+			fmt.Printf("Tasks: %d created, %d updated, %d already present\n", report.TasksCreated, report.TasksUpdated, report.TasksSkipped)
+		}
+		if report.TasksTrashed > 0 {
+			fmt.Printf("Tasks moved to the trash: %d\n", report.TasksTrashed)
+		}
+
+		printConflicts(report)
+	},
+}
+
+func init() {
+	calCmd.AddCommand(calImportCmd)
+
+	calImportCmd.Flags().BoolVar(&calImportDryRun, "dry-run", false, "Report what would be imported without changing anything")
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/jskallebak/prod/internal/db/sqlc"
 	"github.com/jskallebak/prod/internal/services"
 )
 
@@ -25,3 +26,16 @@ func ConfirmCmd(ctx context.Context, taskID, userID int32, action ActionType, ts
 	}
 	return nil
 }
+
+// unseenTasks leaves out the tasks already taken by an earlier ID of the same
+// command, like a subtask of a task given before it, and marks the rest seen
+func unseenTasks(seen map[int32]bool, tasks []sqlc.Task) []sqlc.Task {
+	var unseen []sqlc.Task
+	for _, task := range tasks {
+		if !seen[task.ID] {
+			seen[task.ID] = true
+			unseen = append(unseen, task)
+		}
+	}
+	return unseen
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFile   string
+	exportFormat string
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export all your data to a backup file",
+	Long: `Export your tasks with their hierarchy and dependencies, projects, pomodoro
+sessions, presets, settings, calendar events, habits and notes to a versioned
+JSON backup. Items in the trash are not exported. Load the backup with 'prod
+import'.
+
+With --format taskwarrior, write your tasks as JSON accepted by 'task import'
+instead. Tasks keep their UUID, so exporting again updates the same tasks in
+Taskwarrior. With --format todotxt, write one todo.txt line per task; see
+'prod sync todotxt' to keep a todo.txt file in sync.
+
+Available Commands:
+  ics          Export deadlines and calendar events as iCalendar
+
+Examples:
+  prod export --file backup.json                    # Write a backup file
+  prod export > backup.json                         # Write the backup to stdout
+  prod export --format taskwarrior | task import    # Copy tasks to Taskwarrior
+  prod export --format todotxt --file todo.txt      # Write a todo.txt file
+  prod export ics --file deadlines.ics              # Write an iCalendar file`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to export your data")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		var write func(w io.Writer) error
+		var summary string
+		switch exportFormat {
+		case "json":
+			backupService := services.NewBackupService(queries)
+			backup, err := backupService.Export(context.Background(), user.ID)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error exporting data: %v\n", err)
+				return
+			}
+			write = func(w io.Writer) error { return services.WriteBackup(w, backup) }
+			summary = fmt.Sprintf("%d task(s), %d project(s) and %d pomodoro session(s)",
+				len(backup.Tasks), len(backup.Projects), len(backup.PomodoroSessions))
+		case "taskwarrior":
+			taskwarriorService := services.NewTaskwarriorService(queries)
+			tasks, err := taskwarriorService.Export(context.Background(), user.ID)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error exporting tasks: %v\n", err)
+				return
+			}
+			write = func(w io.Writer) error { return services.WriteTaskwarrior(w, tasks) }
+			summary = fmt.Sprintf("%d task(s)", len(tasks))
+		case "todotxt":
+			todoTxtService := services.NewTodoTxtService(queries)
+			lines, err := todoTxtService.Export(context.Background(), user.ID)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error exporting tasks: %v\n", err)
+				return
+			}
+			write = func(w io.Writer) error {
+				for _, line := range lines {
+					if _, err := fmt.Fprintln(w, line); err != nil {
+						return fmt.Errorf("failed to write todo.txt: %w", err)
+					}
+				}
+				return nil
+			}
+			summary = fmt.Sprintf("%d task(s)", len(lines))
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown format %q, use json, taskwarrior or todotxt\n", exportFormat)
+			return
+		}
+
+		writeExport(write, summary)
+	},
+}
+
+// writeExport writes an export to --file, or to stdout without it
+func writeExport(write func(w io.Writer) error, summary string) {
+	if exportFile == "" {
+		if err := write(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		return
+	}
+
+	file, err := os.Create(exportFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating %s: %v\n", exportFile, err)
+		return
+	}
+	defer file.Close()
+
+	if err := write(file); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Exported %s to %s\n", summary, exportFile)
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.PersistentFlags().StringVar(&exportFile, "file", "", "File to write the export to (default stdout)")
+	exportCmd.Flags().StringVar(&exportFormat, "format", "json", "Export format (json, taskwarrior, todotxt)")
+}
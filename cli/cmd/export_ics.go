@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportICSFrom string
+	exportICSTo   string
+)
+
+var exportICSCmd = &cobra.Command{
+	Use:   "ics",
+	Short: "Export deadlines and calendar events as iCalendar",
+	Long: `Write an iCalendar (.ics) file that calendar apps can import. Tasks with a
+due date become to-dos with their status, priority, tags as categories and
+recurrence as a repeat rule, and calendar events become events.
+
+By default everything from 30 days ago until a year from now is exported.
+To subscribe to your deadlines instead of importing a file, see 'prod serve'.
+
+Examples:
+  prod export ics --file deadlines.ics                           # Write an .ics file
+  prod export ics --from 2025-06-01 --to 2025-06-30 > june.ics   # Export June`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		from, to := icalWindow(time.Now())
+		if exportICSFrom != "" {
+			date, err := util.ParseDate(exportICSFrom)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+			from = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+		}
+		if exportICSTo != "" {
+			date, err := util.ParseDate(exportICSTo)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+			// Include the whole day
+			to = time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 0, time.Local)
+		}
+		if to.Before(from) {
+			fmt.Fprintln(os.Stderr, "Error: --to is before --from")
+			return
+		}
+
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to export your calendar")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		icalService := services.NewICalService(queries)
+		cal, err := icalService.Export(context.Background(), user.ID, from, to)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error exporting calendar: %v\n", err)
+			return
+		}
+
+		var todos, events int
+		for _, component := range cal.Components {
+			switch component.Name {
+			case "VTODO":
+				todos++
+			case "VEVENT":
+				events++
+			}
+		}
+
+		writeExport(func(w io.Writer) error { return services.WriteICalendar(w, cal) },
+			fmt.Sprintf("%d task(s) and %d event(s)", todos, events))
+	},
+}
+
+// icalWindow returns the range of dates exported to calendars by default
+func icalWindow(now time.Time) (time.Time, time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	return today.AddDate(0, 0, -30), today.AddDate(1, 0, 0)
+}
+
+func init() {
+	exportCmd.AddCommand(exportICSCmd)
+
+	exportICSCmd.Flags().StringVar(&exportICSFrom, "from", "", "First day to export (YYYY-MM-DD)")
+	exportICSCmd.Flags().StringVar(&exportICSTo, "to", "", "Last day to export (YYYY-MM-DD)")
+}
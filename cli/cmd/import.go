@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importFile   string
+	importDryRun bool
+)
+
+// errDryRun rolls back the unit of work of a dry run
+var errDryRun = errors.New("dry run")
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import a backup file",
+	Long: `Import a backup written by 'prod export' into your account. Everything gets
+a new ID. Projects and habits are merged with existing ones of the same name,
+and tasks, sessions, calendar events and notes that were already imported are
+skipped, so importing the same file twice is safe. Presets, pomodoro config and
+goal are overwritten.
+
+Use --dry-run to see what would be imported and which conflicts were found
+without changing anything. An import can be reverted with 'prod undo'.
+
+Examples:
+  prod import --file backup.json             # Import a backup
+  prod import --file backup.json --dry-run   # Report what an import would do
+  task export | prod import taskwarrior      # Import from Taskwarrior`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		file, err := os.Open(importFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening %s: %v\n", importFile, err)
+			return
+		}
+		defer file.Close()
+
+		backup, err := services.ReadBackup(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return
+		}
+
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to import data")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		// A dry run imports for real and rolls back, so the report is exact
+		var report *services.ImportReport
+		uow := services.NewUnitOfWork(dbpool, queries)
+		err = uow.Do(context.Background(), func(tx *services.TxServices) error {
+			report, err = tx.Backup.Import(context.Background(), user.ID, backup)
+			if err != nil {
+				return err
+			}
+			if importDryRun {
+				return errDryRun
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errDryRun) {
+			fmt.Fprintf(os.Stderr, "Error importing backup: %v\n", err)
+			return
+		}
+
+		if importDryRun {
+			fmt.Println("Dry run, nothing was imported")
+		}
+		printImportReport(report)
+	},
+}
+
+func printImportReport(report *services.ImportReport) {
+	fmt.Printf("Projects: %d created, %d merged\n", report.ProjectsCreated, report.ProjectsMerged)
+	fmt.Printf("Tasks: %d created, %d updated, %d already present\n", report.TasksCreated, report.TasksUpdated, report.TasksSkipped)
+	if report.TasksTrashed > 0 {
+		fmt.Printf("Tasks moved to the trash: %d\n", report.TasksTrashed)
+	}
+	fmt.Printf("Dependencies: %d created\n", report.Dependencies)
+	if report.SessionsCreated+report.SessionsSkipped > 0 {
+		fmt.Printf("Pomodoro sessions: %d created, %d already present\n", report.SessionsCreated, report.SessionsSkipped)
+	}
+	if report.PresetsImported > 0 {
+		fmt.Printf("Presets: %d imported\n", report.PresetsImported)
+	}
+	if report.EventsCreated+report.EventsSkipped > 0 {
+		fmt.Printf("Calendar events: %d created, %d already present\n", report.EventsCreated, report.EventsSkipped)
+	}
+	if report.HabitsCreated+report.HabitsMerged > 0 {
+		fmt.Printf("Habits: %d created, %d merged, %d completions added\n", report.HabitsCreated, report.HabitsMerged, report.HabitsCompleted)
+	}
+	if report.NotesCreated+report.NotesSkipped > 0 {
+		fmt.Printf("Notes: %d created, %d already present\n", report.NotesCreated, report.NotesSkipped)
+	}
+
+	printConflicts(report)
+}
+
+func printConflicts(report *services.ImportReport) {
+	if len(report.Conflicts) > 0 {
+		fmt.Printf("\nConflicts (%d):\n", len(report.Conflicts))
+		for _, conflict := range report.Conflicts {
+			fmt.Printf("  - %s\n", conflict)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().StringVar(&importFile, "file", "", "Backup file to import")
+	importCmd.PersistentFlags().BoolVar(&importDryRun, "dry-run", false, "Report what would be imported without changing anything")
+	importCmd.MarkFlagRequired("file")
+}
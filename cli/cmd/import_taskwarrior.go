@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var taskwarriorFile string
+
+var importTaskwarriorCmd = &cobra.Command{
+	Use:   "taskwarrior",
+	Short: "Import tasks exported by Taskwarrior",
+	Long: `Import the output of 'task export' into prod. uuid, description, status,
+priority, due, wait, scheduled, project, tags, annotations, depends and recur
+are mapped to prod tasks, projects and dependencies. Annotations become the
+task notes.
+
+Tasks are matched on their UUID: importing again updates the tasks imported
+before, and tasks deleted in Taskwarrior are moved to the trash. Together with
+'prod export --format taskwarrior' this keeps both tools in sync during a
+transition. Pending instances of recurring tasks are skipped since prod
+creates the next instance itself.
+
+Examples:
+  task export | prod import taskwarrior             # Import all Taskwarrior tasks
+  prod import taskwarrior < task-export.json        # Import from a file
+  prod import taskwarrior --file tw.json --dry-run  # Report what would change`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var input io.Reader = os.Stdin
+		if taskwarriorFile != "" {
+			file, err := os.Open(taskwarriorFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error opening %s: %v\n", taskwarriorFile, err)
+				return
+			}
+			defer file.Close()
+			input = file
+		}
+
+		tasks, err := services.ReadTaskwarrior(input)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return
+		}
+		if len(tasks) == 0 {
+			fmt.Println("No tasks to import")
+			return
+		}
+
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to import tasks")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		var report *services.ImportReport
+		uow := services.NewUnitOfWork(dbpool, queries)
+		err = uow.Do(context.Background(), func(tx *services.TxServices) error {
+			report, err = tx.Taskwarrior.Import(context.Background(), user.ID, tasks)
+			if err != nil {
+				return err
+			}
+			if importDryRun {
+				return errDryRun
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errDryRun) {
+			fmt.Fprintf(os.Stderr, "Error importing tasks: %v\n", err)
+			return
+		}
+
+		if importDryRun {
+			fmt.Println("Dry run, nothing was imported")
+		}
+		printImportReport(report)
+	},
+}
+
+func init() {
+	importCmd.AddCommand(importTaskwarriorCmd)
+
+	importTaskwarriorCmd.Flags().StringVar(&taskwarriorFile, "file", "", "Taskwarrior export to read (default stdin)")
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var importTodoTxtCmd = &cobra.Command{
+	Use:   "todotxt <file>",
+	Short: "Import tasks from a todo.txt file",
+	Long: `Import the tasks of a todo.txt file. Priorities (A), (B) and (C) become H, M
+and L, +project assigns the task to a project, created by name when needed,
+@context becomes a tag, due: the due date, t: the start date, rec: the
+recurrence, and lines starting with x are completed.
+
+Lines exported by prod carry a prod: tag and update the task they came from.
+To keep a file and prod in sync both ways, use 'prod sync todotxt'.
+
+Examples:
+  prod import todotxt todo.txt             # Import a todo.txt file
+  prod import todotxt todo.txt --dry-run   # Report what would be imported`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		file, err := os.Open(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening %s: %v\n", args[0], err)
+			return
+		}
+		defer file.Close()
+
+		var items []services.TodoItem
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if item, ok := services.ParseTodoItem(scanner.Text()); ok {
+				items = append(items, item)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", args[0], err)
+			return
+		}
+		if len(items) == 0 {
+			fmt.Println("No tasks to import")
+			return
+		}
+
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to import tasks")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		var report *services.ImportReport
+		uow := services.NewUnitOfWork(dbpool, queries)
+		err = uow.Do(context.Background(), func(tx *services.TxServices) error {
+			report, err = tx.TodoTxt.Import(context.Background(), user.ID, items)
+			if err != nil {
+				return err
+			}
+			if importDryRun {
+				return errDryRun
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errDryRun) {
+			fmt.Fprintf(os.Stderr, "Error importing tasks: %v\n", err)
+			return
+		}
+
+		if importDryRun {
+			fmt.Println("Dry run, nothing was imported")
+		}
+		printImportReport(report)
+	},
+}
+
+func init() {
+	importCmd.AddCommand(importTodoTxtCmd)
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	nextCount int
+	nextAll   bool
+	nextStart bool
+	nextPomo  bool
+)
+
+var nextCmd = &cobra.Command{
+	Use:   "next",
+	Short: "Show the tasks to work on next",
+	Long: `Show the most urgent tasks you can work on now, each with why it is urgent.
+
+Tasks waiting for an open dependency or subtask, tasks hidden with 'prod task
+wait' and tasks scheduled after today are left out. With an active project
+only its tasks are considered, unless --all is given. See 'prod task urgency'
+for how urgency is computed.
+
+Tasks are numbered like in 'prod task list', so the numbers can be used with
+the other task commands.
+
+Examples:
+  prod next                   # The three most urgent tasks
+  prod next -n 1              # Only the most urgent task
+  prod next --start           # Start the most urgent task
+  prod next --start --pomo    # Start it together with a Pomodoro`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if nextCount < 1 {
+			fmt.Fprintf(os.Stderr, "Error: -n must be at least 1\n")
+			return
+		}
+		if nextPomo && !nextStart {
+			fmt.Fprintf(os.Stderr, "Error: --pomo starts a Pomodoro with --start\n")
+			return
+		}
+
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to see your next tasks")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		var projectID *int32
+		if !nextAll {
+			userService := services.NewUserService(queries)
+			if project, err := userService.GetActiveProject(context.Background(), user.ID); err == nil {
+				projectID = &project.ID
+			}
+		}
+
+		urgencyService := services.NewUrgencyService(queries)
+		next, err := urgencyService.Next(context.Background(), user.ID, projectID, nextCount, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error finding next tasks: %v\n", err)
+			return
+		}
+
+		taskMap := make(map[int]int32)
+		if len(next) == 0 {
+			fmt.Println("Nothing to do right now")
+		}
+		for _, task := range next {
+			fmt.Printf("%s  %s%s%s\n", agendaTaskLine(task.Task, taskMap), ColorBrightBlack, task.Reason, ColorReset)
+		}
+		if err := services.MakeTaskMapFile(taskMap); err != nil {
+			fmt.Fprintf(os.Stderr, "Error making task map file: %v\n", err)
+		}
+
+		if !nextStart || len(next) == 0 {
+			return
+		}
+		fmt.Println()
+		startNextTask(dbpool, queries, user.ID, next[0].Task)
+	},
+}
+
+// startNextTask starts a task, or with --pomo a Pomodoro linked to it, which
+// also marks it active
+func startNextTask(dbpool services.TxBeginner, queries *sqlc.Queries, userID int32, next sqlc.Task) {
+	taskID := next.ID
+	if !nextPomo {
+		if next.Status == "active" {
+			fmt.Printf("Already started: %s\n", next.Description)
+			return
+		}
+		var task *sqlc.Task
+		err := services.NewUnitOfWork(dbpool, queries).Do(context.Background(), func(tx *services.TxServices) error {
+			var err error
+			task, err = tx.Tasks.StartTask(context.Background(), taskID, userID)
+			return err
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error starting task: %v\n", err)
+			return
+		}
+		fmt.Printf("Started: %s\n", task.Description)
+		return
+	}
+
+	pomoService := services.NewPomodoroService(queries)
+	if session, err := pomoService.GetActiveSession(context.Background(), userID); err == nil && session != nil {
+		fmt.Println("You already have an active Pomodoro session")
+		fmt.Println("Use 'prod pomo stop' to stop it before starting a new one")
+		return
+	}
+
+	preset, err := pomoService.GetTaskPreset(context.Background(), userID, taskID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return
+	}
+	workDuration, breakDuration, _ := pomodoroDurations(pomoService, userID, preset, 0, nil)
+
+	var session *services.PomodoroSession
+	var task *sqlc.Task
+	uow := services.NewUnitOfWork(dbpool, queries)
+	err = uow.Do(context.Background(), func(tx *services.TxServices) error {
+		session, task, err = tx.Pomodoros.StartSessionWithTask(
+			context.Background(),
+			userID,
+			taskID,
+			time.Duration(workDuration)*time.Minute,
+			time.Duration(breakDuration)*time.Minute,
+			"",
+		)
+		return err
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error starting Pomodoro session: %v\n", err)
+		return
+	}
+
+	savePomodoroState(queries, userID, session)
+
+	endTime := session.StartTime.Time.Add(time.Duration(workDuration) * time.Minute)
+	fmt.Printf("Started: %s\n", task.Description)
+	fmt.Printf("🍅 Pomodoro session started, work until %s\n", endTime.Format("15:04"))
+}
+
+func init() {
+	rootCmd.AddCommand(nextCmd)
+
+	nextCmd.Flags().IntVarP(&nextCount, "count", "n", 3, "Number of tasks to show")
+	nextCmd.Flags().BoolVarP(&nextAll, "all", "a", false, "Consider tasks of all projects, not only the active one")
+	nextCmd.Flags().BoolVar(&nextStart, "start", false, "Start the most urgent task")
+	nextCmd.Flags().BoolVar(&nextPomo, "pomo", false, "With --start, also start a Pomodoro for the task")
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextCommandStructure(t *testing.T) {
+	assert.Equal(t, "next", nextCmd.Use)
+
+	// Check the flags
+	countFlag := nextCmd.Flag("count")
+	assert.NotNil(t, countFlag, "count flag should exist")
+	assert.Equal(t, "n", countFlag.Shorthand)
+	assert.Equal(t, "3", countFlag.DefValue)
+	assert.NotNil(t, nextCmd.Flag("all"), "all flag should exist")
+	assert.NotNil(t, nextCmd.Flag("start"), "start flag should exist")
+	assert.NotNil(t, nextCmd.Flag("pomo"), "pomo flag should exist")
+}
@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	planDate   string
+	planHours  string
+	planYes    bool
+	planDryRun bool
+)
+
+// planCmd represents the plan command
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Plan open tasks into the free time in your calendar",
+	Long: `Place your open tasks in the gaps between calendar events, within your
+working hours, and add them to your calendar as blocks linked to the tasks.
+
+Tasks are taken by due date, then priority, then estimate. Each task gets its
+estimate less the Pomodoros already spent on it, or a single Pomodoro when it
+has no estimate. The proposed schedule is shown first and only written to the
+calendar once confirmed. Planning again replaces the blocks that haven't
+started yet, so a plan can be redone whenever things change.
+
+Available Commands:
+  day         Plan today or another day
+  week        Plan the week, Monday to Sunday
+  config      Show and set your working hours and days`,
+}
+
+// runPlan proposes a plan for a day or, with week, the week of --date, and
+// writes it to the calendar once confirmed
+func runPlan(week bool) {
+	now := time.Now()
+	from := now
+	if planDate != "" {
+		date, err := util.ParseDate(planDate)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return
+		}
+		from = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+	}
+
+	days := 1
+	if week {
+		from = util.StartOfWeek(from)
+		days = 7
+	}
+
+	dbpool, queries, ok := util.InitDBAndQueriesCLI()
+	if !ok {
+		return
+	}
+	defer dbpool.Close()
+
+	// Get authenticated user
+	authService := services.NewAuthService(queries)
+	user, err := authService.GetCurrentUser(context.Background())
+	if err != nil {
+		fmt.Println("You need to be logged in to plan your tasks")
+		fmt.Println("Use 'prod login' to authenticate")
+		return
+	}
+
+	plannerService := services.NewPlannerService(queries)
+	config, err := plannerService.GetConfig(context.Background(), user.ID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error retrieving working hours: %v\n", err)
+		return
+	}
+	if planHours != "" {
+		if config.Hours, err = services.ParseWorkingHours(planHours); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return
+		}
+	}
+
+	plan, err := plannerService.Plan(context.Background(), user.ID, from, days, *config, now)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error planning tasks: %v\n", err)
+		return
+	}
+
+	taskMap := make(map[int]int32)
+	printPlan(plan, *config, now, taskMap)
+	if err := services.MakeTaskMapFile(taskMap); err != nil {
+		fmt.Fprintf(os.Stderr, "Error making task map file: %v\n", err)
+	}
+
+	if len(plan.Blocks) == 0 && len(plan.Replaced) == 0 || planDryRun {
+		return
+	}
+
+	if !planYes {
+		fmt.Println()
+		switch {
+		case len(plan.Replaced) > 0 && len(plan.Blocks) > 0:
+			fmt.Printf("Replace %d planned block(s) with these %d? (y/N): ", len(plan.Replaced), len(plan.Blocks))
+		case len(plan.Replaced) > 0:
+			fmt.Printf("Remove %d planned block(s) that are no longer needed? (y/N): ", len(plan.Replaced))
+		default:
+			fmt.Printf("Add these %d block(s) to your calendar? (y/N): ", len(plan.Blocks))
+		}
+		var answer string
+		fmt.Scanln(&answer)
+
+		if answer != "y" && answer != "Y" {
+			fmt.Println("Operation cancelled")
+			return
+		}
+	}
+
+	uow := services.NewUnitOfWork(dbpool, queries)
+	err = uow.Do(context.Background(), func(tx *services.TxServices) error {
+		return tx.Planner.Apply(context.Background(), user.ID, plan)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing plan: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Planned %d block(s)\n", len(plan.Blocks))
+}
+
+// printPlan prints the proposed blocks of each day from today on between the
+// events they were planned around, followed by the tasks there was no room for
+func printPlan(plan *services.Plan, config services.PlannerConfig, now time.Time, taskMap map[int]int32) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	printed := false
+	for day := plan.From; day.Before(plan.To); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+		if day.Before(today) {
+			continue
+		}
+
+		type line struct {
+			start time.Time
+			text  string
+		}
+		var lines []line
+		var planned time.Duration
+		for _, block := range plan.Blocks {
+			if !block.Start.Before(day) && block.Start.Before(next) {
+				duration := block.End.Sub(block.Start)
+				planned += duration
+				lines = append(lines, line{block.Start, fmt.Sprintf("  %s  %s %s",
+					agendaSpan(block.Start, block.End, day), agendaTaskLine(block.Task, taskMap),
+					ColorBrightBlack+"("+formatAgendaDuration(duration)+")"+ColorReset)})
+			}
+		}
+		if len(lines) == 0 && !config.Works(day) {
+			continue
+		}
+		for _, occurrence := range plan.Events {
+			if occurrence.Start.Before(next) && occurrence.End.After(day) {
+				lines = append(lines, line{occurrence.Start, fmt.Sprintf("  %s  %s%s%s",
+					agendaSpan(occurrence.Start, occurrence.End, day), ColorBrightBlack, occurrence.Event.Title, ColorReset)})
+			}
+		}
+		sort.SliceStable(lines, func(i, j int) bool {
+			return lines[i].start.Before(lines[j].start)
+		})
+
+		if printed {
+			fmt.Println()
+		}
+		printed = true
+		header := TextBold + day.Format("Monday, 2 January 2006") + ColorReset
+		switch {
+		case day.Equal(today):
+			header += " (today)"
+		case day.Equal(today.AddDate(0, 0, 1)):
+			header += " (tomorrow)"
+		}
+		if planned > 0 {
+			header += ColorGreen + " · " + formatAgendaDuration(planned) + " planned" + ColorReset
+		}
+		fmt.Println(header)
+		for _, l := range lines {
+			fmt.Println(l.text)
+		}
+		if planned == 0 {
+			fmt.Println("  " + ColorBrightBlack + "No tasks planned" + ColorReset)
+		}
+	}
+
+	if !printed {
+		fmt.Println("There are no work days left to plan")
+	}
+
+	if len(plan.Unplanned) > 0 {
+		fmt.Println()
+		fmt.Println(ColorYellow + "No room for" + ColorReset)
+		for _, task := range plan.Unplanned {
+			fmt.Printf("  %s %s\n", agendaTaskLine(task.Task, taskMap),
+				ColorBrightBlack+"("+formatAgendaDuration(task.Remaining)+" left)"+ColorReset)
+		}
+	}
+}
+
+// addPlanFlags adds the flags shared by plan day and plan week
+func addPlanFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&planHours, "hours", "", "Working hours to plan in, instead of the configured ones (e.g. 9-17)")
+	cmd.Flags().BoolVarP(&planYes, "yes", "y", false, "Write the plan without asking")
+	cmd.Flags().BoolVar(&planDryRun, "dry-run", false, "Only show the proposed plan")
+	cmd.MarkFlagsMutuallyExclusive("yes", "dry-run")
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanCommandStructure(t *testing.T) {
+	// Check that the subcommands exist
+	assert.Equal(t, "plan", planCmd.Use)
+	assert.Equal(t, "day", planDayCmd.Use)
+	assert.Equal(t, "week", planWeekCmd.Use)
+	assert.Equal(t, "config", planConfigCmd.Use)
+
+	// Check the flags
+	for _, cmd := range []*cobra.Command{planDayCmd, planWeekCmd} {
+		assert.NotNil(t, cmd.Flag("date"), "date flag should exist")
+		assert.NotNil(t, cmd.Flag("hours"), "hours flag should exist")
+		assert.NotNil(t, cmd.Flag("yes"), "yes flag should exist")
+		assert.NotNil(t, cmd.Flag("dry-run"), "dry-run flag should exist")
+	}
+	assert.NotNil(t, planConfigSetCmd.Flag("hours"), "hours flag should exist")
+	assert.NotNil(t, planConfigSetCmd.Flag("days"), "days flag should exist")
+}
+
+func TestFormatWorkingHours(t *testing.T) {
+	assert.Equal(t, "09:00-17:00", formatWorkingHours(services.DefaultWorkingHours))
+	assert.Equal(t, "08:30-16:45", formatWorkingHours(services.WorkingHours{
+		Start: 8*time.Hour + 30*time.Minute,
+		End:   16*time.Hour + 45*time.Minute,
+	}))
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	planConfigHours string
+	planConfigDays  string
+)
+
+var planConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show your working hours and days",
+	Long: `Show the working hours and days tasks are planned in. The agenda looks for
+free time within the same hours.
+
+Examples:
+  prod plan config                                  # Show working hours and days
+  prod plan config set --hours 8-16                 # Work from 8 to 16
+  prod plan config set --hours 08:30-17 --days mon-thu`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to view your working hours")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		plannerService := services.NewPlannerService(queries)
+		config, err := plannerService.GetConfig(context.Background(), user.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error retrieving working hours: %v\n", err)
+			return
+		}
+
+		printPlanConfig(config)
+	},
+}
+
+var planConfigSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set your working hours and days",
+	Long: `Set the hours of the day and the weekdays tasks are planned in.
+
+Examples:
+  prod plan config set --hours 9-17
+  prod plan config set --days mon-fri
+  prod plan config set --hours 07:30-15:30 --days mon,tue,thu,fri`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !cmd.Flags().Changed("hours") && !cmd.Flags().Changed("days") {
+			fmt.Println("Specify --hours and/or --days")
+			return
+		}
+
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to set your working hours")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		plannerService := services.NewPlannerService(queries)
+		config, err := plannerService.GetConfig(context.Background(), user.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error retrieving working hours: %v\n", err)
+			return
+		}
+
+		if cmd.Flags().Changed("hours") {
+			if config.Hours, err = services.ParseWorkingHours(planConfigHours); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+		}
+		if cmd.Flags().Changed("days") {
+			if config.Days, err = services.ParseWorkDays(planConfigDays); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+		}
+
+		config, err = plannerService.SetConfig(context.Background(), user.ID, *config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error setting working hours: %v\n", err)
+			return
+		}
+
+		fmt.Println("Working hours updated successfully!")
+		printPlanConfig(config)
+	},
+}
+
+func printPlanConfig(config *services.PlannerConfig) {
+	fmt.Printf("Working hours: %s\n", formatWorkingHours(config.Hours))
+	fmt.Printf("Work days:     %s\n", services.FormatWorkDays(config.Days))
+}
+
+// formatWorkingHours formats working hours like 09:00-17:00
+func formatWorkingHours(hours services.WorkingHours) string {
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return clock(hours.Start) + "-" + clock(hours.End)
+}
+
+func init() {
+	planCmd.AddCommand(planConfigCmd)
+	planConfigCmd.AddCommand(planConfigSetCmd)
+
+	planConfigSetCmd.Flags().StringVar(&planConfigHours, "hours", "", "Working hours, like 9-17 or 08:30-16:30")
+	planConfigSetCmd.Flags().StringVar(&planConfigDays, "days", "", "Work days, like mon-fri or mon,wed,fri")
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var planDayCmd = &cobra.Command{
+	Use:   "day",
+	Short: "Plan today or another day",
+	Long: `Propose blocks of time for your open tasks in the free time of a day, between
+its calendar events and within your working hours. Once confirmed, the blocks
+are added to your calendar, linked to their tasks.
+
+Running it again replaces the blocks that haven't started yet.
+
+Examples:
+  prod plan day                      # Plan the rest of today
+  prod plan day --date 2025-06-02    # Plan a given day
+  prod plan day --hours 13-17        # Plan this afternoon only
+  prod plan day --dry-run            # Only show the proposal`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runPlan(false)
+	},
+}
+
+func init() {
+	planCmd.AddCommand(planDayCmd)
+
+	planDayCmd.Flags().StringVar(&planDate, "date", "", "Day to plan (YYYY-MM-DD)")
+	addPlanFlags(planDayCmd)
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var planWeekCmd = &cobra.Command{
+	Use:   "week",
+	Short: "Plan the week, Monday to Sunday",
+	Long: `Propose blocks of time for your open tasks in the free time of the week, from
+now until Sunday, on your work days only. Once confirmed, the blocks are added
+to your calendar, linked to their tasks.
+
+Running it again replaces the blocks that haven't started yet, so the rest of
+the week can be replanned at any time.
+
+Examples:
+  prod plan week                     # Plan the rest of this week
+  prod plan week --date 2025-06-09   # Plan the week of a given day
+  prod plan week -y                  # Write the plan without asking`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runPlan(true)
+	},
+}
+
+func init() {
+	planCmd.AddCommand(planWeekCmd)
+
+	planWeekCmd.Flags().StringVar(&planDate, "date", "", "A day in the week to plan (YYYY-MM-DD)")
+	addPlanFlags(planWeekCmd)
+}
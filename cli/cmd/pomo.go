@@ -21,8 +21,14 @@ Available Commands:
   status      Show the status of the current Pomodoro session
   stats       Show Pomodoro statistics
   list        List completed Pomodoro sessions
+  log         Record a Pomodoro session done in the past
+  edit        Edit a Pomodoro session
+  delete      Delete a Pomodoro session
   report      Generate reports on Pomodoro usage
   config      Configure Pomodoro settings
+  goal        Show and set daily and weekly Pomodoro goals
+  preset      Manage named work/break presets
+  accuracy    Compare estimated and actual Pomodoros per task
   attach      Attach a task to the current Pomodoro session
   detach      Remove task attachment from current Pomodoro`,
 }
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var accuracyCmd = &cobra.Command{
+	Use:   "accuracy",
+	Short: "Compare estimated and actual Pomodoros",
+	Long: `Show how well your task estimates match the Pomodoros you actually spent.
+
+Only completed tasks with an estimate are included. Estimates are converted
+to Pomodoros using your configured work duration. A ratio above 1.00 means
+tasks took more Pomodoros than estimated.
+
+Examples:
+  prod pomo accuracy`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to view estimate accuracy")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		pomoService := services.NewPomodoroService(queries)
+		accuracy, err := pomoService.GetEstimateAccuracy(context.Background(), user.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error generating accuracy report: %v\n", err)
+			return
+		}
+
+		if accuracy.Overall.Tasks == 0 {
+			fmt.Println("No completed tasks with estimates found")
+			fmt.Println("Add an estimate with: prod task add \"My task\" --est 4p")
+			return
+		}
+
+		fmt.Println("Estimate Accuracy")
+		fmt.Println("=================")
+		fmt.Printf("Pomodoro length: %d min\n", accuracy.PomodoroMinutes)
+		fmt.Println()
+
+		printAccuracyTable("Overall", []services.AccuracyStat{accuracy.Overall})
+		printAccuracyTable("By Project", accuracy.ByProject)
+		printAccuracyTable("By Priority", accuracy.ByPriority)
+	},
+}
+
+func printAccuracyTable(title string, stats []services.AccuracyStat) {
+	fmt.Printf("%s:\n", title)
+	fmt.Printf("  %-20s %6s %10s %8s %7s %6s %6s\n", "Group", "Tasks", "Estimated", "Actual", "Ratio", "Over", "Under")
+	for _, stat := range stats {
+		key := stat.Key
+		if runes := []rune(key); len(runes) > 20 {
+			key = string(runes[:17]) + "..."
+		}
+		fmt.Printf("  %-20s %6d %10s %8d %7.2f %6d %6d\n",
+			key,
+			stat.Tasks,
+			formatPomodoroCount(stat.EstimatedPomodoros),
+			stat.ActualPomodoros,
+			stat.Ratio,
+			stat.Over,
+			stat.Under)
+	}
+	fmt.Println()
+}
+
+func init() {
+	pomoCmd.AddCommand(accuracyCmd)
+}
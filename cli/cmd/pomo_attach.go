@@ -74,6 +74,8 @@ Examples:
 			return
 		}
 
+		syncPomodoroState(queries, user.ID)
+
 		fmt.Printf("📎 Task '%s' (ID: %d) attached to the current Pomodoro session\n",
 			task.Description, task.ID)
 
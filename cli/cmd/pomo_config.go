@@ -17,6 +17,7 @@ var (
 	configLongBreakInterval int
 	configAutoStartBreaks   bool
 	configAutoStartPomos    bool
+	configLinkTasks         bool
 )
 
 var configCmd = &cobra.Command{
@@ -28,7 +29,11 @@ Examples:
   prod pomo config                         # Show current configuration
   prod pomo config --work 25 --break 5     # Set work and break durations
   prod pomo config --long-break 15         # Set long break duration
-  prod pomo config --auto-breaks           # Enable automatic break start`,
+  prod pomo config --auto-breaks           # Enable automatic break start
+  prod pomo config --link-tasks            # Start, pause and complete tasks with their sessions
+
+With --link-tasks, 'prod pomo start <task>' marks the task active, 'prod pomo stop'
+pauses it again and 'prod task done' offers to stop a session running on the task.`,
 
 	Run: func(cmd *cobra.Command, args []string) {
 		dbpool, queries, ok := util.InitDBAndQueriesCLI()
@@ -61,6 +66,7 @@ Examples:
 				LongBreakInterval:  4,
 				AutoStartBreaks:    false,
 				AutoStartPomodoros: false,
+				LinkTasks:          false,
 			}
 		}
 
@@ -71,9 +77,10 @@ Examples:
 		intervalFlag := cmd.Flags().Changed("interval")
 		autoBreaksFlag := cmd.Flags().Changed("auto-breaks")
 		autoPomoFlag := cmd.Flags().Changed("auto-pomos")
+		linkTasksFlag := cmd.Flags().Changed("link-tasks")
 
 		// If no flags were provided, just show current settings
-		if !workFlag && !breakFlag && !longBreakFlag && !intervalFlag && !autoBreaksFlag && !autoPomoFlag {
+		if !workFlag && !breakFlag && !longBreakFlag && !intervalFlag && !autoBreaksFlag && !autoPomoFlag && !linkTasksFlag {
 			showConfig(currentConfig)
 			return
 		}
@@ -119,6 +126,10 @@ Examples:
 			currentConfig.AutoStartPomodoros = configAutoStartPomos
 		}
 
+		if linkTasksFlag {
+			currentConfig.LinkTasks = configLinkTasks
+		}
+
 		// Save the updated configuration
 		updatedConfig, err := pomoService.UpdateUserConfig(
 			context.Background(),
@@ -129,6 +140,7 @@ Examples:
 			currentConfig.LongBreakInterval,
 			currentConfig.AutoStartBreaks,
 			currentConfig.AutoStartPomodoros,
+			currentConfig.LinkTasks,
 		)
 		if err != nil {
 			fmt.Printf("Error updating Pomodoro configuration: %v\n", err)
@@ -148,6 +160,7 @@ func showConfig(config *services.PomodoroConfig) {
 	fmt.Printf("Long Break Interval:  Every %d pomodoros\n", config.LongBreakInterval)
 	fmt.Printf("Auto-start Breaks:    %s\n", strconv.FormatBool(config.AutoStartBreaks))
 	fmt.Printf("Auto-start Pomodoros: %s\n", strconv.FormatBool(config.AutoStartPomodoros))
+	fmt.Printf("Link Tasks:           %s\n", strconv.FormatBool(config.LinkTasks))
 }
 
 func init() {
@@ -160,4 +173,5 @@ func init() {
 	configCmd.Flags().IntVar(&configLongBreakInterval, "interval", 0, "Number of pomodoros before a long break")
 	configCmd.Flags().BoolVar(&configAutoStartBreaks, "auto-breaks", false, "Automatically start breaks after work sessions")
 	configCmd.Flags().BoolVar(&configAutoStartPomos, "auto-pomos", false, "Automatically start next pomodoro after breaks")
+	configCmd.Flags().BoolVar(&configLinkTasks, "link-tasks", false, "Start, pause and complete tasks together with their Pomodoro sessions")
 }
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var confirmPomoDelete bool
+
+var pomoDeleteCmd = &cobra.Command{
+	Use:   "delete [session-id]",
+	Short: "Delete a Pomodoro session",
+	Long: `Delete a Pomodoro session, for example one started by mistake.
+
+Session IDs are shown by 'prod pomo list'.
+
+Examples:
+  prod pomo delete 42         # Prompts for confirmation
+  prod pomo delete 42 --yes   # Deletes without confirmation`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sessionID, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid session ID: %v\n", err)
+			return
+		}
+
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to delete Pomodoro sessions")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		pomoService := services.NewPomodoroService(queries)
+		session, err := pomoService.GetSession(context.Background(), user.ID, int32(sessionID))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return
+		}
+
+		if !confirmPomoDelete {
+			fmt.Printf("You are about to delete Pomodoro session %d (%s, %s)\n",
+				session.ID,
+				session.StartTime.Time.Format("2006-01-02 15:04"),
+				session.Status)
+			fmt.Print("Are you sure? (y/N): ")
+			var confirmation string
+			fmt.Scanln(&confirmation)
+			if confirmation != "y" && confirmation != "Y" {
+				fmt.Println("Delete cancelled")
+				return
+			}
+		}
+
+		if err := pomoService.DeleteSession(context.Background(), user.ID, session.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "Error deleting Pomodoro session: %v\n", err)
+			return
+		}
+
+		syncPomodoroState(queries, user.ID)
+
+		fmt.Printf("Pomodoro session %d deleted\n", session.ID)
+	},
+}
+
+func init() {
+	pomoCmd.AddCommand(pomoDeleteCmd)
+
+	pomoDeleteCmd.Flags().BoolVar(&confirmPomoDelete, "yes", false, "Delete without confirmation")
+}
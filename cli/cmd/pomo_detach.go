@@ -62,6 +62,8 @@ Examples:
 			return
 		}
 
+		syncPomodoroState(queries, user.ID)
+
 		fmt.Printf("Task '%s' (ID: %d) detached from the current Pomodoro session\n",
 			task.Description, task.ID)
 
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pomoEditTask   int
+	pomoEditNoTask bool
+	pomoEditNote   string
+	pomoEditStart  string
+	pomoEditEnd    string
+	pomoEditStatus string
+)
+
+var pomoEditCmd = &cobra.Command{
+	Use:   "edit [session-id]",
+	Short: "Edit a Pomodoro session",
+	Long: `Change the task, note, times or status of a Pomodoro session.
+
+Session IDs are shown by 'prod pomo list'. New times must not overlap
+any of your other sessions. Times and status can only be changed on
+sessions that have been stopped.
+
+Examples:
+  prod pomo edit 42 --task 12                  # Attach session 42 to task 12
+  prod pomo edit 42 --no-task                  # Remove the task from session 42
+  prod pomo edit 42 --start "today 09:10" --end "today 09:35"
+  prod pomo edit 42 --status cancelled
+  prod pomo edit 42 --note "Actually worked on the report"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sessionID, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid session ID: %v\n", err)
+			return
+		}
+
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to edit Pomodoro sessions")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		var params services.SessionParams
+
+		if cmd.Flags().Changed("task") {
+			// Verify the task exists and belongs to the user
+			taskService := services.NewTaskService(queries)
+			_, err = taskService.GetTask(context.Background(), int32(pomoEditTask), user.ID)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+
+			id := int32(pomoEditTask)
+			params.TaskID = &id
+		}
+		params.ClearTask = pomoEditNoTask
+
+		if cmd.Flags().Changed("note") {
+			params.Note = &pomoEditNote
+		}
+
+		if pomoEditStart != "" {
+			start, err := util.ParseDateTime(pomoEditStart)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --start value: %v\n", err)
+				return
+			}
+			params.StartTime = &start
+		}
+
+		if pomoEditEnd != "" {
+			end, err := util.ParseDateTime(pomoEditEnd)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --end value: %v\n", err)
+				return
+			}
+			params.EndTime = &end
+		}
+
+		if pomoEditStatus != "" {
+			status := services.PomodoroStatus(pomoEditStatus)
+			params.Status = &status
+		}
+
+		if params == (services.SessionParams{}) {
+			fmt.Println("Nothing to change. See 'prod pomo edit --help' for the available flags")
+			return
+		}
+
+		// The overlap check and the update share a transaction
+		var session *services.PomodoroSession
+		err = services.NewUnitOfWork(dbpool, queries).Do(context.Background(), func(tx *services.TxServices) error {
+			session, err = tx.Pomodoros.UpdateSession(context.Background(), user.ID, int32(sessionID), params)
+			return err
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error updating Pomodoro session: %v\n", err)
+			return
+		}
+
+		syncPomodoroState(queries, user.ID)
+
+		fmt.Printf("Pomodoro session %d updated\n", session.ID)
+		fmt.Printf("Start:  %s\n", session.StartTime.Time.Format("2006-01-02 15:04"))
+		if session.EndTime.Valid {
+			fmt.Printf("End:    %s\n", session.EndTime.Time.Format("2006-01-02 15:04"))
+		}
+		fmt.Printf("Status: %s\n", session.Status)
+		if session.TaskID != nil {
+			fmt.Printf("Task:   %d\n", *session.TaskID)
+		}
+		if session.Note != "" {
+			fmt.Printf("Note:   %s\n", session.Note)
+		}
+	},
+}
+
+func init() {
+	pomoCmd.AddCommand(pomoEditCmd)
+
+	// Add flags
+	pomoEditCmd.Flags().IntVar(&pomoEditTask, "task", 0, "Attach the session to this task ID")
+	pomoEditCmd.Flags().BoolVar(&pomoEditNoTask, "no-task", false, "Remove the task from the session")
+	pomoEditCmd.Flags().StringVar(&pomoEditNote, "note", "", "New note (empty string clears it)")
+	pomoEditCmd.Flags().StringVar(&pomoEditStart, "start", "", "New start time (\"YYYY-MM-DD HH:MM\", \"today HH:MM\", ...)")
+	pomoEditCmd.Flags().StringVar(&pomoEditEnd, "end", "", "New end time (\"YYYY-MM-DD HH:MM\", \"today HH:MM\", ...)")
+	pomoEditCmd.Flags().StringVar(&pomoEditStatus, "status", "", "New status (completed, cancelled)")
+	pomoEditCmd.MarkFlagsMutuallyExclusive("task", "no-task")
+}
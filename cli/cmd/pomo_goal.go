@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	goalDaily  int
+	goalWeekly int
+)
+
+var goalCmd = &cobra.Command{
+	Use:   "goal",
+	Short: "Show your Pomodoro goals",
+	Long: `Show your daily and weekly Pomodoro goals and today's progress.
+
+Examples:
+  prod pomo goal                           # Show goals and progress
+  prod pomo goal set --daily 8 --weekly 35 # Set both goals
+  prod pomo goal set --daily 0             # Remove the daily goal`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to view Pomodoro goals")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		pomoService := services.NewPomodoroService(queries)
+		progress, err := pomoService.GetGoalProgress(context.Background(), user.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error retrieving Pomodoro goals: %v\n", err)
+			return
+		}
+
+		if progress.Goal.DailyGoal == 0 && progress.Goal.WeeklyGoal == 0 {
+			fmt.Println("You have not set any Pomodoro goals")
+			fmt.Println("Use 'prod pomo goal set --daily 8 --weekly 35' to set them")
+			return
+		}
+
+		printGoalProgress(progress)
+	},
+}
+
+var goalSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set your daily and weekly Pomodoro goals",
+	Long: `Set the number of completed Pomodoros you aim for each day and week.
+A goal of 0 removes it.
+
+Examples:
+  prod pomo goal set --daily 8 --weekly 35
+  prod pomo goal set --weekly 40`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !cmd.Flags().Changed("daily") && !cmd.Flags().Changed("weekly") {
+			fmt.Println("Specify --daily and/or --weekly")
+			return
+		}
+
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to set Pomodoro goals")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		pomoService := services.NewPomodoroService(queries)
+		current, err := pomoService.GetGoal(context.Background(), user.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error retrieving Pomodoro goals: %v\n", err)
+			return
+		}
+
+		daily := current.DailyGoal
+		if cmd.Flags().Changed("daily") {
+			daily = int32(goalDaily)
+		}
+
+		weekly := current.WeeklyGoal
+		if cmd.Flags().Changed("weekly") {
+			weekly = int32(goalWeekly)
+		}
+
+		goal, err := pomoService.SetGoal(context.Background(), user.ID, daily, weekly)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error setting Pomodoro goals: %v\n", err)
+			return
+		}
+
+		fmt.Println("Goals updated successfully!")
+		fmt.Printf("Daily goal:  %s\n", formatGoal(goal.DailyGoal))
+		fmt.Printf("Weekly goal: %s\n", formatGoal(goal.WeeklyGoal))
+	},
+}
+
+// printGoalProgress prints today's and this week's progress towards the user's goals
+func printGoalProgress(progress *services.GoalProgress) {
+	if progress.Goal.DailyGoal > 0 {
+		fmt.Printf("Today's goal: %d/%d pomodoros", progress.Today, progress.Goal.DailyGoal)
+		if progress.Today >= int(progress.Goal.DailyGoal) {
+			fmt.Print(" ✓")
+		}
+		fmt.Println()
+		renderProgressBar(goalRatio(progress.Today, progress.Goal.DailyGoal), 25)
+	}
+
+	if progress.Goal.WeeklyGoal > 0 {
+		fmt.Printf("This week: %d/%d pomodoros", progress.ThisWeek, progress.Goal.WeeklyGoal)
+		if progress.ThisWeek >= int(progress.Goal.WeeklyGoal) {
+			fmt.Print(" ✓")
+		}
+		fmt.Println()
+	}
+}
+
+func goalRatio(done int, goal int32) float64 {
+	if goal <= 0 {
+		return 0
+	}
+	ratio := float64(done) / float64(goal)
+	if ratio > 1.0 {
+		ratio = 1.0
+	}
+	return ratio
+}
+
+func formatGoal(goal int32) string {
+	if goal == 0 {
+		return "not set"
+	}
+	return fmt.Sprintf("%d pomodoros", goal)
+}
+
+func init() {
+	pomoCmd.AddCommand(goalCmd)
+	goalCmd.AddCommand(goalSetCmd)
+
+	goalSetCmd.Flags().IntVar(&goalDaily, "daily", 0, "Completed pomodoros to aim for each day")
+	goalSetCmd.Flags().IntVar(&goalWeekly, "weekly", 0, "Completed pomodoros to aim for each week")
+}
@@ -121,7 +121,7 @@ Examples:
 
 		for _, session := range sessions {
 			// Format duration
-			duration := fmt.Sprintf("%d min", session.WorkDuration.Minutes())
+			duration := fmt.Sprintf("%d min", int(session.WorkDuration.Minutes()))
 
 			// Format status
 			status := string(session.Status)
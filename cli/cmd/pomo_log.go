@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logAt    string
+	logWork  int
+	logBreak int
+	logTask  int
+	logNote  string
+)
+
+var pomoLogCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Record a Pomodoro session done in the past",
+	Long: `Record a completed Pomodoro session that was not tracked live,
+for example one done away from the computer.
+
+The session must not overlap any of your existing sessions.
+
+Examples:
+  prod pomo log --at "today 10:00"                 # 25 minute session at 10:00 today
+  prod pomo log --at "yesterday 14:30" --work 50   # 50 minute session yesterday
+  prod pomo log --at "2025-04-10 09:00" --task 12 --note "Reviewed specs"`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to log a Pomodoro session")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		startTime, err := util.ParseDateTime(logAt)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --at value: %v\n", err)
+			return
+		}
+
+		// Verify the task exists and belongs to the user
+		var taskID *int32
+		if cmd.Flags().Changed("task") {
+			taskService := services.NewTaskService(queries)
+			_, err = taskService.GetTask(context.Background(), int32(logTask), user.ID)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+
+			id := int32(logTask)
+			taskID = &id
+		}
+
+		// Use default durations if not specified
+		pomoService := services.NewPomodoroService(queries)
+		config, configErr := pomoService.GetUserConfig(context.Background(), user.ID)
+
+		workDuration := logWork
+		if workDuration <= 0 {
+			workDuration = 25 // Default: 25 minutes
+			if configErr == nil {
+				workDuration = int(config.WorkDuration)
+			}
+		}
+
+		breakDuration := logBreak
+		if breakDuration <= 0 {
+			breakDuration = 5 // Default: 5 minutes
+			if configErr == nil {
+				breakDuration = int(config.BreakDuration)
+			}
+		}
+
+		// The overlap check and the insert share a transaction
+		var session *services.PomodoroSession
+		err = services.NewUnitOfWork(dbpool, queries).Do(context.Background(), func(tx *services.TxServices) error {
+			session, err = tx.Pomodoros.LogSession(
+				context.Background(),
+				user.ID,
+				taskID,
+				startTime,
+				time.Duration(workDuration)*time.Minute,
+				time.Duration(breakDuration)*time.Minute,
+				logNote,
+			)
+			return err
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error logging Pomodoro session: %v\n", err)
+			return
+		}
+
+		fmt.Printf("🍅 Logged Pomodoro session %d\n", session.ID)
+		fmt.Printf("From %s to %s (%d minutes)\n",
+			session.StartTime.Time.Format("2006-01-02 15:04"),
+			session.EndTime.Time.Format("15:04"),
+			workDuration)
+		if session.Note != "" {
+			fmt.Printf("Note: %s\n", session.Note)
+		}
+	},
+}
+
+func init() {
+	pomoCmd.AddCommand(pomoLogCmd)
+
+	// Add flags
+	pomoLogCmd.Flags().StringVar(&logAt, "at", "", "Start time (\"YYYY-MM-DD HH:MM\", \"today HH:MM\", \"yesterday HH:MM\" or \"HH:MM\")")
+	pomoLogCmd.Flags().IntVar(&logWork, "work", 0, "Work duration in minutes (default: from config or 25)")
+	pomoLogCmd.Flags().IntVar(&logBreak, "break", 0, "Break duration in minutes (default: from config or 5)")
+	pomoLogCmd.Flags().IntVar(&logTask, "task", 0, "ID of the task the session was spent on")
+	pomoLogCmd.Flags().StringVar(&logNote, "note", "", "Add a note to the session")
+	pomoLogCmd.MarkFlagRequired("at")
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPomoLogCommandStructure(t *testing.T) {
+	cmd := pomoLogCmd
+
+	// Check that the command exists
+	assert.NotNil(t, cmd)
+	assert.Equal(t, "log", cmd.Use)
+	assert.Equal(t, "Record a Pomodoro session done in the past", cmd.Short)
+
+	// Check the flags
+	for _, name := range []string{"at", "work", "break", "task", "note"} {
+		assert.NotNil(t, cmd.Flag(name), "%s flag should exist", name)
+	}
+
+	// Check that edit and delete are registered next to log
+	var found []string
+	for _, c := range pomoCmd.Commands() {
+		found = append(found, c.Name())
+	}
+	assert.Contains(t, found, "log")
+	assert.Contains(t, found, "edit")
+	assert.Contains(t, found, "delete")
+}
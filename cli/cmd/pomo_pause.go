@@ -55,6 +55,8 @@ Examples:
 			return
 		}
 
+		savePomodoroState(queries, user.ID, pausedSession)
+
 		// Print result
 		fmt.Println("⏸️  Pomodoro session paused")
 		fmt.Printf("Started at: %s\n", pausedSession.StartTime.Time.Format("15:04:05"))
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	presetWork      int
+	presetBreak     int
+	presetLongBreak int
+	presetInterval  int
+)
+
+var presetCmd = &cobra.Command{
+	Use:   "preset",
+	Short: "List your Pomodoro presets",
+	Long: `List your named Pomodoro presets.
+
+A preset is a named work/break cadence that can be used with
+'prod pomo start --preset NAME' or set as the default for a project.
+
+Examples:
+  prod pomo preset                   # List presets
+  prod pomo preset add deep --work 50 --break 10 --long-break 20 --interval 3
+  prod pomo preset add sprint --work 15 --break 3
+  prod pomo preset default 2 deep    # Use "deep" for tasks in project 2
+  prod pomo preset delete sprint`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to view Pomodoro presets")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		pomoService := services.NewPomodoroService(queries)
+		presets, err := pomoService.ListPresets(context.Background(), user.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing Pomodoro presets: %v\n", err)
+			return
+		}
+
+		if len(presets) == 0 {
+			fmt.Println("You have no Pomodoro presets")
+			fmt.Println("Use 'prod pomo preset add deep --work 50 --break 10' to create one")
+			return
+		}
+
+		fmt.Printf("%-15s %6s %6s %11s %9s\n", "Name", "Work", "Break", "Long Break", "Interval")
+		for _, preset := range presets {
+			fmt.Printf("%-15s %5dm %5dm %10dm %9d\n",
+				preset.Name,
+				preset.WorkDuration,
+				preset.BreakDuration,
+				preset.LongBreakDuration,
+				preset.LongBreakInterval)
+		}
+	},
+}
+
+var presetAddCmd = &cobra.Command{
+	Use:   "add [name]",
+	Short: "Create or update a Pomodoro preset",
+	Long: `Create a named Pomodoro preset, or update it if the name already exists.
+Updating a preset only changes the durations given, new presets start from
+25 minutes of work, 5 minute breaks and a 15 minute break every 4 pomodoros.
+
+Examples:
+  prod pomo preset add deep --work 50 --break 10 --long-break 20 --interval 3
+  prod pomo preset add sprint --work 15 --break 3`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to manage Pomodoro presets")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		// Only the flags given change an existing preset
+		var changes services.PresetChanges
+		if cmd.Flags().Changed("work") {
+			work := int32(presetWork)
+			changes.WorkDuration = &work
+		}
+		if cmd.Flags().Changed("break") {
+			shortBreak := int32(presetBreak)
+			changes.BreakDuration = &shortBreak
+		}
+		if cmd.Flags().Changed("long-break") {
+			longBreak := int32(presetLongBreak)
+			changes.LongBreakDuration = &longBreak
+		}
+		if cmd.Flags().Changed("interval") {
+			interval := int32(presetInterval)
+			changes.LongBreakInterval = &interval
+		}
+
+		pomoService := services.NewPomodoroService(queries)
+		preset, err := pomoService.SavePreset(context.Background(), user.ID, args[0], changes)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving Pomodoro preset: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Preset %q saved\n", preset.Name)
+		fmt.Printf("Work: %d minutes, break: %d minutes\n", preset.WorkDuration, preset.BreakDuration)
+		fmt.Printf("Long break: %d minutes every %d pomodoros\n", preset.LongBreakDuration, preset.LongBreakInterval)
+	},
+}
+
+var presetDeleteCmd = &cobra.Command{
+	Use:   "delete [name]",
+	Short: "Delete a Pomodoro preset",
+	Long: `Delete a named Pomodoro preset. Projects using it as default fall back to
+your regular configuration.
+
+Examples:
+  prod pomo preset delete sprint`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to manage Pomodoro presets")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		pomoService := services.NewPomodoroService(queries)
+		if err := pomoService.DeletePreset(context.Background(), user.ID, args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error deleting Pomodoro preset: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Preset %q deleted\n", args[0])
+	},
+}
+
+var presetDefaultCmd = &cobra.Command{
+	Use:   "default [project-id] [name]",
+	Short: "Set the default Pomodoro preset of a project",
+	Long: `Set the preset used when starting a Pomodoro on a task in the project.
+Leave out the preset name to remove the project's default.
+
+Examples:
+  prod pomo preset default 2 writing  # Tasks in project 2 use the "writing" preset
+  prod pomo preset default 2          # Remove the default from project 2`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		projectID, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Invalid project ID\n")
+			return
+		}
+
+		var name string
+		if len(args) == 2 {
+			name = args[1]
+		}
+
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to manage Pomodoro presets")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		pomoService := services.NewPomodoroService(queries)
+		project, err := pomoService.SetProjectPreset(context.Background(), user.ID, int32(projectID), name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error setting project preset: %v\n", err)
+			return
+		}
+
+		if name == "" {
+			fmt.Printf("Removed the default preset from project %s\n", project.Name)
+		} else {
+			fmt.Printf("Project %s now uses preset %q\n", project.Name, name)
+		}
+	},
+}
+
+func init() {
+	pomoCmd.AddCommand(presetCmd)
+	presetCmd.AddCommand(presetAddCmd)
+	presetCmd.AddCommand(presetDeleteCmd)
+	presetCmd.AddCommand(presetDefaultCmd)
+
+	presetAddCmd.Flags().IntVar(&presetWork, "work", 25, "Work duration in minutes")
+	presetAddCmd.Flags().IntVar(&presetBreak, "break", 5, "Break duration in minutes")
+	presetAddCmd.Flags().IntVar(&presetLongBreak, "long-break", 15, "Long break duration in minutes")
+	presetAddCmd.Flags().IntVar(&presetInterval, "interval", 4, "Number of pomodoros before a long break")
+}
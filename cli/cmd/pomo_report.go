@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jskallebak/prod/internal/services"
@@ -16,6 +19,7 @@ var (
 	reportPeriod string
 	reportFormat string
 	reportOutput string
+	reportBy     string
 )
 
 var reportCmd = &cobra.Command{
@@ -28,11 +32,25 @@ Examples:
   prod pomo report 5          # Generate a report for task with ID 5
   prod pomo report --period week    # Report for this week
   prod pomo report --period month   # Report for this month
-  prod pomo report --format text    # Plain text report
-  prod pomo report --output file    # Output to file`,
+  prod pomo report --by project     # Focused time per project
+  prod pomo report --by tag --period month
+  prod pomo report --period week --output week.html   # HTML report with charts
+  prod pomo report --period week --output week.md     # Markdown summary
+  prod pomo report --format csv --output sessions.csv # Raw sessions as CSV
+  prod pomo report --format json                      # JSON to the console
+  prod pomo report --output file                      # Text report to pomodoro_report_<date>.txt
+
+The format is taken from --format, or from the extension of the --output path
+when --format is not given. Supported formats: text, json, csv, markdown, html.`,
 
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		format, outputPath, err := resolveReportOutput(reportFormat, reportOutput, cmd.Flags().Changed("format"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return
+		}
+
 		dbpool, queries, ok := util.InitDBAndQueriesCLI()
 		if !ok {
 			return
@@ -48,6 +66,15 @@ Examples:
 			return
 		}
 
+		// The text report is collected first and written once all data is ready
+		var text bytes.Buffer
+		out := io.Writer(&text)
+		export := &services.ReportExport{
+			Title:       "Pomodoro Report - All Tasks",
+			GeneratedAt: time.Now(),
+			GroupBy:     reportBy,
+		}
+
 		// Parse task ID if provided
 		var taskID *int32
 		if len(args) == 1 {
@@ -68,9 +95,10 @@ Examples:
 			intID := int32(id)
 			taskID = &intID
 
-			fmt.Printf("Pomodoro Report for Task: %s (ID: %d)\n\n", task.Description, task.ID)
+			export.Title = fmt.Sprintf("Pomodoro Report for Task: %s (ID: %d)", task.Description, task.ID)
+			fmt.Fprintf(out, "%s\n\n", export.Title)
 		} else {
-			fmt.Println("Pomodoro Report - All Tasks")
+			fmt.Fprintln(out, export.Title)
 		}
 
 		// Determine time range for report
@@ -81,25 +109,23 @@ Examples:
 		case "day":
 			startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 			startDate = &startOfDay
-			fmt.Printf("Period: Today (%s)\n\n", startOfDay.Format("2006-01-02"))
+			export.Period = fmt.Sprintf("Today (%s)", startOfDay.Format("2006-01-02"))
 		case "week":
-			// Get start of week (Sunday)
-			daysToSunday := int(now.Weekday())
-			startOfWeek := now.AddDate(0, 0, -daysToSunday)
-			startOfWeek = time.Date(startOfWeek.Year(), startOfWeek.Month(), startOfWeek.Day(), 0, 0, 0, 0, now.Location())
+			startOfWeek := util.StartOfWeek(now)
 			startDate = &startOfWeek
-			fmt.Printf("Period: This Week (from %s)\n\n", startOfWeek.Format("2006-01-02"))
+			export.Period = fmt.Sprintf("This Week (from %s)", startOfWeek.Format("2006-01-02"))
 		case "month":
 			startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
 			startDate = &startOfMonth
-			fmt.Printf("Period: This Month (%s)\n\n", startOfMonth.Format("2006-01"))
+			export.Period = fmt.Sprintf("This Month (%s)", startOfMonth.Format("2006-01"))
 		case "year":
 			startOfYear := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
 			startDate = &startOfYear
-			fmt.Printf("Period: This Year (%d)\n\n", now.Year())
+			export.Period = fmt.Sprintf("This Year (%d)", now.Year())
 		default:
-			fmt.Println("Period: All Time\n")
+			export.Period = "All Time"
 		}
+		fmt.Fprintf(out, "Period: %s\n\n", export.Period)
 
 		// Get pomodoro service
 		pomoService := services.NewPomodoroService(queries)
@@ -107,47 +133,91 @@ Examples:
 		// Generate report
 		report, err := pomoService.GenerateReport(context.Background(), user.ID, taskID, startDate, endDate)
 		if err != nil {
-			fmt.Printf("Error generating Pomodoro report: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Error generating Pomodoro report: %v\n", err)
 			return
 		}
 
-		// Check if report has any data
+		export.Report = report
+
+		// Check if report has any data. A file is still written, with the
+		// headers of its format, so the output path always exists afterwards
 		if report.TotalSessions == 0 {
+			if outputPath == "" {
+				if format == services.ReportFormatText {
+					os.Stdout.Write(text.Bytes())
+				}
+				fmt.Println("No Pomodoro sessions found for the selected criteria")
+				return
+			}
+
+			fmt.Fprintln(out, "No Pomodoro sessions found for the selected criteria")
+			if err := writeReport(format, outputPath, text.Bytes(), export); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing Pomodoro report: %v\n", err)
+				return
+			}
 			fmt.Println("No Pomodoro sessions found for the selected criteria")
+			fmt.Printf("Report saved to %s\n", outputPath)
 			return
 		}
 
 		// Display report stats
-		fmt.Printf("Total Sessions: %d\n", report.TotalSessions)
-		fmt.Printf("Completed: %d (%.1f%%)\n",
+		fmt.Fprintf(out, "Total Sessions: %d\n", report.TotalSessions)
+		fmt.Fprintf(out, "Completed: %d (%.1f%%)\n",
 			report.CompletedSessions,
 			float64(report.CompletedSessions)/float64(report.TotalSessions)*100)
-		fmt.Printf("Cancelled: %d (%.1f%%)\n",
+		fmt.Fprintf(out, "Cancelled: %d (%.1f%%)\n",
 			report.CancelledSessions,
 			float64(report.CancelledSessions)/float64(report.TotalSessions)*100)
 
 		// Time stats
-		fmt.Printf("\nTotal Time: %s\n", util.FormatDurationSeconds(report.TotalTimeSeconds))
-		fmt.Printf("Work Time: %s (%.1f%%)\n",
+		fmt.Fprintf(out, "\nTotal Time: %s\n", util.FormatDurationSeconds(report.TotalTimeSeconds))
+		fmt.Fprintf(out, "Work Time: %s (%.1f%%)\n",
 			util.FormatDurationSeconds(report.WorkTimeSeconds),
 			float64(report.WorkTimeSeconds)/float64(report.TotalTimeSeconds)*100)
-		fmt.Printf("Break Time: %s (%.1f%%)\n",
+		fmt.Fprintf(out, "Break Time: %s (%.1f%%)\n",
 			util.FormatDurationSeconds(report.BreakTimeSeconds),
 			float64(report.BreakTimeSeconds)/float64(report.TotalTimeSeconds)*100)
-		fmt.Printf("Pause Time: %s (%.1f%%)\n",
+		fmt.Fprintf(out, "Pause Time: %s (%.1f%%)\n",
 			util.FormatDurationSeconds(report.PauseTimeSeconds),
 			float64(report.PauseTimeSeconds)/float64(report.TotalTimeSeconds)*100)
 
 		// Productivity stats
-		fmt.Printf("\nAverage Session: %s\n", util.FormatDurationSeconds(report.AvgSessionSeconds))
-		fmt.Printf("Average Work Session: %s\n", util.FormatDurationSeconds(report.AvgWorkSessionSeconds))
-		fmt.Printf("Average Break: %s\n", util.FormatDurationSeconds(report.AvgBreakSeconds))
+		fmt.Fprintf(out, "\nAverage Session: %s\n", util.FormatDurationSeconds(report.AvgSessionSeconds))
+		fmt.Fprintf(out, "Average Work Session: %s\n", util.FormatDurationSeconds(report.AvgWorkSessionSeconds))
+		fmt.Fprintf(out, "Average Break: %s\n", util.FormatDurationSeconds(report.AvgBreakSeconds))
+
+		// Display grouped breakdown if requested
+		if reportBy != "" {
+			groups, err := pomoService.GenerateGroupedReport(context.Background(), user.ID, taskID, startDate, endDate, reportBy)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error grouping Pomodoro report: %v\n", err)
+				return
+			}
+			export.Groups = groups
+
+			fmt.Fprintf(out, "\nBreakdown by %s:\n", reportBy)
+			fmt.Fprintf(out, "%-30s %-10s %-12s %-12s\n", "Group", "Sessions", "Focus Time", "Completion")
+			fmt.Fprintf(out, "%-30s %-10s %-12s %-12s\n", "-----", "--------", "----------", "----------")
+
+			for _, group := range groups {
+				key := group.Key
+				if runes := []rune(key); len(runes) > 27 {
+					key = string(runes[:24]) + "..."
+				}
+
+				fmt.Fprintf(out, "%-30s %-10d %-12s %.1f%%\n",
+					key,
+					group.TotalSessions,
+					util.FormatDurationSeconds(group.FocusSeconds),
+					group.CompletionRate)
+			}
+		}
 
 		// Display daily breakdown if available
-		if len(report.DailyStats) > 0 {
-			fmt.Printf("\nDaily Breakdown:\n")
-			fmt.Printf("%-12s %-12s %-12s %-12s\n", "Date", "Sessions", "Work Time", "Completion")
-			fmt.Printf("%-12s %-12s %-12s %-12s\n", "----", "--------", "---------", "----------")
+		if reportBy == "" && len(report.DailyStats) > 0 {
+			fmt.Fprintf(out, "\nDaily Breakdown:\n")
+			fmt.Fprintf(out, "%-12s %-12s %-12s %-12s\n", "Date", "Sessions", "Work Time", "Completion")
+			fmt.Fprintf(out, "%-12s %-12s %-12s %-12s\n", "----", "--------", "---------", "----------")
 
 			for _, day := range report.DailyStats {
 				completionRate := 0.0
@@ -155,7 +225,7 @@ Examples:
 					completionRate = float64(day.CompletedSessions) / float64(day.TotalSessions) * 100
 				}
 
-				fmt.Printf("%-12s %-12d %-12s %.1f%%\n",
+				fmt.Fprintf(out, "%-12s %-12d %-12s %.1f%%\n",
 					day.Date.Format("2006-01-02"),
 					day.TotalSessions,
 					util.FormatDurationSeconds(day.WorkTimeSeconds),
@@ -164,10 +234,10 @@ Examples:
 		}
 
 		// If task filtering is not applied, show top tasks
-		if taskID == nil && len(report.TopTasks) > 0 {
-			fmt.Printf("\nMost Popular Tasks:\n")
-			fmt.Printf("%-5s %-30s %-12s %-12s\n", "ID", "Description", "Sessions", "Total Time")
-			fmt.Printf("%-5s %-30s %-12s %-12s\n", "--", "-----------", "--------", "----------")
+		if reportBy == "" && taskID == nil && len(report.TopTasks) > 0 {
+			fmt.Fprintf(out, "\nMost Popular Tasks:\n")
+			fmt.Fprintf(out, "%-5s %-30s %-12s %-12s\n", "ID", "Description", "Sessions", "Total Time")
+			fmt.Fprintf(out, "%-5s %-30s %-12s %-12s\n", "--", "-----------", "--------", "----------")
 
 			for _, task := range report.TopTasks {
 				description := task.Description
@@ -175,7 +245,7 @@ Examples:
 					description = description[:24] + "..."
 				}
 
-				fmt.Printf("%-5d %-30s %-12d %s\n",
+				fmt.Fprintf(out, "%-5d %-30s %-12d %s\n",
 					task.ID,
 					description,
 					task.SessionCount,
@@ -183,20 +253,107 @@ Examples:
 			}
 		}
 
-		// If generating a file output, save the report
-		if reportOutput == "file" {
-			filename := fmt.Sprintf("pomodoro_report_%s.txt", time.Now().Format("2006-01-02"))
-			fmt.Printf("\nSaving report to %s\n", filename)
-			// Code to save report to file would go here
+		// Show how often the daily goal was met
+		if taskID == nil {
+			streaks, err := pomoService.GetGoalStreaks(context.Background(), user.ID, startDate)
+			if err == nil && streaks.DaysTracked > 0 {
+				fmt.Fprintf(out, "\nDaily Goal:\n")
+				fmt.Fprintf(out, "Hit Rate: %d/%d days (%.1f%%)\n", streaks.DaysMet, streaks.DaysTracked, streaks.HitRate)
+				fmt.Fprintf(out, "Current Streak: %d days\n", streaks.CurrentStreak)
+				fmt.Fprintf(out, "Longest Streak: %d days\n", streaks.LongestStreak)
+			}
+		}
+
+		// Raw sessions are only part of the CSV and JSON exports
+		if format == services.ReportFormatCSV || format == services.ReportFormatJSON {
+			export.Sessions, err = pomoService.ListReportSessions(context.Background(), user.ID, taskID, startDate, endDate)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing Pomodoro sessions: %v\n", err)
+				return
+			}
+		}
+
+		if err := writeReport(format, outputPath, text.Bytes(), export); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing Pomodoro report: %v\n", err)
+			return
+		}
+
+		if outputPath != "" {
+			fmt.Printf("Report saved to %s\n", outputPath)
 		}
 	},
 }
 
+// resolveReportOutput returns the export format and the file to write to, an
+// empty path meaning the console. Without --format the path extension decides
+func resolveReportOutput(format, output string, formatSet bool) (string, string, error) {
+	format = strings.ToLower(format)
+	if format == "md" {
+		format = services.ReportFormatMarkdown
+	}
+
+	path := output
+	switch output {
+	case "", "console", "-":
+		path = ""
+	case "file":
+		path = fmt.Sprintf("pomodoro_report_%s%s", time.Now().Format("2006-01-02"), services.ReportFileExtension(format))
+	default:
+		if !formatSet {
+			if guessed, ok := services.ReportFormatFromPath(output); ok {
+				format = guessed
+			}
+		}
+	}
+
+	switch format {
+	case services.ReportFormatText, services.ReportFormatJSON, services.ReportFormatCSV,
+		services.ReportFormatMarkdown, services.ReportFormatHTML:
+	default:
+		return "", "", fmt.Errorf("unknown report format %q: must be one of text, json, csv, markdown, html", format)
+	}
+
+	return format, path, nil
+}
+
+// writeReport writes the report in the given format to path, or to the console if path is empty
+func writeReport(format, path string, text []byte, export *services.ReportExport) (err error) {
+	var w io.Writer = os.Stdout
+	if path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", path, err)
+		}
+		// A failed close can lose the end of the report
+		defer func() {
+			if closeErr := file.Close(); closeErr != nil && err == nil {
+				err = fmt.Errorf("failed to write %s: %w", path, closeErr)
+			}
+		}()
+		w = file
+	}
+
+	switch format {
+	case services.ReportFormatJSON:
+		return services.WriteReportJSON(w, export)
+	case services.ReportFormatCSV:
+		return services.WriteReportCSV(w, export.Sessions)
+	case services.ReportFormatMarkdown:
+		return services.WriteReportMarkdown(w, export)
+	case services.ReportFormatHTML:
+		return services.WriteReportHTML(w, export)
+	default:
+		_, err := w.Write(text)
+		return err
+	}
+}
+
 func init() {
 	pomoCmd.AddCommand(reportCmd)
 
 	// Add flags
 	reportCmd.Flags().StringVar(&reportPeriod, "period", "", "Report period (day, week, month, year)")
-	reportCmd.Flags().StringVar(&reportFormat, "format", "text", "Report format (text, json)")
-	reportCmd.Flags().StringVar(&reportOutput, "output", "console", "Report output (console, file)")
+	reportCmd.Flags().StringVar(&reportFormat, "format", "text", "Report format (text, json, csv, markdown, html)")
+	reportCmd.Flags().StringVar(&reportOutput, "output", "console", "Report output: console, file, or a file path")
+	reportCmd.Flags().StringVar(&reportBy, "by", "", "Group the report by project, tag, task, day, weekday or hour")
 }
@@ -56,6 +56,8 @@ Examples:
 			return
 		}
 
+		savePomodoroState(queries, user.ID, resumedSession)
+
 		// Calculate new end time
 		startTime := resumedSession.StartTime.Time
 		workDuration := resumedSession.WorkDuration
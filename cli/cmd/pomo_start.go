@@ -16,6 +16,7 @@ var (
 	pomoWorkDuration  int
 	pomoBreakDuration int
 	pomodoroNote      string
+	pomodoroPreset    string
 )
 
 var startCmd = &cobra.Command{
@@ -23,10 +24,17 @@ var startCmd = &cobra.Command{
 	Short: "Start a new Pomodoro session",
 	Long: `Start a new Pomodoro session, optionally linked to a task.
 
+Durations come from, in order: --work/--break, --preset, the default preset
+of the task's project, and finally your Pomodoro config. Every few pomodoros
+of the day are followed by the long break of the preset or config.
+
+With 'prod pomo config --link-tasks' the task is also marked active.
+
 Examples:
   prod pomo start            # Start a Pomodoro without a task
   prod pomo start 5          # Start a Pomodoro linked to task ID 5
   prod pomo start --work 25  # Start a Pomodoro with custom work duration (25 minutes)
+  prod pomo start --preset deep
   prod pomo start 5 --note "Working on feature X"`,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
@@ -76,51 +84,83 @@ Examples:
 			taskID = &intID
 		}
 
-		// Use default durations if not specified
-		workDuration := pomoWorkDuration
-		if workDuration <= 0 {
-			// Get from user config or use default
-			config, err := pomoService.GetUserConfig(context.Background(), user.ID)
-			if err == nil {
-				workDuration = int(config.WorkDuration)
-			} else {
-				workDuration = 25 // Default: 25 minutes
+		// Pick the preset given on the command line or the project's default
+		var preset *services.PomodoroPreset
+		if pomodoroPreset != "" {
+			preset, err = pomoService.GetPreset(context.Background(), user.ID, pomodoroPreset)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+		} else if taskID != nil {
+			preset, err = pomoService.GetTaskPreset(context.Background(), user.ID, *taskID)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
 			}
 		}
 
-		breakDuration := pomoBreakDuration
-		if breakDuration <= 0 {
-			// Get from user config or use default
-			config, err := pomoService.GetUserConfig(context.Background(), user.ID)
-			if err == nil {
-				breakDuration = int(config.BreakDuration)
-			} else {
-				breakDuration = 5 // Default: 5 minutes
+		var givenBreak *int
+		if cmd.Flags().Changed("break") {
+			if pomoBreakDuration < 0 {
+				fmt.Fprintf(os.Stderr, "Error: --break cannot be negative\n")
+				return
 			}
+			givenBreak = &pomoBreakDuration
+		}
+		workDuration, breakDuration, longBreak := pomodoroDurations(pomoService, user.ID, preset, pomoWorkDuration, givenBreak)
+
+		// Start the Pomodoro session, marking the task active when tasks are linked
+		linkTasks := taskID != nil && pomoService.LinkTasksEnabled(context.Background(), user.ID)
+		var session *services.PomodoroSession
+		if linkTasks {
+			uow := services.NewUnitOfWork(dbpool, queries)
+			err = uow.Do(context.Background(), func(tx *services.TxServices) error {
+				session, _, err = tx.Pomodoros.StartSessionWithTask(
+					context.Background(),
+					user.ID,
+					*taskID,
+					time.Duration(workDuration)*time.Minute,
+					time.Duration(breakDuration)*time.Minute,
+					pomodoroNote,
+				)
+				return err
+			})
+		} else {
+			session, err = pomoService.StartSession(
+				context.Background(),
+				user.ID,
+				taskID,
+				time.Duration(workDuration)*time.Minute,
+				time.Duration(breakDuration)*time.Minute,
+				pomodoroNote,
+			)
 		}
-
-		// Start the Pomodoro session
-		session, err := pomoService.StartSession(
-			context.Background(),
-			user.ID,
-			taskID,
-			time.Duration(workDuration)*time.Minute,
-			time.Duration(breakDuration)*time.Minute,
-			pomodoroNote,
-		)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error starting Pomodoro session: %v\n", err)
 			return
 		}
 
+		savePomodoroState(queries, user.ID, session)
+
 		fmt.Println("🍅 Pomodoro session started!")
+		if preset != nil {
+			fmt.Printf("Preset: %s\n", preset.Name)
+		}
 		fmt.Printf("Work duration: %d minutes\n", workDuration)
-		fmt.Printf("Break duration: %d minutes\n", breakDuration)
+		if longBreak {
+			fmt.Printf("Break duration: %d minutes (long break)\n", breakDuration)
+		} else {
+			fmt.Printf("Break duration: %d minutes\n", breakDuration)
+		}
 
 		if taskID != nil {
 			taskService := services.NewTaskService(queries)
 			task, _ := taskService.GetTask(context.Background(), *taskID, user.ID)
 			fmt.Printf("Task: %s (ID: %d)\n", task.Description, task.ID)
+			if linkTasks {
+				fmt.Println("Task marked as active")
+			}
 		}
 
 		if pomodoroNote != "" {
@@ -138,11 +178,31 @@ Examples:
 	},
 }
 
+// pomodoroDurations picks the work and break minutes of a new session: the
+// given ones, then the ones of preset, then the user's config. Unless the break
+// is given, the long break follows every few pomodoros of the day, which is
+// reported by the last result. A break of 0 minutes is no break
+func pomodoroDurations(pomoService *services.PomodoroService, userID int32, preset *services.PomodoroPreset, workDuration int, breakDuration *int) (int, int, bool) {
+	cadence := pomoService.Cadence(context.Background(), userID, preset)
+	if workDuration <= 0 {
+		workDuration = int(cadence.WorkDuration)
+	}
+	if breakDuration != nil {
+		return workDuration, *breakDuration, false
+	}
+
+	// Without the count every break is a short one
+	completed, _ := pomoService.CompletedToday(context.Background(), userID)
+	minutes, long := cadence.NextBreak(completed)
+	return workDuration, int(minutes), long
+}
+
 func init() {
 	pomoCmd.AddCommand(startCmd)
 
 	// Add flags
 	startCmd.Flags().IntVar(&pomoWorkDuration, "work", 0, "Work duration in minutes (default: from config or 25)")
-	startCmd.Flags().IntVar(&pomoBreakDuration, "break", 0, "Break duration in minutes (default: from config or 5)")
+	startCmd.Flags().IntVar(&pomoBreakDuration, "break", 0, "Break duration in minutes, 0 for none (default: from the preset or config)")
 	startCmd.Flags().StringVar(&pomodoroNote, "note", "", "Add a note to this Pomodoro session")
+	startCmd.Flags().StringVar(&pomodoroPreset, "preset", "", "Use the durations of a named preset")
 }
@@ -79,10 +79,7 @@ Examples:
 			startDate = &startOfDay
 			fmt.Printf("Time Frame: Today (%s)\n\n", startOfDay.Format("2006-01-02"))
 		} else if statsTimeFrame == "week" {
-			// Get start of week (Sunday)
-			daysToSunday := int(now.Weekday())
-			startOfWeek := now.AddDate(0, 0, -daysToSunday)
-			startOfWeek = time.Date(startOfWeek.Year(), startOfWeek.Month(), startOfWeek.Day(), 0, 0, 0, 0, now.Location())
+			startOfWeek := util.StartOfWeek(now)
 			startDate = &startOfWeek
 			fmt.Printf("Time Frame: This Week (from %s)\n\n", startOfWeek.Format("2006-01-02"))
 		} else if statsTimeFrame == "month" {
@@ -90,7 +87,7 @@ Examples:
 			startDate = &startOfMonth
 			fmt.Printf("Time Frame: This Month (%s)\n\n", startOfMonth.Format("2006-01"))
 		} else {
-			fmt.Println("Time Frame: All Time\n")
+			fmt.Print("Time Frame: All Time\n\n")
 		}
 
 		// Get statistics
@@ -102,23 +99,24 @@ Examples:
 		}
 
 		// Display statistics
-		totalSessions := stats["total_sessions"].(int32)
+		totalSessions := stats["total_sessions"].(int64)
 		if totalSessions == 0 {
 			fmt.Println("No Pomodoro sessions found for the selected criteria")
+			showGoalProgress(pomoService, user.ID)
 			return
 		}
 
 		fmt.Printf("Total Sessions: %d\n", totalSessions)
-		fmt.Printf("Completed: %d\n", stats["completed_sessions"].(int32))
-		fmt.Printf("Cancelled: %d\n", stats["cancelled_sessions"].(int32))
+		fmt.Printf("Completed: %d\n", stats["completed_sessions"].(int64))
+		fmt.Printf("Cancelled: %d\n", stats["cancelled_sessions"].(int64))
 
-		completionRate := float64(stats["completed_sessions"].(int32)) / float64(totalSessions) * 100
+		completionRate := float64(stats["completed_sessions"].(int64)) / float64(totalSessions) * 100
 		fmt.Printf("Completion Rate: %.1f%%\n\n", completionRate)
 
-		fmt.Printf("Total Work Time: %d minutes\n", stats["total_work_mins"].(int32))
-		fmt.Printf("Total Break Time: %d minutes\n", stats["total_break_mins"].(int32))
+		fmt.Printf("Total Work Time: %d minutes\n", stats["total_work_mins"].(int64))
+		fmt.Printf("Total Break Time: %d minutes\n", stats["total_break_mins"].(int64))
 		fmt.Printf("Total Time: %d minutes\n", stats["total_duration_mins"].(int32))
-		fmt.Printf("Average Session: %.1f minutes\n\n", float64(stats["avg_duration_mins"].(float64)))
+		fmt.Printf("Average Session: %.1f minutes\n\n", stats["avg_duration_mins"].(float64))
 
 		// Show most productive day/hour if available
 		if mpd, ok := stats["most_productive_day"].(time.Time); ok && !mpd.IsZero() {
@@ -141,6 +139,8 @@ Examples:
 			}
 			fmt.Printf("Most Productive Hour: %d:00 %s\n", hour, ampm)
 		}
+
+		showGoalProgress(pomoService, user.ID)
 	},
 }
 
@@ -3,22 +3,65 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
+	"text/template"
 	"time"
 
+	"github.com/jskallebak/prod/internal/db/sqlc"
 	"github.com/jskallebak/prod/internal/services"
 	"github.com/jskallebak/prod/internal/util"
 	"github.com/spf13/cobra"
 )
 
+var (
+	statusFormat  string
+	statusRefresh bool
+)
+
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show current Pomodoro status",
 	Long: `Display information about the current Pomodoro session, if one exists.
 
+With --format the status is rendered from a Go template using the local state
+file kept up to date by start, pause, resume and stop, so no database connection
+is needed. Add --refresh to reload the state from the database first.
+
+Template fields:
+  .Active     true while a session is running or paused
+  .Status     active, paused or idle
+  .Emoji      🍅 when active, ⏸️ when paused, empty when idle
+  .Remaining  work time left (MM:SS)
+  .Elapsed    focused time so far (MM:SS)
+  .Progress   percentage of the work duration done
+  .Task       description of the attached task
+  .TaskID     ID of the attached task
+  .Note       session note
+  .SessionID  ID of the session
+
 Examples:
-  prod pomo status  # Show status of the current Pomodoro session`,
+  prod pomo status  # Show status of the current Pomodoro session
+  prod pomo status --format '{{.Emoji}} {{.Remaining}} {{.Task}}'
+  prod pomo status --format '{{if .Active}}{{.Remaining}}{{end}}' --refresh`,
 
 	Run: func(cmd *cobra.Command, args []string) {
+		// Render straight from the state file unless a refresh is requested.
+		// Logged out there is no session to show
+		if statusFormat != "" && !statusRefresh {
+			userID, err := services.CurrentUserID()
+			if err != nil {
+				renderPomoStatus(statusFormat, nil)
+				return
+			}
+			state, err := services.LoadPomodoroState(userID)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading Pomodoro state: %v\n", err)
+				return
+			}
+			renderPomoStatus(statusFormat, state)
+			return
+		}
+
 		dbpool, queries, ok := util.InitDBAndQueriesCLI()
 		if !ok {
 			return
@@ -38,8 +81,20 @@ Examples:
 		pomoService := services.NewPomodoroService(queries)
 		activeSession, err := pomoService.GetActiveSession(context.Background(), user.ID)
 		if err != nil {
+			activeSession = nil
+		}
+
+		// Keep the state file in sync with the database
+		state := savePomodoroState(queries, user.ID, activeSession)
+		if statusFormat != "" {
+			renderPomoStatus(statusFormat, state)
+			return
+		}
+
+		if activeSession == nil {
 			fmt.Println("You don't have an active Pomodoro session")
 			fmt.Println("Use 'prod pomo start' to start a new session")
+			showGoalProgress(pomoService, user.ID)
 			return
 		}
 
@@ -146,6 +201,8 @@ Examples:
 			fmt.Printf("\nNote: %s\n", activeSession.Note)
 		}
 
+		showGoalProgress(pomoService, user.ID)
+
 		// Show available commands
 		fmt.Println("\nAvailable commands:")
 		if activeSession.Status == services.StatusActive {
@@ -162,6 +219,113 @@ Examples:
 	},
 }
 
+// pomoStatusView is the data available to status --format templates
+type pomoStatusView struct {
+	Active    bool
+	Status    string
+	Emoji     string
+	Remaining string
+	Elapsed   string
+	Progress  int
+	Task      string
+	TaskID    int32
+	Note      string
+	SessionID int32
+}
+
+// renderPomoStatus executes the status template for the given state, nil meaning idle
+func renderPomoStatus(format string, state *services.PomodoroState) {
+	tmpl, err := template.New("status").Parse(format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid format template: %v\n", err)
+		return
+	}
+
+	view := pomoStatusView{Status: "idle"}
+	if state != nil {
+		now := time.Now()
+		view.Active = true
+		view.Status = string(state.Status)
+		view.Emoji = "🍅"
+		if state.Status == services.StatusPaused {
+			view.Emoji = "⏸️"
+		}
+		view.Remaining = formatClock(state.Remaining(now))
+		view.Elapsed = formatClock(state.Elapsed(now))
+		if state.WorkDuration > 0 {
+			view.Progress = int(100 * state.Elapsed(now) / state.WorkDuration)
+			if view.Progress > 100 {
+				view.Progress = 100
+			}
+		}
+		view.Task = state.Task
+		if state.TaskID != nil {
+			view.TaskID = *state.TaskID
+		}
+		view.Note = state.Note
+		view.SessionID = state.SessionID
+	}
+
+	if err := tmpl.Execute(os.Stdout, view); err != nil {
+		fmt.Fprintf(os.Stderr, "Error rendering format template: %v\n", err)
+		return
+	}
+	fmt.Println()
+}
+
+// formatClock formats a duration as MM:SS for status bars
+func formatClock(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%02d:%02d", int(d/time.Minute), int(d%time.Minute/time.Second))
+}
+
+// savePomodoroState writes the session to the local state file, or clears the
+// file when there is no running session, and returns the saved state
+func savePomodoroState(queries *sqlc.Queries, userID int32, session *services.PomodoroSession) *services.PomodoroState {
+	if session == nil || (session.Status != services.StatusActive && session.Status != services.StatusPaused) {
+		if err := services.ClearPomodoroState(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to update Pomodoro state file: %v\n", err)
+		}
+		return nil
+	}
+
+	var taskDescription string
+	if session.TaskID != nil {
+		taskService := services.NewTaskService(queries)
+		task, err := taskService.GetTask(context.Background(), *session.TaskID, userID)
+		if err == nil {
+			taskDescription = task.Description
+		}
+	}
+
+	state := services.NewPomodoroState(session, taskDescription)
+	if err := services.SavePomodoroState(state); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to update Pomodoro state file: %v\n", err)
+	}
+	return state
+}
+
+// syncPomodoroState reloads the running session from the database into the state file
+func syncPomodoroState(queries *sqlc.Queries, userID int32) {
+	pomoService := services.NewPomodoroService(queries)
+	session, err := pomoService.GetActiveSession(context.Background(), userID)
+	if err != nil {
+		session = nil
+	}
+	savePomodoroState(queries, userID, session)
+}
+
+// showGoalProgress prints progress towards the user's goals, if any are set
+func showGoalProgress(pomoService *services.PomodoroService, userID int32) {
+	progress, err := pomoService.GetGoalProgress(context.Background(), userID)
+	if err != nil || (progress.Goal.DailyGoal == 0 && progress.Goal.WeeklyGoal == 0) {
+		return
+	}
+
+	fmt.Println()
+	printGoalProgress(progress)
+}
+
 // renderProgressBar displays a text-based progress bar
 func renderProgressBar(progress float64, width int) {
 	fmt.Println()
@@ -185,4 +349,7 @@ func renderProgressBar(progress float64, width int) {
 
 func init() {
 	pomoCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().StringVar(&statusFormat, "format", "", "Go template for a single status line, rendered from the local state file")
+	statusCmd.Flags().BoolVar(&statusRefresh, "refresh", false, "Reload the state from the database before rendering")
 }
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPomoStatusCommandStructure(t *testing.T) {
+	cmd := statusCmd
+
+	// Check that the command exists
+	assert.NotNil(t, cmd)
+	assert.Equal(t, "status", cmd.Use)
+
+	// Check the flags
+	assert.NotNil(t, cmd.Flag("format"), "format flag should exist")
+	assert.NotNil(t, cmd.Flag("refresh"), "refresh flag should exist")
+}
+
+func TestFormatClock(t *testing.T) {
+	assert.Equal(t, "00:00", formatClock(0))
+	assert.Equal(t, "04:05", formatClock(4*time.Minute+5*time.Second))
+	assert.Equal(t, "50:00", formatClock(50*time.Minute))
+}
@@ -10,7 +10,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var forceComplete bool
+var (
+	forceComplete bool
+	stopDone      bool
+)
 
 var stopCmd = &cobra.Command{
 	Use:   "stop",
@@ -19,7 +22,12 @@ var stopCmd = &cobra.Command{
 
 Examples:
   prod pomo stop            # Stop and mark as cancelled
-  prod pomo stop --complete # Stop and mark as completed`,
+  prod pomo stop --complete # Stop and mark as completed
+  prod pomo stop --done     # Stop and complete the attached task
+
+With 'prod pomo config --link-tasks' an active task is paused when the session
+stops. --done completes the task instead, creating the next occurrence of a
+recurring task.`,
 
 	Run: func(cmd *cobra.Command, args []string) {
 		dbpool, queries, ok := util.InitDBAndQueriesCLI()
@@ -45,40 +53,38 @@ Examples:
 			return
 		}
 
+		if stopDone && activeSession.TaskID == nil {
+			fmt.Println("There is no task attached to this Pomodoro session")
+			fmt.Println("Use 'prod pomo attach' to attach one, or stop without --done")
+			return
+		}
+
 		// Determine whether to mark as completed
-		// If work duration has elapsed, we mark as completed
-		complete := forceComplete
-		if !complete {
-			startTime := activeSession.StartTime.Time
-			elapsed := time.Since(startTime)
-
-			// Check if we've met at least 80% of the work duration
-			targetDuration := activeSession.WorkDuration
-			minDuration := time.Duration(float64(targetDuration) * 0.8)
-
-			// Factor in pause time if the session was paused
-			if activeSession.Status == services.StatusPaused {
-				// If paused, we subtract pause time from elapsed time
-				if activeSession.PauseTime.Valid {
-					pauseStart := activeSession.PauseTime.Time
-					pauseDuration := time.Since(pauseStart)
-					elapsed = elapsed - pauseDuration
-				}
+		// If at least 80% of the work duration has elapsed, we mark as completed
+		complete := forceComplete || services.SessionMeetsTarget(activeSession, time.Now())
+
+		// Stop the session, updating the task in the same transaction when linked
+		var stoppedSession *services.PomodoroSession
+		var linked *services.LinkedStop
+		if stopDone || (activeSession.TaskID != nil && pomoService.LinkTasksEnabled(context.Background(), user.ID)) {
+			uow := services.NewUnitOfWork(dbpool, queries)
+			err = uow.Do(context.Background(), func(tx *services.TxServices) error {
+				linked, err = tx.Pomodoros.StopSessionWithTask(context.Background(), user.ID, complete, stopDone)
+				return err
+			})
+			if linked != nil {
+				stoppedSession = linked.Session
 			}
-
-			// Also factor in total pause duration
-			elapsed = elapsed - activeSession.TotalPauseDuration
-
-			complete = elapsed >= minDuration
+		} else {
+			stoppedSession, err = pomoService.StopSession(context.Background(), user.ID, complete)
 		}
-
-		// Stop the session
-		stoppedSession, err := pomoService.StopSession(context.Background(), user.ID, complete)
 		if err != nil {
 			fmt.Printf("Error stopping Pomodoro session: %v\n", err)
 			return
 		}
 
+		savePomodoroState(queries, user.ID, nil)
+
 		// Print result
 		status := "cancelled"
 		if complete {
@@ -102,6 +108,17 @@ Examples:
 			fmt.Printf("Task: %s (ID: %d)\n", task.Description, task.ID)
 		}
 
+		if linked != nil && linked.Task != nil {
+			if linked.Completed {
+				fmt.Println("Task marked as completed")
+			} else {
+				fmt.Println("Task paused")
+			}
+		}
+		if linked != nil && linked.NextTask != nil {
+			printNextOccurrence(linked.NextTask)
+		}
+
 		if stoppedSession.Note != "" {
 			fmt.Printf("Note: %s\n", stoppedSession.Note)
 		}
@@ -113,4 +130,5 @@ func init() {
 
 	// Add flags
 	stopCmd.Flags().BoolVar(&forceComplete, "complete", false, "Mark the session as completed regardless of duration")
+	stopCmd.Flags().BoolVar(&stopDone, "done", false, "Also mark the attached task as completed")
 }
@@ -29,7 +29,7 @@ to quickly create a Cobra application.`,
 
 		dbpool, queries, ok := util.InitDBAndQueriesCLI()
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error connection to database: %w")
+			fmt.Fprintf(os.Stderr, "Error connection to database\n")
 			return
 		}
 		defer dbpool.Close()
@@ -39,7 +39,7 @@ to quickly create a Cobra application.`,
 
 		user, err := authService.GetCurrentUser(context.Background())
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting user %w", err)
+			fmt.Fprintf(os.Stderr, "Error getting user %v", err)
 			return
 		}
 
@@ -51,7 +51,7 @@ to quickly create a Cobra application.`,
 
 		err = userService.SetActiveProject(context.Background(), user.ID, int32(projectID))
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error setting active project %w", err)
+			fmt.Fprintf(os.Stderr, "Error setting active project %v", err)
 			return
 		}
 
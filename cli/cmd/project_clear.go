@@ -28,7 +28,7 @@ to quickly create a Cobra application.`,
 
 		dbpool, queries, ok := util.InitDBAndQueriesCLI()
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error connection to database: %w", ok)
+			fmt.Fprintf(os.Stderr, "Error connection to database: %v", ok)
 			return
 		}
 		defer dbpool.Close()
@@ -38,13 +38,13 @@ to quickly create a Cobra application.`,
 
 		user, err := authService.GetCurrentUser(context.Background())
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting the user %w", err)
+			fmt.Fprintf(os.Stderr, "Error getting the user %v", err)
 			return
 		}
 
 		err = userService.ClearActiveProject(context.Background(), user.ID)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error claring the active project %w", err)
+			fmt.Fprintf(os.Stderr, "Error claring the active project %v", err)
 			return
 		}
 
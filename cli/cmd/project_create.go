@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/jskallebak/prod/internal/db/sqlc"
 	"github.com/jskallebak/prod/internal/services"
 	"github.com/jskallebak/prod/internal/util"
 	"github.com/spf13/cobra"
@@ -44,9 +45,6 @@ Examples:
 		// Get project name from argument
 		projectName := args[0]
 
-		// Create project service
-		projectService := services.NewProjectService(queries)
-
 		// Prepare params
 		params := services.ProjectParams{
 			Name: projectName,
@@ -69,7 +67,11 @@ Examples:
 		}
 
 		// Create the project
-		project, err := projectService.CreateProject(context.Background(), user.ID, params)
+		var project *sqlc.Project
+		err = services.NewUnitOfWork(dbpool, queries).Do(context.Background(), func(tx *services.TxServices) error {
+			project, err = tx.Projects.CreateProject(context.Background(), user.ID, params)
+			return err
+		})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error creating project: %v\n", err)
 			return
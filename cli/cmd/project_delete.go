@@ -16,7 +16,8 @@ var forceDeleteProject bool
 var deleteProjectCmd = &cobra.Command{
 	Use:   "delete [project-id]",
 	Short: "Delete a project",
-	Long: `Delete a project and optionally all its associated tasks.
+	Long: `Move a project to the trash. Its tasks are kept without a project and are
+re-attached when the project is restored with 'prod trash restore --project'.
 
 Examples:
   prod project delete 1
@@ -57,7 +58,7 @@ Examples:
 		}
 
 		// Get project tasks to inform user what will be affected
-		tasks, err := projectService.GetProjectTasks(context.Background(), int32(projectID), user.ID)
+		tasks, err := projectService.GetProjectTasks(context.Background(), int32(projectID), user.ID, true)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error retrieving project tasks: %v\n", err)
 			return
@@ -69,7 +70,8 @@ Examples:
 
 			if len(tasks) > 0 {
 				fmt.Printf("This project has %d associated tasks. ", len(tasks))
-				fmt.Println("Tasks will be removed from the project but not deleted.")
+				fmt.Println("Tasks will be removed from the project but not deleted,")
+				fmt.Println("and re-attached if the project is restored from the trash.")
 			}
 
 			fmt.Print("Are you sure you want to proceed? (y/N): ")
@@ -82,14 +84,18 @@ Examples:
 			}
 		}
 
-		// Delete the project
-		err = projectService.DeleteProject(context.Background(), int32(projectID), user.ID)
+		// Delete the project, journaling its tasks in the same transaction
+		uow := services.NewUnitOfWork(dbpool, queries)
+		err = uow.Do(context.Background(), func(tx *services.TxServices) error {
+			return tx.Projects.DeleteProject(context.Background(), int32(projectID), user.ID)
+		})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error deleting project: %v\n", err)
 			return
 		}
 
-		fmt.Printf("Project '%s' (ID: %d) deleted successfully\n", project.Name, project.ID)
+		fmt.Printf("Project '%s' (ID: %d) moved to the trash\n", project.Name, project.ID)
+		fmt.Printf("Use 'prod trash restore %d --project' to restore it\n", project.ID)
 	},
 }
 
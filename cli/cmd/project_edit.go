@@ -6,6 +6,7 @@ import (
 	"os"
 	"strconv"
 
+	"github.com/jskallebak/prod/internal/db/sqlc"
 	"github.com/jskallebak/prod/internal/services"
 	"github.com/jskallebak/prod/internal/util"
 	"github.com/spf13/cobra"
@@ -112,7 +113,11 @@ At least one flag must be specified.`,
 		}
 
 		// Update the project
-		updatedProject, err := projectService.UpdateProject(context.Background(), int32(projectID), user.ID, params)
+		var updatedProject *sqlc.Project
+		err = services.NewUnitOfWork(dbpool, queries).Do(context.Background(), func(tx *services.TxServices) error {
+			updatedProject, err = tx.Projects.UpdateProject(context.Background(), int32(projectID), user.ID, params)
+			return err
+		})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error updating project: %v\n", err)
 			return
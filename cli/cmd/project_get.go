@@ -28,7 +28,7 @@ to quickly create a Cobra application.`,
 
 		dbpool, queries, ok := util.InitDBAndQueriesCLI()
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error connection to database: %w")
+			fmt.Fprintf(os.Stderr, "Error connection to database\n")
 			return
 		}
 		defer dbpool.Close()
@@ -38,13 +38,13 @@ to quickly create a Cobra application.`,
 
 		user, err := authService.GetCurrentUser(context.Background())
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting user %w", err)
+			fmt.Fprintf(os.Stderr, "Error getting user %v", err)
 			return
 		}
 
 		proj, err := userService.GetActiveProject(context.Background(), user.ID)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting the active project %w", err)
+			fmt.Fprintf(os.Stderr, "Error getting the active project %v", err)
 			return
 		}
 
@@ -76,7 +76,7 @@ This command shows project details and a summary of associated tasks.`,
 		fmt.Printf("Last Updated: %s\n", project.UpdatedAt.Time.Format("2006-01-02"))
 
 		// Get and display tasks associated with the project
-		tasks, err := projectService.GetProjectTasks(context.Background(), int32(projectID), user.ID)
+		tasks, err := projectService.GetProjectTasks(context.Background(), int32(projectID), user.ID, false)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error retrieving project tasks: %v\n", err)
 			return
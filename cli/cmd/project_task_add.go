@@ -85,8 +85,11 @@ Examples:
 			Notes:       task.Notes,
 		}
 
-		// Call the UpdateTask method directly from queries
-		updatedTask, err := queries.UpdateTask(context.Background(), updateParams)
+		var updatedTask *sqlc.Task
+		err = services.NewUnitOfWork(dbpool, queries).Do(context.Background(), func(tx *services.TxServices) error {
+			updatedTask, err = tx.Tasks.UpdateTask(context.Background(), user.ID, updateParams)
+			return err
+		})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error adding task to project: %v\n", err)
 			return
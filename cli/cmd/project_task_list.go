@@ -14,7 +14,8 @@ import (
 var listTasksCmd = &cobra.Command{
 	Use:   "list [project-id]",
 	Short: "List tasks in a project",
-	Long: `List all tasks associated with a project.
+	Long: `List all tasks associated with a project. Tasks hidden with 'prod task
+wait' are left out until their wait date.
 
 Examples:
   prod project task list 1  # List all tasks in project with ID 1`,
@@ -52,7 +53,7 @@ Examples:
 		}
 
 		// Get tasks for this project
-		tasks, err := projectService.GetProjectTasks(context.Background(), int32(projectID), user.ID)
+		tasks, err := projectService.GetProjectTasks(context.Background(), int32(projectID), user.ID, false)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error retrieving project tasks: %v\n", err)
 			return
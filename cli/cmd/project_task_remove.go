@@ -6,7 +6,6 @@ import (
 	"os"
 	"strconv"
 
-	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jskallebak/prod/internal/db/sqlc"
 	"github.com/jskallebak/prod/internal/services"
 	"github.com/jskallebak/prod/internal/util"
@@ -67,23 +66,12 @@ Examples:
 			return
 		}
 
-		// Create updateParams with task's current values
-		updateParams := sqlc.UpdateTaskParams{
-			ID:          task.ID,
-			UserID:      pgtype.Int4{Int32: user.ID, Valid: true},
-			Description: task.Description,
-			Status:      task.Status,
-			Priority:    task.Priority,
-			DueDate:     task.DueDate,
-			StartDate:   task.StartDate,
-			ProjectID:   pgtype.Int4{Valid: false}, // Set ProjectID to null
-			Recurrence:  task.Recurrence,
-			Tags:        task.Tags,
-			Notes:       task.Notes,
-		}
-
 		// Update the task to remove project association
-		updatedTask, err := queries.UpdateTask(context.Background(), updateParams)
+		var updatedTask *sqlc.Task
+		err = services.NewUnitOfWork(dbpool, queries).Do(context.Background(), func(tx *services.TxServices) error {
+			updatedTask, err = tx.Projects.RemoveTaskFromProject(context.Background(), task.ID, user.ID)
+			return err
+		})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error removing task from project: %v\n", err)
 			return
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var redoSteps int
+
+var redoCmd = &cobra.Command{
+	Use:   "redo",
+	Short: "Apply undone changes again",
+	Long: `Apply operations undone with 'prod undo' again, oldest undo first.
+Making a new change to your tasks or projects clears what can be redone.
+
+Examples:
+  prod redo            # Redo the last undone operation
+  prod redo --steps 2  # Redo two operations`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if redoSteps < 1 {
+			fmt.Fprintf(os.Stderr, "Error: --steps must be at least 1\n")
+			return
+		}
+
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to redo changes")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		var operations []sqlc.JournalOperation
+		uow := services.NewUnitOfWork(dbpool, queries)
+		err = uow.Do(context.Background(), func(tx *services.TxServices) error {
+			operations, err = tx.Journal.Redo(context.Background(), user.ID, redoSteps)
+			return err
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error redoing changes: %v\n", err)
+			return
+		}
+
+		if len(operations) == 0 {
+			fmt.Println("Nothing to redo")
+			return
+		}
+
+		for _, operation := range operations {
+			fmt.Printf("Redid: %s\n", operation.Description)
+		}
+		fmt.Println("\nUse 'prod task list' to refresh task IDs")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(redoCmd)
+
+	redoCmd.Flags().IntVar(&redoSteps, "steps", 1, "Number of operations to redo")
+}
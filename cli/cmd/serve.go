@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/jskallebak/prod/internal/caldav"
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddr  string
+	serveToken string
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Publish your deadlines and sync tasks over CalDAV",
+	Long: `Run a small web server that publishes your tasks with a due date and your
+calendar events as the "prod deadlines" iCalendar feed. Subscribe to
+http://<addr>/deadlines.ics from a calendar app to see your deadlines next to
+your other calendars. The feed is built when it is requested, so it is always
+up to date, and covers 30 days back until a year ahead.
+
+The server is also a CalDAV server at http://<addr>/caldav/, so apps like
+Thunderbird, Apple Reminders or DAVx5 can read and change your tasks and
+events. Tasks without a project, every project and your calendar events are
+calendars of their own. Changes are made like with the CLI, so completing a
+recurring task creates its next occurrence and deleted tasks go to the trash.
+
+The server listens on localhost only unless --addr says otherwise. When it is
+reachable by others, set --token so every request requires ?token=<token>.
+CalDAV apps log in with any user name and the token as password.
+
+Examples:
+  prod serve                                        # Serve on localhost:8080
+  prod serve --addr :8080 --token s3cret            # Serve on all interfaces
+  curl 'http://localhost:8080/deadlines.ics'        # Fetch the feed`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to serve your calendar")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		icalService := services.NewICalService(queries)
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /deadlines.ics", func(w http.ResponseWriter, r *http.Request) {
+			from, to := icalWindow(time.Now())
+			cal, err := icalService.Export(r.Context(), user.ID, from, to)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error exporting calendar: %v\n", err)
+				http.Error(w, "failed to build calendar", http.StatusInternalServerError)
+				return
+			}
+			// Ask subscribed calendar apps to refresh every 15 minutes
+			cal.Properties = append(cal.Properties, services.ICalProperty{
+				Name:   "REFRESH-INTERVAL",
+				Params: map[string]string{"VALUE": "DURATION"},
+				Value:  "PT15M",
+			})
+			cal.Add("X-PUBLISHED-TTL", "PT15M")
+
+			w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+			w.Header().Set("Content-Disposition", `inline; filename="deadlines.ics"`)
+			if err := services.WriteICalendar(w, cal); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+		})
+
+		caldavHandler := caldav.NewHandler(caldav.NewProdBackend(dbpool, queries, user.ID), "/caldav/")
+		mux.Handle("/caldav/", caldavHandler)
+		mux.Handle("/.well-known/caldav", caldavHandler)
+
+		server := &http.Server{
+			Addr: serveAddr,
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !serveAuthorized(r) {
+					w.Header().Set("WWW-Authenticate", `Basic realm="prod"`)
+					http.Error(w, "unauthorized", http.StatusUnauthorized)
+					return
+				}
+				mux.ServeHTTP(w, r)
+			}),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			server.Shutdown(shutdownCtx)
+		}()
+
+		fmt.Printf("Serving %s at http://%s/deadlines.ics\n", services.ICalFeedName, serveAddr)
+		fmt.Printf("Serving CalDAV at http://%s/caldav/\n", serveAddr)
+		fmt.Println("Press Ctrl+C to stop")
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+	},
+}
+
+// serveAuthorized checks the token of a request when --token is set. It is
+// given as ?token= or, by CalDAV apps, as the password
+func serveAuthorized(r *http.Request) bool {
+	if serveToken == "" {
+		return true
+	}
+	token := r.URL.Query().Get("token")
+	if _, password, ok := r.BasicAuth(); ok && token == "" {
+		token = password
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(serveToken)) == 1
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "localhost:8080", "Address to listen on")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "Require ?token=<token> or the token as password on every request")
+}
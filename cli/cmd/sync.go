@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Keep prod in sync with other tools",
+	Long: `Synchronize your tasks both ways with files used by other tools.
+
+Available Commands:
+  todotxt     Sync tasks with a todo.txt file`,
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var syncDryRun bool
+
+var syncTodoTxtCmd = &cobra.Command{
+	Use:   "todotxt <file>",
+	Short: "Sync tasks with a todo.txt file",
+	Long: `Sync your tasks both ways with a todo.txt file, so a todo.txt app on your
+phone can feed prod. Every line gets a prod: tag with the ID of its task.
+
+Lines added in the file become tasks and new pending tasks are appended to the
+file. Edits on either side are copied to the other; when a task changed on
+both sides since the last sync, the most recent change wins and is reported.
+Deleting a line moves its task to the trash and deleting a task removes its
+line. Completed lines archived to done.txt are left alone.
+
+Examples:
+  prod sync todotxt ~/Dropbox/todo/todo.txt             # Sync with a file
+  prod sync todotxt ~/Dropbox/todo/todo.txt --dry-run   # Report what would change`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := filepath.Abs(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return
+		}
+
+		// A missing file is created with all pending tasks
+		var lines []string
+		var modified time.Time
+		info, err := os.Stat(path)
+		if err == nil {
+			modified = info.ModTime()
+			content, err := os.ReadFile(path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", path, err)
+				return
+			}
+			lines = strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+		} else if !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", path, err)
+			return
+		}
+
+		state, err := services.LoadTodoSyncState(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return
+		}
+
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to sync tasks")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		// The new file is written inside the transaction so a failed write
+		// keeps prod unchanged, and it replaces the old one only once the
+		// transaction is committed. The state is saved once both are done
+		var tmp string
+		var synced *services.TodoSyncState
+		var report *services.TodoSyncReport
+		uow := services.NewUnitOfWork(dbpool, queries)
+		err = uow.Do(context.Background(), func(tx *services.TxServices) error {
+			var out []string
+			out, synced, report, err = tx.TodoTxt.Sync(context.Background(), user.ID, lines, state, modified)
+			if err != nil {
+				return err
+			}
+			if syncDryRun {
+				return errDryRun
+			}
+			tmp, err = writeTempTodoTxt(path, out)
+			return err
+		})
+		if err != nil {
+			if tmp != "" {
+				os.Remove(tmp)
+			}
+			if !errors.Is(err, errDryRun) {
+				fmt.Fprintf(os.Stderr, "Error syncing %s: %v\n", path, err)
+				return
+			}
+		}
+
+		if syncDryRun {
+			fmt.Println("Dry run, nothing was changed")
+		} else if err := os.Rename(tmp, path); err != nil {
+			os.Remove(tmp)
+			fmt.Fprintf(os.Stderr, "Error: tasks were synced but %s could not be replaced: %v\n", path, err)
+			return
+		} else if err := services.SaveTodoSyncState(path, synced); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+
+		fmt.Printf("Tasks: %d created, %d updated, %d moved to the trash\n", report.TasksCreated, report.TasksUpdated, report.TasksTrashed)
+		fmt.Printf("Lines: %d added, %d updated, %d removed\n", report.LinesAdded, report.LinesUpdated, report.LinesRemoved)
+		if len(report.Conflicts) > 0 {
+			fmt.Printf("\nConflicts (%d):\n", len(report.Conflicts))
+			for _, conflict := range report.Conflicts {
+				fmt.Printf("  - %s\n", conflict)
+			}
+		}
+	},
+}
+
+// writeTempTodoTxt writes the lines to a temporary file next to path, which
+// is renamed over it so the file is never left half written
+func writeTempTodoTxt(path string, lines []string) (string, error) {
+	content := strings.Join(lines, "\n")
+	if content != "" {
+		content += "\n"
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".todo-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return tmp.Name(), nil
+}
+
+func init() {
+	syncCmd.AddCommand(syncTodoTxtCmd)
+
+	syncTodoTxtCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Report what would change without changing anything")
+}
@@ -21,6 +21,9 @@ var (
 	taskTags       []string
 	taskNotes      string
 	taskRecurrence string
+	taskEstimate   string
+	taskWait       string
+	taskScheduled  string
 	dependent      int
 	interactive    bool
 )
@@ -33,7 +36,13 @@ var addCmd = &cobra.Command{
 	
 For example:
   prod task add "Make breakfast"
-  prod task add "Finish report" --priority=H --due=2025-04-01 --project=2 --tags=work,urgent`,
+  prod task add "Finish report" --priority=H --due=2025-04-01 --project=2 --tags=work,urgent
+  prod task add "Write chapter" --est 4p    # Estimate 4 pomodoros
+  prod task add "Review PR" --est 90m       # Estimate 90 minutes
+  prod task add "Renew passport" --wait 2025-09-01 --scheduled +2w
+
+Estimates are stored in minutes. Pomodoros are converted with your current
+pomodoro length when the task is added and don't change with it later.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Combine all arguments into a single task description
 		description := strings.Join(args, " ")
@@ -49,7 +58,7 @@ For example:
 
 		// Create queries and services
 		queries := sqlc.New(dbpool)
-		taskService := services.NewTaskService(queries)
+		uow := services.NewUnitOfWork(dbpool, queries)
 		authService := services.NewAuthService(queries)
 		userService := services.NewUserService(queries)
 
@@ -65,7 +74,7 @@ For example:
 		}
 
 		if interactive {
-			addIMode(user, taskService)
+			addIMode(user, uow)
 			return
 		}
 
@@ -179,7 +188,31 @@ For example:
 			params.Recurrence = &taskRecurrence
 		}
 
-		task, err := taskService.CreateTask(context.Background(), user.ID, params)
+		// Add estimate if provided
+		if cmd.Flags().Changed("est") {
+			estimate, err := parseEstimateFlag(queries, user.ID, taskEstimate)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid estimate: %v\n", err)
+				return
+			}
+			params.EstimateMinutes = estimate
+		}
+
+		// Add wait and scheduled days if provided
+		if cmd.Flags().Changed("wait") {
+			if params.WaitUntil, err = parseDayFlag(taskWait); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid wait date: %v\n", err)
+				return
+			}
+		}
+		if cmd.Flags().Changed("scheduled") {
+			if params.Scheduled, err = parseDayFlag(taskScheduled); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid scheduled date: %v\n", err)
+				return
+			}
+		}
+
+		task, err := createTask(uow, user.ID, params)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error creating task: %v\n", err)
 			return
@@ -212,11 +245,14 @@ func init() {
 	addCmd.Flags().StringSliceVarP(&taskTags, "tags", "t", []string{}, "Task tags (comma-separated)")
 	addCmd.Flags().StringVarP(&taskNotes, "notes", "n", "", "Additional notes for the task")
 	addCmd.Flags().StringVarP(&taskRecurrence, "recur", "r", "", "Recurrence pattern (e.g., daily:1)")
+	addCmd.Flags().StringVar(&taskEstimate, "est", "", "Estimated effort in pomodoros (4p) or time (90m)")
+	addCmd.Flags().StringVar(&taskWait, "wait", "", "Hide the task until a day (YYYY-MM-DD, tomorrow, mon, +2d)")
+	addCmd.Flags().StringVar(&taskScheduled, "scheduled", "", "Day to begin work on the task (YYYY-MM-DD, tomorrow, mon, +2d)")
 	addCmd.Flags().IntVarP(&dependent, "subtask", "s", 0, "Makes a sub task of a task")
 	addCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Interactive add")
 }
 
-func addIMode(user *sqlc.User, ts *services.TaskService) {
+func addIMode(user *sqlc.User, uow *services.UnitOfWork) {
 	params := services.TaskParams{}
 	var description string
 
@@ -358,7 +394,7 @@ func addIMode(user *sqlc.User, ts *services.TaskService) {
 		return
 	}
 
-	task, err := ts.CreateTask(context.Background(), user.ID, params)
+	task, err := createTask(uow, user.ID, params)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating task: %v\n", err)
 		return
@@ -376,10 +412,35 @@ func addIMode(user *sqlc.User, ts *services.TaskService) {
 		return
 	}
 
-	fmt.Printf("Created task: %s (ID: %d) (dbID: %s)\n", description, index, task.ID)
+	fmt.Printf("Created task: %s (ID: %d) (dbID: %d)\n", description, index, task.ID)
 	fmt.Printf("Created at: %s\n", task.CreatedAt.Time.Format("2006-01-02 15:04"))
 }
 
+// createTask creates a task together with its undo step and history
+func createTask(uow *services.UnitOfWork, userID int32, params services.TaskParams) (*sqlc.Task, error) {
+	var task *sqlc.Task
+	err := uow.Do(context.Background(), func(tx *services.TxServices) error {
+		var err error
+		task, err = tx.Tasks.CreateTask(context.Background(), userID, params)
+		return err
+	})
+	return task, err
+}
+
+// parseEstimateFlag converts an estimate like 4p or 90m to minutes,
+// counting pomodoros with the user's configured work duration. Only the
+// minutes are stored, so changing the duration later keeps the estimate
+func parseEstimateFlag(queries *sqlc.Queries, userID int32, input string) (*int32, error) {
+	pomoService := services.NewPomodoroService(queries)
+	minutes, err := util.ParseEstimate(input, pomoService.PomodoroMinutes(context.Background(), userID))
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := int32(minutes)
+	return &estimate, nil
+}
+
 // Run task add command
 func runAddCommand(cmd *cobra.Command, args []string, isSubcommand bool) {
 	// Check for description
@@ -406,7 +467,7 @@ func runAddCommand(cmd *cobra.Command, args []string, isSubcommand bool) {
 	defer dbpool.Close()
 
 	// Create queries and services
-	taskService := services.NewTaskService(queries)
+	uow := services.NewUnitOfWork(dbpool, queries)
 	authService := services.NewAuthService(queries)
 	userService := services.NewUserService(queries)
 
@@ -422,7 +483,7 @@ func runAddCommand(cmd *cobra.Command, args []string, isSubcommand bool) {
 	}
 
 	if interactive {
-		addIMode(user, taskService)
+		addIMode(user, uow)
 		return
 	}
 
@@ -536,7 +597,17 @@ func runAddCommand(cmd *cobra.Command, args []string, isSubcommand bool) {
 		params.Recurrence = &taskRecurrence
 	}
 
-	task, err := taskService.CreateTask(context.Background(), user.ID, params)
+	// Add estimate if provided
+	if cmd.Flags().Changed("est") {
+		estimate, err := parseEstimateFlag(queries, user.ID, taskEstimate)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid estimate: %v\n", err)
+			return
+		}
+		params.EstimateMinutes = estimate
+	}
+
+	task, err := createTask(uow, user.ID, params)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating task: %v\n", err)
 		return
@@ -566,7 +637,7 @@ func createAlternatingTestTasks() {
 	}
 	defer dbpool.Close()
 
-	taskService := services.NewTaskService(queries)
+	uow := services.NewUnitOfWork(dbpool, queries)
 	authService := services.NewAuthService(queries)
 
 	user, err := authService.GetCurrentUser(context.Background())
@@ -595,7 +666,7 @@ func createAlternatingTestTasks() {
 		today := time.Now()
 		params.DueDate = &today
 
-		task, err := taskService.CreateTask(context.Background(), user.ID, params)
+		task, err := createTask(uow, user.ID, params)
 		if err != nil {
 			fmt.Printf("Error creating test task: %v\n", err)
 			continue
@@ -17,8 +17,9 @@ var confirmDelete bool
 var deleteTaskCmd = &cobra.Command{
 	Use:   "delete [task_id]",
 	Short: "Delete a task",
-	Long: `Delete a task from your productivity system.
-	
+	Long: `Move a task and its subtasks to the trash. Use 'prod trash list' and
+'prod trash restore <id>' to get them back.
+
 For example:
   prod task delete 5         # Prompts for confirmation
   prod task delete 5 --yes   # Deletes without confirmation`,
@@ -38,6 +39,7 @@ For example:
 
 		// Create queries and service
 		queries := sqlc.New(dbpool)
+		uow := services.NewUnitOfWork(dbpool, queries)
 		taskService := services.NewTaskService(queries)
 		authService := services.NewAuthService(queries)
 
@@ -51,16 +53,27 @@ For example:
 			return ConfirmCmd(ctx, taskID, userID, ActionType(action), ts)
 		}
 
+		// Confirm every task and its subtasks before locking anything
+		type deletion struct {
+			input    int
+			taskID   int32
+			subtasks []sqlc.Task
+		}
+		var deletions []deletion
+		seen := make(map[int32]bool)
 		for _, input := range inputs {
 			taskID, err := services.GetID(services.GetTaskMap, input)
 			if err != nil {
 				fmt.Fprintln(os.Stderr, err)
 				return
 			}
+			if seen[taskID] {
+				continue
+			}
 
-			err = services.RecursiveSubtasks(ctx, user.ID, taskID, taskService, "delete", input, adaptedConfirm, taskService.DeleteTask)
+			subtasks, err := services.ConfirmSubtasks(ctx, user.ID, taskID, taskService, "delete", adaptedConfirm)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "DeleteCmd: %v", err)
+				fmt.Fprintf(os.Stderr, "DeleteCmd: %v\n", err)
 				return
 			}
 
@@ -71,19 +84,41 @@ For example:
 					return
 				}
 			}
+			seen[taskID] = true
+			deletions = append(deletions, deletion{input, taskID, unseenTasks(seen, subtasks)})
+		}
 
-			_, err = taskService.DeleteTask(ctx, taskID, user.ID)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "task_delete: Error deleting task: %v\n", err)
-				return
+		// Delete all the tasks with their subtasks, or none, as one undo step
+		err = uow.Do(ctx, func(tx *services.TxServices) error {
+			if len(deletions) > 1 {
+				if err := tx.Describe(ctx, user.ID, fmt.Sprintf("delete %d tasks", len(deletions))); err != nil {
+					return err
+				}
 			}
+			for _, d := range deletions {
+				if err := services.ApplyToSubtasks(ctx, user.ID, d.subtasks, "deleted", tx.Tasks.DeleteTask); err != nil {
+					return fmt.Errorf("DeleteCmd: %v", err)
+				}
+
+				_, err := tx.Tasks.DeleteTask(ctx, d.taskID, user.ID)
+				if err != nil {
+					return fmt.Errorf("task_delete: Error deleting task: %v", err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			return
+		}
 
-			err = services.RemoveFromMap(input)
+		for _, d := range deletions {
+			err = services.RemoveFromMap(d.input)
 			if err != nil {
 				fmt.Println(err)
 			}
 
-			fmt.Printf("Task %d deleted successfully\n", input)
+			fmt.Printf("Task %d moved to the trash\n", d.input)
 		}
 	},
 }
@@ -18,7 +18,10 @@ var doneCmd = &cobra.Command{
 	Long: `Mark a task as completed in your productivity system.
 	
 For example:
-  prod task done 5  # Marks task with ID 5 as completed`,
+  prod task done 5  # Marks task with ID 5 as completed
+
+With 'prod pomo config --link-tasks', completing the task a Pomodoro session
+is running on offers to stop that session as well.`,
 	// Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := context.Background()
@@ -34,7 +37,9 @@ For example:
 
 		// Create queries and services
 		queries := sqlc.New(dbpool)
+		uow := services.NewUnitOfWork(dbpool, queries)
 		taskService := services.NewTaskService(queries)
+		pomoService := services.NewPomodoroService(queries)
 		authService := services.NewAuthService(queries)
 
 		user, err := authService.GetCurrentUser(ctx)
@@ -43,18 +48,31 @@ For example:
 			return
 		}
 
+		adaptedConfirm := func(ctx context.Context, taskID int32, userID int32, action string, ts *services.TaskService) error {
+			return ConfirmCmd(ctx, taskID, userID, ActionType(action), ts)
+		}
+
+		// Collect every answer before the transaction starts
+		type completion struct {
+			input       int
+			taskID      int32
+			subtasks    []sqlc.Task
+			stopSession bool
+			linked      *services.LinkedStop
+		}
+		var completions []completion
+		seen := make(map[int32]bool)
 		for _, input := range inputs {
 			taskID, err := services.GetID(services.GetTaskMap, input)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "doneCmd: getID: %v\n", err)
 				return
 			}
-
-			adaptedConfirm := func(ctx context.Context, taskID int32, userID int32, action string, ts *services.TaskService) error {
-				return ConfirmCmd(ctx, taskID, userID, ActionType(action), ts)
+			if seen[taskID] {
+				continue
 			}
 
-			err = services.RecursiveSubtasks(ctx, user.ID, taskID, taskService, "finish", input, adaptedConfirm, taskService.CompleteTask)
+			subtasks, err := services.ConfirmSubtasks(ctx, user.ID, taskID, taskService, "finish", adaptedConfirm)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "doneCmd: %v\n", err)
 				return
@@ -66,39 +84,93 @@ For example:
 				return
 			}
 
-			// Complete the task
-			completedTask, newTask, err := taskService.CompleteRecurringTask(ctx, taskID, user.ID)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "doneCmd: taskService.CompleteRecurringTask: %v\n", err)
-				return
+			// Offer to stop a linked Pomodoro session running on this task
+			stopSession := false
+			if pomoService.LinkTasksEnabled(ctx, user.ID) {
+				session, err := pomoService.GetActiveSession(ctx, user.ID)
+				if err == nil && session.TaskID != nil && *session.TaskID == taskID {
+					fmt.Print("A Pomodoro session is running on this task. Stop it? (Y/n): ")
+					var answer string
+					fmt.Scanln(&answer)
+					stopSession = answer != "n" && answer != "N"
+				}
+			}
+
+			seen[taskID] = true
+			completions = append(completions, completion{
+				input:       input,
+				taskID:      taskID,
+				subtasks:    unseenTasks(seen, subtasks),
+				stopSession: stopSession,
+			})
+		}
+
+		// Complete the tasks and their subtasks, and stop a linked session, in
+		// one transaction that is undone in one step
+		err = uow.Do(ctx, func(tx *services.TxServices) error {
+			if len(completions) > 1 {
+				if err := tx.Describe(ctx, user.ID, fmt.Sprintf("complete %d tasks", len(completions))); err != nil {
+					return err
+				}
+			}
+			for i := range completions {
+				c := &completions[i]
+				if err := services.ApplyToSubtasks(ctx, user.ID, c.subtasks, "completed", tx.Tasks.CompleteTask); err != nil {
+					return err
+				}
+
+				c.linked, err = tx.Pomodoros.CompleteTaskAndStopSession(ctx, user.ID, c.taskID, c.stopSession)
+				if err != nil {
+					return err
+				}
 			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "doneCmd: %v\n", err)
+			return
+		}
 
-			fmt.Printf("Task %d marked as completed\n", input)
+		for _, c := range completions {
+			completedTask, newTask := c.linked.Task, c.linked.NextTask
+
+			fmt.Printf("Task %d marked as completed\n", c.input)
 			fmt.Printf("Description: %s\n", completedTask.Description)
 			fmt.Printf("Completed at: %s\n", completedTask.CompletedAt.Time.Format("2006-01-02 15:04:05"))
 
+			if c.linked.Session != nil {
+				savePomodoroState(queries, user.ID, nil)
+				fmt.Printf("🍅 Pomodoro session %s\n", c.linked.Session.Status)
+			}
+
 			// If this was a recurring task and a new task was created
 			if newTask != nil {
-				// Get a new task display ID for the newly created task
-				taskMap, index, err := services.AppendToMap(newTask.ID)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error adding new recurring task to map: %v\n", err)
-				} else {
-					err = services.MakeTaskMapFile(taskMap)
-					if err != nil {
-						fmt.Fprintf(os.Stderr, "Error updating task map: %v\n", err)
-					}
-
-					fmt.Printf("\nNext occurrence created as task %d\n", index)
-					if newTask.DueDate.Valid {
-						fmt.Printf("Due: %s\n", newTask.DueDate.Time.Format("2006-01-02"))
-					}
-				}
+				printNextOccurrence(newTask)
 			}
 		}
 	},
 }
 
+// printNextOccurrence adds the next instance of a recurring task to the task map and prints it
+func printNextOccurrence(newTask *sqlc.Task) {
+	// Get a new task display ID for the newly created task
+	taskMap, index, err := services.AppendToMap(newTask.ID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error adding new recurring task to map: %v\n", err)
+		return
+	}
+
+	err = services.MakeTaskMapFile(taskMap)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error updating task map: %v\n", err)
+	}
+
+	fmt.Printf("\nNext occurrence created as task %d\n", index)
+	if newTask.DueDate.Valid {
+		fmt.Printf("Due: %s\n", newTask.DueDate.Time.Format("2006-01-02"))
+	}
+}
+
 func init() {
 	taskCmd.AddCommand(doneCmd)
 }
@@ -9,6 +9,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/jskallebak/prod/internal/db/sqlc"
 	"github.com/jskallebak/prod/internal/services"
 	"github.com/jskallebak/prod/internal/util"
 	"github.com/spf13/cobra"
@@ -53,6 +54,17 @@ to quickly create a Cobra application.`,
 			fmt.Fprintf(os.Stderr, "due: error in ParseArgs: %v\n", err)
 		}
 
+		var parsedDate *time.Time
+		if date != "" {
+			parsed, err := time.Parse("2006-01-02", date)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Due: Invalid date format %v\n", err)
+				return
+			}
+			parsedDate = &parsed
+		}
+
+		var taskIDs []int32
 		for _, input := range inputs {
 			taskID, err := services.GetID(services.GetTaskMap, input)
 			if err != nil {
@@ -67,29 +79,35 @@ to quickly create a Cobra application.`,
 					return
 				}
 			}
+			taskIDs = append(taskIDs, taskID)
+		}
 
-			var parsedDate *time.Time
-			if date != "" {
-				parsed, err := time.Parse("2006-01-02", date)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Due: Invalid date format %v\n", err)
-					return
+		// Set every due date in one transaction, undone in one step
+		tasks := make([]*sqlc.Task, len(taskIDs))
+		err = services.NewUnitOfWork(dbpool, queries).Do(ctx, func(tx *services.TxServices) error {
+			if len(taskIDs) > 1 {
+				if err := tx.Describe(ctx, user.ID, fmt.Sprintf("set due date of %d tasks", len(taskIDs))); err != nil {
+					return err
 				}
-				parsedDate = &parsed
 			}
-
-			task, err := taskService.SetDue(ctx, user.ID, taskID, parsedDate)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Due: %v", err)
-				return
+			for i, taskID := range taskIDs {
+				tasks[i], err = tx.Tasks.SetDue(ctx, user.ID, taskID, parsedDate)
+				if err != nil {
+					return err
+				}
 			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Due: %v", err)
+			return
+		}
 
-			fmt.Printf("Task %d due_date set to today\n", input)
+		for i, task := range tasks {
+			fmt.Printf("Task %d due_date set to today\n", inputs[i])
 			fmt.Printf("Description: %s\n", task.Description)
 			fmt.Printf("updated at: %s\n", task.UpdatedAt.Time.Format("2006-01-02 15:04:05"))
-
 		}
-
 	},
 }
 
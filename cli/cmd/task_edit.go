@@ -23,6 +23,10 @@ var (
 	editNotes     string
 	editDesc      string
 	editStatus    string
+	editEstimate  string
+	editWait      string
+	editScheduled string
+	editBulk      bool
 )
 
 // editCmd represents the edit command
@@ -35,6 +39,9 @@ For example:
   prod task edit 5 --desc="Updated task description"
   prod task edit 5 --priority=H --due=2025-04-01 --project=2 --tags=work,urgent --notes="Important update"
   prod task edit 5 --status=completed
+  prod task edit 5 --est 3p
+  prod task edit 5 --scheduled monday
+  prod task edit --bulk project:Home    # Edit the tasks of Home in $EDITOR
 
 Available flags:
   --desc        Update task description
@@ -43,9 +50,20 @@ Available flags:
   --project     Set project ID
   --tags        Set tags (comma separated)
   --notes       Set additional notes
-  --status      Set status (pending/completed)`,
+  --status      Set status (pending/completed)
+  --est         Set estimate (4p, 90m, or none to clear), 4p is stored as
+                4 times your current pomodoro length
+  --wait        Hide the task until a day (YYYY-MM-DD, tomorrow, mon, +2d, or none to clear)
+  --scheduled   Set the day to begin work (YYYY-MM-DD, tomorrow, mon, +2d, or none to clear)
+  --bulk        Edit the tasks matching a filter (see 'prod task modify') or
+                the pending and active tasks in $EDITOR, one line each`,
 	// Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		if editBulk {
+			runBulkEdit(args)
+			return
+		}
+
 		// Parse task ID from arguments
 		inputs, err := util.ParseArgs(args)
 
@@ -146,16 +164,71 @@ Available flags:
 
 			}
 
+			var estimate *int32
+			if cmd.Flags().Changed("est") && editEstimate != "none" {
+				estimate, err = parseEstimateFlag(queries, user.ID, editEstimate)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Invalid estimate: %v\n", err)
+					return
+				}
+			}
+
+			var waitUntil, scheduled *time.Time
+			if cmd.Flags().Changed("wait") {
+				if waitUntil, err = parseDayFlag(editWait); err != nil {
+					fmt.Fprintf(os.Stderr, "Invalid wait date: %v\n", err)
+					return
+				}
+			}
+			if cmd.Flags().Changed("scheduled") {
+				if scheduled, err = parseDayFlag(editScheduled); err != nil {
+					fmt.Fprintf(os.Stderr, "Invalid scheduled date: %v\n", err)
+					return
+				}
+			}
+
 			err = ConfirmCmd(ctx, taskID, user.ID, EDIT, taskService)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "%s\n", err)
 				return
 			}
 
-			// Call the service to update the task
-			updatedTask, err := queries.UpdateTask(ctx, updateParams)
+			// Update the task and its estimate together
+			var updatedTask sqlc.Task
+			err = services.NewUnitOfWork(dbpool, queries).Do(ctx, func(tx *services.TxServices) error {
+				task, err := tx.Tasks.UpdateTask(ctx, user.ID, updateParams)
+				if err != nil {
+					return fmt.Errorf("Error updating task: %v", err)
+				}
+				updatedTask = *task
+
+				if cmd.Flags().Changed("est") {
+					estimatedTask, err := tx.Tasks.SetEstimate(ctx, user.ID, updatedTask.ID, estimate)
+					if err != nil {
+						return fmt.Errorf("Error updating estimate: %v", err)
+					}
+					updatedTask = *estimatedTask
+				}
+
+				if cmd.Flags().Changed("wait") {
+					waitingTask, err := tx.Tasks.SetWait(ctx, user.ID, updatedTask.ID, waitUntil)
+					if err != nil {
+						return fmt.Errorf("Error updating wait date: %v", err)
+					}
+					updatedTask = *waitingTask
+				}
+
+				if cmd.Flags().Changed("scheduled") {
+					scheduledTask, err := tx.Tasks.SetScheduled(ctx, user.ID, updatedTask.ID, scheduled)
+					if err != nil {
+						return fmt.Errorf("Error updating scheduled date: %v", err)
+					}
+					updatedTask = *scheduledTask
+				}
+				return nil
+			})
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error updating task: %v\n", err)
+				fmt.Fprintf(os.Stderr, "%v\n", err)
 				return
 			}
 
@@ -173,6 +246,15 @@ Available flags:
 			if updatedTask.Notes.Valid {
 				fmt.Printf("Notes: %s\n", updatedTask.Notes.String)
 			}
+			if updatedTask.EstimateMinutes.Valid {
+				fmt.Printf("Estimate: %d min\n", updatedTask.EstimateMinutes.Int32)
+			}
+			if updatedTask.WaitUntil.Valid {
+				fmt.Printf("Wait until: %s\n", updatedTask.WaitUntil.Time.Format("2006-01-02"))
+			}
+			if updatedTask.Scheduled.Valid {
+				fmt.Printf("Scheduled: %s\n", updatedTask.Scheduled.Time.Format("2006-01-02"))
+			}
 
 		}
 	},
@@ -189,4 +271,8 @@ func init() {
 	editCmd.Flags().StringSliceVarP(&editTags, "tags", "t", []string{}, "Task tags (comma-separated)")
 	editCmd.Flags().StringVar(&editNotes, "notes", "", "Additional notes for the task")
 	editCmd.Flags().StringVarP(&editStatus, "status", "s", "", "Task status (pending, active, completed, archived)")
+	editCmd.Flags().StringVar(&editEstimate, "est", "", "Estimated effort in pomodoros (4p) or time (90m), none to clear")
+	editCmd.Flags().StringVar(&editWait, "wait", "", "Hide the task until a day, none to clear")
+	editCmd.Flags().StringVar(&editScheduled, "scheduled", "", "Day to begin work on the task, none to clear")
+	editCmd.Flags().BoolVar(&editBulk, "bulk", false, "Edit many tasks as text in $EDITOR")
 }
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+)
+
+const bulkEditHeader = `# Edit the tasks and save the file to apply the changes. Each line is
+#   [x] #<number> [(H|M|L)] [due:YYYY-MM-DD] [+tag...] [project:Name] description
+# Indent a line by two spaces to make it a subtask of the line above it.
+# Add lines without a #number to create tasks, start a line with x to
+# complete its task and remove a line to delete its task. Lines starting
+# with "# " are ignored, and an unchanged file changes nothing.
+`
+
+// runBulkEdit opens the tasks matching a filter in $EDITOR and applies the
+// changes made to the file
+func runBulkEdit(args []string) {
+	dbpool, queries, ok := util.InitDBAndQueriesCLI()
+	if !ok {
+		return
+	}
+	defer dbpool.Close()
+
+	// Get authenticated user
+	ctx := context.Background()
+	authService := services.NewAuthService(queries)
+	user, err := authService.GetCurrentUser(ctx)
+	if err != nil {
+		fmt.Println("You need to be logged in to edit tasks")
+		fmt.Println("Use 'prod login' to authenticate")
+		return
+	}
+
+	taskService := services.NewTaskService(queries)
+	var tasks []sqlc.Task
+	if len(args) == 0 {
+		tasks, err = taskService.FilterTasks(ctx, user.ID, services.TaskFilter{})
+	} else {
+		tasks, err = selectTasks(ctx, taskService, user.ID, args)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return
+	}
+
+	projects, err := services.NewProjectService(queries).ListProjects(ctx, user.ID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing projects: %v\n", err)
+		return
+	}
+	projectNames := make(map[int32]string, len(projects))
+	for _, project := range projects {
+		projectNames[project.ID] = project.Name
+	}
+
+	// The numbers in the file are also the task numbers until the next list
+	ordered, lines := services.FormatBulkEdit(tasks, projectNames)
+	if err := services.MakeTaskMapFile(services.MakeTaskMap(ordered)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error making task map file: %v\n", err)
+	}
+	content := bulkEditHeader + "\n" + strings.Join(lines, "\n") + "\n"
+
+	file, err := os.CreateTemp("", "prod-tasks-*.txt")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating file to edit: %v\n", err)
+		return
+	}
+	path := file.Name()
+	_, err = file.WriteString(content)
+	file.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing file to edit: %v\n", err)
+		os.Remove(path)
+		return
+	}
+
+	if err := openEditor(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Error running editor: %v\n", err)
+		os.Remove(path)
+		return
+	}
+
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading edited file: %v\n", err)
+		os.Remove(path)
+		return
+	}
+	if string(edited) == content {
+		os.Remove(path)
+		fmt.Println("No changes")
+		return
+	}
+
+	// The file is kept on errors so the edits aren't lost
+	edit, err := services.PlanBulkEdit(user.ID, ordered, strings.Split(string(edited), "\n"), projectNames)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Printf("Your edits are kept in %s\n", path)
+		return
+	}
+
+	var created, changed, completed int
+	for _, line := range edit.Lines {
+		switch {
+		case line.Before == nil:
+			created++
+		case !line.Changed(user.ID):
+		case line.Task.Status == "completed" && line.Before.Status != "completed":
+			completed++
+		default:
+			changed++
+		}
+	}
+	if created+changed+completed+len(edit.Deleted) == 0 {
+		os.Remove(path)
+		fmt.Println("No changes")
+		return
+	}
+
+	if len(edit.Deleted) > 0 {
+		fmt.Println("These tasks will be deleted:")
+		for _, task := range edit.Deleted {
+			fmt.Printf("  %s\n", task.Description)
+		}
+		fmt.Printf("Delete %d tasks? (y/N): ", len(edit.Deleted))
+		var confirmation string
+		fmt.Scanln(&confirmation)
+		if confirmation != "y" && confirmation != "Y" {
+			fmt.Println("Operation cancelled")
+			fmt.Printf("Your edits are kept in %s\n", path)
+			return
+		}
+	}
+
+	err = services.NewUnitOfWork(dbpool, queries).Do(ctx, func(tx *services.TxServices) error {
+		return tx.Tasks.ApplyBulkEdit(ctx, user.ID, edit)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving tasks: %v\n", err)
+		if errors.Is(err, services.ErrEditConflict) {
+			fmt.Println("Nothing was saved. Run 'prod task edit --bulk' again to edit the tasks as they are now")
+		}
+		fmt.Printf("Your edits are kept in %s\n", path)
+		return
+	}
+	os.Remove(path)
+
+	fmt.Printf("Created %d, changed %d, completed %d and deleted %d tasks\n", created, changed, completed, len(edit.Deleted))
+	fmt.Println("Use 'prod task list' to see the new task numbers")
+}
+
+// openEditor edits a file with $VISUAL or $EDITOR, vi when neither is set
+func openEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// The editor may come with arguments, like "code --wait"
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,11 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkEditFlag(t *testing.T) {
+	assert.NotNil(t, editCmd.Flag("bulk"), "bulk flag should exist")
+}
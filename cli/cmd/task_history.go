@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyPostponed bool
+	historyDays      int
+	historyLimit     int
+)
+
+// historyCmd represents the task history command
+var historyCmd = &cobra.Command{
+	Use:   "history [task_id]",
+	Short: "Show the change history of a task",
+	Long: `Show every change made to a task as a timeline: status transitions,
+priority changes, due date moves, re-parenting, tags and more. The history
+is kept when a task is deleted.
+
+With --postponed, list the tasks whose due date was pushed back most often.
+
+Examples:
+  prod task history 5                    # Timeline of task 5
+  prod task history --postponed          # Most postponed tasks in the last 30 days
+  prod task history --postponed --days 7 # Most postponed tasks this week`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !historyPostponed && len(args) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: a task ID is required unless --postponed is used\n")
+			return
+		}
+
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to view task history")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		taskService := services.NewTaskService(queries)
+		projectService := services.NewProjectService(queries)
+
+		if historyPostponed {
+			showPostponedTasks(taskService, user.ID)
+			return
+		}
+
+		input, err := util.Input2Int(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Invalid task ID\n")
+			return
+		}
+		taskID, err := services.GetID(services.GetTaskMap, input)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Invalid task ID\n")
+			return
+		}
+
+		events, err := taskService.GetTaskHistory(context.Background(), user.ID, taskID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting task history: %v\n", err)
+			return
+		}
+		if len(events) == 0 {
+			fmt.Printf("No history recorded for task %d\n", input)
+			return
+		}
+
+		title := events[0].NewValue.String
+		if task, err := taskService.GetTask(context.Background(), taskID, user.ID); err == nil {
+			title = task.Description
+		}
+		fmt.Printf("\nHistory of task %d: %s\n\n", input, title)
+
+		names := &historyNames{
+			tasks:    taskService,
+			projects: projectService,
+			userID:   user.ID,
+		}
+		for _, event := range events {
+			fmt.Printf("  %s  %s\n", event.CreatedAt.Local().Format("2006-01-02 15:04"), names.describe(event))
+		}
+
+		pushbacks, err := taskService.CountDueDatePushbacks(context.Background(), user.ID, taskID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error counting due date changes: %v\n", err)
+			return
+		}
+		if pushbacks > 0 {
+			fmt.Printf("\nDue date pushed back %d time(s)\n", pushbacks)
+		}
+	},
+}
+
+func showPostponedTasks(taskService *services.TaskService, userID int32) {
+	since := time.Now().AddDate(0, 0, -historyDays)
+	rows, err := taskService.ListPostponedTasks(context.Background(), userID, since, int32(historyLimit))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing postponed tasks: %v\n", err)
+		return
+	}
+
+	if len(rows) == 0 {
+		fmt.Printf("No due dates were pushed back in the last %d days\n", historyDays)
+		return
+	}
+
+	fmt.Printf("\nMost postponed tasks in the last %d days:\n\n", historyDays)
+	for _, row := range rows {
+		description := row.Description.String
+		if !row.Description.Valid {
+			description = "(deleted task)"
+		}
+		fmt.Printf("  %3dx  %s\n", row.Pushbacks, description)
+	}
+}
+
+// historyNames resolves the IDs stored in task events to names, caching lookups
+type historyNames struct {
+	tasks    *services.TaskService
+	projects *services.ProjectService
+	userID   int32
+	cache    map[string]string
+}
+
+func (n *historyNames) describe(event sqlc.TaskEvent) string {
+	oldValue, newValue := event.OldValue.String, event.NewValue.String
+
+	switch event.EventType {
+	case services.TaskEventCreated:
+		return fmt.Sprintf("Created %q", newValue)
+	case services.TaskEventDeleted:
+		return "Deleted"
+	case services.TaskEventRestored:
+		return "Restored from trash"
+	case services.TaskEventDescription:
+		return fmt.Sprintf("Renamed %q → %q", oldValue, newValue)
+	case services.TaskEventDueDate:
+		change := fmt.Sprintf("Due date: %s → %s", historyDate(oldValue), historyDate(newValue))
+		if event.OldValue.Valid && event.NewValue.Valid && newValue > oldValue {
+			change += " (pushed back)"
+		}
+		return change
+	case services.TaskEventStartDate:
+		return fmt.Sprintf("Start date: %s → %s", historyDate(oldValue), historyDate(newValue))
+	case services.TaskEventWaitUntil:
+		return fmt.Sprintf("Waiting until: %s → %s", historyDate(oldValue), historyDate(newValue))
+	case services.TaskEventScheduled:
+		return fmt.Sprintf("Scheduled: %s → %s", historyDate(oldValue), historyDate(newValue))
+	case services.TaskEventProject:
+		return fmt.Sprintf("Project: %s → %s", n.project(oldValue), n.project(newValue))
+	case services.TaskEventParent:
+		return fmt.Sprintf("Parent: %s → %s", n.task(oldValue), n.task(newValue))
+	case services.TaskEventEstimate:
+		return fmt.Sprintf("Estimate: %s → %s", historyMinutes(oldValue), historyMinutes(newValue))
+	case services.TaskEventNotes:
+		return "Notes changed"
+	default:
+		label := strings.ToUpper(event.EventType[:1]) + event.EventType[1:]
+		return fmt.Sprintf("%s: %s → %s", label, historyValue(oldValue), historyValue(newValue))
+	}
+}
+
+func (n *historyNames) project(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return n.lookup("project:"+value, func(id int32) (string, error) {
+		project, err := n.projects.GetProject(context.Background(), id, n.userID)
+		if err != nil {
+			return "", err
+		}
+		return project.Name, nil
+	}, value)
+}
+
+func (n *historyNames) task(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return n.lookup("task:"+value, func(id int32) (string, error) {
+		task, err := n.tasks.GetTask(context.Background(), id, n.userID)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%q", task.Description), nil
+	}, value)
+}
+
+func (n *historyNames) lookup(key string, find func(int32) (string, error), value string) string {
+	if name, ok := n.cache[key]; ok {
+		return name
+	}
+	if n.cache == nil {
+		n.cache = map[string]string{}
+	}
+
+	name := "#" + value
+	if id, err := strconv.Atoi(value); err == nil {
+		if found, err := find(int32(id)); err == nil {
+			name = found
+		}
+	}
+	n.cache[key] = name
+	return name
+}
+
+func historyValue(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
+
+func historyDate(value string) string {
+	if value == "" {
+		return "none"
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	t = t.Local()
+	if t.Hour() == 0 && t.Minute() == 0 {
+		return t.Format("2006-01-02")
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+func historyMinutes(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value + "m"
+}
+
+func init() {
+	taskCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().BoolVar(&historyPostponed, "postponed", false, "List the tasks whose due date was pushed back most often")
+	historyCmd.Flags().IntVar(&historyDays, "days", 30, "Number of days to look back with --postponed")
+	historyCmd.Flags().IntVar(&historyLimit, "limit", 10, "Maximum number of tasks to list with --postponed")
+}
@@ -6,6 +6,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"math"
 	"os"
 	"regexp"
 	"strconv"
@@ -30,12 +31,14 @@ var (
 	showToday     bool
 	showTable     bool
 	showRecurring bool
+	showWaiting   bool
 )
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List your tasks",
-	Long: `List all your tasks or filter them by priority.
+	Long: `List all your tasks or filter them by priority. Tasks are listed by
+urgency, the most urgent first (see 'prod task urgency').
 
 Examples:
   prod task list                 # List all incomplete tasks
@@ -43,6 +46,8 @@ Examples:
   prod task list --priority=H    # List only high priority tasks
   prod task list -p M            # List only medium priority tasks
   prod task list --recurring     # List only recurring tasks
+  prod task list --waiting       # List the tasks hidden with 'prod task wait'
+  prod task list --table         # Show a table with the urgency of each task
   
 Priority levels:
   H - High
@@ -113,7 +118,7 @@ Priority levels:
 			projectPtr = nil
 		}
 
-		tasks, err := taskService.ListTasks(context.Background(), user.ID, priorityPtr, projectPtr, tagsList, status, showToday)
+		tasks, err := taskService.ListTasks(context.Background(), user.ID, priorityPtr, projectPtr, tagsList, status, showToday, showWaiting)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error getting list of tasks: %v\n", err)
 			return
@@ -148,6 +153,15 @@ Priority levels:
 			tasks = filtered
 		}
 
+		// Tasks are listed by urgency, the most urgent first
+		urgencyService := services.NewUrgencyService(queries)
+		urgency, err := urgencyService.Model(context.Background(), user.ID, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error computing urgency: %v\n", err)
+			return
+		}
+		urgency.Sort(tasks)
+
 		if len(tasks) == 0 {
 			if cmd.Flags().Changed("priority") {
 				fmt.Printf("No tasks found with priority '%s'\n", listPriority)
@@ -184,7 +198,7 @@ Priority levels:
 			for i, t := range tasks {
 				taskMap[i+1] = t.ID
 			}
-			PrintTaskTableList(tasks, taskMap, queries, user)
+			PrintTaskTableList(tasks, taskMap, queries, user, urgency)
 			err = services.MakeTaskMapFile(taskMap)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error making task map file: %v\n", err)
@@ -226,6 +240,9 @@ func init() {
 	// Add recurring flag
 	listCmd.Flags().BoolVarP(&showRecurring, "recurring", "r", false, "Show only recurring tasks")
 
+	// Add waiting flag
+	listCmd.Flags().BoolVarP(&showWaiting, "waiting", "w", false, "Show only tasks waiting until a later date")
+
 	// Add table flag
 	listCmd.Flags().BoolVarP(&showTable, "table", "T", false, "Show tasks in Taskwarrior-style table format")
 
@@ -243,14 +260,14 @@ func init() {
 // padAnsi will be moved to after the colors constants are defined in PrintTaskTableRow
 
 func PrintTaskTableHeader() {
-	fmt.Printf("%-4s %-40s %-6s %-12s %-15s %-12s %-12s %-12s\n",
-		"ID", "Description", "Pri", "Due", "Tags", "Proj", "Status", "Recurrence")
+	fmt.Printf("%-4s %-40s %-6s %-12s %-15s %-12s %-8s %-6s %-12s %-12s\n",
+		"ID", "Description", "Pri", "Due", "Tags", "Proj", "Pomo", "Urg", "Status", "Recurrence")
 }
 
 // ansiRegexp to match ANSI color codes
 var ansiRegexp = regexp.MustCompile(`\x1b\[[0-9;]*m`)
 
-func PrintTaskTableRow(displayIdx int, task sqlc.Task, projectName string, altBg bool) {
+func PrintTaskTableRow(displayIdx int, task sqlc.Task, projectName string, pomodoros string, urgency string, altBg bool) {
 	// ANSI colors
 	const (
 		reset        = "\033[0m"
@@ -271,6 +288,8 @@ func PrintTaskTableRow(displayIdx int, task sqlc.Task, projectName string, altBg
 		dueWidth       = 13 // 12 + 1 space
 		tagsWidth      = 16 // 15 + 1 space
 		projWidth      = 13 // 12 + 1 space
+		pomoWidth      = 9  // 8 + 1 space
+		urgWidth       = 7  // 6 + 1 space
 		statusWidth    = 13 // 12 + 1 space
 		completedWidth = 20 // no trailing space needed
 	)
@@ -385,6 +404,10 @@ func PrintTaskTableRow(displayIdx int, task sqlc.Task, projectName string, altBg
 	}
 	proj = fmt.Sprintf("%-*s", projWidth, proj)
 
+	pomo := fmt.Sprintf("%-*s", pomoWidth, pomodoros)
+
+	urg := fmt.Sprintf("%-*s", urgWidth, urgency)
+
 	status := task.Status
 	if status == "" {
 		status = "--"
@@ -422,7 +445,7 @@ func PrintTaskTableRow(displayIdx int, task sqlc.Task, projectName string, altBg
 	}
 
 	// Print the first line of description with all columns
-	printRow(descLines[0], id, priority, due, tags, proj, status, recurrenceStr, altBg, task, overdue)
+	printRow(descLines[0], id, priority, due, tags, proj, pomo, urg, status, recurrenceStr, altBg, task, overdue)
 
 	// Print continuation lines if any (only description column has content)
 	for i := 1; i < len(descLines); i++ {
@@ -439,7 +462,7 @@ func PrintTaskTableRow(displayIdx int, task sqlc.Task, projectName string, altBg
 }
 
 // Helper function to print a row with proper colors
-func printRow(desc, id, priority, due, tags, proj, status, completed string, altBg bool, task sqlc.Task, overdue bool) {
+func printRow(desc, id, priority, due, tags, proj, pomo, urg, status, completed string, altBg bool, task sqlc.Task, overdue bool) {
 	// ANSI colors
 	const (
 		reset        = "\033[0m"
@@ -495,6 +518,12 @@ func printRow(desc, id, priority, due, tags, proj, status, completed string, alt
 		// Project (no color)
 		fmt.Print(proj)
 
+		// Pomodoros (no color)
+		fmt.Print(pomo)
+
+		// Urgency (no color)
+		fmt.Print(urg)
+
 		// Status (with color)
 		switch strings.TrimSpace(status) {
 		case "completed":
@@ -561,6 +590,12 @@ func printRow(desc, id, priority, due, tags, proj, status, completed string, alt
 		// Project (no color)
 		fmt.Print(proj)
 
+		// Pomodoros (no color)
+		fmt.Print(pomo)
+
+		// Urgency (no color)
+		fmt.Print(urg)
+
 		// Status (with color)
 		switch strings.TrimSpace(status) {
 		case "completed":
@@ -591,9 +626,10 @@ func printRow(desc, id, priority, due, tags, proj, status, completed string, alt
 }
 
 // PrintTaskTableList prints tasks in Taskwarrior-style table format
-func PrintTaskTableList(tasks []sqlc.Task, taskMap map[int]int32, queries *sqlc.Queries, user *sqlc.User) {
+func PrintTaskTableList(tasks []sqlc.Task, taskMap map[int]int32, queries *sqlc.Queries, user *sqlc.User, urgency *services.UrgencyModel) {
 	PrintTaskTableHeader()
 	projectService := services.NewProjectService(queries)
+	pomoCounts, pomoMinutes := taskPomodoroCounts(queries, user)
 	// Build a reverse map from task ID to display index
 	idToDisplay := make(map[int32]int)
 	for displayIdx, taskID := range taskMap {
@@ -615,7 +651,8 @@ func PrintTaskTableList(tasks []sqlc.Task, taskMap map[int]int32, queries *sqlc.
 		altBg := (idx%2 == 1)
 
 		// Render the task row with its background setting
-		PrintTaskTableRow(displayIdx, t, projectName, altBg)
+		PrintTaskTableRow(displayIdx, t, projectName, formatTaskPomodoros(t, pomoCounts[t.ID], pomoMinutes),
+			formatUrgency(urgency.Urgency(t).Score), altBg)
 	}
 }
 
@@ -635,6 +672,8 @@ func PrintTaskMultiLineList(tasks []sqlc.Task, queries *sqlc.Queries, user *sqlc
 		gray         = "\033[90m"
 	)
 
+	pomoCounts, pomoMinutes := taskPomodoroCounts(queries, user)
+
 	// Create a map from display index to database ID
 	taskMap := make(map[int]int32)
 	for i, task := range tasks {
@@ -762,9 +801,46 @@ func PrintTaskMultiLineList(tasks []sqlc.Task, queries *sqlc.Queries, user *sqlc
 			fmt.Printf("    %s\n", tagsStr)
 		}
 
+		// Show estimated vs actual pomodoros if there is anything to compare
+		if task.EstimateMinutes.Valid || pomoCounts[task.ID] > 0 {
+			pomoStr := gray + "◷ " + reset + formatTaskPomodoros(task, pomoCounts[task.ID], pomoMinutes) + " pomodoros"
+			fmt.Printf("    %s\n", pomoStr)
+		}
+
 		// Add extra newline for better separation
 		fmt.Println()
 	}
 
 	return taskMap
 }
+
+// taskPomodoroCounts returns the completed pomodoros per task and the user's pomodoro length
+func taskPomodoroCounts(queries *sqlc.Queries, user *sqlc.User) (map[int32]int, int) {
+	pomoService := services.NewPomodoroService(queries)
+	pomoMinutes := pomoService.PomodoroMinutes(context.Background(), user.ID)
+
+	counts, err := pomoService.GetTaskPomodoroCounts(context.Background(), user.ID)
+	if err != nil {
+		counts = map[int32]int{}
+	}
+
+	return counts, pomoMinutes
+}
+
+// formatTaskPomodoros formats actual vs estimated pomodoros, e.g. "3/4", "3" or "--"
+func formatTaskPomodoros(task sqlc.Task, actual int, pomodoroMinutes int) string {
+	if !task.EstimateMinutes.Valid {
+		if actual == 0 {
+			return "--"
+		}
+		return strconv.Itoa(actual)
+	}
+
+	estimated := services.EstimatePomodoros(task.EstimateMinutes.Int32, pomodoroMinutes)
+	return fmt.Sprintf("%d/%s", actual, formatPomodoroCount(estimated))
+}
+
+// formatPomodoroCount formats a fractional pomodoro count with at most one decimal
+func formatPomodoroCount(count float64) string {
+	return strconv.FormatFloat(math.Round(count*10)/10, 'f', -1, 64)
+}
@@ -0,0 +1,352 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	modifyPriority   string
+	modifyAddTags    []string
+	modifyRemoveTags []string
+	modifyProject    string
+	modifyDue        string
+	modifyParent     string
+	modifyDryRun     bool
+	modifyYes        bool
+	modifyLimit      int
+)
+
+// taskModifyCmd represents the task modify command
+var taskModifyCmd = &cobra.Command{
+	Use:   "modify [ids|filter]",
+	Short: "Change several tasks at once",
+	Long: `Make the same changes to several tasks at once. All tasks are changed
+together, or none when something goes wrong, and 'prod undo' undoes them in
+one step.
+
+Tasks are given by their IDs, like 1-3,5 or 2 4 7, or by a filter of:
+  project:<name|id>   Tasks in the project
+  +<tag>              Tasks with the tag
+  priority:<H|M|L>    Tasks with the priority
+  status:<status>     pending, active, completed or waiting (hidden with
+                      'prod task wait'). Pending and active tasks by default
+  <word>              Tasks with the word in their description
+
+The changes are listed before anything is saved. More tasks than --limit are
+never changed, raise the limit to go ahead.
+
+Examples:
+  prod task modify 1-3,5 --priority H
+  prod task modify +errand --add-tag weekend --remove-tag errand
+  prod task modify project:Home status:waiting --due +2d
+  prod task modify 4 6 --parent 2
+  prod task modify project:Old --project New --dry-run`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		changes := services.TaskChanges{
+			AddTags:    modifyAddTags,
+			RemoveTags: modifyRemoveTags,
+		}
+		if cmd.Flags().Changed("priority") {
+			priority, err := parsePriorityFlag(modifyPriority)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+			changes.Priority = &priority
+		}
+		if cmd.Flags().Changed("due") {
+			due, err := parseDayFlag(modifyDue)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+			changes.DueDate = &pgtype.Timestamptz{}
+			if due != nil {
+				*changes.DueDate = pgtype.Timestamptz{
+					Time:  *due,
+					Valid: true,
+				}
+			}
+		}
+		if !cmd.Flags().Changed("project") && !cmd.Flags().Changed("parent") && changes.Priority == nil &&
+			changes.DueDate == nil && len(changes.AddTags) == 0 && len(changes.RemoveTags) == 0 {
+			fmt.Println("Specify at least one change, like --priority or --add-tag")
+			return
+		}
+
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		ctx := context.Background()
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(ctx)
+		if err != nil {
+			fmt.Println("You need to be logged in to modify tasks")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		taskService := services.NewTaskService(queries)
+		projectService := services.NewProjectService(queries)
+
+		if cmd.Flags().Changed("project") {
+			changes.ProjectID = &pgtype.Int4{}
+			if modifyProject != "none" {
+				project, err := projectService.FindProject(ctx, user.ID, modifyProject)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					return
+				}
+				*changes.ProjectID = pgtype.Int4{
+					Int32: project.ID,
+					Valid: true,
+				}
+			}
+		}
+		var parent *sqlc.Task
+		if cmd.Flags().Changed("parent") {
+			changes.ParentID = &pgtype.Int4{}
+			if modifyParent != "none" {
+				if parent, err = findTaskByInput(ctx, taskService, user.ID, modifyParent); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					return
+				}
+				*changes.ParentID = pgtype.Int4{
+					Int32: parent.ID,
+					Valid: true,
+				}
+			}
+		}
+
+		tasks, err := selectTasks(ctx, taskService, user.ID, args)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return
+		}
+
+		projects, err := projectService.ListProjects(ctx, user.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing projects: %v\n", err)
+			return
+		}
+		projectNames := make(map[int32]string, len(projects))
+		for _, project := range projects {
+			projectNames[project.ID] = project.Name
+		}
+
+		var changed []sqlc.Task
+		taskMap, err := services.GetTaskMap()
+		if err != nil {
+			taskMap = make(map[int]int32)
+		}
+		for _, task := range tasks {
+			descriptions := describeTaskChanges(task, changes.Apply(task), projectNames, parent)
+			if len(descriptions) == 0 {
+				continue
+			}
+			changed = append(changed, task)
+			fmt.Printf("%s  %s%s%s\n", agendaTaskLine(task, taskMap), ColorBrightBlack, strings.Join(descriptions, ", "), ColorReset)
+		}
+		if err := services.MakeTaskMapFile(taskMap); err != nil {
+			fmt.Fprintf(os.Stderr, "Error making task map file: %v\n", err)
+		}
+
+		if len(changed) == 0 {
+			fmt.Printf("Nothing to change in the %d matching tasks\n", len(tasks))
+			return
+		}
+		if unchanged := len(tasks) - len(changed); unchanged > 0 {
+			fmt.Printf("%d matching tasks already have these changes\n", unchanged)
+		}
+		if modifyLimit > 0 && len(changed) > modifyLimit {
+			fmt.Fprintf(os.Stderr, "Error: this would change %d tasks, more than the limit of %d. Use --limit %d to go ahead\n",
+				len(changed), modifyLimit, len(changed))
+			return
+		}
+		if modifyDryRun {
+			fmt.Println("Dry run, no tasks were changed")
+			return
+		}
+
+		if !modifyYes {
+			fmt.Printf("Modify %d tasks? (y/N): ", len(changed))
+			var confirmation string
+			fmt.Scanln(&confirmation)
+			if confirmation != "y" && confirmation != "Y" {
+				fmt.Println("Operation cancelled")
+				return
+			}
+		}
+
+		var modified []sqlc.Task
+		err = services.NewUnitOfWork(dbpool, queries).Do(ctx, func(tx *services.TxServices) error {
+			modified, err = tx.Tasks.ModifyTasks(ctx, user.ID, changed, changes)
+			return err
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error modifying tasks: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Modified %d tasks\n", len(modified))
+	},
+}
+
+// selectTasks returns the tasks given by IDs like 1-3,5, or else by a filter
+// like project:Home +errand
+func selectTasks(ctx context.Context, taskService *services.TaskService, userID int32, args []string) ([]sqlc.Task, error) {
+	// Tags like +5 would otherwise parse as numbers
+	isFilter := slices.ContainsFunc(args, func(arg string) bool {
+		return strings.HasPrefix(arg, "+")
+	})
+	inputs, err := util.ParseArgs(args)
+	if err != nil || isFilter {
+		filter, err := services.ParseTaskFilter(args)
+		if err != nil {
+			return nil, err
+		}
+		return taskService.FilterTasks(ctx, userID, filter)
+	}
+
+	tasks := []sqlc.Task{}
+	seen := make(map[int32]bool)
+	for _, input := range inputs {
+		taskID, err := services.GetID(services.GetTaskMap, input)
+		if err != nil {
+			return nil, fmt.Errorf("invalid task ID %d", input)
+		}
+		if seen[taskID] {
+			continue
+		}
+		seen[taskID] = true
+
+		task, err := taskService.GetTask(ctx, taskID, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find task %d: %w", input, err)
+		}
+		tasks = append(tasks, *task)
+	}
+	return tasks, nil
+}
+
+// findTaskByInput returns the task with a number shown by the task lists
+func findTaskByInput(ctx context.Context, taskService *services.TaskService, userID int32, input string) (*sqlc.Task, error) {
+	number, err := util.Input2Int(input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid task ID %q", input)
+	}
+	taskID, err := services.GetID(services.GetTaskMap, number)
+	if err != nil {
+		return nil, fmt.Errorf("invalid task ID %d", number)
+	}
+	task, err := taskService.GetTask(ctx, taskID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find task %d: %w", number, err)
+	}
+	return task, nil
+}
+
+// parsePriorityFlag parses H, M or L in any case, none clearing the priority
+func parsePriorityFlag(input string) (pgtype.Text, error) {
+	if input == "none" {
+		return pgtype.Text{}, nil
+	}
+	priority := strings.ToUpper(input)
+	if priority != "H" && priority != "M" && priority != "L" {
+		return pgtype.Text{}, fmt.Errorf("invalid priority: %q (use H, M, L or none)", input)
+	}
+	return pgtype.Text{
+		String: priority,
+		Valid:  true,
+	}, nil
+}
+
+// describeTaskChanges lists what differs between a task and its changed
+// version, like "priority M → H" or "+errand"
+func describeTaskChanges(before, after sqlc.Task, projectNames map[int32]string, parent *sqlc.Task) []string {
+	var changes []string
+
+	if before.Priority != after.Priority {
+		changes = append(changes, fmt.Sprintf("priority %s → %s", orNone(before.Priority.String), orNone(after.Priority.String)))
+	}
+	if before.DueDate.Valid != after.DueDate.Valid || !before.DueDate.Time.Equal(after.DueDate.Time) {
+		changes = append(changes, fmt.Sprintf("due %s → %s", formatDay(before.DueDate), formatDay(after.DueDate)))
+	}
+	if before.ProjectID != after.ProjectID {
+		changes = append(changes, fmt.Sprintf("project %s → %s", projectName(before.ProjectID, projectNames), projectName(after.ProjectID, projectNames)))
+	}
+	if before.Dependent != after.Dependent {
+		if parent != nil && after.Dependent.Valid {
+			changes = append(changes, fmt.Sprintf("parent → %q", parent.Description))
+		} else {
+			changes = append(changes, "no parent")
+		}
+	}
+	for _, tag := range after.Tags {
+		if !slices.Contains(before.Tags, tag) {
+			changes = append(changes, "+"+tag)
+		}
+	}
+	for _, tag := range before.Tags {
+		if !slices.Contains(after.Tags, tag) {
+			changes = append(changes, "-"+tag)
+		}
+	}
+
+	return changes
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
+
+func formatDay(t pgtype.Timestamptz) string {
+	if !t.Valid {
+		return "none"
+	}
+	return t.Time.In(time.Local).Format("2006-01-02")
+}
+
+func projectName(id pgtype.Int4, names map[int32]string) string {
+	if !id.Valid {
+		return "none"
+	}
+	if name, ok := names[id.Int32]; ok {
+		return name
+	}
+	return fmt.Sprint(id.Int32)
+}
+
+func init() {
+	taskCmd.AddCommand(taskModifyCmd)
+
+	taskModifyCmd.Flags().StringVarP(&modifyPriority, "priority", "p", "", "Set the priority (H, M, L or none)")
+	taskModifyCmd.Flags().StringSliceVar(&modifyAddTags, "add-tag", []string{}, "Tags to add (comma-separated)")
+	taskModifyCmd.Flags().StringSliceVar(&modifyRemoveTags, "remove-tag", []string{}, "Tags to remove (comma-separated)")
+	taskModifyCmd.Flags().StringVarP(&modifyProject, "project", "P", "", "Move to a project, by name or ID, or none")
+	taskModifyCmd.Flags().StringVarP(&modifyDue, "due", "d", "", "Set the due date (YYYY-MM-DD, tomorrow, mon, +2d or none)")
+	taskModifyCmd.Flags().StringVar(&modifyParent, "parent", "", "Make subtasks of a task, or none")
+	taskModifyCmd.Flags().BoolVar(&modifyDryRun, "dry-run", false, "Show the changes without saving them")
+	taskModifyCmd.Flags().BoolVarP(&modifyYes, "yes", "y", false, "Don't ask for confirmation")
+	taskModifyCmd.Flags().IntVar(&modifyLimit, "limit", 20, "Most tasks to change at once, 0 for no limit")
+	taskModifyCmd.MarkFlagsMutuallyExclusive("dry-run", "yes")
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModifyCommandStructure(t *testing.T) {
+	assert.Equal(t, "modify [ids|filter]", taskModifyCmd.Use)
+
+	// Check the flags
+	for _, name := range []string{"priority", "add-tag", "remove-tag", "project", "due", "parent", "dry-run", "yes", "limit"} {
+		assert.NotNil(t, taskModifyCmd.Flag(name), name+" flag should exist")
+	}
+	assert.Equal(t, "20", taskModifyCmd.Flag("limit").DefValue)
+}
+
+func TestDescribeTaskChanges(t *testing.T) {
+	task := sqlc.Task{
+		Description: "Buy milk",
+		Priority: pgtype.Text{
+			String: "M",
+			Valid:  true,
+		},
+		ProjectID: pgtype.Int4{
+			Int32: 1,
+			Valid: true,
+		},
+		Tags: []string{"errand", "shop"},
+	}
+	changed := task
+	changed.Priority = pgtype.Text{
+		String: "H",
+		Valid:  true,
+	}
+	changed.ProjectID = pgtype.Int4{}
+	changed.Tags = []string{"shop", "weekend"}
+
+	assert.Equal(t, []string{"priority M → H", "project Home → none", "+weekend", "-errand"},
+		describeTaskChanges(task, changed, map[int32]string{1: "Home"}, nil))
+	assert.Empty(t, describeTaskChanges(task, task, nil, nil))
+}
+
+func TestParsePriorityFlag(t *testing.T) {
+	priority, err := parsePriorityFlag("l")
+	assert.NoError(t, err)
+	assert.Equal(t, "L", priority.String)
+
+	priority, err = parsePriorityFlag("none")
+	assert.NoError(t, err)
+	assert.False(t, priority.Valid)
+
+	_, err = parsePriorityFlag("urgent")
+	assert.Error(t, err)
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/jskallebak/prod/internal/db/sqlc"
 	"github.com/jskallebak/prod/internal/services"
 	"github.com/jskallebak/prod/internal/util"
 	"github.com/spf13/cobra"
@@ -38,6 +39,7 @@ to quickly create a Cobra application.`,
 		}
 		defer dbpool.Close()
 
+		uow := services.NewUnitOfWork(dbpool, queries)
 		taskService := services.NewTaskService(queries)
 		authService := services.NewAuthService(queries)
 
@@ -47,18 +49,30 @@ to quickly create a Cobra application.`,
 			return
 		}
 
+		adaptedConfirm := func(ctx context.Context, taskID int32, userID int32, action string, ts *services.TaskService) error {
+			return ConfirmCmd(ctx, taskID, userID, ActionType(action), ts)
+		}
+
+		// Confirm first so the transaction never waits on input
+		type pause struct {
+			input    int
+			taskID   int32
+			subtasks []sqlc.Task
+			task     *sqlc.Task
+		}
+		var pauses []pause
+		seen := make(map[int32]bool)
 		for _, input := range inputs {
 			taskID, err := services.GetID(services.GetTaskMap, input)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error invalid task ID\n")
 				return
 			}
-
-			adaptedConfirm := func(ctx context.Context, taskID int32, userID int32, action string, ts *services.TaskService) error {
-				return ConfirmCmd(ctx, taskID, userID, ActionType(action), ts)
+			if seen[taskID] {
+				continue
 			}
 
-			err = services.RecursiveSubtasks(ctx, user.ID, taskID, taskService, "pause", input, adaptedConfirm, taskService.PauseTask)
+			subtasks, err := services.ConfirmSubtasks(ctx, user.ID, taskID, taskService, "pause", adaptedConfirm)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "%s\n", err)
 				return
@@ -70,18 +84,44 @@ to quickly create a Cobra application.`,
 				return
 			}
 
-			task, err := taskService.PauseTask(context.Background(), int32(taskID), user.ID)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: Failed to find task with ID %d: %v\n", taskID, err)
-				return
-			}
-
-			fmt.Printf("Task %d marked as pending\n", input)
-			fmt.Printf("Description: %s\n", task.Description)
-			fmt.Printf("updated at: %s\n", task.UpdatedAt.Time.Format("2006-01-02 15:04:05"))
+			seen[taskID] = true
+			pauses = append(pauses, pause{
+				input:    input,
+				taskID:   taskID,
+				subtasks: unseenTasks(seen, subtasks),
+			})
+		}
 
+		// Pause the tasks and their subtasks together, or not at all
+		err = uow.Do(ctx, func(tx *services.TxServices) error {
+			if len(pauses) > 1 {
+				if err := tx.Describe(ctx, user.ID, fmt.Sprintf("pause %d tasks", len(pauses))); err != nil {
+					return err
+				}
+			}
+			for i := range pauses {
+				p := &pauses[i]
+				if err := services.ApplyToSubtasks(ctx, user.ID, p.subtasks, "paused", tx.Tasks.PauseTask); err != nil {
+					return err
+				}
+
+				p.task, err = tx.Tasks.PauseTask(ctx, p.taskID, user.ID)
+				if err != nil {
+					return fmt.Errorf("Error: Failed to find task with ID %d: %v", p.taskID, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			return
 		}
 
+		for _, p := range pauses {
+			fmt.Printf("Task %d marked as pending\n", p.input)
+			fmt.Printf("Description: %s\n", p.task.Description)
+			fmt.Printf("updated at: %s\n", p.task.UpdatedAt.Time.Format("2006-01-02 15:04:05"))
+		}
 	},
 }
 
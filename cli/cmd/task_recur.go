@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/jskallebak/prod/internal/db/sqlc"
 	"github.com/jskallebak/prod/internal/services"
 	"github.com/jskallebak/prod/internal/util"
 	"github.com/spf13/cobra"
@@ -57,6 +58,7 @@ Examples:
 		defer dbpool.Close()
 
 		// Create task service and get authenticated user
+		uow := services.NewUnitOfWork(dbpool, queries)
 		taskService := services.NewTaskService(queries)
 		authService := services.NewAuthService(queries)
 
@@ -89,7 +91,11 @@ Examples:
 
 		// If clear flag is set, remove recurrence
 		if clearRecurrence {
-			updatedTask, err := taskService.UpdateTaskRecurrence(context.Background(), taskID, user.ID, "")
+			var updatedTask *sqlc.Task
+			err = uow.Do(context.Background(), func(tx *services.TxServices) error {
+				updatedTask, err = tx.Tasks.UpdateTaskRecurrence(context.Background(), taskID, user.ID, "")
+				return err
+			})
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error clearing recurrence: %v\n", err)
 				return
@@ -109,7 +115,11 @@ Examples:
 		}
 
 		// Update the task with the recurrence pattern
-		updatedTask, err := taskService.UpdateTaskRecurrence(context.Background(), taskID, user.ID, recurrencePattern)
+		var updatedTask *sqlc.Task
+		err = uow.Do(context.Background(), func(tx *services.TxServices) error {
+			updatedTask, err = tx.Tasks.UpdateTaskRecurrence(context.Background(), taskID, user.ID, recurrencePattern)
+			return err
+		})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error setting recurrence: %v\n", err)
 			return
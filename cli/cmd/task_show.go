@@ -93,6 +93,12 @@ For example:
 		if task.StartDate.Valid {
 			fmt.Printf("Start: %s\n", task.StartDate.Time.Format("2006-01-02"))
 		}
+		if task.Scheduled.Valid {
+			fmt.Printf("Scheduled: %s\n", task.Scheduled.Time.Format("2006-01-02"))
+		}
+		if task.WaitUntil.Valid {
+			fmt.Printf("Wait until: %s\n", task.WaitUntil.Time.Format("2006-01-02 15:04"))
+		}
 		if task.CompletedAt.Valid {
 			fmt.Printf("Completed: %s\n", task.CompletedAt.Time.Format("2006-01-02"))
 		}
@@ -177,6 +183,21 @@ For example:
 			}
 		}
 
+		// Show estimated vs actual pomodoros
+		pomoService := services.NewPomodoroService(queries)
+		pomoCounts, err := pomoService.GetTaskPomodoroCounts(context.Background(), userID)
+		if err == nil {
+			actual := pomoCounts[task.ID]
+			if task.EstimateMinutes.Valid {
+				pomoMinutes := pomoService.PomodoroMinutes(context.Background(), userID)
+				fmt.Printf("Estimate: %d min (%s pomodoros)\n", task.EstimateMinutes.Int32,
+					formatPomodoroCount(services.EstimatePomodoros(task.EstimateMinutes.Int32, pomoMinutes)))
+				fmt.Printf("Pomodoros: %s\n", formatTaskPomodoros(*task, actual, pomoMinutes))
+			} else if actual > 0 {
+				fmt.Printf("Pomodoros: %d\n", actual)
+			}
+		}
+
 		// Show tags if any
 		if len(task.Tags) > 0 {
 			fmt.Printf("Tags: %s\n", strings.Join(task.Tags, ", "))
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// taskSnoozeCmd represents the task snooze command
+var taskSnoozeCmd = &cobra.Command{
+	Use:   "snooze [task_id] [duration]",
+	Short: "Hide a task for a while",
+	Long: `Hide a task from 'prod task list' and 'prod next' for a while, a day by
+default. Snoozing a task that is still waiting hides it for longer.
+
+Durations are like 2d, 1w, 3h or 1h30m.
+
+Examples:
+  prod task snooze 3         # Hide task 3 until this time tomorrow
+  prod task snooze 3 2d      # Hide it for two days
+  prod task snooze 3 4h`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		input, err := util.Input2Int(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Invalid task ID\n")
+			return
+		}
+		taskID, err := services.GetID(services.GetTaskMap, input)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Invalid task ID\n")
+			return
+		}
+
+		duration := 24 * time.Hour
+		if len(args) > 1 {
+			if duration, err = util.ParseDuration(args[1]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+		}
+
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to snooze tasks")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		// Read and extend the wait in one transaction
+		var task *sqlc.Task
+		var until time.Time
+		err = services.NewUnitOfWork(dbpool, queries).Do(context.Background(), func(tx *services.TxServices) error {
+			task, err = tx.Tasks.GetTask(context.Background(), taskID, user.ID)
+			if err != nil {
+				return fmt.Errorf("Failed to find task %d: %v", input, err)
+			}
+
+			until = services.SnoozeUntil(task.WaitUntil, duration, time.Now())
+			task, err = tx.Tasks.SetWait(context.Background(), user.ID, taskID, &until)
+			if err != nil {
+				return fmt.Errorf("Failed to update task %d: %v", input, err)
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return
+		}
+
+		printWait(input, task.Description, &until)
+	},
+}
+
+func init() {
+	taskCmd.AddCommand(taskSnoozeCmd)
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/jskallebak/prod/internal/db/sqlc"
 	"github.com/jskallebak/prod/internal/services"
 	"github.com/jskallebak/prod/internal/util"
 	"github.com/spf13/cobra"
@@ -46,6 +47,12 @@ to quickly create a Cobra application.`,
 			return
 		}
 
+		type start struct {
+			input  int
+			taskID int32
+			task   *sqlc.Task
+		}
+		var starts []start
 		for _, input := range inputs {
 			taskID, err := services.GetID(services.GetTaskMap, input)
 			if err != nil {
@@ -61,19 +68,35 @@ to quickly create a Cobra application.`,
 				fmt.Fprintf(os.Stderr, "%s\n", err)
 				return
 			}
+			starts = append(starts, start{input: input, taskID: taskID})
+		}
 
-			task, err := taskService.StartTask(ctx, taskID, user.ID)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "No tasks with ID %v\n", taskID)
-				return
+		// Start all the tasks in one transaction, undone in one step
+		err = services.NewUnitOfWork(dbpool, queries).Do(ctx, func(tx *services.TxServices) error {
+			if len(starts) > 1 {
+				if err := tx.Describe(ctx, user.ID, fmt.Sprintf("start %d tasks", len(starts))); err != nil {
+					return err
+				}
 			}
-
-			fmt.Printf("Task %d marked as active\n", input)
-			fmt.Printf("Description: %s\n", task.Description)
-			fmt.Printf("updated at: %s\n", task.UpdatedAt.Time.Format("2006-01-02 15:04:05"))
-
+			for i := range starts {
+				task, err := tx.Tasks.StartTask(ctx, starts[i].taskID, user.ID)
+				if err != nil {
+					return fmt.Errorf("No tasks with ID %v", starts[i].taskID)
+				}
+				starts[i].task = task
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			return
 		}
 
+		for _, s := range starts {
+			fmt.Printf("Task %d marked as active\n", s.input)
+			fmt.Printf("Description: %s\n", s.task.Description)
+			fmt.Printf("updated at: %s\n", s.task.UpdatedAt.Time.Format("2006-01-02 15:04:05"))
+		}
 	},
 }
 
@@ -39,7 +39,6 @@ to quickly create a Cobra application.`,
 		}
 		defer dbpool.Close()
 
-		taskService := services.NewTaskService(queries)
 		authService := services.NewAuthService(queries)
 
 		user, err := authService.GetCurrentUser(context.Background())
@@ -47,39 +46,49 @@ to quickly create a Cobra application.`,
 			fmt.Fprintf(os.Stderr, "Error getting the user %v", err)
 		}
 
+		var taskIDs []int32
 		for _, input := range inputs {
 			taskID, err := services.GetID(services.GetTaskMap, input)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "%v", err)
 				return
 			}
+			taskIDs = append(taskIDs, taskID)
+		}
 
-			if cmd.Flags().Changed("add") {
-				err := taskService.AddTag(context.Background(), user.ID, taskID, taskTags)
-				if err != nil {
-					fmt.Fprintln(os.Stderr, err)
-					return
+		// Tag all the tasks in one transaction, undone in one step
+		ctx := context.Background()
+		err = services.NewUnitOfWork(dbpool, queries).Do(ctx, func(tx *services.TxServices) error {
+			if len(taskIDs) > 1 {
+				if err := tx.Describe(ctx, user.ID, fmt.Sprintf("tag %d tasks", len(taskIDs))); err != nil {
+					return err
 				}
 			}
+			for _, taskID := range taskIDs {
+				if cmd.Flags().Changed("add") {
+					if err := tx.Tasks.AddTag(ctx, user.ID, taskID, taskTags); err != nil {
+						return err
+					}
+				}
 
-			if cmd.Flags().Changed("clear") {
-				err := taskService.ClearTags(context.Background(), user.ID, taskID)
-				if err != nil {
-					fmt.Fprintln(os.Stderr, err)
-					return
+				if cmd.Flags().Changed("clear") {
+					if err := tx.Tasks.ClearTags(ctx, user.ID, taskID); err != nil {
+						return err
+					}
 				}
-			}
 
-			if cmd.Flags().Changed("remove") {
-				err := taskService.RemoveTags(context.Background(), user.ID, taskID, taskTags)
-				if err != nil {
-					fmt.Fprintln(os.Stderr, err)
-					return
+				if cmd.Flags().Changed("remove") {
+					if err := tx.Tasks.RemoveTags(ctx, user.ID, taskID, taskTags); err != nil {
+						return err
+					}
 				}
 			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
 			return
 		}
-
 	},
 }
 
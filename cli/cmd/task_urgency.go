@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// urgencyCmd represents the task urgency command
+var urgencyCmd = &cobra.Command{
+	Use:   "urgency [task_id]",
+	Short: "Explain the urgency of a task",
+	Long: `Show how the urgency score of a task is made up. Tasks are listed by urgency,
+the most urgent first.
+
+The score adds up a term for each thing that makes a task urgent: its
+priority, how close its due date is, its age, whether other tasks wait for it,
+whether it is started, whether it belongs to a project and its tags. Each term
+is a factor, mostly between 0 and 1, times a coefficient that can be changed
+with 'prod task urgency config'.
+
+Examples:
+  prod task urgency 3               # Explain the urgency of task 3
+  prod task urgency config          # Show the coefficients
+  prod task urgency config due=15   # Make due dates count more`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		input, err := util.Input2Int(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Invalid task ID\n")
+			return
+		}
+		taskID, err := services.GetID(services.GetTaskMap, input)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Invalid task ID\n")
+			return
+		}
+
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to see the urgency of a task")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		taskService := services.NewTaskService(queries)
+		task, err := taskService.GetTask(context.Background(), taskID, user.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to find task %d: %v\n", input, err)
+			return
+		}
+
+		urgencyService := services.NewUrgencyService(queries)
+		model, err := urgencyService.Model(context.Background(), user.ID, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error computing urgency: %v\n", err)
+			return
+		}
+
+		fmt.Printf("%sTask %d:%s %s\n\n", TextBold, input, ColorReset, task.Description)
+		printUrgency(model.Urgency(*task))
+	},
+}
+
+// printUrgency prints the terms of an urgency and their sum
+func printUrgency(urgency services.Urgency) {
+	if len(urgency.Terms) == 0 {
+		fmt.Println(ColorBrightBlack + "Nothing makes this task urgent" + ColorReset)
+	}
+	for _, term := range urgency.Terms {
+		fmt.Printf("  %-12s %-22s %5.2f × %5.1f = %6.2f\n",
+			term.Name, term.Detail, term.Factor, term.Coefficient, term.Value)
+	}
+	fmt.Printf("  %-12s %-22s %22s\n", "", "", "------")
+	fmt.Printf("  %s%-12s%s %-22s %22.2f\n", TextBold, "urgency", ColorReset, "", urgency.Score)
+}
+
+// formatUrgency formats an urgency score with one decimal
+func formatUrgency(score float64) string {
+	return fmt.Sprintf("%.1f", score)
+}
+
+func init() {
+	taskCmd.AddCommand(urgencyCmd)
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUrgencyCommandStructure(t *testing.T) {
+	// Check that the command exists
+	assert.NotNil(t, urgencyCmd)
+	assert.Equal(t, "urgency [task_id]", urgencyCmd.Use)
+
+	// Check the config subcommand and its flags
+	assert.Equal(t, "config [name=value...]", urgencyConfigCmd.Use)
+	assert.NotNil(t, urgencyConfigCmd.Flag("reset"), "reset flag should exist")
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var urgencyReset []string
+
+var urgencyConfigCmd = &cobra.Command{
+	Use:   "config [name=value...]",
+	Short: "Show and set the urgency coefficients",
+	Long: `Show the coefficients the urgency of tasks is computed with, or set them.
+
+Coefficients:
+  priority.H, priority.M, priority.L   Tasks with that priority
+  due                                  Rises from 0.2 two weeks before the due date to 1 a week after it
+  age                                  Rises from 0 when a task is created to 1 after a year
+  blocking                             Tasks that other tasks or a parent task wait for
+  active                               Started tasks
+  project                              Tasks in a project
+  tag.<name>                           Tasks with the tag, e.g. tag.next
+
+Negative coefficients make tasks less urgent.
+
+Examples:
+  prod task urgency config                          # Show the coefficients
+  prod task urgency config due=15 tag.waiting=-3    # Set coefficients
+  prod task urgency config --reset due              # Use the default again`,
+	Run: func(cmd *cobra.Command, args []string) {
+		values := make(map[string]float64)
+		for _, arg := range args {
+			name, value, ok := strings.Cut(arg, "=")
+			if !ok {
+				fmt.Fprintf(os.Stderr, "Error: expected name=value, got %q\n", arg)
+				return
+			}
+			if err := services.ValidateUrgencyCoefficient(name); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid value for %s: %q\n", name, value)
+				return
+			}
+			values[name] = v
+		}
+
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to configure urgency")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		urgencyService := services.NewUrgencyService(queries)
+		for _, name := range urgencyReset {
+			reset, err := urgencyService.ResetCoefficient(context.Background(), user.ID, name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+			if !reset {
+				fmt.Printf("%s was not set\n", name)
+			}
+		}
+		for name, value := range values {
+			if err := urgencyService.SetCoefficient(context.Background(), user.ID, name, value); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+		}
+		if len(values) > 0 || len(urgencyReset) > 0 {
+			fmt.Println("Urgency coefficients updated successfully!")
+		}
+
+		coefficients, err := urgencyService.Coefficients(context.Background(), user.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return
+		}
+		for _, name := range coefficients.Names() {
+			line := fmt.Sprintf("  %-12s %6.1f", name, coefficients[name])
+			if value, ok := services.DefaultUrgencyCoefficients[name]; !ok || value != coefficients[name] {
+				line += ColorBrightBlack + "  (changed)" + ColorReset
+			}
+			fmt.Println(line)
+		}
+	},
+}
+
+func init() {
+	urgencyCmd.AddCommand(urgencyConfigCmd)
+
+	urgencyConfigCmd.Flags().StringSliceVar(&urgencyReset, "reset", []string{}, "Coefficients to put back to their default")
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// taskWaitCmd represents the task wait command
+var taskWaitCmd = &cobra.Command{
+	Use:   "wait [task_id] [date]",
+	Short: "Hide a task until a date",
+	Long: `Hide a task from 'prod task list' and 'prod next' until the start of a day.
+Use 'prod task list --waiting' to see the hidden tasks, and none as the date to
+show a task again.
+
+Dates are YYYY-MM-DD, today, tomorrow, a weekday like mon (the next one) or a
+number of days or weeks from today like +2d or 1w.
+
+Examples:
+  prod task wait 3 monday        # Don't show task 3 before next Monday
+  prod task wait 3 2025-06-01
+  prod task wait 3 +2w
+  prod task wait 3 none          # Show task 3 again`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		input, err := util.Input2Int(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Invalid task ID\n")
+			return
+		}
+		taskID, err := services.GetID(services.GetTaskMap, input)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Invalid task ID\n")
+			return
+		}
+
+		until, err := parseDayFlag(args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return
+		}
+
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to hide tasks")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		var task *sqlc.Task
+		err = services.NewUnitOfWork(dbpool, queries).Do(context.Background(), func(tx *services.TxServices) error {
+			task, err = tx.Tasks.SetWait(context.Background(), user.ID, taskID, until)
+			return err
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to update task %d: %v\n", input, err)
+			return
+		}
+
+		printWait(input, task.Description, until)
+	},
+}
+
+// parseDayFlag parses a day like util.ParseDay, none giving nil
+func parseDayFlag(input string) (*time.Time, error) {
+	if input == "none" {
+		return nil, nil
+	}
+	day, err := util.ParseDay(input, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &day, nil
+}
+
+// printWait tells until when a task is hidden
+func printWait(input int, description string, until *time.Time) {
+	if until == nil {
+		fmt.Printf("Task %d is no longer waiting\n", input)
+	} else {
+		fmt.Printf("Task %d is waiting until %s\n", input, until.Format("Mon, Jan 2 2006 15:04"))
+	}
+	fmt.Printf("Description: %s\n", description)
+}
+
+func init() {
+	taskCmd.AddCommand(taskWaitCmd)
+}
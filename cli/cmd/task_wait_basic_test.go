@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitCommandStructure(t *testing.T) {
+	assert.Equal(t, "wait [task_id] [date]", taskWaitCmd.Use)
+	assert.Equal(t, "snooze [task_id] [duration]", taskSnoozeCmd.Use)
+
+	// Check the flags
+	assert.NotNil(t, listCmd.Flag("waiting"), "waiting flag should exist")
+	assert.NotNil(t, addCmd.Flag("wait"), "wait flag should exist")
+	assert.NotNil(t, addCmd.Flag("scheduled"), "scheduled flag should exist")
+	assert.NotNil(t, editCmd.Flag("wait"), "wait flag should exist")
+	assert.NotNil(t, editCmd.Flag("scheduled"), "scheduled flag should exist")
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// trashCmd represents the trash command
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage deleted tasks and projects",
+	Long: `Deleted tasks and projects are moved to the trash, where they can be
+restored until the trash is emptied.
+
+Available Commands:
+  list        List the tasks and projects in the trash
+  restore     Restore a task or project
+  empty       Permanently delete old items`,
+}
+
+func init() {
+	rootCmd.AddCommand(trashCmd)
+}
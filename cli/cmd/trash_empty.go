@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	emptyOlderThan  string
+	forceEmptyTrash bool
+)
+
+var emptyTrashCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Permanently delete items from the trash",
+	Long: `Permanently delete the tasks and projects that have been in the trash for
+longer than --older-than. This can't be undone.
+
+Examples:
+  prod trash empty                      # Purge everything in the trash
+  prod trash empty --older-than 30d     # Purge items deleted more than 30 days ago
+  prod trash empty --older-than 2w -f   # Skip the confirmation prompt`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		olderThan, err := util.ParseDuration(emptyOlderThan)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return
+		}
+
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to empty the trash")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		if !forceEmptyTrash {
+			if olderThan > 0 {
+				fmt.Printf("Permanently delete everything trashed more than %s ago? (y/N): ", emptyOlderThan)
+			} else {
+				fmt.Print("Permanently delete everything in the trash? (y/N): ")
+			}
+			var answer string
+			fmt.Scanln(&answer)
+
+			if answer != "y" && answer != "Y" {
+				fmt.Println("Operation cancelled")
+				return
+			}
+		}
+
+		var tasks, projects int64
+		uow := services.NewUnitOfWork(dbpool, queries)
+		err = uow.Do(context.Background(), func(tx *services.TxServices) error {
+			tasks, projects, err = tx.Trash.Empty(context.Background(), user.ID, olderThan)
+			return err
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error emptying trash: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Permanently deleted %d task(s) and %d project(s)\n", tasks, projects)
+	},
+}
+
+func init() {
+	trashCmd.AddCommand(emptyTrashCmd)
+
+	emptyTrashCmd.Flags().StringVar(&emptyOlderThan, "older-than", "0d", "Only purge items deleted longer ago than this (e.g. 30d, 2w)")
+	emptyTrashCmd.Flags().BoolVarP(&forceEmptyTrash, "force", "f", false, "Skip confirmation prompt")
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var listTrashCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the tasks and projects in the trash",
+	Long: `List the deleted tasks and projects with the ID to restore them by.
+
+Example:
+  prod trash list`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to view the trash")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		trashService := services.NewTrashService(queries)
+		projects, tasks, err := trashService.List(context.Background(), user.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing trash: %v\n", err)
+			return
+		}
+
+		if len(projects) == 0 && len(tasks) == 0 {
+			fmt.Println("The trash is empty")
+			return
+		}
+
+		if len(projects) > 0 {
+			fmt.Printf("Projects (%d):\n", len(projects))
+			for _, project := range projects {
+				fmt.Printf("  %-5d %-40s deleted %s\n", project.ID, project.Name, project.DeletedAt.Time.Local().Format("2006-01-02 15:04"))
+			}
+			fmt.Println()
+		}
+
+		if len(tasks) > 0 {
+			fmt.Printf("Tasks (%d):\n", len(tasks))
+			for _, task := range tasks {
+				description := task.Description
+				if task.Dependent.Valid {
+					description = "  " + description
+				}
+				fmt.Printf("  %-5d %-40s deleted %s\n", task.ID, description, task.DeletedAt.Time.Local().Format("2006-01-02 15:04"))
+			}
+			fmt.Println()
+		}
+
+		fmt.Println("Use 'prod trash restore <id>' to restore a task, or add --project for a project")
+	},
+}
+
+func init() {
+	trashCmd.AddCommand(listTrashCmd)
+}
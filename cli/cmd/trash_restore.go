@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var restoreProject bool
+
+var restoreTrashCmd = &cobra.Command{
+	Use:   "restore <id>",
+	Short: "Restore a task or project from the trash",
+	Long: `Restore a task or project from the trash using the ID shown by 'prod trash list'.
+
+A task is restored with the subtasks deleted along with it. A project is
+restored with the tasks it had when it was deleted re-attached to it.
+
+Examples:
+  prod trash restore 42            # Restore task 42 and its subtasks
+  prod trash restore 3 --project   # Restore project 3 and re-attach its tasks`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid ID: %v\n", err)
+			return
+		}
+
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to restore from the trash")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		var project *sqlc.Project
+		var tasks []sqlc.Task
+		uow := services.NewUnitOfWork(dbpool, queries)
+		err = uow.Do(context.Background(), func(tx *services.TxServices) error {
+			var err error
+			if restoreProject {
+				project, tasks, err = tx.Trash.RestoreProject(context.Background(), user.ID, int32(id))
+				return err
+			}
+			tasks, err = tx.Trash.RestoreTask(context.Background(), user.ID, int32(id))
+			return err
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error restoring from trash: %v\n", err)
+			return
+		}
+
+		if restoreProject {
+			fmt.Printf("Project '%s' (ID: %d) restored\n", project.Name, project.ID)
+			if len(tasks) > 0 {
+				fmt.Printf("%d task(s) re-attached to the project\n", len(tasks))
+			}
+		} else {
+			fmt.Printf("Restored %d task(s)\n", len(tasks))
+		}
+
+		fmt.Println("\nUse 'prod task list' to refresh task IDs")
+	},
+}
+
+func init() {
+	trashCmd.AddCommand(restoreTrashCmd)
+
+	restoreTrashCmd.Flags().BoolVar(&restoreProject, "project", false, "Restore a project instead of a task")
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/jskallebak/prod/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var undoSteps int
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Undo your last task or project changes",
+	Long: `Undo the last changes made to your tasks and projects.
+
+Every command that changes tasks or projects is recorded as one operation,
+so 'prod task done 1-3' is undone in one step. Undoing a delete restores the
+task with its original ID, subtasks, tags and project. Undoing the completion
+of a recurring task also removes the next occurrence that was created. Undone
+operations can be applied again with 'prod redo' until you make a new change.
+
+Examples:
+  prod undo            # Undo the last operation
+  prod undo --steps 3  # Undo the last three operations`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if undoSteps < 1 {
+			fmt.Fprintf(os.Stderr, "Error: --steps must be at least 1\n")
+			return
+		}
+
+		dbpool, queries, ok := util.InitDBAndQueriesCLI()
+		if !ok {
+			return
+		}
+		defer dbpool.Close()
+
+		// Get authenticated user
+		authService := services.NewAuthService(queries)
+		user, err := authService.GetCurrentUser(context.Background())
+		if err != nil {
+			fmt.Println("You need to be logged in to undo changes")
+			fmt.Println("Use 'prod login' to authenticate")
+			return
+		}
+
+		var operations []sqlc.JournalOperation
+		uow := services.NewUnitOfWork(dbpool, queries)
+		err = uow.Do(context.Background(), func(tx *services.TxServices) error {
+			operations, err = tx.Journal.Undo(context.Background(), user.ID, undoSteps)
+			return err
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error undoing changes: %v\n", err)
+			return
+		}
+
+		if len(operations) == 0 {
+			fmt.Println("Nothing to undo")
+			return
+		}
+
+		for _, operation := range operations {
+			fmt.Printf("Undid: %s\n", operation.Description)
+		}
+		fmt.Println("\nUse 'prod task list' to refresh task IDs, or 'prod redo' to apply the changes again")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+
+	undoCmd.Flags().IntVar(&undoSteps, "steps", 1, "Number of operations to undo")
+}
@@ -0,0 +1,315 @@
+// Package caldav serves calendars over CalDAV (RFC 4791), so calendar and
+// to-do apps can read and write them. It implements the part of the protocol
+// clients use to sync: discovery with PROPFIND, calendar-query and
+// calendar-multiget reports, and GET, PUT and DELETE of single objects with
+// ETags. Collections carry a ctag so clients can skip unchanged ones.
+package caldav
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jskallebak/prod/internal/services"
+)
+
+// ErrNotFound is returned by a Backend for calendars and objects that don't exist
+var ErrNotFound = errors.New("not found")
+
+// Calendar is a collection of objects of one component type
+type Calendar struct {
+	Name        string
+	DisplayName string
+	Description string
+	// Component is the component type of the objects, VTODO or VEVENT
+	Component string
+}
+
+// Object is a calendar resource, a VCALENDAR with one to-do or event series
+type Object struct {
+	Name     string
+	Data     *services.ICalComponent
+	Modified time.Time
+}
+
+// Backend stores the calendars served by a Handler
+type Backend interface {
+	Calendars(ctx context.Context) ([]Calendar, error)
+	Objects(ctx context.Context, calendar string) ([]Object, error)
+	// PutObject creates or replaces an object. The name of the stored object
+	// may differ from the one it was put as
+	PutObject(ctx context.Context, calendar, name string, data *services.ICalComponent) error
+	DeleteObject(ctx context.Context, calendar, name string) error
+}
+
+// Handler serves the calendars of a Backend below Prefix
+type Handler struct {
+	Backend Backend
+	// Prefix is the path of the calendar home, e.g. /caldav/
+	Prefix string
+}
+
+// NewHandler creates a Handler serving backend at prefix
+func NewHandler(backend Backend, prefix string) *Handler {
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &Handler{
+		Backend: backend,
+		Prefix:  prefix,
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/.well-known/caldav" {
+		http.Redirect(w, r, h.Prefix, http.StatusMovedPermanently)
+		return
+	}
+	if r.URL.Path+"/" == h.Prefix {
+		r.URL.Path = h.Prefix
+	}
+	path, ok := strings.CutPrefix(r.URL.Path, h.Prefix)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	var err error
+	switch r.Method {
+	case http.MethodOptions:
+		w.Header().Set("DAV", "1, 3, calendar-access")
+		w.Header().Set("Allow", "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, PROPPATCH, REPORT")
+		w.WriteHeader(http.StatusNoContent)
+	case "PROPFIND":
+		err = h.propfind(w, r, path)
+	case "REPORT":
+		err = h.report(w, r, path)
+	case "PROPPATCH":
+		// Calendars are named after projects, so their properties are read-only
+		http.Error(w, "properties are read-only", http.StatusForbidden)
+	case http.MethodGet, http.MethodHead:
+		err = h.get(w, r, path)
+	case http.MethodPut:
+		err = h.put(w, r, path)
+	case http.MethodDelete:
+		err = h.delete(w, r, path)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+
+	var httpErr *httpError
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrNotFound):
+		http.NotFound(w, r)
+	case errors.As(err, &httpErr):
+		http.Error(w, httpErr.message, httpErr.code)
+	case errors.Is(err, services.ErrInvalidCalendar):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		fmt.Fprintf(os.Stderr, "Error serving %s %s: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
+
+// httpError is an error answered with its status code
+type httpError struct {
+	code    int
+	message string
+}
+
+func (e *httpError) Error() string {
+	return e.message
+}
+
+// splitPath splits a path below the prefix into a calendar and object name,
+// both empty for the calendar home
+func splitPath(path string) (string, string, error) {
+	calendar, name, _ := strings.Cut(path, "/")
+	if strings.Contains(name, "/") {
+		return "", "", ErrNotFound
+	}
+	return calendar, name, nil
+}
+
+func (h *Handler) calendar(ctx context.Context, name string) (*Calendar, error) {
+	calendars, err := h.Backend.Calendars(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, calendar := range calendars {
+		if calendar.Name == name {
+			return &calendar, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// object looks up an object together with its serialized data and ETag
+func (h *Handler) object(ctx context.Context, calendar, name string) (*resource, error) {
+	objects, err := h.Backend.Objects(ctx, calendar)
+	if err != nil {
+		return nil, err
+	}
+	for _, object := range objects {
+		if object.Name == name {
+			return newObjectResource(h.Prefix, calendar, object)
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request, path string) error {
+	calendar, name, err := splitPath(path)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return &httpError{http.StatusMethodNotAllowed, "collections can't be fetched with GET"}
+	}
+	c, err := h.calendar(r.Context(), calendar)
+	if err != nil {
+		return err
+	}
+	object, err := h.object(r.Context(), c.Name, name)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", contentType(c.Component))
+	w.Header().Set("ETag", object.etag)
+	w.Header().Set("Last-Modified", object.modified.UTC().Format(http.TimeFormat))
+	if r.Method == http.MethodHead {
+		return nil
+	}
+	w.Write(object.data)
+	return nil
+}
+
+func (h *Handler) put(w http.ResponseWriter, r *http.Request, path string) error {
+	calendar, name, err := splitPath(path)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return &httpError{http.StatusMethodNotAllowed, "collections can't be replaced"}
+	}
+	c, err := h.calendar(r.Context(), calendar)
+	if err != nil {
+		return err
+	}
+
+	calendars, err := services.ParseICalendar(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		return &httpError{http.StatusBadRequest, err.Error()}
+	}
+	if len(calendars) != 1 {
+		return &httpError{http.StatusBadRequest, "expected one VCALENDAR"}
+	}
+	data := calendars[0]
+	if !slices.ContainsFunc(data.Components, func(component *services.ICalComponent) bool {
+		return component.Name == c.Component
+	}) {
+		return &httpError{http.StatusForbidden, fmt.Sprintf("this calendar only holds %s components", c.Component)}
+	}
+
+	existing, err := h.object(r.Context(), c.Name, name)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if err := checkPreconditions(r, existing); err != nil {
+		return err
+	}
+
+	if err := h.Backend.PutObject(r.Context(), c.Name, name, data); err != nil {
+		return err
+	}
+
+	// No ETag is sent since the object is stored as prod writes it, not as it
+	// was put, so clients fetch it again
+	if existing == nil {
+		w.WriteHeader(http.StatusCreated)
+	} else {
+		w.WriteHeader(http.StatusNoContent)
+	}
+	return nil
+}
+
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request, path string) error {
+	calendar, name, err := splitPath(path)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return &httpError{http.StatusForbidden, "calendars can't be deleted"}
+	}
+	c, err := h.calendar(r.Context(), calendar)
+	if err != nil {
+		return err
+	}
+	existing, err := h.object(r.Context(), c.Name, name)
+	if err != nil {
+		return err
+	}
+	if err := checkPreconditions(r, existing); err != nil {
+		return err
+	}
+
+	if err := h.Backend.DeleteObject(r.Context(), c.Name, name); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// checkPreconditions checks If-Match and If-None-Match, which clients send to
+// not overwrite changes they haven't seen yet. existing is nil for new objects
+func checkPreconditions(r *http.Request, existing *resource) error {
+	failed := &httpError{http.StatusPreconditionFailed, "the object was changed"}
+	if match := r.Header.Get("If-Match"); match != "" {
+		if existing == nil || (match != "*" && !slices.Contains(splitETags(match), existing.etag)) {
+			return failed
+		}
+	}
+	if match := r.Header.Get("If-None-Match"); match != "" && existing != nil {
+		if match == "*" || slices.Contains(splitETags(match), existing.etag) {
+			return failed
+		}
+	}
+	return nil
+}
+
+func splitETags(header string) []string {
+	var etags []string
+	for _, etag := range strings.Split(header, ",") {
+		etags = append(etags, strings.TrimPrefix(strings.TrimSpace(etag), "W/"))
+	}
+	return etags
+}
+
+// etag hashes data into a strong ETag
+func etag(data []byte) string {
+	sum := sha1.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func serialize(data *services.ICalComponent) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := services.WriteICalendar(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func contentType(component string) string {
+	return "text/calendar; charset=utf-8; component=" + component
+}
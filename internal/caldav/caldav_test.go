@@ -0,0 +1,248 @@
+package caldav
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-ical"
+	"github.com/emersion/go-webdav"
+	webdavcaldav "github.com/emersion/go-webdav/caldav"
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryBackend keeps objects in memory under the name they were put as
+type memoryBackend struct {
+	objects map[string]map[string]Object
+}
+
+func (b *memoryBackend) Calendars(ctx context.Context) ([]Calendar, error) {
+	return []Calendar{
+		{Name: "tasks", DisplayName: "Tasks", Component: "VTODO"},
+		{Name: "events", DisplayName: "Events", Component: "VEVENT"},
+	}, nil
+}
+
+func (b *memoryBackend) Objects(ctx context.Context, calendar string) ([]Object, error) {
+	var objects []Object
+	for _, object := range b.objects[calendar] {
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+func (b *memoryBackend) PutObject(ctx context.Context, calendar, name string, data *services.ICalComponent) error {
+	if b.objects[calendar] == nil {
+		b.objects[calendar] = make(map[string]Object)
+	}
+	b.objects[calendar][name] = Object{Name: name, Data: data, Modified: time.Now()}
+	return nil
+}
+
+func (b *memoryBackend) DeleteObject(ctx context.Context, calendar, name string) error {
+	delete(b.objects[calendar], name)
+	return nil
+}
+
+const todo = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//test//EN\r\n" +
+	"BEGIN:VTODO\r\nUID:buy-milk\r\nSUMMARY:%s\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+
+func request(t *testing.T, server *httptest.Server, method, path, body string, headers map[string]string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	assert.NoError(t, err)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+	return res, string(data)
+}
+
+func TestCalDAVSync(t *testing.T) {
+	backend := &memoryBackend{objects: make(map[string]map[string]Object)}
+	server := httptest.NewServer(NewHandler(backend, "/caldav/"))
+	defer server.Close()
+
+	res, _ := request(t, server, "OPTIONS", "/caldav/", "", nil)
+	assert.Contains(t, res.Header.Get("DAV"), "calendar-access")
+
+	client := server.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	res, err := client.Get(server.URL + "/.well-known/caldav")
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusMovedPermanently, res.StatusCode)
+	assert.Equal(t, "/caldav/", res.Header.Get("Location"))
+
+	propfind := `<?xml version="1.0"?><d:propfind xmlns:d="DAV:" xmlns:cs="http://calendarserver.org/ns/" xmlns:x="urn:x">` +
+		`<d:prop><d:resourcetype/><d:displayname/><cs:getctag/><x:unknown/></d:prop></d:propfind>`
+	res, body := request(t, server, "PROPFIND", "/caldav/", propfind, map[string]string{"Depth": "1"})
+	assert.Equal(t, http.StatusMultiStatus, res.StatusCode)
+	assert.Contains(t, body, "<d:href>/caldav/tasks/</d:href>")
+	assert.Contains(t, body, "<c:calendar/>")
+	assert.Contains(t, body, `<unknown xmlns="urn:x"/>`)
+	ctag := between(body, "<d:href>/caldav/tasks/</d:href>", "<cs:getctag>", "</cs:getctag>")
+	assert.NotEmpty(t, ctag)
+
+	// Events don't belong in a to-do calendar
+	event := strings.ReplaceAll(todo, "VTODO", "VEVENT")
+	res, _ = request(t, server, "PUT", "/caldav/tasks/buy-milk.ics", event, nil)
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+
+	res, _ = request(t, server, "PUT", "/caldav/tasks/buy-milk.ics", strings.Replace(todo, "%s", "Buy milk", 1),
+		map[string]string{"If-None-Match": "*"})
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	assert.Empty(t, res.Header.Get("ETag"), "the stored data differs from the one put")
+
+	res, _ = request(t, server, "PUT", "/caldav/tasks/buy-milk.ics", strings.Replace(todo, "%s", "Buy milk", 1),
+		map[string]string{"If-None-Match": "*"})
+	assert.Equal(t, http.StatusPreconditionFailed, res.StatusCode, "the object exists")
+
+	res, body = request(t, server, "GET", "/caldav/tasks/buy-milk.ics", "", nil)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	etag := res.Header.Get("ETag")
+	assert.NotEmpty(t, etag)
+	assert.Contains(t, body, "SUMMARY:Buy milk")
+
+	_, body = request(t, server, "PROPFIND", "/caldav/tasks/", propfind, map[string]string{"Depth": "0"})
+	assert.NotEqual(t, ctag, between(body, "<d:href>/caldav/tasks/</d:href>", "<cs:getctag>", "</cs:getctag>"),
+		"the ctag changes with the objects")
+	assert.NotContains(t, body, "buy-milk.ics", "depth 0 leaves out the objects")
+
+	res, _ = request(t, server, "PUT", "/caldav/tasks/buy-milk.ics", strings.Replace(todo, "%s", "Buy oat milk", 1),
+		map[string]string{"If-Match": `"stale"`})
+	assert.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+	res, _ = request(t, server, "PUT", "/caldav/tasks/buy-milk.ics", strings.Replace(todo, "%s", "Buy oat milk", 1),
+		map[string]string{"If-Match": etag})
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	res, _ = request(t, server, "HEAD", "/caldav/tasks/buy-milk.ics", "", nil)
+	assert.NotEqual(t, etag, res.Header.Get("ETag"))
+
+	query := `<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav"><d:prop><d:getetag/></d:prop>` +
+		`<c:filter><c:comp-filter name="VCALENDAR"><c:comp-filter name="VTODO"/></c:comp-filter></c:filter></c:calendar-query>`
+	_, body = request(t, server, "REPORT", "/caldav/tasks/", query, map[string]string{"Depth": "1"})
+	assert.Contains(t, body, "<d:href>/caldav/tasks/buy-milk.ics</d:href>")
+	assert.Contains(t, body, "<d:getetag>")
+	_, body = request(t, server, "REPORT", "/caldav/tasks/", strings.ReplaceAll(query, `"VTODO"`, `"VEVENT"`), nil)
+	assert.NotContains(t, body, "buy-milk.ics")
+
+	multiget := `<c:calendar-multiget xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">` +
+		`<d:prop><d:getetag/><c:calendar-data/></d:prop>` +
+		`<d:href>/caldav/tasks/buy-milk.ics</d:href><d:href>/caldav/tasks/missing.ics</d:href></c:calendar-multiget>`
+	res, body = request(t, server, "REPORT", "/caldav/tasks/", multiget, nil)
+	assert.Equal(t, http.StatusMultiStatus, res.StatusCode)
+	assert.Contains(t, body, "SUMMARY:Buy oat milk")
+	assert.Contains(t, body, "<d:href>/caldav/tasks/missing.ics</d:href><d:status>HTTP/1.1 404 Not Found</d:status>")
+
+	res, _ = request(t, server, "DELETE", "/caldav/tasks/buy-milk.ics", "", map[string]string{"If-Match": etag})
+	assert.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+	res, _ = request(t, server, "DELETE", "/caldav/tasks/buy-milk.ics", "", nil)
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	res, _ = request(t, server, "GET", "/caldav/tasks/buy-milk.ics", "", nil)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+// between returns the text between open and close after marker
+func between(s, marker, open, close string) string {
+	_, s, _ = strings.Cut(s, marker)
+	_, s, _ = strings.Cut(s, open)
+	s, _, _ = strings.Cut(s, close)
+	return s
+}
+
+// newClientTodo builds a to-do calendar the way a client library does
+func newClientTodo(uid, summary string, props ...*ical.Prop) *ical.Calendar {
+	todo := ical.NewComponent(ical.CompToDo)
+	todo.Props.SetText(ical.PropUID, uid)
+	todo.Props.SetText(ical.PropSummary, summary)
+	todo.Props.SetDateTime(ical.PropDateTimeStamp, time.Now())
+	for _, prop := range props {
+		todo.Props.Set(prop)
+	}
+
+	cal := ical.NewCalendar()
+	cal.Props.SetText(ical.PropVersion, "2.0")
+	cal.Props.SetText(ical.PropProductID, "-//test//EN")
+	cal.Children = append(cal.Children, todo)
+	return cal
+}
+
+// clientRoundTrip puts cal at path in the tasks calendar with a CalDAV client
+// library, finds it again with a calendar-query and fetches it, checking the
+// ETags agree. The fetched object is returned
+func clientRoundTrip(t *testing.T, server *httptest.Server, path string, cal *ical.Calendar) *webdavcaldav.CalendarObject {
+	t.Helper()
+	ctx := context.Background()
+
+	dav, err := webdav.NewClient(server.Client(), server.URL+"/caldav/")
+	require.NoError(t, err)
+	principal, err := dav.FindCurrentUserPrincipal(ctx)
+	require.NoError(t, err)
+	client, err := webdavcaldav.NewClient(server.Client(), server.URL+"/caldav/")
+	require.NoError(t, err)
+	home, err := client.FindCalendarHomeSet(ctx, principal)
+	require.NoError(t, err)
+	calendars, err := client.FindCalendars(ctx, home)
+	require.NoError(t, err)
+	var tasks *webdavcaldav.Calendar
+	for i := range calendars {
+		if calendars[i].Path == home+"tasks/" {
+			tasks = &calendars[i]
+		}
+	}
+	require.NotNil(t, tasks, "the tasks calendar is found")
+	assert.Equal(t, []string{"VTODO"}, tasks.SupportedComponentSet)
+
+	_, err = client.PutCalendarObject(ctx, path, cal)
+	require.NoError(t, err)
+
+	objects, err := client.QueryCalendar(ctx, tasks.Path, &webdavcaldav.CalendarQuery{
+		CompRequest: webdavcaldav.CalendarCompRequest{
+			Name:     "VCALENDAR",
+			AllProps: true,
+			AllComps: true,
+		},
+		CompFilter: webdavcaldav.CompFilter{
+			Name:  "VCALENDAR",
+			Comps: []webdavcaldav.CompFilter{{Name: "VTODO"}},
+		},
+	})
+	require.NoError(t, err)
+	var listed *webdavcaldav.CalendarObject
+	for i := range objects {
+		if objects[i].Path == path {
+			listed = &objects[i]
+		}
+	}
+	require.NotNil(t, listed, "the object is listed under the name it was put as")
+	assert.NotEmpty(t, listed.ETag)
+
+	object, err := client.GetCalendarObject(ctx, path)
+	require.NoError(t, err)
+	assert.Equal(t, listed.ETag, object.ETag)
+	return object
+}
+
+func TestCalDAVClient(t *testing.T) {
+	backend := &memoryBackend{objects: make(map[string]map[string]Object)}
+	server := httptest.NewServer(NewHandler(backend, "/caldav/"))
+	defer server.Close()
+
+	object := clientRoundTrip(t, server, "/caldav/tasks/6B29FC40-CA47-1067-B31D-00DD010662DA.ics",
+		newClientTodo("6B29FC40-CA47-1067-B31D-00DD010662DA", "Buy milk"))
+	todos := object.Data.Children
+	require.Len(t, todos, 1)
+	summary, err := todos[0].Props.Text(ical.PropSummary)
+	require.NoError(t, err)
+	assert.Equal(t, "Buy milk", summary)
+}
@@ -0,0 +1,318 @@
+package caldav
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/jskallebak/prod/internal/services"
+)
+
+const (
+	// tasksCalendar holds the tasks without a project
+	tasksCalendar = "tasks"
+	// eventsCalendar holds the calendar events
+	eventsCalendar = "events"
+	projectPrefix  = "project-"
+)
+
+// safeName matches UIDs that can be used as object names as they are
+var safeName = regexp.MustCompile(`^[A-Za-z0-9._@-]{1,200}$`)
+
+// ProdBackend serves the tasks and calendar events of a user: a to-do calendar
+// for the tasks without a project, one for each project and an event
+// calendar. Objects keep the name a client put them as, the others are named
+// after their UID. Every change runs in a UnitOfWork and goes through the
+// services, like changes made with the CLI
+type ProdBackend struct {
+	db      services.TxBeginner
+	queries *sqlc.Queries
+	userID  int32
+}
+
+// NewProdBackend creates a backend for the calendars of a user
+func NewProdBackend(db services.TxBeginner, queries *sqlc.Queries, userID int32) *ProdBackend {
+	return &ProdBackend{
+		db:      db,
+		queries: queries,
+		userID:  userID,
+	}
+}
+
+func (b *ProdBackend) user() pgtype.Int4 {
+	return pgtype.Int4{
+		Int32: b.userID,
+		Valid: true,
+	}
+}
+
+// Calendars implements Backend
+func (b *ProdBackend) Calendars(ctx context.Context) ([]Calendar, error) {
+	projects, err := b.queries.ListProjects(ctx, b.user())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	calendars := []Calendar{{
+		Name:        tasksCalendar,
+		DisplayName: "Tasks",
+		Description: "prod tasks without a project",
+		Component:   "VTODO",
+	}}
+	for _, project := range projects {
+		calendars = append(calendars, Calendar{
+			Name:        fmt.Sprintf("%s%d", projectPrefix, project.ID),
+			DisplayName: project.Name,
+			Description: project.Description.String,
+			Component:   "VTODO",
+		})
+	}
+	calendars = append(calendars, Calendar{
+		Name:        eventsCalendar,
+		DisplayName: "Events",
+		Description: "prod calendar events",
+		Component:   "VEVENT",
+	})
+	return calendars, nil
+}
+
+// projectID returns the project of a to-do calendar, unset for the tasks
+// without a project
+func projectID(calendar string) (pgtype.Int4, error) {
+	if calendar == tasksCalendar {
+		return pgtype.Int4{}, nil
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(calendar, projectPrefix), 10, 32)
+	if err != nil || !strings.HasPrefix(calendar, projectPrefix) {
+		return pgtype.Int4{}, ErrNotFound
+	}
+	return pgtype.Int4{
+		Int32: int32(id),
+		Valid: true,
+	}, nil
+}
+
+func (b *ProdBackend) tasks(ctx context.Context, calendar string) ([]sqlc.Task, error) {
+	project, err := projectID(calendar)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []sqlc.Task
+	// Waiting tasks are synced too, like the tasks without a project
+	if project.Valid {
+		tasks, err = b.queries.GetProjectTasks(ctx, sqlc.GetProjectTasksParams{
+			ProjectID: project,
+			UserID:    b.user(),
+		})
+	} else {
+		tasks, err = b.queries.ListTasksWithoutProject(ctx, b.user())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// series groups the calendar events by UID
+func (b *ProdBackend) series(ctx context.Context) ([][]sqlc.CalendarEvent, error) {
+	events, err := b.queries.ListCalendarEvents(ctx, b.user())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calendar events: %w", err)
+	}
+
+	// Events are ordered by UID with the series first
+	var series [][]sqlc.CalendarEvent
+	for i, event := range events {
+		if i == 0 || events[i-1].Uid != event.Uid {
+			series = append(series, nil)
+		}
+		series[len(series)-1] = append(series[len(series)-1], event)
+	}
+	return series, nil
+}
+
+// names returns the names clients put objects as by their UID
+func (b *ProdBackend) names(ctx context.Context) (map[string]string, error) {
+	objects, err := b.queries.ListCaldavObjects(ctx, b.userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list object names: %w", err)
+	}
+	names := make(map[string]string, len(objects))
+	for _, object := range objects {
+		names[object.Uid] = object.Name
+	}
+	return names, nil
+}
+
+// taskName names a task after the name it was put as, or its UUID
+func taskName(names map[string]string, task sqlc.Task) string {
+	uid := services.FormatUUID(task.Uuid)
+	if name, ok := names[uid]; ok {
+		return name
+	}
+	return uid + ".ics"
+}
+
+// eventName names an event after the name it was put as, or its UID. A hash of
+// the UID is used when it has characters that don't belong in a URL
+func eventName(names map[string]string, uid string) string {
+	if name, ok := names[uid]; ok {
+		return name
+	}
+	if safeName.MatchString(uid) {
+		return uid + ".ics"
+	}
+	sum := sha1.Sum([]byte(uid))
+	return hex.EncodeToString(sum[:]) + ".ics"
+}
+
+// Objects implements Backend
+func (b *ProdBackend) Objects(ctx context.Context, calendar string) ([]Object, error) {
+	icalService := services.NewICalService(b.queries)
+	names, err := b.names(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if calendar == eventsCalendar {
+		series, err := b.series(ctx)
+		if err != nil {
+			return nil, err
+		}
+		objects := make([]Object, 0, len(series))
+		for _, events := range series {
+			object := Object{
+				Name: eventName(names, events[0].Uid),
+				Data: services.EventCalendar(events),
+			}
+			for _, event := range events {
+				if event.UpdatedAt.Time.After(object.Modified) {
+					object.Modified = event.UpdatedAt.Time
+				}
+			}
+			objects = append(objects, object)
+		}
+		return objects, nil
+	}
+
+	tasks, err := b.tasks(ctx, calendar)
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]Object, 0, len(tasks))
+	for _, task := range tasks {
+		data, err := icalService.TaskCalendar(ctx, b.userID, task)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, Object{
+			Name:     taskName(names, task),
+			Data:     data,
+			Modified: task.UpdatedAt.Time,
+		})
+	}
+	return objects, nil
+}
+
+// PutObject implements Backend. Objects are stored under the name they are put
+// as, so an object put under another name moves there. A name can't be reused
+// for an object with another UID
+func (b *ProdBackend) PutObject(ctx context.Context, calendar, name string, data *services.ICalComponent) error {
+	objects, err := b.Objects(ctx, calendar)
+	if err != nil {
+		return err
+	}
+	var taken string
+	for _, object := range objects {
+		if object.Name == name {
+			taken = objectUID(object)
+		}
+	}
+
+	uow := services.NewUnitOfWork(b.db, b.queries)
+	return uow.Do(ctx, func(tx *services.TxServices) error {
+		var uid string
+		if calendar == eventsCalendar {
+			var err error
+			uid, err = tx.ICal.PutEvent(ctx, b.userID, data)
+			if err != nil {
+				return err
+			}
+		} else {
+			project, err := projectID(calendar)
+			if err != nil {
+				return err
+			}
+			task, err := tx.ICal.PutTask(ctx, b.userID, project, data)
+			if err != nil {
+				return err
+			}
+			uid = services.FormatUUID(task.Uuid)
+		}
+
+		if taken != "" && taken != uid {
+			return fmt.Errorf("%w: %s holds another object", services.ErrInvalidCalendar, name)
+		}
+		return tx.Queries.SetCaldavObjectName(ctx, sqlc.SetCaldavObjectNameParams{
+			UserID: b.userID,
+			Uid:    uid,
+			Name:   name,
+		})
+	})
+}
+
+// objectUID returns the UID of the to-do or event of an object
+func objectUID(object Object) string {
+	for _, component := range object.Data.Components {
+		if component.Name == "VTODO" || component.Name == "VEVENT" {
+			uid, _ := component.Property("UID")
+			return uid.Value
+		}
+	}
+	return ""
+}
+
+// DeleteObject implements Backend. Tasks are moved to the trash
+func (b *ProdBackend) DeleteObject(ctx context.Context, calendar, name string) error {
+	uow := services.NewUnitOfWork(b.db, b.queries)
+	names, err := b.names(ctx)
+	if err != nil {
+		return err
+	}
+
+	if calendar == eventsCalendar {
+		series, err := b.series(ctx)
+		if err != nil {
+			return err
+		}
+		for _, events := range series {
+			if eventName(names, events[0].Uid) == name {
+				return uow.Do(ctx, func(tx *services.TxServices) error {
+					return tx.ICal.DeleteEvent(ctx, b.userID, events[0].Uid)
+				})
+			}
+		}
+		return ErrNotFound
+	}
+
+	tasks, err := b.tasks(ctx, calendar)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if taskName(names, task) == name {
+			return uow.Do(ctx, func(tx *services.TxServices) error {
+				_, err := tx.Tasks.DeleteTask(ctx, task.ID, b.userID)
+				return err
+			})
+		}
+	}
+	return ErrNotFound
+}
@@ -0,0 +1,100 @@
+package caldav
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-ical"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/dbtest"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/jskallebak/prod/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProdBackend(t *testing.T) {
+	db, queries := dbtest.Open(t)
+	ctx := context.Background()
+	user := dbtest.User(t, queries)
+	userID := pgtype.Int4{Int32: user.ID, Valid: true}
+
+	server := httptest.NewServer(NewHandler(NewProdBackend(db, queries, user.ID), "/caldav/"))
+	defer server.Close()
+
+	// The task keeps the name the client put it as
+	path := "/caldav/tasks/water-plants.ics"
+	due := ical.NewProp(ical.PropDue)
+	due.SetDate(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	rrule := ical.NewProp(ical.PropRecurrenceRule)
+	rrule.SetValueType(ical.ValueRecurrence)
+	rrule.Value = "FREQ=DAILY"
+	clientRoundTrip(t, server, path, newClientTodo("water-plants", "Water plants", due, rrule))
+
+	// The task is created through the services
+	tasks, err := queries.ListTasksWithoutProject(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	task := tasks[0]
+	assert.Equal(t, "Water plants", task.Description)
+	assert.Equal(t, "daily", task.Recurrence.String)
+
+	// The ETag only changes with the task
+	res, body := request(t, server, "GET", path, "", nil)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	etag := res.Header.Get("ETag")
+	assert.NotEmpty(t, etag)
+	res, _ = request(t, server, "GET", path, "", nil)
+	assert.Equal(t, etag, res.Header.Get("ETag"))
+
+	// The name can't be taken by another task
+	other := strings.Replace(body, "SUMMARY:Water plants", "SUMMARY:Feed the cat", 1)
+	other = strings.Replace(other, "UID:"+services.FormatUUID(task.Uuid), "UID:feed-the-cat", 1)
+	res, _ = request(t, server, "PUT", path, other, nil)
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+
+	// Completing the task the way a client does creates the next occurrence
+	completed := strings.Replace(body, "STATUS:NEEDS-ACTION", "STATUS:COMPLETED", 1)
+	assert.Contains(t, completed, "RRULE:")
+	res, _ = request(t, server, "PUT", path, completed, map[string]string{"If-Match": etag})
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	res, _ = request(t, server, "GET", path, "", nil)
+	assert.NotEqual(t, etag, res.Header.Get("ETag"))
+
+	tasks, err = queries.ListTasksWithoutProject(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	var next sqlc.Task
+	for _, other := range tasks {
+		if other.ID != task.ID {
+			next = other
+		}
+	}
+	done, err := queries.GetTask(ctx, sqlc.GetTaskParams{ID: task.ID, UserID: userID})
+	require.NoError(t, err)
+	assert.Equal(t, "completed", done.Status)
+	assert.Equal(t, "Water plants", next.Description)
+	assert.NotEqual(t, "completed", next.Status)
+	assert.True(t, next.DueDate.Time.After(done.DueDate.Time), "the next occurrence is due later")
+
+	// Deleting moves the task to the trash
+	res, _ = request(t, server, "DELETE", "/caldav/tasks/"+taskName(nil, next), "", nil)
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	trashed, err := queries.GetTaskIncludingDeleted(ctx, sqlc.GetTaskIncludingDeletedParams{ID: next.ID, UserID: userID})
+	require.NoError(t, err)
+	assert.True(t, trashed.DeletedAt.Valid)
+	res, _ = request(t, server, "GET", "/caldav/tasks/"+taskName(nil, next), "", nil)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	// Every change can be undone like one made with the CLI
+	operations, err := queries.ListUndoableJournalOperations(ctx, sqlc.ListUndoableJournalOperationsParams{
+		UserID: user.ID,
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	assert.Len(t, operations, 3)
+}
@@ -0,0 +1,409 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	nsDAV    = "DAV:"
+	nsCalDAV = "urn:ietf:params:xml:ns:caldav"
+	// nsCS is the Calendar Server namespace of getctag
+	nsCS = "http://calendarserver.org/ns/"
+)
+
+var prefixes = map[string]string{
+	nsDAV:    "d",
+	nsCalDAV: "c",
+	nsCS:     "cs",
+}
+
+var calendarData = xml.Name{Space: nsCalDAV, Local: "calendar-data"}
+
+// property is a WebDAV property with its value as XML
+type property struct {
+	name  xml.Name
+	value string
+}
+
+// resource is a collection or object answered in a multistatus response
+type resource struct {
+	href  string
+	props []property
+
+	// Objects only
+	data     []byte
+	etag     string
+	modified time.Time
+}
+
+func (r *resource) add(space, local, value string) {
+	r.props = append(r.props, property{xml.Name{Space: space, Local: local}, value})
+}
+
+func (r *resource) prop(name xml.Name) (string, bool) {
+	for _, p := range r.props {
+		if p.name == name {
+			return p.value, true
+		}
+	}
+	return "", false
+}
+
+func hrefXML(href string) string {
+	return "<d:href>" + escapeXML(href) + "</d:href>"
+}
+
+func (h *Handler) homeResource() *resource {
+	home := &resource{href: h.Prefix}
+	home.add(nsDAV, "resourcetype", "<d:collection/><d:principal/>")
+	home.add(nsDAV, "displayname", "prod")
+	home.add(nsDAV, "current-user-principal", hrefXML(h.Prefix))
+	home.add(nsDAV, "principal-URL", hrefXML(h.Prefix))
+	home.add(nsCalDAV, "calendar-home-set", hrefXML(h.Prefix))
+	return home
+}
+
+// calendarResource describes a calendar. The ctag is a hash of the names and
+// ETags of its objects, so it changes whenever one of them does
+func (h *Handler) calendarResource(calendar Calendar, objects []*resource) *resource {
+	var state strings.Builder
+	for _, object := range objects {
+		fmt.Fprintf(&state, "%s %s\n", object.href, object.etag)
+	}
+	ctag := etag([]byte(state.String()))
+
+	c := &resource{href: h.Prefix + url.PathEscape(calendar.Name) + "/"}
+	c.add(nsDAV, "resourcetype", "<d:collection/><c:calendar/>")
+	c.add(nsDAV, "displayname", escapeXML(calendar.DisplayName))
+	c.add(nsCalDAV, "calendar-description", escapeXML(calendar.Description))
+	c.add(nsCalDAV, "supported-calendar-component-set", `<c:comp name="`+calendar.Component+`"/>`)
+	c.add(nsCS, "getctag", escapeXML(ctag))
+	c.add(nsDAV, "getetag", escapeXML(ctag))
+	c.add(nsDAV, "current-user-principal", hrefXML(h.Prefix))
+	c.add(nsDAV, "owner", hrefXML(h.Prefix))
+	c.add(nsDAV, "supported-report-set",
+		"<d:supported-report><d:report><c:calendar-query/></d:report></d:supported-report>"+
+			"<d:supported-report><d:report><c:calendar-multiget/></d:report></d:supported-report>")
+	c.add(nsDAV, "current-user-privilege-set",
+		"<d:privilege><d:read/></d:privilege><d:privilege><d:write/></d:privilege>"+
+			"<d:privilege><d:write-content/></d:privilege><d:privilege><d:bind/></d:privilege>"+
+			"<d:privilege><d:unbind/></d:privilege>")
+	return c
+}
+
+func newObjectResource(prefix, calendar string, object Object) (*resource, error) {
+	data, err := serialize(object.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	component := ""
+	for _, c := range object.Data.Components {
+		if c.Name == "VTODO" || c.Name == "VEVENT" {
+			component = c.Name
+			break
+		}
+	}
+
+	r := &resource{
+		href:     prefix + url.PathEscape(calendar) + "/" + url.PathEscape(object.Name),
+		data:     data,
+		etag:     etag(data),
+		modified: object.Modified,
+	}
+	r.add(nsDAV, "resourcetype", "")
+	r.add(nsDAV, "getetag", escapeXML(r.etag))
+	r.add(nsDAV, "getcontenttype", escapeXML(contentType(component)))
+	r.add(nsDAV, "getcontentlength", fmt.Sprint(len(data)))
+	r.add(nsDAV, "getlastmodified", object.Modified.UTC().Format(http.TimeFormat))
+	// Only sent when asked for, see writeMultistatus
+	r.props = append(r.props, property{calendarData, escapeXML(string(data))})
+	return r, nil
+}
+
+// propList collects the names of the properties in a DAV:prop element
+type propList []xml.Name
+
+func (p *propList) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			*p = append(*p, t.Name)
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+type propfindRequest struct {
+	XMLName xml.Name  `xml:"DAV: propfind"`
+	Prop    *propList `xml:"DAV: prop"`
+}
+
+type compFilter struct {
+	Name  string       `xml:"name,attr"`
+	Comps []compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+type reportRequest struct {
+	XMLName xml.Name
+	Prop    *propList `xml:"DAV: prop"`
+	Hrefs   []string  `xml:"DAV: href"`
+	Filter  *struct {
+		Comp compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	} `xml:"urn:ietf:params:xml:ns:caldav filter"`
+}
+
+// decodeBody decodes the XML body of a request into v. false is returned for
+// an empty body
+func decodeBody(r *http.Request, v any) (bool, error) {
+	err := xml.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(v)
+	if errors.Is(err, io.EOF) {
+		return false, nil
+	}
+	if err != nil {
+		return false, &httpError{http.StatusBadRequest, "invalid XML: " + err.Error()}
+	}
+	return true, nil
+}
+
+func (h *Handler) propfind(w http.ResponseWriter, r *http.Request, path string) error {
+	var req propfindRequest
+	if _, err := decodeBody(r, &req); err != nil {
+		return err
+	}
+	// An empty body, allprop and propname all get every property
+	var names []xml.Name
+	if req.Prop != nil {
+		names = *req.Prop
+	}
+	depth := r.Header.Get("Depth")
+
+	calendar, name, err := splitPath(path)
+	if err != nil {
+		return err
+	}
+
+	var resources []*resource
+	switch {
+	case calendar == "":
+		resources = append(resources, h.homeResource())
+		if depth != "0" {
+			calendars, err := h.Backend.Calendars(r.Context())
+			if err != nil {
+				return err
+			}
+			for _, c := range calendars {
+				objects, err := h.objects(r, c.Name)
+				if err != nil {
+					return err
+				}
+				resources = append(resources, h.calendarResource(c, objects))
+			}
+		}
+	case name == "":
+		c, err := h.calendar(r.Context(), calendar)
+		if err != nil {
+			return err
+		}
+		objects, err := h.objects(r, c.Name)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, h.calendarResource(*c, objects))
+		if depth != "0" {
+			resources = append(resources, objects...)
+		}
+	default:
+		object, err := h.object(r.Context(), calendar, name)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, object)
+	}
+
+	writeMultistatus(w, resources, names, nil)
+	return nil
+}
+
+func (h *Handler) report(w http.ResponseWriter, r *http.Request, path string) error {
+	calendar, name, err := splitPath(path)
+	if err != nil {
+		return err
+	}
+	if calendar == "" || name != "" {
+		return &httpError{http.StatusForbidden, "reports are only supported on calendars"}
+	}
+	c, err := h.calendar(r.Context(), calendar)
+	if err != nil {
+		return err
+	}
+
+	var req reportRequest
+	if ok, err := decodeBody(r, &req); err != nil {
+		return err
+	} else if !ok {
+		return &httpError{http.StatusBadRequest, "missing report"}
+	}
+	var names []xml.Name
+	if req.Prop != nil {
+		names = *req.Prop
+	}
+
+	objects, err := h.objects(r, c.Name)
+	if err != nil {
+		return err
+	}
+
+	switch req.XMLName {
+	case xml.Name{Space: nsCalDAV, Local: "calendar-query"}:
+		// Only component filters are applied. Clients filter time ranges and
+		// properties again themselves, so they get every matching object
+		if req.Filter != nil && !matchesComponent(req.Filter.Comp, c.Component) {
+			objects = nil
+		}
+		writeMultistatus(w, objects, names, nil)
+	case xml.Name{Space: nsCalDAV, Local: "calendar-multiget"}:
+		var found []*resource
+		var missing []string
+		for _, href := range req.Hrefs {
+			target := hrefPath(href)
+			i := -1
+			for j, object := range objects {
+				if hrefPath(object.href) == target {
+					i = j
+					break
+				}
+			}
+			if i < 0 {
+				missing = append(missing, href)
+				continue
+			}
+			found = append(found, objects[i])
+		}
+		writeMultistatus(w, found, names, missing)
+	default:
+		return &httpError{http.StatusForbidden, "unsupported report " + req.XMLName.Local}
+	}
+	return nil
+}
+
+// objects lists the objects of a calendar ordered by name
+func (h *Handler) objects(r *http.Request, calendar string) ([]*resource, error) {
+	objects, err := h.Backend.Objects(r.Context(), calendar)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]*resource, 0, len(objects))
+	for _, object := range objects {
+		resource, err := newObjectResource(h.Prefix, calendar, object)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, resource)
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].href < resources[j].href
+	})
+	return resources, nil
+}
+
+// matchesComponent checks the comp-filter of a calendar-query against the
+// component type of a calendar
+func matchesComponent(filter compFilter, component string) bool {
+	if filter.Name != "VCALENDAR" {
+		return false
+	}
+	for _, comp := range filter.Comps {
+		if comp.Name != component {
+			return false
+		}
+	}
+	return true
+}
+
+// hrefPath returns the unescaped path of an href, which may be a full URL
+func hrefPath(href string) string {
+	u, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return href
+	}
+	return u.Path
+}
+
+// writeMultistatus answers with the properties names of resources, or all of
+// them but the calendar data when names is empty. missing are hrefs that
+// weren't found
+func writeMultistatus(w http.ResponseWriter, resources []*resource, names []xml.Name, missing []string) {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n")
+	b.WriteString(`<d:multistatus xmlns:d="DAV:" xmlns:c="` + nsCalDAV + `" xmlns:cs="` + nsCS + `">`)
+	for _, r := range resources {
+		var found, notFound strings.Builder
+		if len(names) == 0 {
+			for _, p := range r.props {
+				if p.name != calendarData {
+					found.WriteString(element(p.name, p.value))
+				}
+			}
+		}
+		for _, name := range names {
+			if value, ok := r.prop(name); ok {
+				found.WriteString(element(name, value))
+			} else {
+				notFound.WriteString(element(name, ""))
+			}
+		}
+
+		b.WriteString("<d:response>" + hrefXML(r.href))
+		if found.Len() > 0 {
+			b.WriteString("<d:propstat><d:prop>" + found.String() + "</d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>")
+		}
+		if notFound.Len() > 0 {
+			b.WriteString("<d:propstat><d:prop>" + notFound.String() + "</d:prop><d:status>HTTP/1.1 404 Not Found</d:status></d:propstat>")
+		}
+		b.WriteString("</d:response>")
+	}
+	for _, href := range missing {
+		b.WriteString("<d:response>" + hrefXML(href) + "<d:status>HTTP/1.1 404 Not Found</d:status></d:response>")
+	}
+	b.WriteString("</d:multistatus>\n")
+
+	w.Header().Set("Content-Type", `application/xml; charset="utf-8"`)
+	w.WriteHeader(http.StatusMultiStatus)
+	io.WriteString(w, b.String())
+}
+
+// element formats an XML element, declaring the namespace when it has no
+// prefix of its own
+func element(name xml.Name, value string) string {
+	tag, declaration := name.Local, ` xmlns="`+escapeXML(name.Space)+`"`
+	if prefix, ok := prefixes[name.Space]; ok {
+		tag, declaration = prefix+":"+name.Local, ""
+	}
+	if value == "" {
+		return "<" + tag + declaration + "/>"
+	}
+	return "<" + tag + declaration + ">" + value + "</" + tag + ">"
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
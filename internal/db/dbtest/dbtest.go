@@ -0,0 +1,74 @@
+// Package dbtest gives tests a migrated database
+package dbtest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+// Open migrates a schema of its own in the database of PROD_TEST_DATABASE_URL
+// and drops it when the test ends. The test is skipped when the variable is
+// not set
+func Open(t *testing.T) (*pgxpool.Pool, *sqlc.Queries) {
+	t.Helper()
+	url := os.Getenv("PROD_TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("PROD_TEST_DATABASE_URL not set")
+	}
+	ctx := context.Background()
+
+	admin, err := pgxpool.New(ctx, url)
+	require.NoError(t, err)
+	schema := fmt.Sprintf("prod_test_%d", time.Now().UnixNano())
+	_, err = admin.Exec(ctx, "CREATE SCHEMA "+schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		admin.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE")
+		admin.Close()
+	})
+
+	config, err := pgxpool.ParseConfig(url)
+	require.NoError(t, err)
+	config.ConnConfig.RuntimeParams["search_path"] = schema
+	db, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	// Run the Up part of every goose migration in order
+	_, source, _, _ := runtime.Caller(0)
+	files, err := filepath.Glob(filepath.Join(filepath.Dir(source), "..", "migrations", "*.sql"))
+	require.NoError(t, err)
+	sort.Strings(files)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		_, up, _ := strings.Cut(string(data), "-- +goose Up")
+		up, _, _ = strings.Cut(up, "-- +goose Down")
+		_, err = db.Exec(ctx, up)
+		require.NoError(t, err, file)
+	}
+
+	return db, sqlc.New(db)
+}
+
+// User creates a user to own the test data
+func User(t *testing.T, queries *sqlc.Queries) sqlc.User {
+	t.Helper()
+	user, err := queries.CreateUser(context.Background(), sqlc.CreateUserParams{
+		Email:        fmt.Sprintf("test-%d@example.com", time.Now().UnixNano()),
+		PasswordHash: "-",
+	})
+	require.NoError(t, err)
+	return user
+}
@@ -0,0 +1,15 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied
+
+-- Daily and weekly targets for completed pomodoros (0 means no goal)
+CREATE TABLE IF NOT EXISTS pomodoro_goals (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id),
+    daily_goal INTEGER NOT NULL DEFAULT 0,
+    weekly_goal INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back
+DROP TABLE IF EXISTS pomodoro_goals;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied
+ALTER TABLE tasks
+ADD COLUMN estimate_minutes INTEGER;
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back
+ALTER TABLE tasks
+DROP COLUMN estimate_minutes;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied
+
+-- Named work/break cadences, e.g. "deep" 50/10
+CREATE TABLE IF NOT EXISTS pomodoro_presets (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    work_duration INTEGER NOT NULL DEFAULT 25,
+    break_duration INTEGER NOT NULL DEFAULT 5,
+    long_break_duration INTEGER NOT NULL DEFAULT 15,
+    long_break_interval INTEGER NOT NULL DEFAULT 4,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, name)
+);
+
+-- Optional default preset used when starting a pomodoro on a task in the project
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS pomodoro_preset_id INTEGER REFERENCES pomodoro_presets(id) ON DELETE SET NULL;
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back
+ALTER TABLE projects DROP COLUMN IF EXISTS pomodoro_preset_id;
+DROP TABLE IF EXISTS pomodoro_presets;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied
+
+-- Opt-in coupling of pomodoro sessions and the state of their task
+ALTER TABLE pomodoro_config ADD COLUMN IF NOT EXISTS link_tasks BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back
+ALTER TABLE pomodoro_config DROP COLUMN IF EXISTS link_tasks;
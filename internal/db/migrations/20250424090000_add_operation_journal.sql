@@ -0,0 +1,31 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied
+
+-- One row per undoable command, undone_at is set while it sits on the redo stack
+CREATE TABLE IF NOT EXISTS journal_operations (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    description TEXT NOT NULL,
+    undone_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_journal_operations_user_id ON journal_operations(user_id, id);
+
+-- State of a task or project before and after each change, NULL when it didn't exist
+CREATE TABLE IF NOT EXISTS journal_changes (
+    id SERIAL PRIMARY KEY,
+    operation_id INTEGER NOT NULL REFERENCES journal_operations(id) ON DELETE CASCADE,
+    entity_type TEXT NOT NULL,
+    entity_id INTEGER NOT NULL,
+    before_state JSONB,
+    after_state JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_journal_changes_operation_id ON journal_changes(operation_id);
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back
+DROP TABLE IF EXISTS journal_changes;
+DROP TABLE IF EXISTS journal_operations;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied
+
+-- Append-only history of task changes. task_id has no foreign key so the
+-- history of a deleted task is kept
+CREATE TABLE IF NOT EXISTS task_events (
+    id SERIAL PRIMARY KEY,
+    task_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_events_task_id ON task_events(task_id, id);
+CREATE INDEX IF NOT EXISTS idx_task_events_user_type ON task_events(user_id, event_type, created_at);
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back
+DROP TABLE IF EXISTS task_events;
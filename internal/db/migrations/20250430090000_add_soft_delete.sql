@@ -0,0 +1,32 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied
+
+-- Deleted tasks and projects stay in the trash until purged
+ALTER TABLE tasks
+ADD COLUMN deleted_at TIMESTAMPTZ;
+
+ALTER TABLE projects
+ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_deleted_at ON tasks(user_id, deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_projects_deleted_at ON projects(user_id, deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Tasks detached from a trashed project, re-attached when it is restored
+CREATE TABLE IF NOT EXISTS trashed_project_tasks (
+    project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    PRIMARY KEY (project_id, task_id)
+);
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back
+DROP TABLE IF EXISTS trashed_project_tasks;
+
+DROP INDEX IF EXISTS idx_projects_deleted_at;
+DROP INDEX IF EXISTS idx_tasks_deleted_at;
+
+ALTER TABLE projects
+DROP COLUMN deleted_at;
+
+ALTER TABLE tasks
+DROP COLUMN deleted_at;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied
+
+-- Stable identifier used to match tasks with other tools across imports
+ALTER TABLE tasks
+ADD COLUMN uuid UUID NOT NULL DEFAULT gen_random_uuid();
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_user_uuid ON tasks(user_id, uuid);
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back
+DROP INDEX IF EXISTS idx_tasks_user_uuid;
+
+ALTER TABLE tasks
+DROP COLUMN uuid;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied
+
+-- iCalendar UID of an event, stable across exports and imports
+ALTER TABLE calendar_events
+ADD COLUMN uid TEXT NOT NULL DEFAULT gen_random_uuid()::text;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_events_user_uid ON calendar_events(user_id, uid);
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back
+DROP INDEX IF EXISTS idx_calendar_events_user_uid;
+
+ALTER TABLE calendar_events
+DROP COLUMN uid;
@@ -0,0 +1,37 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied
+
+-- Recurring events keep their iCalendar RRULE, the excluded occurrences and
+-- the time zone the rule is expanded in. A changed occurrence of a series is
+-- stored as its own event with the UID of the series and a recurrence_id
+ALTER TABLE calendar_events
+ADD COLUMN rrule TEXT,
+ADD COLUMN exdates TIMESTAMPTZ[] NOT NULL DEFAULT '{}',
+ADD COLUMN recurrence_id TIMESTAMPTZ,
+ADD COLUMN timezone TEXT,
+ADD COLUMN sequence INTEGER NOT NULL DEFAULT 0;
+
+DROP INDEX IF EXISTS idx_calendar_events_user_uid;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_events_user_uid ON calendar_events(user_id, uid)
+WHERE recurrence_id IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_events_user_uid_recurrence ON calendar_events(user_id, uid, recurrence_id)
+WHERE recurrence_id IS NOT NULL;
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back
+DROP INDEX IF EXISTS idx_calendar_events_user_uid_recurrence;
+
+DELETE FROM calendar_events WHERE recurrence_id IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_calendar_events_user_uid;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_events_user_uid ON calendar_events(user_id, uid);
+
+ALTER TABLE calendar_events
+DROP COLUMN rrule,
+DROP COLUMN exdates,
+DROP COLUMN recurrence_id,
+DROP COLUMN timezone,
+DROP COLUMN sequence;
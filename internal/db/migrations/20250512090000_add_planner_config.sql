@@ -0,0 +1,21 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied
+
+-- Working hours 'prod plan' places tasks in, as minutes since midnight, and
+-- the ISO weekdays worked on (1 is Monday)
+CREATE TABLE IF NOT EXISTS planner_config (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id),
+    work_start INTEGER NOT NULL DEFAULT 540,
+    work_end INTEGER NOT NULL DEFAULT 1020,
+    work_days INTEGER[] NOT NULL DEFAULT '{1,2,3,4,5}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Planned time blocks are looked up by task and event
+CREATE INDEX IF NOT EXISTS idx_task_calendar_event_id ON task_calendar(event_id);
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back
+DROP INDEX IF EXISTS idx_task_calendar_event_id;
+DROP TABLE IF EXISTS planner_config;
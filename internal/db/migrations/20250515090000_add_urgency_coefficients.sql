@@ -0,0 +1,16 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied
+
+-- Urgency coefficients that differ from the defaults, by name like "due",
+-- "priority.H" or "tag.next"
+CREATE TABLE IF NOT EXISTS urgency_coefficients (
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    name TEXT NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, name)
+);
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back
+DROP TABLE IF EXISTS urgency_coefficients;
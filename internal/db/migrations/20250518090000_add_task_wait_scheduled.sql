@@ -0,0 +1,19 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied
+
+-- wait_until hides a task from the default lists until then, scheduled is the
+-- day work on it is planned to begin. start_date keeps recording when a task
+-- was started
+ALTER TABLE tasks
+ADD COLUMN wait_until TIMESTAMP WITH TIME ZONE,
+ADD COLUMN scheduled TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_wait_until ON tasks(user_id, wait_until) WHERE wait_until IS NOT NULL;
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back
+DROP INDEX IF EXISTS idx_tasks_wait_until;
+
+ALTER TABLE tasks
+DROP COLUMN wait_until,
+DROP COLUMN scheduled;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied
+
+-- The names sync clients put calendar objects as, by the UID of the task or
+-- event. Clients keep their own names and fetch objects by them
+CREATE TABLE IF NOT EXISTS caldav_objects (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    uid TEXT NOT NULL,
+    name TEXT NOT NULL,
+    PRIMARY KEY (user_id, uid)
+);
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back
+DROP TABLE IF EXISTS caldav_objects;
@@ -0,0 +1,8 @@
+-- name: ListCaldavObjects :many
+SELECT * FROM caldav_objects
+WHERE user_id = $1;
+
+-- name: SetCaldavObjectName :exec
+INSERT INTO caldav_objects (user_id, uid, name)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, uid) DO UPDATE SET name = EXCLUDED.name;
@@ -0,0 +1,97 @@
+-- name: ListCalendarEventsInRange :many
+-- Recurring events are listed from their first occurrence on, since later
+-- occurrences may fall in the range, and with all their changed occurrences
+SELECT * FROM calendar_events
+WHERE user_id = sqlc.arg(user_id)
+AND (
+    (end_time >= sqlc.arg(range_start) OR rrule IS NOT NULL)
+    AND start_time <= sqlc.arg(range_end)
+    OR recurrence_id IS NOT NULL
+)
+ORDER BY start_time, id;
+
+-- name: GetCalendarEventByUID :one
+SELECT * FROM calendar_events
+WHERE user_id = $1 AND uid = $2
+AND recurrence_id IS NOT DISTINCT FROM $3;
+
+-- name: CreateCalendarEvent :one
+INSERT INTO calendar_events (
+    user_id,
+    title,
+    description,
+    start_time,
+    end_time,
+    all_day,
+    location,
+    project_id,
+    uid,
+    rrule,
+    exdates,
+    recurrence_id,
+    timezone,
+    sequence
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+)
+RETURNING *;
+
+-- name: UpdateCalendarEvent :one
+UPDATE calendar_events
+SET
+    title = $3,
+    description = $4,
+    start_time = $5,
+    end_time = $6,
+    all_day = $7,
+    location = $8,
+    rrule = $9,
+    exdates = $10,
+    timezone = $11,
+    sequence = $12,
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteCalendarEvent :exec
+DELETE FROM calendar_events
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteCalendarEventsByUID :execrows
+-- Deletes a series together with its changed occurrences
+DELETE FROM calendar_events
+WHERE user_id = $1 AND uid = $2;
+
+-- name: ListCalendarEvents :many
+SELECT * FROM calendar_events
+WHERE user_id = $1
+ORDER BY uid, recurrence_id NULLS FIRST;
+
+-- name: ListCalendarEventsByUID :many
+-- The series comes first, followed by its changed occurrences
+SELECT * FROM calendar_events
+WHERE user_id = $1 AND uid = $2
+ORDER BY recurrence_id NULLS FIRST;
+
+-- name: ImportCalendarEvent :one
+INSERT INTO calendar_events (
+    user_id,
+    title,
+    description,
+    start_time,
+    end_time,
+    all_day,
+    location,
+    project_id,
+    uid,
+    rrule,
+    exdates,
+    recurrence_id,
+    timezone,
+    sequence,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+)
+RETURNING *;
@@ -0,0 +1,43 @@
+-- name: ListHabits :many
+SELECT * FROM habits
+WHERE user_id = $1
+ORDER BY name;
+
+-- name: ListHabitCompletionsInRange :many
+SELECT hc.id, hc.habit_id, hc.completed_date, hc.created_at
+FROM habit_completions hc
+JOIN habits h ON h.id = hc.habit_id
+WHERE h.user_id = sqlc.arg(user_id)
+  AND hc.completed_date >= sqlc.arg(range_start)
+  AND hc.completed_date <= sqlc.arg(range_end)
+ORDER BY hc.completed_date;
+
+-- name: ListUserHabitCompletions :many
+SELECT hc.id, hc.habit_id, hc.completed_date, hc.created_at
+FROM habit_completions hc
+JOIN habits h ON h.id = hc.habit_id
+WHERE h.user_id = $1
+ORDER BY hc.habit_id, hc.completed_date;
+
+-- name: GetHabitByName :one
+SELECT * FROM habits
+WHERE user_id = $1 AND name = $2
+LIMIT 1;
+
+-- name: ImportHabit :one
+INSERT INTO habits (
+    user_id,
+    name,
+    description,
+    frequency,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: AddHabitCompletion :execrows
+INSERT INTO habit_completions (habit_id, completed_date)
+VALUES ($1, $2)
+ON CONFLICT (habit_id, completed_date) DO NOTHING;
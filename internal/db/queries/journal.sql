@@ -0,0 +1,44 @@
+-- name: CreateJournalOperation :one
+INSERT INTO journal_operations (
+    user_id,
+    description
+) VALUES (
+    $1, $2
+) RETURNING *;
+
+-- name: CreateJournalChange :exec
+INSERT INTO journal_changes (
+    operation_id,
+    entity_type,
+    entity_id,
+    before_state,
+    after_state
+) VALUES (
+    $1, $2, $3, $4, $5
+);
+
+-- name: DeleteUndoneJournalOperations :exec
+DELETE FROM journal_operations
+WHERE user_id = $1 AND undone_at IS NOT NULL;
+
+-- name: ListUndoableJournalOperations :many
+SELECT * FROM journal_operations
+WHERE user_id = $1 AND undone_at IS NULL
+ORDER BY id DESC
+LIMIT $2;
+
+-- name: ListRedoableJournalOperations :many
+SELECT * FROM journal_operations
+WHERE user_id = $1 AND undone_at IS NOT NULL
+ORDER BY id ASC
+LIMIT $2;
+
+-- name: ListJournalChanges :many
+SELECT * FROM journal_changes
+WHERE operation_id = $1
+ORDER BY id;
+
+-- name: SetJournalOperationUndone :exec
+UPDATE journal_operations
+SET undone_at = sqlc.narg(undone_at)
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id);
@@ -0,0 +1,34 @@
+-- name: ListNotes :many
+SELECT * FROM notes
+WHERE user_id = $1
+ORDER BY id;
+
+-- name: ListUserTaskNotes :many
+-- Links of tasks that aren't in the trash to their notes
+SELECT tn.* FROM task_notes tn
+JOIN tasks t ON t.id = tn.task_id
+WHERE t.user_id = $1 AND t.deleted_at IS NULL
+ORDER BY tn.id;
+
+-- name: FindDuplicateNote :one
+SELECT * FROM notes
+WHERE user_id = $1 AND title = $2 AND created_at = $3
+LIMIT 1;
+
+-- name: ImportNote :one
+INSERT INTO notes (
+    user_id,
+    title,
+    content,
+    project_id,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: LinkTaskToNote :exec
+INSERT INTO task_notes (task_id, note_id)
+VALUES ($1, $2)
+ON CONFLICT (task_id, note_id) DO NOTHING;
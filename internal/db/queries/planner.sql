@@ -0,0 +1,42 @@
+-- name: GetPlannerConfig :one
+SELECT * FROM planner_config
+WHERE user_id = $1
+LIMIT 1;
+
+-- name: UpsertPlannerConfig :one
+INSERT INTO planner_config (
+    user_id,
+    work_start,
+    work_end,
+    work_days
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (user_id)
+DO UPDATE SET
+    work_start = $2,
+    work_end = $3,
+    work_days = $4,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: LinkTaskToEvent :exec
+INSERT INTO task_calendar (task_id, event_id)
+VALUES ($1, $2)
+ON CONFLICT (task_id, event_id) DO NOTHING;
+
+-- name: ListTaskBlocks :many
+-- Events linked to a task, the blocks of time tasks are planned in, that end
+-- after a time
+SELECT tc.task_id, ce.id AS event_id, ce.start_time, ce.end_time
+FROM task_calendar tc
+JOIN calendar_events ce ON ce.id = tc.event_id
+WHERE ce.user_id = $1 AND ce.end_time > $2
+ORDER BY ce.start_time, ce.id;
+
+-- name: ListUserTaskCalendarLinks :many
+-- Links of tasks that aren't in the trash to their events
+SELECT tc.* FROM task_calendar tc
+JOIN tasks t ON t.id = tc.task_id
+WHERE t.user_id = $1 AND t.deleted_at IS NULL
+ORDER BY tc.id;
@@ -128,9 +128,10 @@ INSERT INTO pomodoro_config (
     long_break_duration,
     long_break_interval,
     auto_start_breaks,
-    auto_start_pomodoros
+    auto_start_pomodoros,
+    link_tasks
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
+    $1, $2, $3, $4, $5, $6, $7, $8
 )
 ON CONFLICT (user_id)
 DO UPDATE SET
@@ -140,5 +141,154 @@ DO UPDATE SET
     long_break_interval = $5,
     auto_start_breaks = $6,
     auto_start_pomodoros = $7,
+    link_tasks = $8,
     updated_at = NOW()
-RETURNING *; 
\ No newline at end of file
+RETURNING *; 
+-- name: LogPomodoroSession :one
+INSERT INTO pomodoro_sessions (
+    user_id,
+    task_id,
+    status,
+    work_duration,
+    break_duration,
+    start_time,
+    end_time,
+    actual_work_duration,
+    note,
+    duration
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9,
+    $4 /* Use work_duration for duration */
+) RETURNING *;
+
+-- name: UpdatePomodoroSession :one
+UPDATE pomodoro_sessions
+SET
+    task_id = $3,
+    status = $4,
+    start_time = $5,
+    end_time = $6,
+    work_duration = $7,
+    duration = $7,
+    actual_work_duration = $8,
+    note = $9
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeletePomodoroSession :exec
+DELETE FROM pomodoro_sessions
+WHERE id = $1 AND user_id = $2;
+
+-- name: CountOverlappingPomodoroSessions :one
+SELECT COUNT(*) FROM pomodoro_sessions
+WHERE user_id = sqlc.arg(user_id)
+  AND id <> sqlc.arg(exclude_id)
+  AND start_time < sqlc.arg(range_end)::timestamptz
+  AND COALESCE(end_time, NOW()) > sqlc.arg(range_start)::timestamptz;
+
+-- name: LockPomodoroSessions :exec
+-- Holds a lock on the sessions of a user until the transaction ends, so
+-- concurrent writers check for overlaps one after the other
+SELECT pg_advisory_xact_lock(hashtext('pomodoro_sessions'), sqlc.arg(user_id)::int);
+
+-- name: GetPomodoroGoal :one
+SELECT * FROM pomodoro_goals
+WHERE user_id = $1
+LIMIT 1;
+
+-- name: UpsertPomodoroGoal :one
+INSERT INTO pomodoro_goals (
+    user_id,
+    daily_goal,
+    weekly_goal
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (user_id)
+DO UPDATE SET
+    daily_goal = $2,
+    weekly_goal = $3,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: ListCompletedPomodoroStartTimes :many
+SELECT start_time FROM pomodoro_sessions
+WHERE user_id = sqlc.arg(user_id)
+  AND status = 'completed'
+  AND (sqlc.narg(since)::timestamptz IS NULL OR start_time >= sqlc.narg(since))
+ORDER BY start_time;
+
+-- name: ListPomodoroSessionsWithTask :many
+SELECT
+    ps.id,
+    ps.task_id,
+    ps.status,
+    ps.start_time,
+    ps.end_time,
+    ps.work_duration,
+    ps.break_duration,
+    ps.total_pause_duration,
+    ps.actual_work_duration,
+    ps.note,
+    t.description AS task_description,
+    t.tags AS task_tags,
+    p.name AS project_name
+FROM pomodoro_sessions ps
+LEFT JOIN tasks t ON t.id = ps.task_id
+LEFT JOIN projects p ON p.id = t.project_id
+WHERE ps.user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(task_id)::integer IS NULL OR ps.task_id = sqlc.narg(task_id))
+  AND (sqlc.narg(start_date)::timestamptz IS NULL OR ps.start_time >= sqlc.narg(start_date))
+  AND (sqlc.narg(end_date)::timestamptz IS NULL OR ps.start_time <= sqlc.narg(end_date))
+ORDER BY ps.start_time;
+
+-- name: CountCompletedPomodorosByTask :many
+SELECT task_id, COUNT(*) AS completed_count
+FROM pomodoro_sessions
+WHERE user_id = $1 AND status = 'completed' AND task_id IS NOT NULL
+GROUP BY task_id;
+
+-- name: ListEstimateAccuracy :many
+SELECT
+    t.id,
+    t.priority,
+    t.estimate_minutes,
+    p.name AS project_name,
+    COUNT(ps.id) AS actual_pomodoros
+FROM tasks t
+LEFT JOIN projects p ON p.id = t.project_id
+LEFT JOIN pomodoro_sessions ps ON ps.task_id = t.id AND ps.status = 'completed'
+WHERE t.user_id = $1
+  AND t.deleted_at IS NULL
+  AND t.status = 'completed'
+  AND t.estimate_minutes IS NOT NULL
+GROUP BY t.id, p.name;
+
+-- name: ListAllPomodoroSessions :many
+SELECT * FROM pomodoro_sessions
+WHERE user_id = $1
+ORDER BY start_time;
+
+-- name: CountPomodoroSessionsStartingAt :one
+SELECT COUNT(*) FROM pomodoro_sessions
+WHERE user_id = $1 AND start_time = $2;
+
+-- name: ImportPomodoroSession :one
+INSERT INTO pomodoro_sessions (
+    user_id,
+    task_id,
+    status,
+    start_time,
+    end_time,
+    duration,
+    completed,
+    work_duration,
+    break_duration,
+    pause_time,
+    total_pause_duration,
+    actual_work_duration,
+    note,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+) RETURNING *;
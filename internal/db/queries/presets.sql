@@ -0,0 +1,38 @@
+-- name: UpsertPomodoroPreset :one
+INSERT INTO pomodoro_presets (
+    user_id,
+    name,
+    work_duration,
+    break_duration,
+    long_break_duration,
+    long_break_interval
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (user_id, name)
+DO UPDATE SET
+    work_duration = $3,
+    break_duration = $4,
+    long_break_duration = $5,
+    long_break_interval = $6,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetPomodoroPreset :one
+SELECT * FROM pomodoro_presets
+WHERE id = $1 AND user_id = $2
+LIMIT 1;
+
+-- name: GetPomodoroPresetByName :one
+SELECT * FROM pomodoro_presets
+WHERE user_id = $1 AND name = $2
+LIMIT 1;
+
+-- name: ListPomodoroPresets :many
+SELECT * FROM pomodoro_presets
+WHERE user_id = $1
+ORDER BY name;
+
+-- name: DeletePomodoroPreset :exec
+DELETE FROM pomodoro_presets
+WHERE user_id = $1 AND name = $2;
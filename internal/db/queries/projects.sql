@@ -10,12 +10,12 @@ INSERT INTO projects (
 
 -- name: GetProject :one
 SELECT * FROM projects
-WHERE id = $1 AND user_id = $2
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
 LIMIT 1;
 
 -- name: ListProjects :many
 SELECT * FROM projects
-WHERE user_id = $1
+WHERE user_id = $1 AND deleted_at IS NULL
 ORDER BY created_at DESC;
 
 -- name: UpdateProject :one
@@ -28,13 +28,25 @@ SET
 WHERE id = $1 AND user_id = $2
 RETURNING *;
 
--- name: DeleteProject :exec
+-- name: DeleteProject :one
+-- Moves the project to the trash
+UPDATE projects
+SET
+    deleted_at = NOW()
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: PurgeProject :exec
 DELETE FROM projects
 WHERE id = $1 AND user_id = $2;
 
 -- name: GetProjectTasks :many
 SELECT t.* FROM tasks t
-WHERE t.project_id = $1 AND t.user_id = $2
+WHERE t.project_id = $1 AND t.user_id = $2 AND t.deleted_at IS NULL
+AND (
+    sqlc.narg(waiting)::boolean IS NULL
+    OR sqlc.narg(waiting) = (t.wait_until IS NOT NULL AND t.wait_until > NOW())
+)
 ORDER BY t.created_at DESC;
 
 -- name: RemoveTaskFromProject :one
@@ -43,4 +55,112 @@ SET
     project_id = NULL,
     updated_at = NOW()
 WHERE id = $1 AND user_id = $2
-RETURNING *; 
\ No newline at end of file
+RETURNING *; 
+-- name: SetProjectPomodoroPreset :one
+UPDATE projects
+SET
+    pomodoro_preset_id = $3,
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: RestoreProject :one
+INSERT INTO projects (
+    id,
+    user_id,
+    name,
+    description,
+    deadline,
+    created_at,
+    updated_at,
+    pomodoro_preset_id,
+    deleted_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+ON CONFLICT (id)
+DO UPDATE SET
+    name = EXCLUDED.name,
+    description = EXCLUDED.description,
+    deadline = EXCLUDED.deadline,
+    created_at = EXCLUDED.created_at,
+    updated_at = EXCLUDED.updated_at,
+    pomodoro_preset_id = EXCLUDED.pomodoro_preset_id,
+    deleted_at = EXCLUDED.deleted_at
+WHERE projects.user_id = EXCLUDED.user_id
+RETURNING *;
+
+-- name: GetTrashedProject :one
+SELECT * FROM projects
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+LIMIT 1;
+
+-- name: ListTrashedProjects :many
+SELECT * FROM projects
+WHERE user_id = $1 AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id;
+
+-- name: UntrashProject :one
+UPDATE projects
+SET
+    deleted_at = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: PurgeTrashedProjects :execrows
+DELETE FROM projects
+WHERE user_id = $1 AND deleted_at < $2;
+
+-- name: ForgetProjectTasks :exec
+DELETE FROM trashed_project_tasks
+WHERE project_id = $1;
+
+-- name: RememberProjectTasks :exec
+INSERT INTO trashed_project_tasks (
+    project_id,
+    task_id
+)
+SELECT project_id, id FROM tasks
+WHERE project_id = $1 AND user_id = $2
+ON CONFLICT DO NOTHING;
+
+-- name: DetachProjectTasks :many
+UPDATE tasks
+SET
+    project_id = NULL,
+    updated_at = NOW()
+WHERE project_id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: ReattachProjectTasks :many
+-- Tasks moved to another project in the meantime are left alone
+UPDATE tasks
+SET
+    project_id = $1,
+    updated_at = NOW()
+WHERE user_id = $2
+AND project_id IS NULL
+AND id IN (
+    SELECT task_id FROM trashed_project_tasks
+    WHERE trashed_project_tasks.project_id = $1
+)
+RETURNING *;
+
+-- name: GetProjectByName :one
+SELECT * FROM projects
+WHERE user_id = $1 AND name = $2 AND deleted_at IS NULL
+LIMIT 1;
+
+-- name: ImportProject :one
+INSERT INTO projects (
+    user_id,
+    name,
+    description,
+    deadline,
+    created_at,
+    updated_at,
+    pomodoro_preset_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
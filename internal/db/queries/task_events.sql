@@ -0,0 +1,35 @@
+-- name: CreateTaskEvent :exec
+INSERT INTO task_events (
+    task_id,
+    user_id,
+    event_type,
+    old_value,
+    new_value
+) VALUES (
+    $1, $2, $3, $4, $5
+);
+
+-- name: ListTaskEvents :many
+SELECT * FROM task_events
+WHERE task_id = $1 AND user_id = $2
+ORDER BY created_at, id;
+
+-- name: CountDueDatePushbacks :one
+SELECT COUNT(*) FROM task_events
+WHERE task_id = $1 AND user_id = $2
+  AND event_type = 'due_date'
+  AND old_value IS NOT NULL AND new_value IS NOT NULL
+  AND new_value::timestamptz > old_value::timestamptz;
+
+-- name: ListPostponedTasks :many
+SELECT e.task_id, t.description, COUNT(*) AS pushbacks
+FROM task_events e
+LEFT JOIN tasks t ON t.id = e.task_id
+WHERE e.user_id = $1
+  AND e.event_type = 'due_date'
+  AND e.old_value IS NOT NULL AND e.new_value IS NOT NULL
+  AND e.new_value::timestamptz > e.old_value::timestamptz
+  AND e.created_at >= $2
+GROUP BY e.task_id, t.description
+ORDER BY pushbacks DESC, e.task_id
+LIMIT $3;
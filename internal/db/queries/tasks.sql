@@ -10,16 +10,30 @@ INSERT INTO tasks (
     recurrence,
     tags,
     notes, 
-    dependent
+    dependent,
+    estimate_minutes,
+    uuid,
+    wait_until,
+    scheduled
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
 ) RETURNING *;
 
 -- name: GetTask :one
 SELECT * FROM tasks
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+LIMIT 1;
+
+-- name: GetTaskIncludingDeleted :one
+SELECT * FROM tasks
 WHERE id = $1 AND user_id = $2
 LIMIT 1;
 
+-- name: GetTaskForUpdate :one
+SELECT * FROM tasks
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+FOR UPDATE;
+
 
 -- name: ListTasks :many
 SELECT 
@@ -37,10 +51,16 @@ SELECT
     notes,
     created_at,
     updated_at,
-    dependent
+    dependent,
+    estimate_minutes,
+    deleted_at,
+    uuid,
+    wait_until,
+    scheduled
 FROM 
     tasks
 WHERE user_id = $1
+AND deleted_at IS NULL
 AND (
     sqlc.narg(priority)::text IS NULL 
     OR priority = sqlc.narg(priority)
@@ -61,6 +81,10 @@ AND (
     NOT sqlc.narg(today_filter)::boolean IS TRUE
     OR DATE(due_date) <= CURRENT_DATE
 )
+AND (
+    sqlc.narg(waiting)::boolean IS NULL
+    OR sqlc.narg(waiting) = (wait_until IS NOT NULL AND wait_until > NOW())
+)
 ORDER BY
     CASE 
         WHEN status = 'completed' THEN 0 
@@ -87,6 +111,7 @@ FROM
     tasks
 WHERE 
     tasks.user_id = sqlc.arg(user_id)
+    AND tasks.deleted_at IS NULL
     AND (
         sqlc.arg(status)::text IS NULL OR status = sqlc.arg(status)
     )
@@ -99,7 +124,7 @@ WHERE
     AND (
         sqlc.arg(project_name)::text IS NULL OR project_id IN (
             SELECT id FROM projects 
-            WHERE name = sqlc.arg(project_name) AND user_id = sqlc.arg(user_id)
+            WHERE name = sqlc.arg(project_name) AND user_id = sqlc.arg(user_id) AND deleted_at IS NULL
         )
     );
 
@@ -151,9 +176,17 @@ WHERE id = $1 AND user_id = $2
 RETURNING *;
 
 -- name: DeleteTask :one
+-- Moves the task to the trash
+UPDATE tasks
+SET
+    deleted_at = NOW()
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: PurgeTask :one
 DELETE FROM tasks
 WHERE id = $1 AND user_id = $2
-RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent;
+RETURNING *;
 
 
 -- name: AddTaskDependency :exec
@@ -171,18 +204,19 @@ WHERE task_id = $1 AND depends_on_id = $2;
 -- name: GetTaskDependencies :many
 SELECT t.* FROM tasks t
 JOIN task_dependencies td ON t.id = td.depends_on_id
-WHERE td.task_id = $1 AND t.user_id = $2
+WHERE td.task_id = $1 AND t.user_id = $2 AND t.deleted_at IS NULL
 ORDER BY t.created_at DESC;
 
 -- name: GetDependentTasks :many
 SELECT t.* FROM tasks t
 JOIN task_dependencies td ON t.id = td.task_id
-WHERE td.depends_on_id = $1 AND t.user_id = $2
+WHERE td.depends_on_id = $1 AND t.user_id = $2 AND t.deleted_at IS NULL
 ORDER BY t.created_at DESC;
 
 -- name: GetTasksWithinDateRange :many
 SELECT * FROM tasks
 WHERE user_id = $1
+AND deleted_at IS NULL
 AND (
     (start_date IS NOT NULL AND start_date >= $2 AND start_date <= $3)
     OR (due_date IS NOT NULL AND due_date >= $2 AND due_date <= $3)
@@ -193,12 +227,14 @@ ORDER BY
 -- name: GetTasksByTag :many
 SELECT * FROM tasks
 WHERE user_id = $1
+AND deleted_at IS NULL
 AND $2 = ANY(tags)
 ORDER BY created_at DESC;
 
 -- name: GetRecentlyCompletedTasks :many
 SELECT * FROM tasks
 WHERE user_id = $1
+AND deleted_at IS NULL
 AND status = 'completed'
 ORDER BY completed_at DESC
 LIMIT $2;
@@ -231,6 +267,26 @@ SET
     tags = $3
 WHERE id = $1 AND user_id = $2;
 
+-- name: AddTaskTags :exec
+UPDATE tasks
+SET
+    tags = COALESCE(tags, '{}') || ARRAY(
+        SELECT DISTINCT tag FROM unnest(sqlc.arg(tags)::text[]) AS tag
+        WHERE NOT tag = ANY(COALESCE(tasks.tags, '{}'))
+    ),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id);
+
+-- name: RemoveTaskTags :exec
+UPDATE tasks
+SET
+    tags = ARRAY(
+        SELECT tag FROM unnest(tags) AS tag
+        WHERE NOT tag = ANY(sqlc.arg(tags)::text[])
+    ),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id);
+
 -- name: SetToday :one
 UPDATE tasks
 SET
@@ -240,7 +296,8 @@ RETURNING *;
 
 -- name: GetToday :many
 SELECT * FROM tasks
-WHERE user_id = $1 AND start_date >= CURRENT_DATE;
+WHERE user_id = $1 AND start_date >= CURRENT_DATE AND deleted_at IS NULL
+AND (wait_until IS NULL OR wait_until <= NOW());
 
 -- name: SetTaskDue :one
 UPDATE tasks
@@ -251,7 +308,23 @@ SET
     ),
     updated_at = NOW()
 WHERE id = $1 AND user_id = $2
-RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent;
+RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled;
+
+-- name: SetTaskWait :one
+UPDATE tasks
+SET
+    wait_until = $3,
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: SetTaskScheduled :one
+UPDATE tasks
+SET
+    scheduled = $3,
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING *;
 
 -- name: ClearRecurrence :one
 UPDATE tasks
@@ -260,3 +333,179 @@ SET
     updated_at = NOW()
 WHERE id = $1 AND user_id = $2
 RETURNING *;
+
+-- name: SetTaskEstimate :one
+UPDATE tasks
+SET
+    estimate_minutes = $3,
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: RestoreTask :one
+INSERT INTO tasks (
+    id,
+    user_id,
+    description,
+    status,
+    priority,
+    due_date,
+    start_date,
+    completed_at,
+    project_id,
+    recurrence,
+    tags,
+    notes,
+    created_at,
+    updated_at,
+    dependent,
+    estimate_minutes,
+    deleted_at,
+    uuid,
+    wait_until,
+    scheduled
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20
+)
+ON CONFLICT (id)
+DO UPDATE SET
+    description = EXCLUDED.description,
+    status = EXCLUDED.status,
+    priority = EXCLUDED.priority,
+    due_date = EXCLUDED.due_date,
+    start_date = EXCLUDED.start_date,
+    completed_at = EXCLUDED.completed_at,
+    project_id = EXCLUDED.project_id,
+    recurrence = EXCLUDED.recurrence,
+    tags = EXCLUDED.tags,
+    notes = EXCLUDED.notes,
+    created_at = EXCLUDED.created_at,
+    updated_at = EXCLUDED.updated_at,
+    dependent = EXCLUDED.dependent,
+    estimate_minutes = EXCLUDED.estimate_minutes,
+    deleted_at = EXCLUDED.deleted_at,
+    uuid = EXCLUDED.uuid,
+    wait_until = EXCLUDED.wait_until,
+    scheduled = EXCLUDED.scheduled
+WHERE tasks.user_id = EXCLUDED.user_id
+RETURNING *;
+
+-- name: ListTrashedTasks :many
+SELECT * FROM tasks
+WHERE user_id = $1 AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id;
+
+-- name: ListTrashedTaskTree :many
+-- The task and its subtasks trashed along with it, parents first
+WITH RECURSIVE tree AS (
+    SELECT t.id, 0 AS depth FROM tasks t
+    WHERE t.id = sqlc.arg(id) AND t.user_id = sqlc.arg(user_id) AND t.deleted_at IS NOT NULL
+    UNION ALL
+    SELECT c.id, tree.depth + 1 FROM tasks c
+    JOIN tree ON c.dependent = tree.id
+    JOIN tasks root ON root.id = sqlc.arg(id)
+    WHERE c.deleted_at = root.deleted_at
+)
+SELECT t.* FROM tasks t
+JOIN tree ON tree.id = t.id
+ORDER BY tree.depth, t.id;
+
+-- name: UntrashTasks :many
+-- Parents and projects that are still in the trash are detached
+UPDATE tasks
+SET
+    deleted_at = NULL,
+    dependent = CASE
+        WHEN dependent = ANY(sqlc.arg(ids)::integer[]) THEN dependent
+        WHEN EXISTS (
+            SELECT 1 FROM tasks parent
+            WHERE parent.id = tasks.dependent AND parent.deleted_at IS NULL
+        ) THEN dependent
+        ELSE NULL
+    END,
+    project_id = CASE
+        WHEN EXISTS (
+            SELECT 1 FROM projects p
+            WHERE p.id = tasks.project_id AND p.deleted_at IS NULL
+        ) THEN project_id
+        ELSE NULL
+    END,
+    updated_at = NOW()
+WHERE id = ANY(sqlc.arg(ids)::integer[]) AND user_id = sqlc.arg(user_id) AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: DetachPurgedSubtasks :exec
+UPDATE tasks
+SET
+    dependent = NULL
+WHERE user_id = $1
+AND dependent IN (
+    SELECT id FROM tasks purged
+    WHERE purged.user_id = $1 AND purged.deleted_at < $2
+)
+AND (deleted_at IS NULL OR deleted_at >= $2);
+
+-- name: PurgeTrashedTasks :execrows
+DELETE FROM tasks
+WHERE user_id = $1 AND deleted_at < $2;
+
+-- name: ImportTask :one
+INSERT INTO tasks (
+    user_id,
+    description,
+    status,
+    priority,
+    due_date,
+    start_date,
+    completed_at,
+    project_id,
+    recurrence,
+    tags,
+    notes,
+    created_at,
+    updated_at,
+    dependent,
+    estimate_minutes,
+    uuid,
+    wait_until,
+    scheduled
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18
+) RETURNING *;
+
+-- name: FindDuplicateTask :one
+SELECT * FROM tasks
+WHERE user_id = $1 AND description = $2 AND created_at = $3 AND deleted_at IS NULL
+LIMIT 1;
+
+-- name: ListUserTaskDependencies :many
+SELECT td.* FROM task_dependencies td
+JOIN tasks t ON t.id = td.task_id
+WHERE t.user_id = $1 AND t.deleted_at IS NULL
+ORDER BY td.id;
+
+-- name: ListTasksWithoutProject :many
+SELECT * FROM tasks
+WHERE user_id = $1 AND project_id IS NULL AND deleted_at IS NULL
+ORDER BY created_at DESC;
+
+-- name: ListSubtasks :many
+-- Waiting subtasks are included, changes to a parent apply to all of them
+SELECT * FROM tasks
+WHERE user_id = $1 AND dependent = $2 AND deleted_at IS NULL
+ORDER BY id;
+
+-- name: GetTaskByUUID :one
+-- Trashed tasks are included since the UUID stays taken until they are purged
+SELECT * FROM tasks
+WHERE user_id = $1 AND uuid = $2
+LIMIT 1;
+
+-- name: ListOverdueTasks :many
+-- Tasks due before a time that aren't completed, oldest first
+SELECT * FROM tasks
+WHERE user_id = $1
+  AND deleted_at IS NULL
+  AND status != 'completed'
+  AND due_date < $2
+ORDER BY due_date;
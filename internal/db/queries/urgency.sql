@@ -0,0 +1,21 @@
+-- name: ListUrgencyCoefficients :many
+SELECT * FROM urgency_coefficients
+WHERE user_id = $1
+ORDER BY name;
+
+-- name: SetUrgencyCoefficient :exec
+INSERT INTO urgency_coefficients (
+    user_id,
+    name,
+    value
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (user_id, name)
+DO UPDATE SET
+    value = $3,
+    updated_at = NOW();
+
+-- name: DeleteUrgencyCoefficient :execrows
+DELETE FROM urgency_coefficients
+WHERE user_id = $1 AND name = $2;
@@ -39,7 +39,7 @@ WHERE id = $1;
 -- name: GetActiveProject :one
 SELECT p.* FROM projects p
 JOIN users u ON p.id = u.active_project_id
-WHERE u.id = $1
+WHERE u.id = $1 AND p.deleted_at IS NULL
 LIMIT 1;
 
 -- name: ClearActiveProject :exec
@@ -10,18 +10,30 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type CaldavObject struct {
+	UserID int32  `json:"user_id"`
+	Uid    string `json:"uid"`
+	Name   string `json:"name"`
+}
+
 type CalendarEvent struct {
-	ID          int32              `json:"id"`
-	UserID      pgtype.Int4        `json:"user_id"`
-	Title       string             `json:"title"`
-	Description pgtype.Text        `json:"description"`
-	StartTime   pgtype.Timestamptz `json:"start_time"`
-	EndTime     pgtype.Timestamptz `json:"end_time"`
-	AllDay      pgtype.Bool        `json:"all_day"`
-	Location    pgtype.Text        `json:"location"`
-	ProjectID   pgtype.Int4        `json:"project_id"`
-	CreatedAt   pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	ID           int32              `json:"id"`
+	UserID       pgtype.Int4        `json:"user_id"`
+	Title        string             `json:"title"`
+	Description  pgtype.Text        `json:"description"`
+	StartTime    pgtype.Timestamptz `json:"start_time"`
+	EndTime      pgtype.Timestamptz `json:"end_time"`
+	AllDay       pgtype.Bool        `json:"all_day"`
+	Location     pgtype.Text        `json:"location"`
+	ProjectID    pgtype.Int4        `json:"project_id"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	Uid          string             `json:"uid"`
+	Rrule        pgtype.Text        `json:"rrule"`
+	Exdates      []time.Time        `json:"exdates"`
+	RecurrenceID pgtype.Timestamptz `json:"recurrence_id"`
+	Timezone     pgtype.Text        `json:"timezone"`
+	Sequence     int32              `json:"sequence"`
 }
 
 type Habit struct {
@@ -41,6 +53,24 @@ type HabitCompletion struct {
 	CreatedAt     pgtype.Timestamptz `json:"created_at"`
 }
 
+type JournalChange struct {
+	ID          int32     `json:"id"`
+	OperationID int32     `json:"operation_id"`
+	EntityType  string    `json:"entity_type"`
+	EntityID    int32     `json:"entity_id"`
+	BeforeState []byte    `json:"before_state"`
+	AfterState  []byte    `json:"after_state"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type JournalOperation struct {
+	ID          int32              `json:"id"`
+	UserID      int32              `json:"user_id"`
+	Description string             `json:"description"`
+	UndoneAt    pgtype.Timestamptz `json:"undone_at"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
 type KanbanBoard struct {
 	ID        int32              `json:"id"`
 	ProjectID pgtype.Int4        `json:"project_id"`
@@ -79,6 +109,15 @@ type Note struct {
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
+type PlannerConfig struct {
+	UserID    int32     `json:"user_id"`
+	WorkStart int32     `json:"work_start"`
+	WorkEnd   int32     `json:"work_end"`
+	WorkDays  []int32   `json:"work_days"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 type PomodoroConfig struct {
 	UserID             int32     `json:"user_id"`
 	WorkDuration       int32     `json:"work_duration"`
@@ -89,6 +128,15 @@ type PomodoroConfig struct {
 	AutoStartPomodoros bool      `json:"auto_start_pomodoros"`
 	CreatedAt          time.Time `json:"created_at"`
 	UpdatedAt          time.Time `json:"updated_at"`
+	LinkTasks          bool      `json:"link_tasks"`
+}
+
+type PomodoroGoal struct {
+	UserID     int32     `json:"user_id"`
+	DailyGoal  int32     `json:"daily_goal"`
+	WeeklyGoal int32     `json:"weekly_goal"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
 }
 
 type PomodoroPause struct {
@@ -99,6 +147,18 @@ type PomodoroPause struct {
 	CreatedAt  pgtype.Timestamptz `json:"created_at"`
 }
 
+type PomodoroPreset struct {
+	ID                int32     `json:"id"`
+	UserID            int32     `json:"user_id"`
+	Name              string    `json:"name"`
+	WorkDuration      int32     `json:"work_duration"`
+	BreakDuration     int32     `json:"break_duration"`
+	LongBreakDuration int32     `json:"long_break_duration"`
+	LongBreakInterval int32     `json:"long_break_interval"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
 type PomodoroSession struct {
 	ID                 int32              `json:"id"`
 	UserID             pgtype.Int4        `json:"user_id"`
@@ -118,13 +178,15 @@ type PomodoroSession struct {
 }
 
 type Project struct {
-	ID          int32              `json:"id"`
-	UserID      pgtype.Int4        `json:"user_id"`
-	Name        string             `json:"name"`
-	Description pgtype.Text        `json:"description"`
-	Deadline    pgtype.Timestamptz `json:"deadline"`
-	CreatedAt   pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	ID               int32              `json:"id"`
+	UserID           pgtype.Int4        `json:"user_id"`
+	Name             string             `json:"name"`
+	Description      pgtype.Text        `json:"description"`
+	Deadline         pgtype.Timestamptz `json:"deadline"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+	PomodoroPresetID pgtype.Int4        `json:"pomodoro_preset_id"`
+	DeletedAt        pgtype.Timestamptz `json:"deleted_at"`
 }
 
 type ProjectMilestone struct {
@@ -139,21 +201,26 @@ type ProjectMilestone struct {
 }
 
 type Task struct {
-	ID          int32              `json:"id"`
-	UserID      pgtype.Int4        `json:"user_id"`
-	Description string             `json:"description"`
-	Status      string             `json:"status"`
-	Priority    pgtype.Text        `json:"priority"`
-	DueDate     pgtype.Timestamptz `json:"due_date"`
-	StartDate   pgtype.Timestamptz `json:"start_date"`
-	CompletedAt pgtype.Timestamptz `json:"completed_at"`
-	ProjectID   pgtype.Int4        `json:"project_id"`
-	Recurrence  pgtype.Text        `json:"recurrence"`
-	Tags        []string           `json:"tags"`
-	Notes       pgtype.Text        `json:"notes"`
-	CreatedAt   pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
-	Dependent   pgtype.Int4        `json:"dependent"`
+	ID              int32              `json:"id"`
+	UserID          pgtype.Int4        `json:"user_id"`
+	Description     string             `json:"description"`
+	Status          string             `json:"status"`
+	Priority        pgtype.Text        `json:"priority"`
+	DueDate         pgtype.Timestamptz `json:"due_date"`
+	StartDate       pgtype.Timestamptz `json:"start_date"`
+	CompletedAt     pgtype.Timestamptz `json:"completed_at"`
+	ProjectID       pgtype.Int4        `json:"project_id"`
+	Recurrence      pgtype.Text        `json:"recurrence"`
+	Tags            []string           `json:"tags"`
+	Notes           pgtype.Text        `json:"notes"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	Dependent       pgtype.Int4        `json:"dependent"`
+	EstimateMinutes pgtype.Int4        `json:"estimate_minutes"`
+	DeletedAt       pgtype.Timestamptz `json:"deleted_at"`
+	Uuid            pgtype.UUID        `json:"uuid"`
+	WaitUntil       pgtype.Timestamptz `json:"wait_until"`
+	Scheduled       pgtype.Timestamptz `json:"scheduled"`
 }
 
 type TaskCalendar struct {
@@ -170,6 +237,16 @@ type TaskDependency struct {
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
 }
 
+type TaskEvent struct {
+	ID        int32       `json:"id"`
+	TaskID    int32       `json:"task_id"`
+	UserID    int32       `json:"user_id"`
+	EventType string      `json:"event_type"`
+	OldValue  pgtype.Text `json:"old_value"`
+	NewValue  pgtype.Text `json:"new_value"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
 type TaskNote struct {
 	ID        int32              `json:"id"`
 	TaskID    pgtype.Int4        `json:"task_id"`
@@ -177,6 +254,18 @@ type TaskNote struct {
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
+type TrashedProjectTask struct {
+	ProjectID int32 `json:"project_id"`
+	TaskID    int32 `json:"task_id"`
+}
+
+type UrgencyCoefficient struct {
+	UserID    int32     `json:"user_id"`
+	Name      string    `json:"name"`
+	Value     float64   `json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 type User struct {
 	ID              int32              `json:"id"`
 	Email           string             `json:"email"`
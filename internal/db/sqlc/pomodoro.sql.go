@@ -48,6 +48,82 @@ func (q *Queries) AttachTaskToPomodoro(ctx context.Context, arg AttachTaskToPomo
 	return i, err
 }
 
+const countCompletedPomodorosByTask = `-- name: CountCompletedPomodorosByTask :many
+SELECT task_id, COUNT(*) AS completed_count
+FROM pomodoro_sessions
+WHERE user_id = $1 AND status = 'completed' AND task_id IS NOT NULL
+GROUP BY task_id
+`
+
+type CountCompletedPomodorosByTaskRow struct {
+	TaskID         pgtype.Int4 `json:"task_id"`
+	CompletedCount int64       `json:"completed_count"`
+}
+
+func (q *Queries) CountCompletedPomodorosByTask(ctx context.Context, userID pgtype.Int4) ([]CountCompletedPomodorosByTaskRow, error) {
+	rows, err := q.db.Query(ctx, countCompletedPomodorosByTask, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CountCompletedPomodorosByTaskRow{}
+	for rows.Next() {
+		var i CountCompletedPomodorosByTaskRow
+		if err := rows.Scan(&i.TaskID, &i.CompletedCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countOverlappingPomodoroSessions = `-- name: CountOverlappingPomodoroSessions :one
+SELECT COUNT(*) FROM pomodoro_sessions
+WHERE user_id = $1
+  AND id <> $2
+  AND start_time < $3::timestamptz
+  AND COALESCE(end_time, NOW()) > $4::timestamptz
+`
+
+type CountOverlappingPomodoroSessionsParams struct {
+	UserID     pgtype.Int4        `json:"user_id"`
+	ExcludeID  int32              `json:"exclude_id"`
+	RangeEnd   pgtype.Timestamptz `json:"range_end"`
+	RangeStart pgtype.Timestamptz `json:"range_start"`
+}
+
+func (q *Queries) CountOverlappingPomodoroSessions(ctx context.Context, arg CountOverlappingPomodoroSessionsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countOverlappingPomodoroSessions,
+		arg.UserID,
+		arg.ExcludeID,
+		arg.RangeEnd,
+		arg.RangeStart,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countPomodoroSessionsStartingAt = `-- name: CountPomodoroSessionsStartingAt :one
+SELECT COUNT(*) FROM pomodoro_sessions
+WHERE user_id = $1 AND start_time = $2
+`
+
+type CountPomodoroSessionsStartingAtParams struct {
+	UserID    pgtype.Int4        `json:"user_id"`
+	StartTime pgtype.Timestamptz `json:"start_time"`
+}
+
+func (q *Queries) CountPomodoroSessionsStartingAt(ctx context.Context, arg CountPomodoroSessionsStartingAtParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countPomodoroSessionsStartingAt, arg.UserID, arg.StartTime)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createPomodoroSession = `-- name: CreatePomodoroSession :one
 INSERT INTO pomodoro_sessions (
     user_id,
@@ -105,6 +181,21 @@ func (q *Queries) CreatePomodoroSession(ctx context.Context, arg CreatePomodoroS
 	return i, err
 }
 
+const deletePomodoroSession = `-- name: DeletePomodoroSession :exec
+DELETE FROM pomodoro_sessions
+WHERE id = $1 AND user_id = $2
+`
+
+type DeletePomodoroSessionParams struct {
+	ID     int32       `json:"id"`
+	UserID pgtype.Int4 `json:"user_id"`
+}
+
+func (q *Queries) DeletePomodoroSession(ctx context.Context, arg DeletePomodoroSessionParams) error {
+	_, err := q.db.Exec(ctx, deletePomodoroSession, arg.ID, arg.UserID)
+	return err
+}
+
 const detachTaskFromPomodoro = `-- name: DetachTaskFromPomodoro :one
 UPDATE pomodoro_sessions
 SET
@@ -172,7 +263,7 @@ func (q *Queries) GetActivePomodoroSession(ctx context.Context, userID pgtype.In
 }
 
 const getPomodoroConfig = `-- name: GetPomodoroConfig :one
-SELECT user_id, work_duration, break_duration, long_break_duration, long_break_interval, auto_start_breaks, auto_start_pomodoros, created_at, updated_at FROM pomodoro_config
+SELECT user_id, work_duration, break_duration, long_break_duration, long_break_interval, auto_start_breaks, auto_start_pomodoros, created_at, updated_at, link_tasks FROM pomodoro_config
 WHERE user_id = $1
 LIMIT 1
 `
@@ -190,6 +281,26 @@ func (q *Queries) GetPomodoroConfig(ctx context.Context, userID int32) (Pomodoro
 		&i.AutoStartPomodoros,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.LinkTasks,
+	)
+	return i, err
+}
+
+const getPomodoroGoal = `-- name: GetPomodoroGoal :one
+SELECT user_id, daily_goal, weekly_goal, created_at, updated_at FROM pomodoro_goals
+WHERE user_id = $1
+LIMIT 1
+`
+
+func (q *Queries) GetPomodoroGoal(ctx context.Context, userID int32) (PomodoroGoal, error) {
+	row := q.db.QueryRow(ctx, getPomodoroGoal, userID)
+	var i PomodoroGoal
+	err := row.Scan(
+		&i.UserID,
+		&i.DailyGoal,
+		&i.WeeklyGoal,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -307,6 +418,208 @@ func (q *Queries) GetPomodoroStats(ctx context.Context, arg GetPomodoroStatsPara
 	return i, err
 }
 
+const importPomodoroSession = `-- name: ImportPomodoroSession :one
+INSERT INTO pomodoro_sessions (
+    user_id,
+    task_id,
+    status,
+    start_time,
+    end_time,
+    duration,
+    completed,
+    work_duration,
+    break_duration,
+    pause_time,
+    total_pause_duration,
+    actual_work_duration,
+    note,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+) RETURNING id, user_id, task_id, start_time, end_time, duration, completed, created_at, status, work_duration, break_duration, pause_time, total_pause_duration, actual_work_duration, note
+`
+
+type ImportPomodoroSessionParams struct {
+	UserID             pgtype.Int4        `json:"user_id"`
+	TaskID             pgtype.Int4        `json:"task_id"`
+	Status             string             `json:"status"`
+	StartTime          pgtype.Timestamptz `json:"start_time"`
+	EndTime            pgtype.Timestamptz `json:"end_time"`
+	Duration           int32              `json:"duration"`
+	Completed          pgtype.Bool        `json:"completed"`
+	WorkDuration       int32              `json:"work_duration"`
+	BreakDuration      int32              `json:"break_duration"`
+	PauseTime          pgtype.Timestamptz `json:"pause_time"`
+	TotalPauseDuration pgtype.Int4        `json:"total_pause_duration"`
+	ActualWorkDuration pgtype.Int4        `json:"actual_work_duration"`
+	Note               pgtype.Text        `json:"note"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) ImportPomodoroSession(ctx context.Context, arg ImportPomodoroSessionParams) (PomodoroSession, error) {
+	row := q.db.QueryRow(ctx, importPomodoroSession,
+		arg.UserID,
+		arg.TaskID,
+		arg.Status,
+		arg.StartTime,
+		arg.EndTime,
+		arg.Duration,
+		arg.Completed,
+		arg.WorkDuration,
+		arg.BreakDuration,
+		arg.PauseTime,
+		arg.TotalPauseDuration,
+		arg.ActualWorkDuration,
+		arg.Note,
+		arg.CreatedAt,
+	)
+	var i PomodoroSession
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TaskID,
+		&i.StartTime,
+		&i.EndTime,
+		&i.Duration,
+		&i.Completed,
+		&i.CreatedAt,
+		&i.Status,
+		&i.WorkDuration,
+		&i.BreakDuration,
+		&i.PauseTime,
+		&i.TotalPauseDuration,
+		&i.ActualWorkDuration,
+		&i.Note,
+	)
+	return i, err
+}
+
+const listAllPomodoroSessions = `-- name: ListAllPomodoroSessions :many
+SELECT id, user_id, task_id, start_time, end_time, duration, completed, created_at, status, work_duration, break_duration, pause_time, total_pause_duration, actual_work_duration, note FROM pomodoro_sessions
+WHERE user_id = $1
+ORDER BY start_time
+`
+
+func (q *Queries) ListAllPomodoroSessions(ctx context.Context, userID pgtype.Int4) ([]PomodoroSession, error) {
+	rows, err := q.db.Query(ctx, listAllPomodoroSessions, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []PomodoroSession{}
+	for rows.Next() {
+		var i PomodoroSession
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.TaskID,
+			&i.StartTime,
+			&i.EndTime,
+			&i.Duration,
+			&i.Completed,
+			&i.CreatedAt,
+			&i.Status,
+			&i.WorkDuration,
+			&i.BreakDuration,
+			&i.PauseTime,
+			&i.TotalPauseDuration,
+			&i.ActualWorkDuration,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCompletedPomodoroStartTimes = `-- name: ListCompletedPomodoroStartTimes :many
+SELECT start_time FROM pomodoro_sessions
+WHERE user_id = $1
+  AND status = 'completed'
+  AND ($2::timestamptz IS NULL OR start_time >= $2)
+ORDER BY start_time
+`
+
+type ListCompletedPomodoroStartTimesParams struct {
+	UserID pgtype.Int4        `json:"user_id"`
+	Since  pgtype.Timestamptz `json:"since"`
+}
+
+func (q *Queries) ListCompletedPomodoroStartTimes(ctx context.Context, arg ListCompletedPomodoroStartTimesParams) ([]pgtype.Timestamptz, error) {
+	rows, err := q.db.Query(ctx, listCompletedPomodoroStartTimes, arg.UserID, arg.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.Timestamptz{}
+	for rows.Next() {
+		var start_time pgtype.Timestamptz
+		if err := rows.Scan(&start_time); err != nil {
+			return nil, err
+		}
+		items = append(items, start_time)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEstimateAccuracy = `-- name: ListEstimateAccuracy :many
+SELECT
+    t.id,
+    t.priority,
+    t.estimate_minutes,
+    p.name AS project_name,
+    COUNT(ps.id) AS actual_pomodoros
+FROM tasks t
+LEFT JOIN projects p ON p.id = t.project_id
+LEFT JOIN pomodoro_sessions ps ON ps.task_id = t.id AND ps.status = 'completed'
+WHERE t.user_id = $1
+  AND t.deleted_at IS NULL
+  AND t.status = 'completed'
+  AND t.estimate_minutes IS NOT NULL
+GROUP BY t.id, p.name
+`
+
+type ListEstimateAccuracyRow struct {
+	ID              int32       `json:"id"`
+	Priority        pgtype.Text `json:"priority"`
+	EstimateMinutes pgtype.Int4 `json:"estimate_minutes"`
+	ProjectName     pgtype.Text `json:"project_name"`
+	ActualPomodoros int64       `json:"actual_pomodoros"`
+}
+
+func (q *Queries) ListEstimateAccuracy(ctx context.Context, userID pgtype.Int4) ([]ListEstimateAccuracyRow, error) {
+	rows, err := q.db.Query(ctx, listEstimateAccuracy, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListEstimateAccuracyRow{}
+	for rows.Next() {
+		var i ListEstimateAccuracyRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Priority,
+			&i.EstimateMinutes,
+			&i.ProjectName,
+			&i.ActualPomodoros,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listPomodoroSessions = `-- name: ListPomodoroSessions :many
 SELECT id, user_id, task_id, start_time, end_time, duration, completed, created_at, status, work_duration, break_duration, pause_time, total_pause_duration, actual_work_duration, note FROM pomodoro_sessions
 WHERE user_id = $1
@@ -367,6 +680,167 @@ func (q *Queries) ListPomodoroSessions(ctx context.Context, arg ListPomodoroSess
 	return items, nil
 }
 
+const listPomodoroSessionsWithTask = `-- name: ListPomodoroSessionsWithTask :many
+SELECT
+    ps.id,
+    ps.task_id,
+    ps.status,
+    ps.start_time,
+    ps.end_time,
+    ps.work_duration,
+    ps.break_duration,
+    ps.total_pause_duration,
+    ps.actual_work_duration,
+    ps.note,
+    t.description AS task_description,
+    t.tags AS task_tags,
+    p.name AS project_name
+FROM pomodoro_sessions ps
+LEFT JOIN tasks t ON t.id = ps.task_id
+LEFT JOIN projects p ON p.id = t.project_id
+WHERE ps.user_id = $1
+  AND ($2::integer IS NULL OR ps.task_id = $2)
+  AND ($3::timestamptz IS NULL OR ps.start_time >= $3)
+  AND ($4::timestamptz IS NULL OR ps.start_time <= $4)
+ORDER BY ps.start_time
+`
+
+type ListPomodoroSessionsWithTaskParams struct {
+	UserID    pgtype.Int4        `json:"user_id"`
+	TaskID    pgtype.Int4        `json:"task_id"`
+	StartDate pgtype.Timestamptz `json:"start_date"`
+	EndDate   pgtype.Timestamptz `json:"end_date"`
+}
+
+type ListPomodoroSessionsWithTaskRow struct {
+	ID                 int32              `json:"id"`
+	TaskID             pgtype.Int4        `json:"task_id"`
+	Status             string             `json:"status"`
+	StartTime          pgtype.Timestamptz `json:"start_time"`
+	EndTime            pgtype.Timestamptz `json:"end_time"`
+	WorkDuration       int32              `json:"work_duration"`
+	BreakDuration      int32              `json:"break_duration"`
+	TotalPauseDuration pgtype.Int4        `json:"total_pause_duration"`
+	ActualWorkDuration pgtype.Int4        `json:"actual_work_duration"`
+	Note               pgtype.Text        `json:"note"`
+	TaskDescription    pgtype.Text        `json:"task_description"`
+	TaskTags           []string           `json:"task_tags"`
+	ProjectName        pgtype.Text        `json:"project_name"`
+}
+
+func (q *Queries) ListPomodoroSessionsWithTask(ctx context.Context, arg ListPomodoroSessionsWithTaskParams) ([]ListPomodoroSessionsWithTaskRow, error) {
+	rows, err := q.db.Query(ctx, listPomodoroSessionsWithTask,
+		arg.UserID,
+		arg.TaskID,
+		arg.StartDate,
+		arg.EndDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListPomodoroSessionsWithTaskRow{}
+	for rows.Next() {
+		var i ListPomodoroSessionsWithTaskRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TaskID,
+			&i.Status,
+			&i.StartTime,
+			&i.EndTime,
+			&i.WorkDuration,
+			&i.BreakDuration,
+			&i.TotalPauseDuration,
+			&i.ActualWorkDuration,
+			&i.Note,
+			&i.TaskDescription,
+			&i.TaskTags,
+			&i.ProjectName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockPomodoroSessions = `-- name: LockPomodoroSessions :exec
+SELECT pg_advisory_xact_lock(hashtext('pomodoro_sessions'), $1::int)
+`
+
+// Holds a lock on the sessions of a user until the transaction ends, so
+// concurrent writers check for overlaps one after the other
+func (q *Queries) LockPomodoroSessions(ctx context.Context, userID int32) error {
+	_, err := q.db.Exec(ctx, lockPomodoroSessions, userID)
+	return err
+}
+
+const logPomodoroSession = `-- name: LogPomodoroSession :one
+INSERT INTO pomodoro_sessions (
+    user_id,
+    task_id,
+    status,
+    work_duration,
+    break_duration,
+    start_time,
+    end_time,
+    actual_work_duration,
+    note,
+    duration
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9,
+    $4 /* Use work_duration for duration */
+) RETURNING id, user_id, task_id, start_time, end_time, duration, completed, created_at, status, work_duration, break_duration, pause_time, total_pause_duration, actual_work_duration, note
+`
+
+type LogPomodoroSessionParams struct {
+	UserID             pgtype.Int4        `json:"user_id"`
+	TaskID             pgtype.Int4        `json:"task_id"`
+	Status             string             `json:"status"`
+	WorkDuration       int32              `json:"work_duration"`
+	BreakDuration      int32              `json:"break_duration"`
+	StartTime          pgtype.Timestamptz `json:"start_time"`
+	EndTime            pgtype.Timestamptz `json:"end_time"`
+	ActualWorkDuration pgtype.Int4        `json:"actual_work_duration"`
+	Note               pgtype.Text        `json:"note"`
+}
+
+func (q *Queries) LogPomodoroSession(ctx context.Context, arg LogPomodoroSessionParams) (PomodoroSession, error) {
+	row := q.db.QueryRow(ctx, logPomodoroSession,
+		arg.UserID,
+		arg.TaskID,
+		arg.Status,
+		arg.WorkDuration,
+		arg.BreakDuration,
+		arg.StartTime,
+		arg.EndTime,
+		arg.ActualWorkDuration,
+		arg.Note,
+	)
+	var i PomodoroSession
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TaskID,
+		&i.StartTime,
+		&i.EndTime,
+		&i.Duration,
+		&i.Completed,
+		&i.CreatedAt,
+		&i.Status,
+		&i.WorkDuration,
+		&i.BreakDuration,
+		&i.PauseTime,
+		&i.TotalPauseDuration,
+		&i.ActualWorkDuration,
+		&i.Note,
+	)
+	return i, err
+}
+
 const pausePomodoroSession = `-- name: PausePomodoroSession :one
 UPDATE pomodoro_sessions
 SET
@@ -506,6 +980,66 @@ func (q *Queries) StopPomodoroSession(ctx context.Context, arg StopPomodoroSessi
 	return i, err
 }
 
+const updatePomodoroSession = `-- name: UpdatePomodoroSession :one
+UPDATE pomodoro_sessions
+SET
+    task_id = $3,
+    status = $4,
+    start_time = $5,
+    end_time = $6,
+    work_duration = $7,
+    duration = $7,
+    actual_work_duration = $8,
+    note = $9
+WHERE id = $1 AND user_id = $2
+RETURNING id, user_id, task_id, start_time, end_time, duration, completed, created_at, status, work_duration, break_duration, pause_time, total_pause_duration, actual_work_duration, note
+`
+
+type UpdatePomodoroSessionParams struct {
+	ID                 int32              `json:"id"`
+	UserID             pgtype.Int4        `json:"user_id"`
+	TaskID             pgtype.Int4        `json:"task_id"`
+	Status             string             `json:"status"`
+	StartTime          pgtype.Timestamptz `json:"start_time"`
+	EndTime            pgtype.Timestamptz `json:"end_time"`
+	WorkDuration       int32              `json:"work_duration"`
+	ActualWorkDuration pgtype.Int4        `json:"actual_work_duration"`
+	Note               pgtype.Text        `json:"note"`
+}
+
+func (q *Queries) UpdatePomodoroSession(ctx context.Context, arg UpdatePomodoroSessionParams) (PomodoroSession, error) {
+	row := q.db.QueryRow(ctx, updatePomodoroSession,
+		arg.ID,
+		arg.UserID,
+		arg.TaskID,
+		arg.Status,
+		arg.StartTime,
+		arg.EndTime,
+		arg.WorkDuration,
+		arg.ActualWorkDuration,
+		arg.Note,
+	)
+	var i PomodoroSession
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TaskID,
+		&i.StartTime,
+		&i.EndTime,
+		&i.Duration,
+		&i.Completed,
+		&i.CreatedAt,
+		&i.Status,
+		&i.WorkDuration,
+		&i.BreakDuration,
+		&i.PauseTime,
+		&i.TotalPauseDuration,
+		&i.ActualWorkDuration,
+		&i.Note,
+	)
+	return i, err
+}
+
 const upsertPomodoroConfig = `-- name: UpsertPomodoroConfig :one
 INSERT INTO pomodoro_config (
     user_id,
@@ -514,9 +1048,10 @@ INSERT INTO pomodoro_config (
     long_break_duration,
     long_break_interval,
     auto_start_breaks,
-    auto_start_pomodoros
+    auto_start_pomodoros,
+    link_tasks
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
+    $1, $2, $3, $4, $5, $6, $7, $8
 )
 ON CONFLICT (user_id)
 DO UPDATE SET
@@ -526,8 +1061,9 @@ DO UPDATE SET
     long_break_interval = $5,
     auto_start_breaks = $6,
     auto_start_pomodoros = $7,
+    link_tasks = $8,
     updated_at = NOW()
-RETURNING user_id, work_duration, break_duration, long_break_duration, long_break_interval, auto_start_breaks, auto_start_pomodoros, created_at, updated_at
+RETURNING user_id, work_duration, break_duration, long_break_duration, long_break_interval, auto_start_breaks, auto_start_pomodoros, created_at, updated_at, link_tasks
 `
 
 type UpsertPomodoroConfigParams struct {
@@ -538,6 +1074,7 @@ type UpsertPomodoroConfigParams struct {
 	LongBreakInterval  int32 `json:"long_break_interval"`
 	AutoStartBreaks    bool  `json:"auto_start_breaks"`
 	AutoStartPomodoros bool  `json:"auto_start_pomodoros"`
+	LinkTasks          bool  `json:"link_tasks"`
 }
 
 func (q *Queries) UpsertPomodoroConfig(ctx context.Context, arg UpsertPomodoroConfigParams) (PomodoroConfig, error) {
@@ -549,6 +1086,7 @@ func (q *Queries) UpsertPomodoroConfig(ctx context.Context, arg UpsertPomodoroCo
 		arg.LongBreakInterval,
 		arg.AutoStartBreaks,
 		arg.AutoStartPomodoros,
+		arg.LinkTasks,
 	)
 	var i PomodoroConfig
 	err := row.Scan(
@@ -561,6 +1099,42 @@ func (q *Queries) UpsertPomodoroConfig(ctx context.Context, arg UpsertPomodoroCo
 		&i.AutoStartPomodoros,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.LinkTasks,
+	)
+	return i, err
+}
+
+const upsertPomodoroGoal = `-- name: UpsertPomodoroGoal :one
+INSERT INTO pomodoro_goals (
+    user_id,
+    daily_goal,
+    weekly_goal
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (user_id)
+DO UPDATE SET
+    daily_goal = $2,
+    weekly_goal = $3,
+    updated_at = NOW()
+RETURNING user_id, daily_goal, weekly_goal, created_at, updated_at
+`
+
+type UpsertPomodoroGoalParams struct {
+	UserID     int32 `json:"user_id"`
+	DailyGoal  int32 `json:"daily_goal"`
+	WeeklyGoal int32 `json:"weekly_goal"`
+}
+
+func (q *Queries) UpsertPomodoroGoal(ctx context.Context, arg UpsertPomodoroGoalParams) (PomodoroGoal, error) {
+	row := q.db.QueryRow(ctx, upsertPomodoroGoal, arg.UserID, arg.DailyGoal, arg.WeeklyGoal)
+	var i PomodoroGoal
+	err := row.Scan(
+		&i.UserID,
+		&i.DailyGoal,
+		&i.WeeklyGoal,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -19,7 +19,7 @@ INSERT INTO projects (
     deadline
 ) VALUES (
     $1, $2, $3, $4
-) RETURNING id, user_id, name, description, deadline, created_at, updated_at
+) RETURNING id, user_id, name, description, deadline, created_at, updated_at, pomodoro_preset_id, deleted_at
 `
 
 type CreateProjectParams struct {
@@ -45,13 +45,18 @@ func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) (P
 		&i.Deadline,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PomodoroPresetID,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
-const deleteProject = `-- name: DeleteProject :exec
-DELETE FROM projects
-WHERE id = $1 AND user_id = $2
+const deleteProject = `-- name: DeleteProject :one
+UPDATE projects
+SET
+    deleted_at = NOW()
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+RETURNING id, user_id, name, description, deadline, created_at, updated_at, pomodoro_preset_id, deleted_at
 `
 
 type DeleteProjectParams struct {
@@ -59,14 +64,92 @@ type DeleteProjectParams struct {
 	UserID pgtype.Int4 `json:"user_id"`
 }
 
-func (q *Queries) DeleteProject(ctx context.Context, arg DeleteProjectParams) error {
-	_, err := q.db.Exec(ctx, deleteProject, arg.ID, arg.UserID)
+// Moves the project to the trash
+func (q *Queries) DeleteProject(ctx context.Context, arg DeleteProjectParams) (Project, error) {
+	row := q.db.QueryRow(ctx, deleteProject, arg.ID, arg.UserID)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Deadline,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PomodoroPresetID,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const detachProjectTasks = `-- name: DetachProjectTasks :many
+UPDATE tasks
+SET
+    project_id = NULL,
+    updated_at = NOW()
+WHERE project_id = $1 AND user_id = $2
+RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled
+`
+
+type DetachProjectTasksParams struct {
+	ProjectID pgtype.Int4 `json:"project_id"`
+	UserID    pgtype.Int4 `json:"user_id"`
+}
+
+func (q *Queries) DetachProjectTasks(ctx context.Context, arg DetachProjectTasksParams) ([]Task, error) {
+	rows, err := q.db.Query(ctx, detachProjectTasks, arg.ProjectID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Description,
+			&i.Status,
+			&i.Priority,
+			&i.DueDate,
+			&i.StartDate,
+			&i.CompletedAt,
+			&i.ProjectID,
+			&i.Recurrence,
+			&i.Tags,
+			&i.Notes,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Dependent,
+			&i.EstimateMinutes,
+			&i.DeletedAt,
+			&i.Uuid,
+			&i.WaitUntil,
+			&i.Scheduled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const forgetProjectTasks = `-- name: ForgetProjectTasks :exec
+DELETE FROM trashed_project_tasks
+WHERE project_id = $1
+`
+
+func (q *Queries) ForgetProjectTasks(ctx context.Context, projectID int32) error {
+	_, err := q.db.Exec(ctx, forgetProjectTasks, projectID)
 	return err
 }
 
 const getProject = `-- name: GetProject :one
-SELECT id, user_id, name, description, deadline, created_at, updated_at FROM projects
-WHERE id = $1 AND user_id = $2
+SELECT id, user_id, name, description, deadline, created_at, updated_at, pomodoro_preset_id, deleted_at FROM projects
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
 LIMIT 1
 `
 
@@ -86,23 +169,58 @@ func (q *Queries) GetProject(ctx context.Context, arg GetProjectParams) (Project
 		&i.Deadline,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PomodoroPresetID,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getProjectByName = `-- name: GetProjectByName :one
+SELECT id, user_id, name, description, deadline, created_at, updated_at, pomodoro_preset_id, deleted_at FROM projects
+WHERE user_id = $1 AND name = $2 AND deleted_at IS NULL
+LIMIT 1
+`
+
+type GetProjectByNameParams struct {
+	UserID pgtype.Int4 `json:"user_id"`
+	Name   string      `json:"name"`
+}
+
+func (q *Queries) GetProjectByName(ctx context.Context, arg GetProjectByNameParams) (Project, error) {
+	row := q.db.QueryRow(ctx, getProjectByName, arg.UserID, arg.Name)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Deadline,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PomodoroPresetID,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const getProjectTasks = `-- name: GetProjectTasks :many
-SELECT t.id, t.user_id, t.description, t.status, t.priority, t.due_date, t.start_date, t.completed_at, t.project_id, t.recurrence, t.tags, t.notes, t.created_at, t.updated_at, t.dependent FROM tasks t
-WHERE t.project_id = $1 AND t.user_id = $2
+SELECT t.id, t.user_id, t.description, t.status, t.priority, t.due_date, t.start_date, t.completed_at, t.project_id, t.recurrence, t.tags, t.notes, t.created_at, t.updated_at, t.dependent, t.estimate_minutes, t.deleted_at, t.uuid, t.wait_until, t.scheduled FROM tasks t
+WHERE t.project_id = $1 AND t.user_id = $2 AND t.deleted_at IS NULL
+AND (
+    $3::boolean IS NULL
+    OR $3 = (t.wait_until IS NOT NULL AND t.wait_until > NOW())
+)
 ORDER BY t.created_at DESC
 `
 
 type GetProjectTasksParams struct {
 	ProjectID pgtype.Int4 `json:"project_id"`
 	UserID    pgtype.Int4 `json:"user_id"`
+	Waiting   pgtype.Bool `json:"waiting"`
 }
 
 func (q *Queries) GetProjectTasks(ctx context.Context, arg GetProjectTasksParams) ([]Task, error) {
-	rows, err := q.db.Query(ctx, getProjectTasks, arg.ProjectID, arg.UserID)
+	rows, err := q.db.Query(ctx, getProjectTasks, arg.ProjectID, arg.UserID, arg.Waiting)
 	if err != nil {
 		return nil, err
 	}
@@ -126,6 +244,11 @@ func (q *Queries) GetProjectTasks(ctx context.Context, arg GetProjectTasksParams
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Dependent,
+			&i.EstimateMinutes,
+			&i.DeletedAt,
+			&i.Uuid,
+			&i.WaitUntil,
+			&i.Scheduled,
 		); err != nil {
 			return nil, err
 		}
@@ -137,9 +260,86 @@ func (q *Queries) GetProjectTasks(ctx context.Context, arg GetProjectTasksParams
 	return items, nil
 }
 
+const getTrashedProject = `-- name: GetTrashedProject :one
+SELECT id, user_id, name, description, deadline, created_at, updated_at, pomodoro_preset_id, deleted_at FROM projects
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+LIMIT 1
+`
+
+type GetTrashedProjectParams struct {
+	ID     int32       `json:"id"`
+	UserID pgtype.Int4 `json:"user_id"`
+}
+
+func (q *Queries) GetTrashedProject(ctx context.Context, arg GetTrashedProjectParams) (Project, error) {
+	row := q.db.QueryRow(ctx, getTrashedProject, arg.ID, arg.UserID)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Deadline,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PomodoroPresetID,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const importProject = `-- name: ImportProject :one
+INSERT INTO projects (
+    user_id,
+    name,
+    description,
+    deadline,
+    created_at,
+    updated_at,
+    pomodoro_preset_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, user_id, name, description, deadline, created_at, updated_at, pomodoro_preset_id, deleted_at
+`
+
+type ImportProjectParams struct {
+	UserID           pgtype.Int4        `json:"user_id"`
+	Name             string             `json:"name"`
+	Description      pgtype.Text        `json:"description"`
+	Deadline         pgtype.Timestamptz `json:"deadline"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+	PomodoroPresetID pgtype.Int4        `json:"pomodoro_preset_id"`
+}
+
+func (q *Queries) ImportProject(ctx context.Context, arg ImportProjectParams) (Project, error) {
+	row := q.db.QueryRow(ctx, importProject,
+		arg.UserID,
+		arg.Name,
+		arg.Description,
+		arg.Deadline,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.PomodoroPresetID,
+	)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Deadline,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PomodoroPresetID,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
 const listProjects = `-- name: ListProjects :many
-SELECT id, user_id, name, description, deadline, created_at, updated_at FROM projects
-WHERE user_id = $1
+SELECT id, user_id, name, description, deadline, created_at, updated_at, pomodoro_preset_id, deleted_at FROM projects
+WHERE user_id = $1 AND deleted_at IS NULL
 ORDER BY created_at DESC
 `
 
@@ -160,6 +360,8 @@ func (q *Queries) ListProjects(ctx context.Context, userID pgtype.Int4) ([]Proje
 			&i.Deadline,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.PomodoroPresetID,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -171,13 +373,163 @@ func (q *Queries) ListProjects(ctx context.Context, userID pgtype.Int4) ([]Proje
 	return items, nil
 }
 
+const listTrashedProjects = `-- name: ListTrashedProjects :many
+SELECT id, user_id, name, description, deadline, created_at, updated_at, pomodoro_preset_id, deleted_at FROM projects
+WHERE user_id = $1 AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id
+`
+
+func (q *Queries) ListTrashedProjects(ctx context.Context, userID pgtype.Int4) ([]Project, error) {
+	rows, err := q.db.Query(ctx, listTrashedProjects, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Project{}
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Description,
+			&i.Deadline,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.PomodoroPresetID,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeProject = `-- name: PurgeProject :exec
+DELETE FROM projects
+WHERE id = $1 AND user_id = $2
+`
+
+type PurgeProjectParams struct {
+	ID     int32       `json:"id"`
+	UserID pgtype.Int4 `json:"user_id"`
+}
+
+func (q *Queries) PurgeProject(ctx context.Context, arg PurgeProjectParams) error {
+	_, err := q.db.Exec(ctx, purgeProject, arg.ID, arg.UserID)
+	return err
+}
+
+const purgeTrashedProjects = `-- name: PurgeTrashedProjects :execrows
+DELETE FROM projects
+WHERE user_id = $1 AND deleted_at < $2
+`
+
+type PurgeTrashedProjectsParams struct {
+	UserID    pgtype.Int4        `json:"user_id"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+}
+
+func (q *Queries) PurgeTrashedProjects(ctx context.Context, arg PurgeTrashedProjectsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeTrashedProjects, arg.UserID, arg.DeletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const reattachProjectTasks = `-- name: ReattachProjectTasks :many
+UPDATE tasks
+SET
+    project_id = $1,
+    updated_at = NOW()
+WHERE user_id = $2
+AND project_id IS NULL
+AND id IN (
+    SELECT task_id FROM trashed_project_tasks
+    WHERE trashed_project_tasks.project_id = $1
+)
+RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled
+`
+
+type ReattachProjectTasksParams struct {
+	ProjectID pgtype.Int4 `json:"project_id"`
+	UserID    pgtype.Int4 `json:"user_id"`
+}
+
+// Tasks moved to another project in the meantime are left alone
+func (q *Queries) ReattachProjectTasks(ctx context.Context, arg ReattachProjectTasksParams) ([]Task, error) {
+	rows, err := q.db.Query(ctx, reattachProjectTasks, arg.ProjectID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Description,
+			&i.Status,
+			&i.Priority,
+			&i.DueDate,
+			&i.StartDate,
+			&i.CompletedAt,
+			&i.ProjectID,
+			&i.Recurrence,
+			&i.Tags,
+			&i.Notes,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Dependent,
+			&i.EstimateMinutes,
+			&i.DeletedAt,
+			&i.Uuid,
+			&i.WaitUntil,
+			&i.Scheduled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rememberProjectTasks = `-- name: RememberProjectTasks :exec
+INSERT INTO trashed_project_tasks (
+    project_id,
+    task_id
+)
+SELECT project_id, id FROM tasks
+WHERE project_id = $1 AND user_id = $2
+ON CONFLICT DO NOTHING
+`
+
+type RememberProjectTasksParams struct {
+	ProjectID pgtype.Int4 `json:"project_id"`
+	UserID    pgtype.Int4 `json:"user_id"`
+}
+
+func (q *Queries) RememberProjectTasks(ctx context.Context, arg RememberProjectTasksParams) error {
+	_, err := q.db.Exec(ctx, rememberProjectTasks, arg.ProjectID, arg.UserID)
+	return err
+}
+
 const removeTaskFromProject = `-- name: RemoveTaskFromProject :one
 UPDATE tasks
 SET
     project_id = NULL,
     updated_at = NOW()
 WHERE id = $1 AND user_id = $2
-RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent
+RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled
 `
 
 type RemoveTaskFromProjectParams struct {
@@ -204,6 +556,140 @@ func (q *Queries) RemoveTaskFromProject(ctx context.Context, arg RemoveTaskFromP
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Dependent,
+		&i.EstimateMinutes,
+		&i.DeletedAt,
+		&i.Uuid,
+		&i.WaitUntil,
+		&i.Scheduled,
+	)
+	return i, err
+}
+
+const restoreProject = `-- name: RestoreProject :one
+INSERT INTO projects (
+    id,
+    user_id,
+    name,
+    description,
+    deadline,
+    created_at,
+    updated_at,
+    pomodoro_preset_id,
+    deleted_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+ON CONFLICT (id)
+DO UPDATE SET
+    name = EXCLUDED.name,
+    description = EXCLUDED.description,
+    deadline = EXCLUDED.deadline,
+    created_at = EXCLUDED.created_at,
+    updated_at = EXCLUDED.updated_at,
+    pomodoro_preset_id = EXCLUDED.pomodoro_preset_id,
+    deleted_at = EXCLUDED.deleted_at
+WHERE projects.user_id = EXCLUDED.user_id
+RETURNING id, user_id, name, description, deadline, created_at, updated_at, pomodoro_preset_id, deleted_at
+`
+
+type RestoreProjectParams struct {
+	ID               int32              `json:"id"`
+	UserID           pgtype.Int4        `json:"user_id"`
+	Name             string             `json:"name"`
+	Description      pgtype.Text        `json:"description"`
+	Deadline         pgtype.Timestamptz `json:"deadline"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+	PomodoroPresetID pgtype.Int4        `json:"pomodoro_preset_id"`
+	DeletedAt        pgtype.Timestamptz `json:"deleted_at"`
+}
+
+func (q *Queries) RestoreProject(ctx context.Context, arg RestoreProjectParams) (Project, error) {
+	row := q.db.QueryRow(ctx, restoreProject,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.Description,
+		arg.Deadline,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.PomodoroPresetID,
+		arg.DeletedAt,
+	)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Deadline,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PomodoroPresetID,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const setProjectPomodoroPreset = `-- name: SetProjectPomodoroPreset :one
+UPDATE projects
+SET
+    pomodoro_preset_id = $3,
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING id, user_id, name, description, deadline, created_at, updated_at, pomodoro_preset_id, deleted_at
+`
+
+type SetProjectPomodoroPresetParams struct {
+	ID               int32       `json:"id"`
+	UserID           pgtype.Int4 `json:"user_id"`
+	PomodoroPresetID pgtype.Int4 `json:"pomodoro_preset_id"`
+}
+
+func (q *Queries) SetProjectPomodoroPreset(ctx context.Context, arg SetProjectPomodoroPresetParams) (Project, error) {
+	row := q.db.QueryRow(ctx, setProjectPomodoroPreset, arg.ID, arg.UserID, arg.PomodoroPresetID)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Deadline,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PomodoroPresetID,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const untrashProject = `-- name: UntrashProject :one
+UPDATE projects
+SET
+    deleted_at = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+RETURNING id, user_id, name, description, deadline, created_at, updated_at, pomodoro_preset_id, deleted_at
+`
+
+type UntrashProjectParams struct {
+	ID     int32       `json:"id"`
+	UserID pgtype.Int4 `json:"user_id"`
+}
+
+func (q *Queries) UntrashProject(ctx context.Context, arg UntrashProjectParams) (Project, error) {
+	row := q.db.QueryRow(ctx, untrashProject, arg.ID, arg.UserID)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Deadline,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PomodoroPresetID,
+		&i.DeletedAt,
 	)
 	return i, err
 }
@@ -216,7 +702,7 @@ SET
     deadline = COALESCE($5, deadline),
     updated_at = NOW()
 WHERE id = $1 AND user_id = $2
-RETURNING id, user_id, name, description, deadline, created_at, updated_at
+RETURNING id, user_id, name, description, deadline, created_at, updated_at, pomodoro_preset_id, deleted_at
 `
 
 type UpdateProjectParams struct {
@@ -244,6 +730,8 @@ func (q *Queries) UpdateProject(ctx context.Context, arg UpdateProjectParams) (P
 		&i.Deadline,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PomodoroPresetID,
+		&i.DeletedAt,
 	)
 	return i, err
 }
@@ -11,56 +11,168 @@ import (
 )
 
 type Querier interface {
+	AddHabitCompletion(ctx context.Context, arg AddHabitCompletionParams) (int64, error)
 	AddTaskDependency(ctx context.Context, arg AddTaskDependencyParams) error
+	AddTaskTags(ctx context.Context, arg AddTaskTagsParams) error
 	AttachTaskToPomodoro(ctx context.Context, arg AttachTaskToPomodoroParams) (PomodoroSession, error)
 	ClearActiveProject(ctx context.Context, id int32) error
 	ClearRecurrence(ctx context.Context, arg ClearRecurrenceParams) (Task, error)
 	ClearTags(ctx context.Context, arg ClearTagsParams) error
 	CompleteTask(ctx context.Context, arg CompleteTaskParams) (Task, error)
+	CountCompletedPomodorosByTask(ctx context.Context, userID pgtype.Int4) ([]CountCompletedPomodorosByTaskRow, error)
+	CountDueDatePushbacks(ctx context.Context, arg CountDueDatePushbacksParams) (int64, error)
+	CountOverlappingPomodoroSessions(ctx context.Context, arg CountOverlappingPomodoroSessionsParams) (int64, error)
+	CountPomodoroSessionsStartingAt(ctx context.Context, arg CountPomodoroSessionsStartingAtParams) (int64, error)
 	CountTasks(ctx context.Context, arg CountTasksParams) (CountTasksRow, error)
+	CreateCalendarEvent(ctx context.Context, arg CreateCalendarEventParams) (CalendarEvent, error)
+	CreateJournalChange(ctx context.Context, arg CreateJournalChangeParams) error
+	CreateJournalOperation(ctx context.Context, arg CreateJournalOperationParams) (JournalOperation, error)
 	CreatePomodoroSession(ctx context.Context, arg CreatePomodoroSessionParams) (PomodoroSession, error)
 	CreateProject(ctx context.Context, arg CreateProjectParams) (Project, error)
 	CreateTask(ctx context.Context, arg CreateTaskParams) (Task, error)
+	CreateTaskEvent(ctx context.Context, arg CreateTaskEventParams) error
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
-	DeleteProject(ctx context.Context, arg DeleteProjectParams) error
+	DeleteCalendarEvent(ctx context.Context, arg DeleteCalendarEventParams) error
+	// Deletes a series together with its changed occurrences
+	DeleteCalendarEventsByUID(ctx context.Context, arg DeleteCalendarEventsByUIDParams) (int64, error)
+	DeletePomodoroPreset(ctx context.Context, arg DeletePomodoroPresetParams) error
+	DeletePomodoroSession(ctx context.Context, arg DeletePomodoroSessionParams) error
+	// Moves the project to the trash
+	DeleteProject(ctx context.Context, arg DeleteProjectParams) (Project, error)
+	// Moves the task to the trash
 	DeleteTask(ctx context.Context, arg DeleteTaskParams) (Task, error)
+	DeleteUndoneJournalOperations(ctx context.Context, userID int32) error
+	DeleteUrgencyCoefficient(ctx context.Context, arg DeleteUrgencyCoefficientParams) (int64, error)
+	DetachProjectTasks(ctx context.Context, arg DetachProjectTasksParams) ([]Task, error)
+	DetachPurgedSubtasks(ctx context.Context, arg DetachPurgedSubtasksParams) error
 	DetachTaskFromPomodoro(ctx context.Context, arg DetachTaskFromPomodoroParams) (PomodoroSession, error)
+	FindDuplicateNote(ctx context.Context, arg FindDuplicateNoteParams) (Note, error)
+	FindDuplicateTask(ctx context.Context, arg FindDuplicateTaskParams) (Task, error)
+	ForgetProjectTasks(ctx context.Context, projectID int32) error
 	GetActivePomodoroSession(ctx context.Context, userID pgtype.Int4) (PomodoroSession, error)
 	GetActiveProject(ctx context.Context, id int32) (Project, error)
+	GetCalendarEventByUID(ctx context.Context, arg GetCalendarEventByUIDParams) (CalendarEvent, error)
 	GetDependentTasks(ctx context.Context, arg GetDependentTasksParams) ([]Task, error)
+	GetHabitByName(ctx context.Context, arg GetHabitByNameParams) (Habit, error)
+	GetPlannerConfig(ctx context.Context, userID int32) (PlannerConfig, error)
 	GetPomodoroConfig(ctx context.Context, userID int32) (PomodoroConfig, error)
+	GetPomodoroGoal(ctx context.Context, userID int32) (PomodoroGoal, error)
+	GetPomodoroPreset(ctx context.Context, arg GetPomodoroPresetParams) (PomodoroPreset, error)
+	GetPomodoroPresetByName(ctx context.Context, arg GetPomodoroPresetByNameParams) (PomodoroPreset, error)
 	GetPomodoroSession(ctx context.Context, arg GetPomodoroSessionParams) (PomodoroSession, error)
 	GetPomodoroStats(ctx context.Context, arg GetPomodoroStatsParams) (GetPomodoroStatsRow, error)
 	GetProject(ctx context.Context, arg GetProjectParams) (Project, error)
+	GetProjectByName(ctx context.Context, arg GetProjectByNameParams) (Project, error)
 	GetProjectTasks(ctx context.Context, arg GetProjectTasksParams) ([]Task, error)
 	GetRecentlyCompletedTasks(ctx context.Context, arg GetRecentlyCompletedTasksParams) ([]Task, error)
 	GetTags(ctx context.Context, arg GetTagsParams) ([]string, error)
 	GetTask(ctx context.Context, arg GetTaskParams) (Task, error)
+	// Trashed tasks are included since the UUID stays taken until they are purged
+	GetTaskByUUID(ctx context.Context, arg GetTaskByUUIDParams) (Task, error)
 	GetTaskDependencies(ctx context.Context, arg GetTaskDependenciesParams) ([]Task, error)
+	GetTaskForUpdate(ctx context.Context, arg GetTaskForUpdateParams) (Task, error)
+	GetTaskIncludingDeleted(ctx context.Context, arg GetTaskIncludingDeletedParams) (Task, error)
 	GetTasksByTag(ctx context.Context, arg GetTasksByTagParams) ([]Task, error)
 	GetTasksWithinDateRange(ctx context.Context, arg GetTasksWithinDateRangeParams) ([]Task, error)
 	GetToday(ctx context.Context, userID pgtype.Int4) ([]Task, error)
+	GetTrashedProject(ctx context.Context, arg GetTrashedProjectParams) (Project, error)
 	GetUser(ctx context.Context, email string) (User, error)
+	ImportCalendarEvent(ctx context.Context, arg ImportCalendarEventParams) (CalendarEvent, error)
+	ImportHabit(ctx context.Context, arg ImportHabitParams) (Habit, error)
+	ImportNote(ctx context.Context, arg ImportNoteParams) (Note, error)
+	ImportPomodoroSession(ctx context.Context, arg ImportPomodoroSessionParams) (PomodoroSession, error)
+	ImportProject(ctx context.Context, arg ImportProjectParams) (Project, error)
+	ImportTask(ctx context.Context, arg ImportTaskParams) (Task, error)
+	LinkTaskToEvent(ctx context.Context, arg LinkTaskToEventParams) error
+	LinkTaskToNote(ctx context.Context, arg LinkTaskToNoteParams) error
+	ListAllPomodoroSessions(ctx context.Context, userID pgtype.Int4) ([]PomodoroSession, error)
+	ListCaldavObjects(ctx context.Context, userID int32) ([]CaldavObject, error)
+	ListCalendarEvents(ctx context.Context, userID pgtype.Int4) ([]CalendarEvent, error)
+	// The series comes first, followed by its changed occurrences
+	ListCalendarEventsByUID(ctx context.Context, arg ListCalendarEventsByUIDParams) ([]CalendarEvent, error)
+	// Recurring events are listed from their first occurrence on, since later
+	// occurrences may fall in the range, and with all their changed occurrences
+	ListCalendarEventsInRange(ctx context.Context, arg ListCalendarEventsInRangeParams) ([]CalendarEvent, error)
+	ListCompletedPomodoroStartTimes(ctx context.Context, arg ListCompletedPomodoroStartTimesParams) ([]pgtype.Timestamptz, error)
+	ListEstimateAccuracy(ctx context.Context, userID pgtype.Int4) ([]ListEstimateAccuracyRow, error)
+	ListHabitCompletionsInRange(ctx context.Context, arg ListHabitCompletionsInRangeParams) ([]HabitCompletion, error)
+	ListHabits(ctx context.Context, userID pgtype.Int4) ([]Habit, error)
+	ListJournalChanges(ctx context.Context, operationID int32) ([]JournalChange, error)
+	ListNotes(ctx context.Context, userID pgtype.Int4) ([]Note, error)
+	// Tasks due before a time that aren't completed, oldest first
+	ListOverdueTasks(ctx context.Context, arg ListOverdueTasksParams) ([]Task, error)
+	ListPomodoroPresets(ctx context.Context, userID int32) ([]PomodoroPreset, error)
 	ListPomodoroSessions(ctx context.Context, arg ListPomodoroSessionsParams) ([]PomodoroSession, error)
+	ListPomodoroSessionsWithTask(ctx context.Context, arg ListPomodoroSessionsWithTaskParams) ([]ListPomodoroSessionsWithTaskRow, error)
+	ListPostponedTasks(ctx context.Context, arg ListPostponedTasksParams) ([]ListPostponedTasksRow, error)
 	ListProjects(ctx context.Context, userID pgtype.Int4) ([]Project, error)
+	ListRedoableJournalOperations(ctx context.Context, arg ListRedoableJournalOperationsParams) ([]JournalOperation, error)
+	// Waiting subtasks are included, changes to a parent apply to all of them
+	ListSubtasks(ctx context.Context, arg ListSubtasksParams) ([]Task, error)
+	// Events linked to a task, the blocks of time tasks are planned in, that end
+	// after a time
+	ListTaskBlocks(ctx context.Context, arg ListTaskBlocksParams) ([]ListTaskBlocksRow, error)
+	ListTaskEvents(ctx context.Context, arg ListTaskEventsParams) ([]TaskEvent, error)
 	ListTasks(ctx context.Context, arg ListTasksParams) ([]Task, error)
+	ListTasksWithoutProject(ctx context.Context, userID pgtype.Int4) ([]Task, error)
+	ListTrashedProjects(ctx context.Context, userID pgtype.Int4) ([]Project, error)
+	// The task and its subtasks trashed along with it, parents first
+	ListTrashedTaskTree(ctx context.Context, arg ListTrashedTaskTreeParams) ([]Task, error)
+	ListTrashedTasks(ctx context.Context, userID pgtype.Int4) ([]Task, error)
+	ListUndoableJournalOperations(ctx context.Context, arg ListUndoableJournalOperationsParams) ([]JournalOperation, error)
+	ListUrgencyCoefficients(ctx context.Context, userID int32) ([]UrgencyCoefficient, error)
+	ListUserHabitCompletions(ctx context.Context, userID pgtype.Int4) ([]HabitCompletion, error)
+	// Links of tasks that aren't in the trash to their events
+	ListUserTaskCalendarLinks(ctx context.Context, userID pgtype.Int4) ([]TaskCalendar, error)
+	ListUserTaskDependencies(ctx context.Context, userID pgtype.Int4) ([]TaskDependency, error)
+	// Links of tasks that aren't in the trash to their notes
+	ListUserTaskNotes(ctx context.Context, userID pgtype.Int4) ([]TaskNote, error)
+	// Holds a lock on the sessions of a user until the transaction ends, so
+	// concurrent writers check for overlaps one after the other
+	LockPomodoroSessions(ctx context.Context, userID int32) error
+	LogPomodoroSession(ctx context.Context, arg LogPomodoroSessionParams) (PomodoroSession, error)
 	PausePomodoroSession(ctx context.Context, arg PausePomodoroSessionParams) (PomodoroSession, error)
 	PauseTask(ctx context.Context, arg PauseTaskParams) (Task, error)
+	PurgeProject(ctx context.Context, arg PurgeProjectParams) error
+	PurgeTask(ctx context.Context, arg PurgeTaskParams) (Task, error)
+	PurgeTrashedProjects(ctx context.Context, arg PurgeTrashedProjectsParams) (int64, error)
+	PurgeTrashedTasks(ctx context.Context, arg PurgeTrashedTasksParams) (int64, error)
+	// Tasks moved to another project in the meantime are left alone
+	ReattachProjectTasks(ctx context.Context, arg ReattachProjectTasksParams) ([]Task, error)
+	RememberProjectTasks(ctx context.Context, arg RememberProjectTasksParams) error
 	RemoveTaskDependency(ctx context.Context, arg RemoveTaskDependencyParams) error
 	RemoveTaskFromProject(ctx context.Context, arg RemoveTaskFromProjectParams) (Task, error)
+	RemoveTaskTags(ctx context.Context, arg RemoveTaskTagsParams) error
+	RestoreProject(ctx context.Context, arg RestoreProjectParams) (Project, error)
+	RestoreTask(ctx context.Context, arg RestoreTaskParams) (Task, error)
 	ResumePomodoroSession(ctx context.Context, arg ResumePomodoroSessionParams) (PomodoroSession, error)
 	SetActiveProject(ctx context.Context, arg SetActiveProjectParams) error
+	SetCaldavObjectName(ctx context.Context, arg SetCaldavObjectNameParams) error
+	SetJournalOperationUndone(ctx context.Context, arg SetJournalOperationUndoneParams) error
+	SetProjectPomodoroPreset(ctx context.Context, arg SetProjectPomodoroPresetParams) (Project, error)
 	SetTags(ctx context.Context, arg SetTagsParams) error
 	SetTaskDue(ctx context.Context, arg SetTaskDueParams) (Task, error)
+	SetTaskEstimate(ctx context.Context, arg SetTaskEstimateParams) (Task, error)
+	SetTaskScheduled(ctx context.Context, arg SetTaskScheduledParams) (Task, error)
+	SetTaskWait(ctx context.Context, arg SetTaskWaitParams) (Task, error)
 	SetToday(ctx context.Context, arg SetTodayParams) (Task, error)
+	SetUrgencyCoefficient(ctx context.Context, arg SetUrgencyCoefficientParams) error
 	StartTask(ctx context.Context, arg StartTaskParams) (Task, error)
 	StopPomodoroSession(ctx context.Context, arg StopPomodoroSessionParams) (PomodoroSession, error)
+	UntrashProject(ctx context.Context, arg UntrashProjectParams) (Project, error)
+	// Parents and projects that are still in the trash are detached
+	UntrashTasks(ctx context.Context, arg UntrashTasksParams) ([]Task, error)
+	UpdateCalendarEvent(ctx context.Context, arg UpdateCalendarEventParams) (CalendarEvent, error)
+	UpdatePomodoroSession(ctx context.Context, arg UpdatePomodoroSessionParams) (PomodoroSession, error)
 	UpdateProject(ctx context.Context, arg UpdateProjectParams) (Project, error)
 	UpdateTask(ctx context.Context, arg UpdateTaskParams) (Task, error)
 	UpdateTaskStatus(ctx context.Context, arg UpdateTaskStatusParams) (Task, error)
 	UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error)
 	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	UpsertPlannerConfig(ctx context.Context, arg UpsertPlannerConfigParams) (PlannerConfig, error)
 	UpsertPomodoroConfig(ctx context.Context, arg UpsertPomodoroConfigParams) (PomodoroConfig, error)
+	UpsertPomodoroGoal(ctx context.Context, arg UpsertPomodoroGoalParams) (PomodoroGoal, error)
+	UpsertPomodoroPreset(ctx context.Context, arg UpsertPomodoroPresetParams) (PomodoroPreset, error)
 }
 
 var _ Querier = (*Queries)(nil)
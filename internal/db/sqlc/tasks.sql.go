@@ -30,13 +30,35 @@ func (q *Queries) AddTaskDependency(ctx context.Context, arg AddTaskDependencyPa
 	return err
 }
 
+const addTaskTags = `-- name: AddTaskTags :exec
+UPDATE tasks
+SET
+    tags = COALESCE(tags, '{}') || ARRAY(
+        SELECT DISTINCT tag FROM unnest($1::text[]) AS tag
+        WHERE NOT tag = ANY(COALESCE(tasks.tags, '{}'))
+    ),
+    updated_at = NOW()
+WHERE id = $2 AND user_id = $3
+`
+
+type AddTaskTagsParams struct {
+	Tags   []string    `json:"tags"`
+	ID     int32       `json:"id"`
+	UserID pgtype.Int4 `json:"user_id"`
+}
+
+func (q *Queries) AddTaskTags(ctx context.Context, arg AddTaskTagsParams) error {
+	_, err := q.db.Exec(ctx, addTaskTags, arg.Tags, arg.ID, arg.UserID)
+	return err
+}
+
 const clearRecurrence = `-- name: ClearRecurrence :one
 UPDATE tasks
 SET
     recurrence = NULL,
     updated_at = NOW()
 WHERE id = $1 AND user_id = $2
-RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent
+RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled
 `
 
 type ClearRecurrenceParams struct {
@@ -63,6 +85,11 @@ func (q *Queries) ClearRecurrence(ctx context.Context, arg ClearRecurrenceParams
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Dependent,
+		&i.EstimateMinutes,
+		&i.DeletedAt,
+		&i.Uuid,
+		&i.WaitUntil,
+		&i.Scheduled,
 	)
 	return i, err
 }
@@ -91,7 +118,7 @@ SET
     completed_at = NOW(),
     updated_at = NOW()
 WHERE id = $1 AND user_id = $2
-RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent
+RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled
 `
 
 type CompleteTaskParams struct {
@@ -118,6 +145,11 @@ func (q *Queries) CompleteTask(ctx context.Context, arg CompleteTaskParams) (Tas
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Dependent,
+		&i.EstimateMinutes,
+		&i.DeletedAt,
+		&i.Uuid,
+		&i.WaitUntil,
+		&i.Scheduled,
 	)
 	return i, err
 }
@@ -131,6 +163,7 @@ FROM
     tasks
 WHERE 
     tasks.user_id = $1
+    AND tasks.deleted_at IS NULL
     AND (
         $2::text IS NULL OR status = $2
     )
@@ -143,7 +176,7 @@ WHERE
     AND (
         $5::text IS NULL OR project_id IN (
             SELECT id FROM projects 
-            WHERE name = $5 AND user_id = $1
+            WHERE name = $5 AND user_id = $1 AND deleted_at IS NULL
         )
     )
 `
@@ -187,24 +220,32 @@ INSERT INTO tasks (
     recurrence,
     tags,
     notes, 
-    dependent
+    dependent,
+    estimate_minutes,
+    uuid,
+    wait_until,
+    scheduled
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
-) RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+) RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled
 `
 
 type CreateTaskParams struct {
-	UserID      pgtype.Int4        `json:"user_id"`
-	Description string             `json:"description"`
-	Status      string             `json:"status"`
-	Priority    pgtype.Text        `json:"priority"`
-	DueDate     pgtype.Timestamptz `json:"due_date"`
-	StartDate   pgtype.Timestamptz `json:"start_date"`
-	ProjectID   pgtype.Int4        `json:"project_id"`
-	Recurrence  pgtype.Text        `json:"recurrence"`
-	Tags        []string           `json:"tags"`
-	Notes       pgtype.Text        `json:"notes"`
-	Dependent   pgtype.Int4        `json:"dependent"`
+	UserID          pgtype.Int4        `json:"user_id"`
+	Description     string             `json:"description"`
+	Status          string             `json:"status"`
+	Priority        pgtype.Text        `json:"priority"`
+	DueDate         pgtype.Timestamptz `json:"due_date"`
+	StartDate       pgtype.Timestamptz `json:"start_date"`
+	ProjectID       pgtype.Int4        `json:"project_id"`
+	Recurrence      pgtype.Text        `json:"recurrence"`
+	Tags            []string           `json:"tags"`
+	Notes           pgtype.Text        `json:"notes"`
+	Dependent       pgtype.Int4        `json:"dependent"`
+	EstimateMinutes pgtype.Int4        `json:"estimate_minutes"`
+	Uuid            pgtype.UUID        `json:"uuid"`
+	WaitUntil       pgtype.Timestamptz `json:"wait_until"`
+	Scheduled       pgtype.Timestamptz `json:"scheduled"`
 }
 
 func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (Task, error) {
@@ -220,6 +261,10 @@ func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (Task, e
 		arg.Tags,
 		arg.Notes,
 		arg.Dependent,
+		arg.EstimateMinutes,
+		arg.Uuid,
+		arg.WaitUntil,
+		arg.Scheduled,
 	)
 	var i Task
 	err := row.Scan(
@@ -238,14 +283,21 @@ func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (Task, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Dependent,
+		&i.EstimateMinutes,
+		&i.DeletedAt,
+		&i.Uuid,
+		&i.WaitUntil,
+		&i.Scheduled,
 	)
 	return i, err
 }
 
 const deleteTask = `-- name: DeleteTask :one
-DELETE FROM tasks
-WHERE id = $1 AND user_id = $2
-RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent
+UPDATE tasks
+SET
+    deleted_at = NOW()
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled
 `
 
 type DeleteTaskParams struct {
@@ -253,6 +305,7 @@ type DeleteTaskParams struct {
 	UserID pgtype.Int4 `json:"user_id"`
 }
 
+// Moves the task to the trash
 func (q *Queries) DeleteTask(ctx context.Context, arg DeleteTaskParams) (Task, error) {
 	row := q.db.QueryRow(ctx, deleteTask, arg.ID, arg.UserID)
 	var i Task
@@ -272,14 +325,81 @@ func (q *Queries) DeleteTask(ctx context.Context, arg DeleteTaskParams) (Task, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Dependent,
+		&i.EstimateMinutes,
+		&i.DeletedAt,
+		&i.Uuid,
+		&i.WaitUntil,
+		&i.Scheduled,
+	)
+	return i, err
+}
+
+const detachPurgedSubtasks = `-- name: DetachPurgedSubtasks :exec
+UPDATE tasks
+SET
+    dependent = NULL
+WHERE user_id = $1
+AND dependent IN (
+    SELECT id FROM tasks purged
+    WHERE purged.user_id = $1 AND purged.deleted_at < $2
+)
+AND (deleted_at IS NULL OR deleted_at >= $2)
+`
+
+type DetachPurgedSubtasksParams struct {
+	UserID    pgtype.Int4        `json:"user_id"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+}
+
+func (q *Queries) DetachPurgedSubtasks(ctx context.Context, arg DetachPurgedSubtasksParams) error {
+	_, err := q.db.Exec(ctx, detachPurgedSubtasks, arg.UserID, arg.DeletedAt)
+	return err
+}
+
+const findDuplicateTask = `-- name: FindDuplicateTask :one
+SELECT id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled FROM tasks
+WHERE user_id = $1 AND description = $2 AND created_at = $3 AND deleted_at IS NULL
+LIMIT 1
+`
+
+type FindDuplicateTaskParams struct {
+	UserID      pgtype.Int4        `json:"user_id"`
+	Description string             `json:"description"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) FindDuplicateTask(ctx context.Context, arg FindDuplicateTaskParams) (Task, error) {
+	row := q.db.QueryRow(ctx, findDuplicateTask, arg.UserID, arg.Description, arg.CreatedAt)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Description,
+		&i.Status,
+		&i.Priority,
+		&i.DueDate,
+		&i.StartDate,
+		&i.CompletedAt,
+		&i.ProjectID,
+		&i.Recurrence,
+		&i.Tags,
+		&i.Notes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Dependent,
+		&i.EstimateMinutes,
+		&i.DeletedAt,
+		&i.Uuid,
+		&i.WaitUntil,
+		&i.Scheduled,
 	)
 	return i, err
 }
 
 const getDependentTasks = `-- name: GetDependentTasks :many
-SELECT t.id, t.user_id, t.description, t.status, t.priority, t.due_date, t.start_date, t.completed_at, t.project_id, t.recurrence, t.tags, t.notes, t.created_at, t.updated_at, t.dependent FROM tasks t
+SELECT t.id, t.user_id, t.description, t.status, t.priority, t.due_date, t.start_date, t.completed_at, t.project_id, t.recurrence, t.tags, t.notes, t.created_at, t.updated_at, t.dependent, t.estimate_minutes, t.deleted_at, t.uuid, t.wait_until, t.scheduled FROM tasks t
 JOIN task_dependencies td ON t.id = td.task_id
-WHERE td.depends_on_id = $1 AND t.user_id = $2
+WHERE td.depends_on_id = $1 AND t.user_id = $2 AND t.deleted_at IS NULL
 ORDER BY t.created_at DESC
 `
 
@@ -313,6 +433,11 @@ func (q *Queries) GetDependentTasks(ctx context.Context, arg GetDependentTasksPa
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Dependent,
+			&i.EstimateMinutes,
+			&i.DeletedAt,
+			&i.Uuid,
+			&i.WaitUntil,
+			&i.Scheduled,
 		); err != nil {
 			return nil, err
 		}
@@ -325,8 +450,9 @@ func (q *Queries) GetDependentTasks(ctx context.Context, arg GetDependentTasksPa
 }
 
 const getRecentlyCompletedTasks = `-- name: GetRecentlyCompletedTasks :many
-SELECT id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent FROM tasks
+SELECT id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled FROM tasks
 WHERE user_id = $1
+AND deleted_at IS NULL
 AND status = 'completed'
 ORDER BY completed_at DESC
 LIMIT $2
@@ -362,6 +488,11 @@ func (q *Queries) GetRecentlyCompletedTasks(ctx context.Context, arg GetRecently
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Dependent,
+			&i.EstimateMinutes,
+			&i.DeletedAt,
+			&i.Uuid,
+			&i.WaitUntil,
+			&i.Scheduled,
 		); err != nil {
 			return nil, err
 		}
@@ -391,8 +522,8 @@ func (q *Queries) GetTags(ctx context.Context, arg GetTagsParams) ([]string, err
 }
 
 const getTask = `-- name: GetTask :one
-SELECT id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent FROM tasks
-WHERE id = $1 AND user_id = $2
+SELECT id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled FROM tasks
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
 LIMIT 1
 `
 
@@ -420,14 +551,59 @@ func (q *Queries) GetTask(ctx context.Context, arg GetTaskParams) (Task, error)
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Dependent,
+		&i.EstimateMinutes,
+		&i.DeletedAt,
+		&i.Uuid,
+		&i.WaitUntil,
+		&i.Scheduled,
+	)
+	return i, err
+}
+
+const getTaskByUUID = `-- name: GetTaskByUUID :one
+SELECT id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled FROM tasks
+WHERE user_id = $1 AND uuid = $2
+LIMIT 1
+`
+
+type GetTaskByUUIDParams struct {
+	UserID pgtype.Int4 `json:"user_id"`
+	Uuid   pgtype.UUID `json:"uuid"`
+}
+
+// Trashed tasks are included since the UUID stays taken until they are purged
+func (q *Queries) GetTaskByUUID(ctx context.Context, arg GetTaskByUUIDParams) (Task, error) {
+	row := q.db.QueryRow(ctx, getTaskByUUID, arg.UserID, arg.Uuid)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Description,
+		&i.Status,
+		&i.Priority,
+		&i.DueDate,
+		&i.StartDate,
+		&i.CompletedAt,
+		&i.ProjectID,
+		&i.Recurrence,
+		&i.Tags,
+		&i.Notes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Dependent,
+		&i.EstimateMinutes,
+		&i.DeletedAt,
+		&i.Uuid,
+		&i.WaitUntil,
+		&i.Scheduled,
 	)
 	return i, err
 }
 
 const getTaskDependencies = `-- name: GetTaskDependencies :many
-SELECT t.id, t.user_id, t.description, t.status, t.priority, t.due_date, t.start_date, t.completed_at, t.project_id, t.recurrence, t.tags, t.notes, t.created_at, t.updated_at, t.dependent FROM tasks t
+SELECT t.id, t.user_id, t.description, t.status, t.priority, t.due_date, t.start_date, t.completed_at, t.project_id, t.recurrence, t.tags, t.notes, t.created_at, t.updated_at, t.dependent, t.estimate_minutes, t.deleted_at, t.uuid, t.wait_until, t.scheduled FROM tasks t
 JOIN task_dependencies td ON t.id = td.depends_on_id
-WHERE td.task_id = $1 AND t.user_id = $2
+WHERE td.task_id = $1 AND t.user_id = $2 AND t.deleted_at IS NULL
 ORDER BY t.created_at DESC
 `
 
@@ -461,6 +637,11 @@ func (q *Queries) GetTaskDependencies(ctx context.Context, arg GetTaskDependenci
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Dependent,
+			&i.EstimateMinutes,
+			&i.DeletedAt,
+			&i.Uuid,
+			&i.WaitUntil,
+			&i.Scheduled,
 		); err != nil {
 			return nil, err
 		}
@@ -472,9 +653,88 @@ func (q *Queries) GetTaskDependencies(ctx context.Context, arg GetTaskDependenci
 	return items, nil
 }
 
+const getTaskForUpdate = `-- name: GetTaskForUpdate :one
+SELECT id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled FROM tasks
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+FOR UPDATE
+`
+
+type GetTaskForUpdateParams struct {
+	ID     int32       `json:"id"`
+	UserID pgtype.Int4 `json:"user_id"`
+}
+
+func (q *Queries) GetTaskForUpdate(ctx context.Context, arg GetTaskForUpdateParams) (Task, error) {
+	row := q.db.QueryRow(ctx, getTaskForUpdate, arg.ID, arg.UserID)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Description,
+		&i.Status,
+		&i.Priority,
+		&i.DueDate,
+		&i.StartDate,
+		&i.CompletedAt,
+		&i.ProjectID,
+		&i.Recurrence,
+		&i.Tags,
+		&i.Notes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Dependent,
+		&i.EstimateMinutes,
+		&i.DeletedAt,
+		&i.Uuid,
+		&i.WaitUntil,
+		&i.Scheduled,
+	)
+	return i, err
+}
+
+const getTaskIncludingDeleted = `-- name: GetTaskIncludingDeleted :one
+SELECT id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled FROM tasks
+WHERE id = $1 AND user_id = $2
+LIMIT 1
+`
+
+type GetTaskIncludingDeletedParams struct {
+	ID     int32       `json:"id"`
+	UserID pgtype.Int4 `json:"user_id"`
+}
+
+func (q *Queries) GetTaskIncludingDeleted(ctx context.Context, arg GetTaskIncludingDeletedParams) (Task, error) {
+	row := q.db.QueryRow(ctx, getTaskIncludingDeleted, arg.ID, arg.UserID)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Description,
+		&i.Status,
+		&i.Priority,
+		&i.DueDate,
+		&i.StartDate,
+		&i.CompletedAt,
+		&i.ProjectID,
+		&i.Recurrence,
+		&i.Tags,
+		&i.Notes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Dependent,
+		&i.EstimateMinutes,
+		&i.DeletedAt,
+		&i.Uuid,
+		&i.WaitUntil,
+		&i.Scheduled,
+	)
+	return i, err
+}
+
 const getTasksByTag = `-- name: GetTasksByTag :many
-SELECT id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent FROM tasks
+SELECT id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled FROM tasks
 WHERE user_id = $1
+AND deleted_at IS NULL
 AND $2 = ANY(tags)
 ORDER BY created_at DESC
 `
@@ -509,6 +769,11 @@ func (q *Queries) GetTasksByTag(ctx context.Context, arg GetTasksByTagParams) ([
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Dependent,
+			&i.EstimateMinutes,
+			&i.DeletedAt,
+			&i.Uuid,
+			&i.WaitUntil,
+			&i.Scheduled,
 		); err != nil {
 			return nil, err
 		}
@@ -521,8 +786,9 @@ func (q *Queries) GetTasksByTag(ctx context.Context, arg GetTasksByTagParams) ([
 }
 
 const getTasksWithinDateRange = `-- name: GetTasksWithinDateRange :many
-SELECT id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent FROM tasks
+SELECT id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled FROM tasks
 WHERE user_id = $1
+AND deleted_at IS NULL
 AND (
     (start_date IS NOT NULL AND start_date >= $2 AND start_date <= $3)
     OR (due_date IS NOT NULL AND due_date >= $2 AND due_date <= $3)
@@ -562,6 +828,11 @@ func (q *Queries) GetTasksWithinDateRange(ctx context.Context, arg GetTasksWithi
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Dependent,
+			&i.EstimateMinutes,
+			&i.DeletedAt,
+			&i.Uuid,
+			&i.WaitUntil,
+			&i.Scheduled,
 		); err != nil {
 			return nil, err
 		}
@@ -574,8 +845,9 @@ func (q *Queries) GetTasksWithinDateRange(ctx context.Context, arg GetTasksWithi
 }
 
 const getToday = `-- name: GetToday :many
-SELECT id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent FROM tasks
-WHERE user_id = $1 AND start_date >= CURRENT_DATE
+SELECT id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled FROM tasks
+WHERE user_id = $1 AND start_date >= CURRENT_DATE AND deleted_at IS NULL
+AND (wait_until IS NULL OR wait_until <= NOW())
 `
 
 func (q *Queries) GetToday(ctx context.Context, userID pgtype.Int4) ([]Task, error) {
@@ -603,6 +875,11 @@ func (q *Queries) GetToday(ctx context.Context, userID pgtype.Int4) ([]Task, err
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Dependent,
+			&i.EstimateMinutes,
+			&i.DeletedAt,
+			&i.Uuid,
+			&i.WaitUntil,
+			&i.Scheduled,
 		); err != nil {
 			return nil, err
 		}
@@ -614,82 +891,116 @@ func (q *Queries) GetToday(ctx context.Context, userID pgtype.Int4) ([]Task, err
 	return items, nil
 }
 
-const listTasks = `-- name: ListTasks :many
-SELECT 
-    id, 
+const importTask = `-- name: ImportTask :one
+INSERT INTO tasks (
     user_id,
-    description, 
-    status, 
-    priority, 
-    due_date, 
-    start_date, 
-    completed_at, 
+    description,
+    status,
+    priority,
+    due_date,
+    start_date,
+    completed_at,
     project_id,
     recurrence,
     tags,
     notes,
     created_at,
     updated_at,
-    dependent
-FROM 
-    tasks
-WHERE user_id = $1
-AND (
-    $2::text IS NULL 
-    OR priority = $2
-)
-AND (
-    $3::text IS NULL 
-    OR project_id = $3::integer
-)
-AND (
-    $4::text[] IS NULL
-    OR status = ANY($4)
-)
-AND (
-    $5::text[] IS NULL
-    OR tags && $5
-)
-AND (
-    NOT $6::boolean IS TRUE
-    OR DATE(due_date) <= CURRENT_DATE
-)
-ORDER BY
-    CASE 
-        WHEN status = 'completed' THEN 0 
-        ELSE 1 
-    END,
-    CASE 
-        WHEN status = 'active' THEN 1
-        ELSE 0
-    END,
-    CASE
-        WHEN priority = 'L' THEN 1
-        WHEN priority = 'M' THEN 2
-        WHEN priority = 'H' THEN 3
-        ELSE 0
-    END,
-    COALESCE(id) ASC
+    dependent,
+    estimate_minutes,
+    uuid,
+    wait_until,
+    scheduled
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18
+) RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled
 `
 
-type ListTasksParams struct {
-	UserID      pgtype.Int4 `json:"user_id"`
-	Priority    pgtype.Text `json:"priority"`
-	Project     pgtype.Text `json:"project"`
-	Status      []string    `json:"status"`
-	Tags        []string    `json:"tags"`
-	TodayFilter pgtype.Bool `json:"today_filter"`
+type ImportTaskParams struct {
+	UserID          pgtype.Int4        `json:"user_id"`
+	Description     string             `json:"description"`
+	Status          string             `json:"status"`
+	Priority        pgtype.Text        `json:"priority"`
+	DueDate         pgtype.Timestamptz `json:"due_date"`
+	StartDate       pgtype.Timestamptz `json:"start_date"`
+	CompletedAt     pgtype.Timestamptz `json:"completed_at"`
+	ProjectID       pgtype.Int4        `json:"project_id"`
+	Recurrence      pgtype.Text        `json:"recurrence"`
+	Tags            []string           `json:"tags"`
+	Notes           pgtype.Text        `json:"notes"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	Dependent       pgtype.Int4        `json:"dependent"`
+	EstimateMinutes pgtype.Int4        `json:"estimate_minutes"`
+	Uuid            pgtype.UUID        `json:"uuid"`
+	WaitUntil       pgtype.Timestamptz `json:"wait_until"`
+	Scheduled       pgtype.Timestamptz `json:"scheduled"`
 }
 
-func (q *Queries) ListTasks(ctx context.Context, arg ListTasksParams) ([]Task, error) {
-	rows, err := q.db.Query(ctx, listTasks,
+func (q *Queries) ImportTask(ctx context.Context, arg ImportTaskParams) (Task, error) {
+	row := q.db.QueryRow(ctx, importTask,
 		arg.UserID,
-		arg.Priority,
-		arg.Project,
+		arg.Description,
 		arg.Status,
+		arg.Priority,
+		arg.DueDate,
+		arg.StartDate,
+		arg.CompletedAt,
+		arg.ProjectID,
+		arg.Recurrence,
 		arg.Tags,
-		arg.TodayFilter,
+		arg.Notes,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Dependent,
+		arg.EstimateMinutes,
+		arg.Uuid,
+		arg.WaitUntil,
+		arg.Scheduled,
+	)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Description,
+		&i.Status,
+		&i.Priority,
+		&i.DueDate,
+		&i.StartDate,
+		&i.CompletedAt,
+		&i.ProjectID,
+		&i.Recurrence,
+		&i.Tags,
+		&i.Notes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Dependent,
+		&i.EstimateMinutes,
+		&i.DeletedAt,
+		&i.Uuid,
+		&i.WaitUntil,
+		&i.Scheduled,
 	)
+	return i, err
+}
+
+const listOverdueTasks = `-- name: ListOverdueTasks :many
+SELECT id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled FROM tasks
+WHERE user_id = $1
+  AND deleted_at IS NULL
+  AND status != 'completed'
+  AND due_date < $2
+ORDER BY due_date
+`
+
+type ListOverdueTasksParams struct {
+	UserID  pgtype.Int4        `json:"user_id"`
+	DueDate pgtype.Timestamptz `json:"due_date"`
+}
+
+// Tasks due before a time that aren't completed, oldest first
+func (q *Queries) ListOverdueTasks(ctx context.Context, arg ListOverdueTasksParams) ([]Task, error) {
+	rows, err := q.db.Query(ctx, listOverdueTasks, arg.UserID, arg.DueDate)
 	if err != nil {
 		return nil, err
 	}
@@ -713,6 +1024,11 @@ func (q *Queries) ListTasks(ctx context.Context, arg ListTasksParams) ([]Task, e
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Dependent,
+			&i.EstimateMinutes,
+			&i.DeletedAt,
+			&i.Uuid,
+			&i.WaitUntil,
+			&i.Scheduled,
 		); err != nil {
 			return nil, err
 		}
@@ -724,14 +1040,382 @@ func (q *Queries) ListTasks(ctx context.Context, arg ListTasksParams) ([]Task, e
 	return items, nil
 }
 
-const pauseTask = `-- name: PauseTask :one
-UPDATE tasks
-SET
-    status = 'pending',
+const listSubtasks = `-- name: ListSubtasks :many
+SELECT id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled FROM tasks
+WHERE user_id = $1 AND dependent = $2 AND deleted_at IS NULL
+ORDER BY id
+`
+
+type ListSubtasksParams struct {
+	UserID    pgtype.Int4 `json:"user_id"`
+	Dependent pgtype.Int4 `json:"dependent"`
+}
+
+// Waiting subtasks are included, changes to a parent apply to all of them
+func (q *Queries) ListSubtasks(ctx context.Context, arg ListSubtasksParams) ([]Task, error) {
+	rows, err := q.db.Query(ctx, listSubtasks, arg.UserID, arg.Dependent)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Description,
+			&i.Status,
+			&i.Priority,
+			&i.DueDate,
+			&i.StartDate,
+			&i.CompletedAt,
+			&i.ProjectID,
+			&i.Recurrence,
+			&i.Tags,
+			&i.Notes,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Dependent,
+			&i.EstimateMinutes,
+			&i.DeletedAt,
+			&i.Uuid,
+			&i.WaitUntil,
+			&i.Scheduled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTasks = `-- name: ListTasks :many
+SELECT 
+    id, 
+    user_id,
+    description, 
+    status, 
+    priority, 
+    due_date, 
+    start_date, 
+    completed_at, 
+    project_id,
+    recurrence,
+    tags,
+    notes,
+    created_at,
+    updated_at,
+    dependent,
+    estimate_minutes,
+    deleted_at,
+    uuid,
+    wait_until,
+    scheduled
+FROM 
+    tasks
+WHERE user_id = $1
+AND deleted_at IS NULL
+AND (
+    $2::text IS NULL 
+    OR priority = $2
+)
+AND (
+    $3::text IS NULL 
+    OR project_id = $3::integer
+)
+AND (
+    $4::text[] IS NULL
+    OR status = ANY($4)
+)
+AND (
+    $5::text[] IS NULL
+    OR tags && $5
+)
+AND (
+    NOT $6::boolean IS TRUE
+    OR DATE(due_date) <= CURRENT_DATE
+)
+AND (
+    $7::boolean IS NULL
+    OR $7 = (wait_until IS NOT NULL AND wait_until > NOW())
+)
+ORDER BY
+    CASE 
+        WHEN status = 'completed' THEN 0 
+        ELSE 1 
+    END,
+    CASE 
+        WHEN status = 'active' THEN 1
+        ELSE 0
+    END,
+    CASE
+        WHEN priority = 'L' THEN 1
+        WHEN priority = 'M' THEN 2
+        WHEN priority = 'H' THEN 3
+        ELSE 0
+    END,
+    COALESCE(id) ASC
+`
+
+type ListTasksParams struct {
+	UserID      pgtype.Int4 `json:"user_id"`
+	Priority    pgtype.Text `json:"priority"`
+	Project     pgtype.Text `json:"project"`
+	Status      []string    `json:"status"`
+	Tags        []string    `json:"tags"`
+	TodayFilter pgtype.Bool `json:"today_filter"`
+	Waiting     pgtype.Bool `json:"waiting"`
+}
+
+func (q *Queries) ListTasks(ctx context.Context, arg ListTasksParams) ([]Task, error) {
+	rows, err := q.db.Query(ctx, listTasks,
+		arg.UserID,
+		arg.Priority,
+		arg.Project,
+		arg.Status,
+		arg.Tags,
+		arg.TodayFilter,
+		arg.Waiting,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Description,
+			&i.Status,
+			&i.Priority,
+			&i.DueDate,
+			&i.StartDate,
+			&i.CompletedAt,
+			&i.ProjectID,
+			&i.Recurrence,
+			&i.Tags,
+			&i.Notes,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Dependent,
+			&i.EstimateMinutes,
+			&i.DeletedAt,
+			&i.Uuid,
+			&i.WaitUntil,
+			&i.Scheduled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTasksWithoutProject = `-- name: ListTasksWithoutProject :many
+SELECT id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled FROM tasks
+WHERE user_id = $1 AND project_id IS NULL AND deleted_at IS NULL
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListTasksWithoutProject(ctx context.Context, userID pgtype.Int4) ([]Task, error) {
+	rows, err := q.db.Query(ctx, listTasksWithoutProject, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Description,
+			&i.Status,
+			&i.Priority,
+			&i.DueDate,
+			&i.StartDate,
+			&i.CompletedAt,
+			&i.ProjectID,
+			&i.Recurrence,
+			&i.Tags,
+			&i.Notes,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Dependent,
+			&i.EstimateMinutes,
+			&i.DeletedAt,
+			&i.Uuid,
+			&i.WaitUntil,
+			&i.Scheduled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTrashedTaskTree = `-- name: ListTrashedTaskTree :many
+WITH RECURSIVE tree AS (
+    SELECT t.id, 0 AS depth FROM tasks t
+    WHERE t.id = $1 AND t.user_id = $2 AND t.deleted_at IS NOT NULL
+    UNION ALL
+    SELECT c.id, tree.depth + 1 FROM tasks c
+    JOIN tree ON c.dependent = tree.id
+    JOIN tasks root ON root.id = $1
+    WHERE c.deleted_at = root.deleted_at
+)
+SELECT t.id, t.user_id, t.description, t.status, t.priority, t.due_date, t.start_date, t.completed_at, t.project_id, t.recurrence, t.tags, t.notes, t.created_at, t.updated_at, t.dependent, t.estimate_minutes, t.deleted_at, t.uuid, t.wait_until, t.scheduled FROM tasks t
+JOIN tree ON tree.id = t.id
+ORDER BY tree.depth, t.id
+`
+
+type ListTrashedTaskTreeParams struct {
+	ID     int32       `json:"id"`
+	UserID pgtype.Int4 `json:"user_id"`
+}
+
+// The task and its subtasks trashed along with it, parents first
+func (q *Queries) ListTrashedTaskTree(ctx context.Context, arg ListTrashedTaskTreeParams) ([]Task, error) {
+	rows, err := q.db.Query(ctx, listTrashedTaskTree, arg.ID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Description,
+			&i.Status,
+			&i.Priority,
+			&i.DueDate,
+			&i.StartDate,
+			&i.CompletedAt,
+			&i.ProjectID,
+			&i.Recurrence,
+			&i.Tags,
+			&i.Notes,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Dependent,
+			&i.EstimateMinutes,
+			&i.DeletedAt,
+			&i.Uuid,
+			&i.WaitUntil,
+			&i.Scheduled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTrashedTasks = `-- name: ListTrashedTasks :many
+SELECT id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled FROM tasks
+WHERE user_id = $1 AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id
+`
+
+func (q *Queries) ListTrashedTasks(ctx context.Context, userID pgtype.Int4) ([]Task, error) {
+	rows, err := q.db.Query(ctx, listTrashedTasks, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Description,
+			&i.Status,
+			&i.Priority,
+			&i.DueDate,
+			&i.StartDate,
+			&i.CompletedAt,
+			&i.ProjectID,
+			&i.Recurrence,
+			&i.Tags,
+			&i.Notes,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Dependent,
+			&i.EstimateMinutes,
+			&i.DeletedAt,
+			&i.Uuid,
+			&i.WaitUntil,
+			&i.Scheduled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserTaskDependencies = `-- name: ListUserTaskDependencies :many
+SELECT td.id, td.task_id, td.depends_on_id, td.created_at FROM task_dependencies td
+JOIN tasks t ON t.id = td.task_id
+WHERE t.user_id = $1 AND t.deleted_at IS NULL
+ORDER BY td.id
+`
+
+func (q *Queries) ListUserTaskDependencies(ctx context.Context, userID pgtype.Int4) ([]TaskDependency, error) {
+	rows, err := q.db.Query(ctx, listUserTaskDependencies, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TaskDependency{}
+	for rows.Next() {
+		var i TaskDependency
+		if err := rows.Scan(
+			&i.ID,
+			&i.TaskID,
+			&i.DependsOnID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const pauseTask = `-- name: PauseTask :one
+UPDATE tasks
+SET
+    status = 'pending',
     start_date = NULL,
     updated_at = NOW()
 WHERE id = $1 AND user_id = $2
-RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent
+RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled
 `
 
 type PauseTaskParams struct {
@@ -758,10 +1442,72 @@ func (q *Queries) PauseTask(ctx context.Context, arg PauseTaskParams) (Task, err
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Dependent,
+		&i.EstimateMinutes,
+		&i.DeletedAt,
+		&i.Uuid,
+		&i.WaitUntil,
+		&i.Scheduled,
+	)
+	return i, err
+}
+
+const purgeTask = `-- name: PurgeTask :one
+DELETE FROM tasks
+WHERE id = $1 AND user_id = $2
+RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled
+`
+
+type PurgeTaskParams struct {
+	ID     int32       `json:"id"`
+	UserID pgtype.Int4 `json:"user_id"`
+}
+
+func (q *Queries) PurgeTask(ctx context.Context, arg PurgeTaskParams) (Task, error) {
+	row := q.db.QueryRow(ctx, purgeTask, arg.ID, arg.UserID)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Description,
+		&i.Status,
+		&i.Priority,
+		&i.DueDate,
+		&i.StartDate,
+		&i.CompletedAt,
+		&i.ProjectID,
+		&i.Recurrence,
+		&i.Tags,
+		&i.Notes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Dependent,
+		&i.EstimateMinutes,
+		&i.DeletedAt,
+		&i.Uuid,
+		&i.WaitUntil,
+		&i.Scheduled,
 	)
 	return i, err
 }
 
+const purgeTrashedTasks = `-- name: PurgeTrashedTasks :execrows
+DELETE FROM tasks
+WHERE user_id = $1 AND deleted_at < $2
+`
+
+type PurgeTrashedTasksParams struct {
+	UserID    pgtype.Int4        `json:"user_id"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+}
+
+func (q *Queries) PurgeTrashedTasks(ctx context.Context, arg PurgeTrashedTasksParams) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeTrashedTasks, arg.UserID, arg.DeletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const removeTaskDependency = `-- name: RemoveTaskDependency :exec
 DELETE FROM task_dependencies
 WHERE task_id = $1 AND depends_on_id = $2
@@ -777,6 +1523,149 @@ func (q *Queries) RemoveTaskDependency(ctx context.Context, arg RemoveTaskDepend
 	return err
 }
 
+const removeTaskTags = `-- name: RemoveTaskTags :exec
+UPDATE tasks
+SET
+    tags = ARRAY(
+        SELECT tag FROM unnest(tags) AS tag
+        WHERE NOT tag = ANY($1::text[])
+    ),
+    updated_at = NOW()
+WHERE id = $2 AND user_id = $3
+`
+
+type RemoveTaskTagsParams struct {
+	Tags   []string    `json:"tags"`
+	ID     int32       `json:"id"`
+	UserID pgtype.Int4 `json:"user_id"`
+}
+
+func (q *Queries) RemoveTaskTags(ctx context.Context, arg RemoveTaskTagsParams) error {
+	_, err := q.db.Exec(ctx, removeTaskTags, arg.Tags, arg.ID, arg.UserID)
+	return err
+}
+
+const restoreTask = `-- name: RestoreTask :one
+INSERT INTO tasks (
+    id,
+    user_id,
+    description,
+    status,
+    priority,
+    due_date,
+    start_date,
+    completed_at,
+    project_id,
+    recurrence,
+    tags,
+    notes,
+    created_at,
+    updated_at,
+    dependent,
+    estimate_minutes,
+    deleted_at,
+    uuid,
+    wait_until,
+    scheduled
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20
+)
+ON CONFLICT (id)
+DO UPDATE SET
+    description = EXCLUDED.description,
+    status = EXCLUDED.status,
+    priority = EXCLUDED.priority,
+    due_date = EXCLUDED.due_date,
+    start_date = EXCLUDED.start_date,
+    completed_at = EXCLUDED.completed_at,
+    project_id = EXCLUDED.project_id,
+    recurrence = EXCLUDED.recurrence,
+    tags = EXCLUDED.tags,
+    notes = EXCLUDED.notes,
+    created_at = EXCLUDED.created_at,
+    updated_at = EXCLUDED.updated_at,
+    dependent = EXCLUDED.dependent,
+    estimate_minutes = EXCLUDED.estimate_minutes,
+    deleted_at = EXCLUDED.deleted_at,
+    uuid = EXCLUDED.uuid,
+    wait_until = EXCLUDED.wait_until,
+    scheduled = EXCLUDED.scheduled
+WHERE tasks.user_id = EXCLUDED.user_id
+RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled
+`
+
+type RestoreTaskParams struct {
+	ID              int32              `json:"id"`
+	UserID          pgtype.Int4        `json:"user_id"`
+	Description     string             `json:"description"`
+	Status          string             `json:"status"`
+	Priority        pgtype.Text        `json:"priority"`
+	DueDate         pgtype.Timestamptz `json:"due_date"`
+	StartDate       pgtype.Timestamptz `json:"start_date"`
+	CompletedAt     pgtype.Timestamptz `json:"completed_at"`
+	ProjectID       pgtype.Int4        `json:"project_id"`
+	Recurrence      pgtype.Text        `json:"recurrence"`
+	Tags            []string           `json:"tags"`
+	Notes           pgtype.Text        `json:"notes"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	Dependent       pgtype.Int4        `json:"dependent"`
+	EstimateMinutes pgtype.Int4        `json:"estimate_minutes"`
+	DeletedAt       pgtype.Timestamptz `json:"deleted_at"`
+	Uuid            pgtype.UUID        `json:"uuid"`
+	WaitUntil       pgtype.Timestamptz `json:"wait_until"`
+	Scheduled       pgtype.Timestamptz `json:"scheduled"`
+}
+
+func (q *Queries) RestoreTask(ctx context.Context, arg RestoreTaskParams) (Task, error) {
+	row := q.db.QueryRow(ctx, restoreTask,
+		arg.ID,
+		arg.UserID,
+		arg.Description,
+		arg.Status,
+		arg.Priority,
+		arg.DueDate,
+		arg.StartDate,
+		arg.CompletedAt,
+		arg.ProjectID,
+		arg.Recurrence,
+		arg.Tags,
+		arg.Notes,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Dependent,
+		arg.EstimateMinutes,
+		arg.DeletedAt,
+		arg.Uuid,
+		arg.WaitUntil,
+		arg.Scheduled,
+	)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Description,
+		&i.Status,
+		&i.Priority,
+		&i.DueDate,
+		&i.StartDate,
+		&i.CompletedAt,
+		&i.ProjectID,
+		&i.Recurrence,
+		&i.Tags,
+		&i.Notes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Dependent,
+		&i.EstimateMinutes,
+		&i.DeletedAt,
+		&i.Uuid,
+		&i.WaitUntil,
+		&i.Scheduled,
+	)
+	return i, err
+}
+
 const setTags = `-- name: SetTags :exec
 UPDATE tasks
 SET
@@ -804,7 +1693,7 @@ SET
     ),
     updated_at = NOW()
 WHERE id = $1 AND user_id = $2
-RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent
+RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled
 `
 
 type SetTaskDueParams struct {
@@ -832,6 +1721,140 @@ func (q *Queries) SetTaskDue(ctx context.Context, arg SetTaskDueParams) (Task, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Dependent,
+		&i.EstimateMinutes,
+		&i.DeletedAt,
+		&i.Uuid,
+		&i.WaitUntil,
+		&i.Scheduled,
+	)
+	return i, err
+}
+
+const setTaskEstimate = `-- name: SetTaskEstimate :one
+UPDATE tasks
+SET
+    estimate_minutes = $3,
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled
+`
+
+type SetTaskEstimateParams struct {
+	ID              int32       `json:"id"`
+	UserID          pgtype.Int4 `json:"user_id"`
+	EstimateMinutes pgtype.Int4 `json:"estimate_minutes"`
+}
+
+func (q *Queries) SetTaskEstimate(ctx context.Context, arg SetTaskEstimateParams) (Task, error) {
+	row := q.db.QueryRow(ctx, setTaskEstimate, arg.ID, arg.UserID, arg.EstimateMinutes)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Description,
+		&i.Status,
+		&i.Priority,
+		&i.DueDate,
+		&i.StartDate,
+		&i.CompletedAt,
+		&i.ProjectID,
+		&i.Recurrence,
+		&i.Tags,
+		&i.Notes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Dependent,
+		&i.EstimateMinutes,
+		&i.DeletedAt,
+		&i.Uuid,
+		&i.WaitUntil,
+		&i.Scheduled,
+	)
+	return i, err
+}
+
+const setTaskScheduled = `-- name: SetTaskScheduled :one
+UPDATE tasks
+SET
+    scheduled = $3,
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled
+`
+
+type SetTaskScheduledParams struct {
+	ID        int32              `json:"id"`
+	UserID    pgtype.Int4        `json:"user_id"`
+	Scheduled pgtype.Timestamptz `json:"scheduled"`
+}
+
+func (q *Queries) SetTaskScheduled(ctx context.Context, arg SetTaskScheduledParams) (Task, error) {
+	row := q.db.QueryRow(ctx, setTaskScheduled, arg.ID, arg.UserID, arg.Scheduled)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Description,
+		&i.Status,
+		&i.Priority,
+		&i.DueDate,
+		&i.StartDate,
+		&i.CompletedAt,
+		&i.ProjectID,
+		&i.Recurrence,
+		&i.Tags,
+		&i.Notes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Dependent,
+		&i.EstimateMinutes,
+		&i.DeletedAt,
+		&i.Uuid,
+		&i.WaitUntil,
+		&i.Scheduled,
+	)
+	return i, err
+}
+
+const setTaskWait = `-- name: SetTaskWait :one
+UPDATE tasks
+SET
+    wait_until = $3,
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled
+`
+
+type SetTaskWaitParams struct {
+	ID        int32              `json:"id"`
+	UserID    pgtype.Int4        `json:"user_id"`
+	WaitUntil pgtype.Timestamptz `json:"wait_until"`
+}
+
+func (q *Queries) SetTaskWait(ctx context.Context, arg SetTaskWaitParams) (Task, error) {
+	row := q.db.QueryRow(ctx, setTaskWait, arg.ID, arg.UserID, arg.WaitUntil)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Description,
+		&i.Status,
+		&i.Priority,
+		&i.DueDate,
+		&i.StartDate,
+		&i.CompletedAt,
+		&i.ProjectID,
+		&i.Recurrence,
+		&i.Tags,
+		&i.Notes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Dependent,
+		&i.EstimateMinutes,
+		&i.DeletedAt,
+		&i.Uuid,
+		&i.WaitUntil,
+		&i.Scheduled,
 	)
 	return i, err
 }
@@ -841,7 +1864,7 @@ UPDATE tasks
 SET
     start_date = TODAY()
 WHERE id = $1 AND user_id = $2
-RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent
+RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled
 `
 
 type SetTodayParams struct {
@@ -868,6 +1891,11 @@ func (q *Queries) SetToday(ctx context.Context, arg SetTodayParams) (Task, error
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Dependent,
+		&i.EstimateMinutes,
+		&i.DeletedAt,
+		&i.Uuid,
+		&i.WaitUntil,
+		&i.Scheduled,
 	)
 	return i, err
 }
@@ -879,7 +1907,7 @@ SET
     start_date = NOW(),
     updated_at = NOW()
 WHERE id = $1 AND user_id = $2
-RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent
+RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled
 `
 
 type StartTaskParams struct {
@@ -906,10 +1934,86 @@ func (q *Queries) StartTask(ctx context.Context, arg StartTaskParams) (Task, err
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Dependent,
+		&i.EstimateMinutes,
+		&i.DeletedAt,
+		&i.Uuid,
+		&i.WaitUntil,
+		&i.Scheduled,
 	)
 	return i, err
 }
 
+const untrashTasks = `-- name: UntrashTasks :many
+UPDATE tasks
+SET
+    deleted_at = NULL,
+    dependent = CASE
+        WHEN dependent = ANY($1::integer[]) THEN dependent
+        WHEN EXISTS (
+            SELECT 1 FROM tasks parent
+            WHERE parent.id = tasks.dependent AND parent.deleted_at IS NULL
+        ) THEN dependent
+        ELSE NULL
+    END,
+    project_id = CASE
+        WHEN EXISTS (
+            SELECT 1 FROM projects p
+            WHERE p.id = tasks.project_id AND p.deleted_at IS NULL
+        ) THEN project_id
+        ELSE NULL
+    END,
+    updated_at = NOW()
+WHERE id = ANY($1::integer[]) AND user_id = $2 AND deleted_at IS NOT NULL
+RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled
+`
+
+type UntrashTasksParams struct {
+	Ids    []int32     `json:"ids"`
+	UserID pgtype.Int4 `json:"user_id"`
+}
+
+// Parents and projects that are still in the trash are detached
+func (q *Queries) UntrashTasks(ctx context.Context, arg UntrashTasksParams) ([]Task, error) {
+	rows, err := q.db.Query(ctx, untrashTasks, arg.Ids, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Description,
+			&i.Status,
+			&i.Priority,
+			&i.DueDate,
+			&i.StartDate,
+			&i.CompletedAt,
+			&i.ProjectID,
+			&i.Recurrence,
+			&i.Tags,
+			&i.Notes,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Dependent,
+			&i.EstimateMinutes,
+			&i.DeletedAt,
+			&i.Uuid,
+			&i.WaitUntil,
+			&i.Scheduled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateTask = `-- name: UpdateTask :one
 UPDATE tasks
 SET
@@ -928,7 +2032,7 @@ SET
         ELSE NULL 
     END
 WHERE id = $1 AND user_id = $2
-RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent
+RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled
 `
 
 type UpdateTaskParams struct {
@@ -976,6 +2080,11 @@ func (q *Queries) UpdateTask(ctx context.Context, arg UpdateTaskParams) (Task, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Dependent,
+		&i.EstimateMinutes,
+		&i.DeletedAt,
+		&i.Uuid,
+		&i.WaitUntil,
+		&i.Scheduled,
 	)
 	return i, err
 }
@@ -990,7 +2099,7 @@ SET
         ELSE completed_at 
     END
 WHERE id = $1 AND user_id = $2
-RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent
+RETURNING id, user_id, description, status, priority, due_date, start_date, completed_at, project_id, recurrence, tags, notes, created_at, updated_at, dependent, estimate_minutes, deleted_at, uuid, wait_until, scheduled
 `
 
 type UpdateTaskStatusParams struct {
@@ -1018,6 +2127,11 @@ func (q *Queries) UpdateTaskStatus(ctx context.Context, arg UpdateTaskStatusPara
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Dependent,
+		&i.EstimateMinutes,
+		&i.DeletedAt,
+		&i.Uuid,
+		&i.WaitUntil,
+		&i.Scheduled,
 	)
 	return i, err
 }
@@ -56,9 +56,9 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 }
 
 const getActiveProject = `-- name: GetActiveProject :one
-SELECT p.id, p.user_id, p.name, p.description, p.deadline, p.created_at, p.updated_at FROM projects p
+SELECT p.id, p.user_id, p.name, p.description, p.deadline, p.created_at, p.updated_at, p.pomodoro_preset_id, p.deleted_at FROM projects p
 JOIN users u ON p.id = u.active_project_id
-WHERE u.id = $1
+WHERE u.id = $1 AND p.deleted_at IS NULL
 LIMIT 1
 `
 
@@ -73,6 +73,8 @@ func (q *Queries) GetActiveProject(ctx context.Context, id int32) (Project, erro
 		&i.Deadline,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PomodoroPresetID,
+		&i.DeletedAt,
 	)
 	return i, err
 }
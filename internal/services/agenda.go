@@ -0,0 +1,452 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/jskallebak/prod/internal/util"
+)
+
+// WorkingHours are the hours of the day free time is looked for in, as the
+// time since midnight
+type WorkingHours struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// DefaultWorkingHours are 9 to 17
+var DefaultWorkingHours = WorkingHours{
+	Start: 9 * time.Hour,
+	End:   17 * time.Hour,
+}
+
+// ParseWorkingHours parses working hours like "9-17" or "08:30-16:30"
+func ParseWorkingHours(input string) (WorkingHours, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(input), "-")
+	if !ok {
+		return WorkingHours{}, fmt.Errorf("invalid working hours %q (use e.g. 9-17 or 08:30-16:30)", input)
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return WorkingHours{}, err
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return WorkingHours{}, err
+	}
+	if end <= start {
+		return WorkingHours{}, fmt.Errorf("working hours end before they start: %q", input)
+	}
+	return WorkingHours{Start: start, End: end}, nil
+}
+
+// parseClock parses a time of day like "9", "17" or "08:30"
+func parseClock(input string) (time.Duration, error) {
+	input = strings.TrimSpace(input)
+	hours, minutes, _ := strings.Cut(input, ":")
+	h, err := strconv.Atoi(hours)
+	if err != nil || h < 0 || h > 24 {
+		return 0, fmt.Errorf("invalid time of day %q", input)
+	}
+	m := 0
+	if minutes != "" {
+		if m, err = strconv.Atoi(minutes); err != nil || m < 0 || m > 59 || h == 24 && m > 0 {
+			return 0, fmt.Errorf("invalid time of day %q", input)
+		}
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// On returns the working hours of day. The times are read off the clock, so
+// days when daylight saving time changes keep the same hours
+func (h WorkingHours) On(day time.Time) TimeSpan {
+	clock := func(since time.Duration) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), int(since/time.Hour), int(since%time.Hour/time.Minute), 0, 0, day.Location())
+	}
+	return TimeSpan{
+		Start: clock(h.Start),
+		End:   clock(h.End),
+	}
+}
+
+// TimeSpan is a stretch of time
+type TimeSpan struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration is the length of the span
+func (s TimeSpan) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// FreeTime returns the parts of within that none of busy overlaps, leaving out
+// the ones shorter than minimum
+func FreeTime(within TimeSpan, busy []TimeSpan, minimum time.Duration) []TimeSpan {
+	busy = slices.Clone(busy)
+	sort.Slice(busy, func(i, j int) bool {
+		return busy[i].Start.Before(busy[j].Start)
+	})
+
+	var free []TimeSpan
+	cursor := within.Start
+	add := func(end time.Time) {
+		if end.After(within.End) {
+			end = within.End
+		}
+		if end.Sub(cursor) >= minimum && end.After(cursor) {
+			free = append(free, TimeSpan{Start: cursor, End: end})
+		}
+	}
+	for _, span := range busy {
+		if !span.Start.Before(within.End) {
+			break
+		}
+		if span.Start.After(cursor) {
+			add(span.Start)
+		}
+		if span.End.After(cursor) {
+			cursor = span.End
+		}
+	}
+	add(within.End)
+	return free
+}
+
+// AgendaKind is the type of an agenda item
+type AgendaKind string
+
+const (
+	AgendaEvent    AgendaKind = "event"
+	AgendaTask     AgendaKind = "task"
+	AgendaPomodoro AgendaKind = "pomodoro"
+)
+
+// AgendaItem is an event, task or completed pomodoro on an agenda day
+type AgendaItem struct {
+	Kind  AgendaKind
+	Title string
+	Start time.Time
+	End   time.Time
+	// Timed is false for all-day events and tasks
+	Timed    bool
+	Location string
+
+	// Task is the task of task items and of pomodoros spent on one. Due and
+	// Scheduled tell whether a task item is due or starts that day
+	Task      *sqlc.Task
+	Due       bool
+	Scheduled bool
+}
+
+// AgendaHabit is a habit due on a day and whether it was done
+type AgendaHabit struct {
+	Habit sqlc.Habit
+	Done  bool
+}
+
+// AgendaDay is the agenda of one day
+type AgendaDay struct {
+	Date time.Time
+	// Overdue are the tasks due before today that aren't completed, listed on
+	// today only
+	Overdue []sqlc.Task
+	// Items are in chronological order, the ones without a time first
+	Items  []AgendaItem
+	Habits []AgendaHabit
+	// Free is the free time within the working hours, for today and later
+	Free []TimeSpan
+}
+
+// AgendaService gathers what is planned for a range of days
+type AgendaService struct {
+	queries *sqlc.Queries
+}
+
+// NewAgendaService creates a new agenda service
+func NewAgendaService(queries *sqlc.Queries) *AgendaService {
+	return &AgendaService{
+		queries: queries,
+	}
+}
+
+// minimumFreeTime is the shortest free time worth showing
+const minimumFreeTime = 15 * time.Minute
+
+// Agenda returns the agenda of the days starting at the day of from. Free time
+// is looked for within hours, from now on
+func (s *AgendaService) Agenda(ctx context.Context, userID int32, from time.Time, days int, hours WorkingHours, now time.Time) ([]AgendaDay, error) {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+	start := dateOf(from)
+	end := start.AddDate(0, 0, days)
+	rangeStart := pgtype.Timestamptz{
+		Time:  start,
+		Valid: true,
+	}
+	rangeEnd := pgtype.Timestamptz{
+		Time:  end.Add(-time.Nanosecond),
+		Valid: true,
+	}
+
+	agenda := make([]AgendaDay, days)
+	dayOf := func(t time.Time) *AgendaDay {
+		t = t.Local()
+		if t.Before(start) || !t.Before(end) {
+			return nil
+		}
+		for i := range agenda {
+			if !dateOf(t).After(agenda[i].Date) {
+				return &agenda[i]
+			}
+		}
+		return nil
+	}
+	for i := range agenda {
+		agenda[i].Date = start.AddDate(0, 0, i)
+	}
+
+	events, err := s.queries.ListCalendarEventsInRange(ctx, sqlc.ListCalendarEventsInRangeParams{
+		UserID:     user,
+		RangeStart: rangeStart,
+		RangeEnd:   rangeEnd,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar events: %w", err)
+	}
+	for _, occurrence := range ExpandEvents(events, start, end) {
+		item := AgendaItem{
+			Kind:     AgendaEvent,
+			Title:    occurrence.Event.Title,
+			Start:    occurrence.Start,
+			End:      occurrence.End,
+			Timed:    !occurrence.Event.AllDay.Bool,
+			Location: occurrence.Event.Location.String,
+		}
+		// Events are listed on every day they cover
+		for i := range agenda {
+			day := &agenda[i]
+			next := day.Date.AddDate(0, 0, 1)
+			if occurrence.Start.Before(next) && (occurrence.End.After(day.Date) || !occurrence.Start.Before(day.Date)) {
+				day.Items = append(day.Items, item)
+			}
+		}
+	}
+
+	tasks, err := s.queries.GetTasksWithinDateRange(ctx, sqlc.GetTasksWithinDateRangeParams{
+		UserID:      user,
+		StartDate:   rangeStart,
+		StartDate_2: rangeEnd,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks: %w", err)
+	}
+	for _, task := range tasks {
+		task := task
+		due, scheduled := dayOf(task.DueDate.Time), dayOf(task.StartDate.Time)
+		if task.DueDate.Valid && due != nil {
+			due.Items = append(due.Items, taskItem(&task, task.DueDate.Time, true, due == scheduled && task.StartDate.Valid))
+		}
+		if task.StartDate.Valid && scheduled != nil && (scheduled != due || !task.DueDate.Valid) {
+			scheduled.Items = append(scheduled.Items, taskItem(&task, task.StartDate.Time, false, true))
+		}
+	}
+
+	if today := dayOf(now); today != nil {
+		overdue, err := s.queries.ListOverdueTasks(ctx, sqlc.ListOverdueTasksParams{
+			UserID: user,
+			DueDate: pgtype.Timestamptz{
+				Time:  dateOf(now),
+				Valid: true,
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get overdue tasks: %w", err)
+		}
+		today.Overdue = overdue
+	}
+
+	sessions, err := s.queries.ListPomodoroSessionsWithTask(ctx, sqlc.ListPomodoroSessionsWithTaskParams{
+		UserID:    user,
+		StartDate: rangeStart,
+		EndDate:   rangeEnd,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pomodoro sessions: %w", err)
+	}
+	for _, session := range sessions {
+		day := dayOf(session.StartTime.Time)
+		if session.Status != "completed" || day == nil {
+			continue
+		}
+		item := AgendaItem{
+			Kind:  AgendaPomodoro,
+			Title: "Pomodoro",
+			Start: session.StartTime.Time,
+			End:   session.StartTime.Time.Add(time.Duration(session.WorkDuration) * time.Minute),
+			Timed: true,
+		}
+		if session.EndTime.Valid {
+			item.End = session.EndTime.Time
+		}
+		if session.TaskDescription.Valid {
+			item.Title = session.TaskDescription.String
+		} else if session.Note.Valid && session.Note.String != "" {
+			item.Title = session.Note.String
+		}
+		day.Items = append(day.Items, item)
+	}
+
+	habits, err := s.queries.ListHabits(ctx, user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get habits: %w", err)
+	}
+	completions, err := s.queries.ListHabitCompletionsInRange(ctx, sqlc.ListHabitCompletionsInRangeParams{
+		UserID: user,
+		RangeStart: pgtype.Date{
+			Time:  start,
+			Valid: true,
+		},
+		RangeEnd: pgtype.Date{
+			Time:  end.AddDate(0, 0, -1),
+			Valid: true,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get habit completions: %w", err)
+	}
+
+	for i := range agenda {
+		day := &agenda[i]
+		for _, habit := range habits {
+			if !HabitDue(habit, day.Date) {
+				continue
+			}
+			done := slices.ContainsFunc(completions, func(c sqlc.HabitCompletion) bool {
+				date := c.CompletedDate.Time
+				return c.HabitID.Int32 == habit.ID && date.Year() == day.Date.Year() && date.YearDay() == day.Date.YearDay()
+			})
+			day.Habits = append(day.Habits, AgendaHabit{Habit: habit, Done: done})
+		}
+
+		sort.SliceStable(day.Items, func(a, b int) bool {
+			x, y := day.Items[a], day.Items[b]
+			if x.Timed != y.Timed {
+				return !x.Timed
+			}
+			return x.Timed && x.Start.Before(y.Start)
+		})
+
+		// Free time is only of use from now on. All-day events don't take
+		// up time, since they are often just reminders
+		if dateOf(now).After(day.Date) {
+			continue
+		}
+		within := hours.On(day.Date)
+		if now.After(within.Start) {
+			within.Start = now.Truncate(time.Minute)
+		}
+		var busy []TimeSpan
+		for _, item := range day.Items {
+			if item.Timed {
+				busy = append(busy, TimeSpan{Start: item.Start, End: item.End})
+			}
+		}
+		day.Free = FreeTime(within, busy, minimumFreeTime)
+	}
+
+	return agenda, nil
+}
+
+// taskItem lists a task on a day. Due and start dates are days, so tasks have
+// no time of day
+func taskItem(task *sqlc.Task, at time.Time, due, scheduled bool) AgendaItem {
+	at = at.Local()
+	return AgendaItem{
+		Kind:      AgendaTask,
+		Title:     task.Description,
+		Start:     at,
+		End:       at,
+		Task:      task,
+		Due:       due,
+		Scheduled: scheduled,
+	}
+}
+
+// HabitDue tells whether a habit is due on day. The frequency of a habit uses
+// the recurrence format of tasks, counted from the day the habit was created.
+// Habits with a frequency that can't be read are due every day
+func HabitDue(habit sqlc.Habit, day time.Time) bool {
+	day = dateOf(day)
+	created := dateOf(habit.CreatedAt.Time.Local())
+	if habit.CreatedAt.Valid && day.Before(created) {
+		return false
+	}
+
+	pattern, err := ParseRecurrence(habit.Frequency)
+	if err != nil {
+		return true
+	}
+	// Until is a date, the habit is due all of that day where it is
+	if until := pattern.Until; until != nil && day.After(time.Date(until.Year(), until.Month(), until.Day(), 0, 0, 0, 0, day.Location())) {
+		return false
+	}
+
+	weekday := int(day.Weekday())
+	if weekday == 0 {
+		weekday = 7
+	}
+	switch pattern.Type {
+	case RecurrenceDaily:
+		return dayCount(created, day)%pattern.Interval == 0
+	case RecurrenceWeekly:
+		// Weeks are counted from the Monday of the week the habit was created
+		monday := util.StartOfWeek(created)
+		if (dayCount(monday, day)/7)%pattern.Interval != 0 {
+			return false
+		}
+		if len(pattern.WeekDays) == 0 {
+			return day.Weekday() == created.Weekday()
+		}
+		return slices.Contains(pattern.WeekDays, weekday)
+	case RecurrenceMonthly:
+		months := (day.Year()-created.Year())*12 + int(day.Month()-created.Month())
+		if months%pattern.Interval != 0 {
+			return false
+		}
+		last := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.Local).Day()
+		switch {
+		case pattern.MonthWeekDay != nil:
+			if weekday != pattern.MonthWeekDay.WeekDay {
+				return false
+			}
+			if pattern.MonthWeekDay.Week == 5 {
+				return day.Day()+7 > last
+			}
+			return (day.Day()-1)/7+1 == pattern.MonthWeekDay.Week
+		case pattern.MonthDay == -1:
+			return day.Day() == last
+		case pattern.MonthDay > 0:
+			return day.Day() == min(pattern.MonthDay, last)
+		}
+		return day.Day() == min(created.Day(), last)
+	case RecurrenceYearly:
+		if (day.Year()-created.Year())%pattern.Interval != 0 {
+			return false
+		}
+		if pattern.YearlyDate != nil {
+			return int(day.Month()) == pattern.YearlyDate.Month && day.Day() == pattern.YearlyDate.Day
+		}
+		return day.Month() == created.Month() && day.Day() == created.Day()
+	}
+	return true
+}
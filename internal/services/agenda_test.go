@@ -0,0 +1,186 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWorkingHours(t *testing.T) {
+	tests := []struct {
+		input string
+		want  WorkingHours
+		err   bool
+	}{
+		{input: "9-17", want: WorkingHours{Start: 9 * time.Hour, End: 17 * time.Hour}},
+		{input: "08:30-16:30", want: WorkingHours{Start: 8*time.Hour + 30*time.Minute, End: 16*time.Hour + 30*time.Minute}},
+		{input: " 7 - 15:45 ", want: WorkingHours{Start: 7 * time.Hour, End: 15*time.Hour + 45*time.Minute}},
+		{input: "0-24", want: WorkingHours{Start: 0, End: 24 * time.Hour}},
+		{input: "", err: true},
+		{input: "9", err: true},
+		{input: "17-9", err: true},
+		{input: "9-9", err: true},
+		{input: "9-25", err: true},
+		{input: "9:60-17", err: true},
+		{input: "9-24:30", err: true},
+		{input: "-1-17", err: true},
+		{input: "nine-five", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			hours, err := ParseWorkingHours(tt.input)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, hours)
+		})
+	}
+}
+
+func TestWorkingHoursOn(t *testing.T) {
+	copenhagen, err := time.LoadLocation("Europe/Copenhagen")
+	require.NoError(t, err)
+	hours := WorkingHours{Start: 8*time.Hour + 30*time.Minute, End: 16 * time.Hour}
+
+	// Daylight saving time starts and ends at night, the hours stay the same
+	for _, day := range []time.Time{
+		time.Date(2025, 6, 2, 13, 0, 0, 0, copenhagen),
+		time.Date(2025, 3, 30, 13, 0, 0, 0, copenhagen),
+		time.Date(2025, 10, 26, 13, 0, 0, 0, copenhagen),
+	} {
+		span := hours.On(day)
+		assert.Equal(t, "08:30-16:00", span.Start.Format("15:04")+"-"+span.End.Format("15:04"), day.Format(time.DateOnly))
+		assert.Equal(t, day.Format(time.DateOnly), span.Start.Format(time.DateOnly))
+		assert.Equal(t, 7*time.Hour+30*time.Minute, span.Duration())
+	}
+
+	span := WorkingHours{Start: 0, End: 24 * time.Hour}.On(time.Date(2025, 6, 2, 13, 0, 0, 0, copenhagen))
+	assert.Equal(t, time.Date(2025, 6, 3, 0, 0, 0, 0, copenhagen), span.End, "the day ends at the next midnight")
+}
+
+func TestFreeTime(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2025, 6, 2, hour, minute, 0, 0, time.UTC)
+	}
+	span := func(fromHour, fromMinute, toHour, toMinute int) TimeSpan {
+		return TimeSpan{Start: at(fromHour, fromMinute), End: at(toHour, toMinute)}
+	}
+	within := span(9, 0, 17, 0)
+
+	tests := []struct {
+		name    string
+		busy    []TimeSpan
+		minimum time.Duration
+		want    []TimeSpan
+	}{
+		{
+			name: "nothing planned",
+			want: []TimeSpan{within},
+		},
+		{
+			name: "overlapping spans",
+			busy: []TimeSpan{span(10, 30, 12, 0), span(10, 0, 11, 0)},
+			want: []TimeSpan{span(9, 0, 10, 0), span(12, 0, 17, 0)},
+		},
+		{
+			name: "adjacent spans",
+			busy: []TimeSpan{span(10, 0, 11, 0), span(11, 0, 12, 0)},
+			want: []TimeSpan{span(9, 0, 10, 0), span(12, 0, 17, 0)},
+		},
+		{
+			name: "a span within another",
+			busy: []TimeSpan{span(10, 0, 13, 0), span(11, 0, 12, 0)},
+			want: []TimeSpan{span(9, 0, 10, 0), span(13, 0, 17, 0)},
+		},
+		{
+			name: "spans past the working hours",
+			busy: []TimeSpan{span(8, 0, 9, 30), span(16, 30, 18, 0)},
+			want: []TimeSpan{span(9, 30, 16, 30)},
+		},
+		{
+			name: "spans outside the working hours",
+			busy: []TimeSpan{span(7, 0, 8, 0), span(17, 0, 18, 0)},
+			want: []TimeSpan{within},
+		},
+		{
+			name: "busy all day",
+			busy: []TimeSpan{span(8, 0, 18, 0)},
+		},
+		{
+			name:    "gaps shorter than the minimum are left out",
+			busy:    []TimeSpan{span(10, 0, 11, 0), span(11, 10, 12, 0), span(16, 50, 17, 0)},
+			minimum: 15 * time.Minute,
+			want:    []TimeSpan{span(9, 0, 10, 0), span(12, 0, 16, 50)},
+		},
+		{
+			name:    "gaps of the minimum are kept",
+			busy:    []TimeSpan{span(10, 0, 11, 0), span(11, 15, 17, 0)},
+			minimum: 15 * time.Minute,
+			want:    []TimeSpan{span(9, 0, 10, 0), span(11, 0, 11, 15)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FreeTime(within, tt.busy, tt.minimum))
+		})
+	}
+}
+
+func TestHabitDue(t *testing.T) {
+	// Created on a Wednesday
+	habit := sqlc.Habit{
+		Name: "Stretch",
+		CreatedAt: pgtype.Timestamptz{
+			Time:  time.Date(2025, 6, 4, 10, 0, 0, 0, time.Local),
+			Valid: true,
+		},
+	}
+
+	tests := []struct {
+		frequency string
+		// days are looked at from Sunday June 1
+		days int
+		want []string
+	}{
+		{"daily", 7, []string{"2025-06-04", "2025-06-05", "2025-06-06", "2025-06-07"}},
+		{"daily:2", 10, []string{"2025-06-04", "2025-06-06", "2025-06-08", "2025-06-10"}},
+		{"daily:1::until:2025-06-06", 10, []string{"2025-06-04", "2025-06-05", "2025-06-06"}},
+		{"weekly", 14, []string{"2025-06-04", "2025-06-11"}},
+		{"weekly:1:1,5", 14, []string{"2025-06-06", "2025-06-09", "2025-06-13"}},
+		// Weeks count from the Monday of the week the habit was created
+		{"weekly:2:1", 31, []string{"2025-06-16", "2025-06-30"}},
+		{"monthly", 70, []string{"2025-06-04", "2025-07-04", "2025-08-04"}},
+		{"monthly:2", 70, []string{"2025-06-04", "2025-08-04"}},
+		{"monthly:1:31", 70, []string{"2025-06-30", "2025-07-31"}},
+		{"monthly:1:last", 70, []string{"2025-06-30", "2025-07-31"}},
+		{"monthly:1:2w3", 70, []string{"2025-06-11", "2025-07-09"}},
+		{"monthly:1:5w5", 70, []string{"2025-06-27", "2025-07-25"}},
+		{"yearly", 400, []string{"2025-06-04", "2026-06-04"}},
+		{"yearly:1:0101", 400, []string{"2026-01-01"}},
+		// Frequencies that can't be read are due every day
+		{"whenever", 7, []string{"2025-06-04", "2025-06-05", "2025-06-06", "2025-06-07"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.frequency, func(t *testing.T) {
+			habit.Frequency = tt.frequency
+			var due []string
+			first := time.Date(2025, 6, 1, 0, 0, 0, 0, time.Local)
+			for i := 0; i < tt.days; i++ {
+				day := first.AddDate(0, 0, i)
+				if HabitDue(habit, day.Add(15*time.Hour)) {
+					due = append(due, day.Format(time.DateOnly))
+				}
+			}
+			assert.Equal(t, tt.want, due)
+		})
+	}
+}
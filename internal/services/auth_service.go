@@ -48,6 +48,22 @@ func (a AuthService) Login(ctx context.Context, email, password string) (*sqlc.U
 	return &user, nil
 }
 
+// CurrentUserID returns the ID of the logged in user from the stored token,
+// without looking the user up in the database
+func CurrentUserID() (int32, error) {
+	token, err := auth.ReadToken()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the token: %w", err)
+	}
+
+	claim, err := auth.VerifyJWT(token)
+	if err != nil {
+		return 0, fmt.Errorf("failed to verify token: %w", err)
+	}
+
+	return claim.UserID, nil
+}
+
 func (a AuthService) GetCurrentUser(ctx context.Context) (*sqlc.User, error) {
 	token, err := auth.ReadToken()
 	if err != nil {
@@ -0,0 +1,1076 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+)
+
+// BackupVersion is the version of the backup schema written by Export.
+// Bump it when the format changes in a way older versions can't read.
+// Version 2 added calendar events, habits and notes
+const BackupVersion = 2
+
+// Backup is the versioned document written by `prod export` and read by
+// `prod import`. IDs are only meaningful inside the document: they link tasks
+// to their parent, project, dependencies, events and notes and are remapped
+// on import
+type Backup struct {
+	Version          int                     `json:"version"`
+	ExportedAt       time.Time               `json:"exported_at"`
+	Projects         []BackupProject         `json:"projects"`
+	Tasks            []BackupTask            `json:"tasks"`
+	Dependencies     []BackupDependency      `json:"dependencies"`
+	PomodoroSessions []BackupPomodoroSession `json:"pomodoro_sessions"`
+	PomodoroPresets  []BackupPomodoroPreset  `json:"pomodoro_presets"`
+	PomodoroConfig   *BackupPomodoroConfig   `json:"pomodoro_config,omitempty"`
+	PomodoroGoal     *BackupPomodoroGoal     `json:"pomodoro_goal,omitempty"`
+	CalendarEvents   []BackupCalendarEvent   `json:"calendar_events"`
+	TaskEvents       []BackupTaskEvent       `json:"task_calendar"`
+	Habits           []BackupHabit           `json:"habits"`
+	Notes            []BackupNote            `json:"notes"`
+}
+
+// BackupProject is a project in a backup. Its preset is referenced by name
+type BackupProject struct {
+	ID          int32              `json:"id"`
+	Name        string             `json:"name"`
+	Description pgtype.Text        `json:"description"`
+	Deadline    pgtype.Timestamptz `json:"deadline"`
+	Preset      pgtype.Text        `json:"preset"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+// BackupTask is a task in a backup. ParentID and ProjectID refer to IDs in the same backup
+type BackupTask struct {
+	ID              int32              `json:"id"`
+	UUID            pgtype.UUID        `json:"uuid"`
+	ParentID        pgtype.Int4        `json:"parent_id"`
+	ProjectID       pgtype.Int4        `json:"project_id"`
+	Description     string             `json:"description"`
+	Status          string             `json:"status"`
+	Priority        pgtype.Text        `json:"priority"`
+	DueDate         pgtype.Timestamptz `json:"due_date"`
+	StartDate       pgtype.Timestamptz `json:"start_date"`
+	WaitUntil       pgtype.Timestamptz `json:"wait_until"`
+	Scheduled       pgtype.Timestamptz `json:"scheduled"`
+	CompletedAt     pgtype.Timestamptz `json:"completed_at"`
+	Recurrence      pgtype.Text        `json:"recurrence"`
+	Tags            []string           `json:"tags"`
+	Notes           pgtype.Text        `json:"notes"`
+	EstimateMinutes pgtype.Int4        `json:"estimate_minutes"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+}
+
+// BackupDependency records that TaskID depends on DependsOnID
+type BackupDependency struct {
+	TaskID      int32 `json:"task_id"`
+	DependsOnID int32 `json:"depends_on_id"`
+}
+
+// BackupPomodoroSession is a pomodoro session in a backup
+type BackupPomodoroSession struct {
+	TaskID             pgtype.Int4        `json:"task_id"`
+	Status             string             `json:"status"`
+	StartTime          pgtype.Timestamptz `json:"start_time"`
+	EndTime            pgtype.Timestamptz `json:"end_time"`
+	Duration           int32              `json:"duration"`
+	Completed          pgtype.Bool        `json:"completed"`
+	WorkDuration       int32              `json:"work_duration"`
+	BreakDuration      int32              `json:"break_duration"`
+	PauseTime          pgtype.Timestamptz `json:"pause_time"`
+	TotalPauseDuration pgtype.Int4        `json:"total_pause_duration"`
+	ActualWorkDuration pgtype.Int4        `json:"actual_work_duration"`
+	Note               pgtype.Text        `json:"note"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+}
+
+// BackupPomodoroPreset is a named pomodoro preset in a backup
+type BackupPomodoroPreset struct {
+	Name              string `json:"name"`
+	WorkDuration      int32  `json:"work_duration"`
+	BreakDuration     int32  `json:"break_duration"`
+	LongBreakDuration int32  `json:"long_break_duration"`
+	LongBreakInterval int32  `json:"long_break_interval"`
+}
+
+// BackupPomodoroConfig is the pomodoro configuration in a backup
+type BackupPomodoroConfig struct {
+	WorkDuration       int32 `json:"work_duration"`
+	BreakDuration      int32 `json:"break_duration"`
+	LongBreakDuration  int32 `json:"long_break_duration"`
+	LongBreakInterval  int32 `json:"long_break_interval"`
+	AutoStartBreaks    bool  `json:"auto_start_breaks"`
+	AutoStartPomodoros bool  `json:"auto_start_pomodoros"`
+	LinkTasks          bool  `json:"link_tasks"`
+}
+
+// BackupPomodoroGoal is the pomodoro goal in a backup
+type BackupPomodoroGoal struct {
+	DailyGoal  int32 `json:"daily_goal"`
+	WeeklyGoal int32 `json:"weekly_goal"`
+}
+
+// BackupCalendarEvent is a calendar event in a backup. A changed occurrence of
+// a recurring event has the UID of its series and a RecurrenceID
+type BackupCalendarEvent struct {
+	ID           int32              `json:"id"`
+	UID          string             `json:"uid"`
+	ProjectID    pgtype.Int4        `json:"project_id"`
+	Title        string             `json:"title"`
+	Description  pgtype.Text        `json:"description"`
+	StartTime    pgtype.Timestamptz `json:"start_time"`
+	EndTime      pgtype.Timestamptz `json:"end_time"`
+	AllDay       pgtype.Bool        `json:"all_day"`
+	Location     pgtype.Text        `json:"location"`
+	Rrule        pgtype.Text        `json:"rrule"`
+	Exdates      []time.Time        `json:"exdates"`
+	RecurrenceID pgtype.Timestamptz `json:"recurrence_id"`
+	Timezone     pgtype.Text        `json:"timezone"`
+	Sequence     int32              `json:"sequence"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+}
+
+// BackupTaskEvent links a task to an event it is planned in
+type BackupTaskEvent struct {
+	TaskID  int32 `json:"task_id"`
+	EventID int32 `json:"event_id"`
+}
+
+// BackupHabit is a habit in a backup with the days it was completed
+type BackupHabit struct {
+	Name        string             `json:"name"`
+	Description pgtype.Text        `json:"description"`
+	Frequency   string             `json:"frequency"`
+	Completions []pgtype.Date      `json:"completions"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+// BackupNote is a note in a backup. TaskIDs are the tasks it is attached to
+type BackupNote struct {
+	Title     string             `json:"title"`
+	Content   pgtype.Text        `json:"content"`
+	ProjectID pgtype.Int4        `json:"project_id"`
+	TaskIDs   []int32            `json:"task_ids"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+// ImportReport sums up what an import created or changed, what it skipped
+// because it already existed, and the conflicts it resolved along the way
+type ImportReport struct {
+	ProjectsCreated int
+	ProjectsMerged  int
+	TasksCreated    int
+	TasksUpdated    int
+	TasksTrashed    int
+	TasksSkipped    int
+	Dependencies    int
+	SessionsCreated int
+	SessionsSkipped int
+	PresetsImported int
+	EventsCreated   int
+	EventsUpdated   int
+	EventsDeleted   int
+	EventsSkipped   int
+	HabitsCreated   int
+	HabitsMerged    int
+	HabitsCompleted int
+	NotesCreated    int
+	NotesSkipped    int
+	Conflicts       []string
+}
+
+func (r *ImportReport) conflict(format string, args ...any) {
+	r.Conflicts = append(r.Conflicts, fmt.Sprintf(format, args...))
+}
+
+// ReadBackup decodes a backup and checks that this version can import it
+func ReadBackup(r io.Reader) (*Backup, error) {
+	var backup Backup
+	if err := json.NewDecoder(r).Decode(&backup); err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	if backup.Version < 1 {
+		return nil, fmt.Errorf("not a prod backup: missing version")
+	}
+	if backup.Version > BackupVersion {
+		return nil, fmt.Errorf("backup version %d is newer than the supported version %d, upgrade prod to import it", backup.Version, BackupVersion)
+	}
+
+	return &backup, nil
+}
+
+// WriteBackup encodes a backup as indented JSON
+func WriteBackup(w io.Writer, backup *Backup) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(backup); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	return nil
+}
+
+// BackupService exports a user's data to a Backup and imports it back
+type BackupService struct {
+	queries *sqlc.Queries
+	journal *journal
+}
+
+// NewBackupService creates a new BackupService
+func NewBackupService(queries *sqlc.Queries) *BackupService {
+	return &BackupService{
+		queries: queries,
+		journal: newJournal(queries),
+	}
+}
+
+// Export collects everything a user owns, except what is in the trash
+func (s *BackupService) Export(ctx context.Context, userID int32) (*Backup, error) {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+
+	backup := &Backup{
+		Version:          BackupVersion,
+		ExportedAt:       time.Now().UTC(),
+		Projects:         []BackupProject{},
+		Tasks:            []BackupTask{},
+		Dependencies:     []BackupDependency{},
+		PomodoroSessions: []BackupPomodoroSession{},
+		PomodoroPresets:  []BackupPomodoroPreset{},
+		CalendarEvents:   []BackupCalendarEvent{},
+		TaskEvents:       []BackupTaskEvent{},
+		Habits:           []BackupHabit{},
+		Notes:            []BackupNote{},
+	}
+
+	presets, err := s.queries.ListPomodoroPresets(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list presets: %w", err)
+	}
+	presetNames := make(map[int32]string, len(presets))
+	for _, preset := range presets {
+		presetNames[preset.ID] = preset.Name
+		backup.PomodoroPresets = append(backup.PomodoroPresets, BackupPomodoroPreset{
+			Name:              preset.Name,
+			WorkDuration:      preset.WorkDuration,
+			BreakDuration:     preset.BreakDuration,
+			LongBreakDuration: preset.LongBreakDuration,
+			LongBreakInterval: preset.LongBreakInterval,
+		})
+	}
+
+	projects, err := s.queries.ListProjects(ctx, user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	for _, project := range projects {
+		var preset pgtype.Text
+		if name, ok := presetNames[project.PomodoroPresetID.Int32]; ok && project.PomodoroPresetID.Valid {
+			preset = pgtype.Text{
+				String: name,
+				Valid:  true,
+			}
+		}
+		backup.Projects = append(backup.Projects, BackupProject{
+			ID:          project.ID,
+			Name:        project.Name,
+			Description: project.Description,
+			Deadline:    project.Deadline,
+			Preset:      preset,
+			CreatedAt:   project.CreatedAt,
+			UpdatedAt:   project.UpdatedAt,
+		})
+	}
+
+	tasks, err := s.queries.ListTasks(ctx, sqlc.ListTasksParams{
+		UserID: user,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	for _, task := range tasks {
+		backup.Tasks = append(backup.Tasks, BackupTask{
+			ID:              task.ID,
+			UUID:            task.Uuid,
+			ParentID:        task.Dependent,
+			ProjectID:       task.ProjectID,
+			Description:     task.Description,
+			Status:          task.Status,
+			Priority:        task.Priority,
+			DueDate:         task.DueDate,
+			StartDate:       task.StartDate,
+			WaitUntil:       task.WaitUntil,
+			Scheduled:       task.Scheduled,
+			CompletedAt:     task.CompletedAt,
+			Recurrence:      task.Recurrence,
+			Tags:            task.Tags,
+			Notes:           task.Notes,
+			EstimateMinutes: task.EstimateMinutes,
+			CreatedAt:       task.CreatedAt,
+			UpdatedAt:       task.UpdatedAt,
+		})
+	}
+
+	dependencies, err := s.queries.ListUserTaskDependencies(ctx, user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task dependencies: %w", err)
+	}
+	for _, dependency := range dependencies {
+		backup.Dependencies = append(backup.Dependencies, BackupDependency{
+			TaskID:      dependency.TaskID.Int32,
+			DependsOnID: dependency.DependsOnID.Int32,
+		})
+	}
+
+	sessions, err := s.queries.ListAllPomodoroSessions(ctx, user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pomodoro sessions: %w", err)
+	}
+	for _, session := range sessions {
+		backup.PomodoroSessions = append(backup.PomodoroSessions, BackupPomodoroSession{
+			TaskID:             session.TaskID,
+			Status:             session.Status,
+			StartTime:          session.StartTime,
+			EndTime:            session.EndTime,
+			Duration:           session.Duration,
+			Completed:          session.Completed,
+			WorkDuration:       session.WorkDuration,
+			BreakDuration:      session.BreakDuration,
+			PauseTime:          session.PauseTime,
+			TotalPauseDuration: session.TotalPauseDuration,
+			ActualWorkDuration: session.ActualWorkDuration,
+			Note:               session.Note,
+			CreatedAt:          session.CreatedAt,
+		})
+	}
+
+	config, err := s.queries.GetPomodoroConfig(ctx, userID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get pomodoro config: %w", err)
+	}
+	if err == nil {
+		backup.PomodoroConfig = &BackupPomodoroConfig{
+			WorkDuration:       config.WorkDuration,
+			BreakDuration:      config.BreakDuration,
+			LongBreakDuration:  config.LongBreakDuration,
+			LongBreakInterval:  config.LongBreakInterval,
+			AutoStartBreaks:    config.AutoStartBreaks,
+			AutoStartPomodoros: config.AutoStartPomodoros,
+			LinkTasks:          config.LinkTasks,
+		}
+	}
+
+	goal, err := s.queries.GetPomodoroGoal(ctx, userID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get pomodoro goal: %w", err)
+	}
+	if err == nil {
+		backup.PomodoroGoal = &BackupPomodoroGoal{
+			DailyGoal:  goal.DailyGoal,
+			WeeklyGoal: goal.WeeklyGoal,
+		}
+	}
+
+	if err := s.exportCalendar(ctx, user, backup); err != nil {
+		return nil, err
+	}
+	if err := s.exportHabits(ctx, user, backup); err != nil {
+		return nil, err
+	}
+	if err := s.exportNotes(ctx, user, backup); err != nil {
+		return nil, err
+	}
+
+	return backup, nil
+}
+
+// exportCalendar adds the calendar events and their links to tasks
+func (s *BackupService) exportCalendar(ctx context.Context, user pgtype.Int4, backup *Backup) error {
+	events, err := s.queries.ListCalendarEvents(ctx, user)
+	if err != nil {
+		return fmt.Errorf("failed to list calendar events: %w", err)
+	}
+	for _, event := range events {
+		backup.CalendarEvents = append(backup.CalendarEvents, BackupCalendarEvent{
+			ID:           event.ID,
+			UID:          event.Uid,
+			ProjectID:    event.ProjectID,
+			Title:        event.Title,
+			Description:  event.Description,
+			StartTime:    event.StartTime,
+			EndTime:      event.EndTime,
+			AllDay:       event.AllDay,
+			Location:     event.Location,
+			Rrule:        event.Rrule,
+			Exdates:      event.Exdates,
+			RecurrenceID: event.RecurrenceID,
+			Timezone:     event.Timezone,
+			Sequence:     event.Sequence,
+			CreatedAt:    event.CreatedAt,
+			UpdatedAt:    event.UpdatedAt,
+		})
+	}
+
+	links, err := s.queries.ListUserTaskCalendarLinks(ctx, user)
+	if err != nil {
+		return fmt.Errorf("failed to list task events: %w", err)
+	}
+	for _, link := range links {
+		backup.TaskEvents = append(backup.TaskEvents, BackupTaskEvent{
+			TaskID:  link.TaskID.Int32,
+			EventID: link.EventID.Int32,
+		})
+	}
+
+	return nil
+}
+
+// exportHabits adds the habits with their completions
+func (s *BackupService) exportHabits(ctx context.Context, user pgtype.Int4, backup *Backup) error {
+	completions, err := s.queries.ListUserHabitCompletions(ctx, user)
+	if err != nil {
+		return fmt.Errorf("failed to list habit completions: %w", err)
+	}
+	days := make(map[int32][]pgtype.Date)
+	for _, completion := range completions {
+		days[completion.HabitID.Int32] = append(days[completion.HabitID.Int32], completion.CompletedDate)
+	}
+
+	habits, err := s.queries.ListHabits(ctx, user)
+	if err != nil {
+		return fmt.Errorf("failed to list habits: %w", err)
+	}
+	for _, habit := range habits {
+		completed := days[habit.ID]
+		if completed == nil {
+			completed = []pgtype.Date{}
+		}
+		backup.Habits = append(backup.Habits, BackupHabit{
+			Name:        habit.Name,
+			Description: habit.Description,
+			Frequency:   habit.Frequency,
+			Completions: completed,
+			CreatedAt:   habit.CreatedAt,
+			UpdatedAt:   habit.UpdatedAt,
+		})
+	}
+
+	return nil
+}
+
+// exportNotes adds the notes with the tasks they are attached to
+func (s *BackupService) exportNotes(ctx context.Context, user pgtype.Int4, backup *Backup) error {
+	links, err := s.queries.ListUserTaskNotes(ctx, user)
+	if err != nil {
+		return fmt.Errorf("failed to list task notes: %w", err)
+	}
+	taskIDs := make(map[int32][]int32)
+	for _, link := range links {
+		taskIDs[link.NoteID.Int32] = append(taskIDs[link.NoteID.Int32], link.TaskID.Int32)
+	}
+
+	notes, err := s.queries.ListNotes(ctx, user)
+	if err != nil {
+		return fmt.Errorf("failed to list notes: %w", err)
+	}
+	for _, note := range notes {
+		tasks := taskIDs[note.ID]
+		if tasks == nil {
+			tasks = []int32{}
+		}
+		backup.Notes = append(backup.Notes, BackupNote{
+			Title:     note.Title,
+			Content:   note.Content,
+			ProjectID: note.ProjectID,
+			TaskIDs:   tasks,
+			CreatedAt: note.CreatedAt,
+			UpdatedAt: note.UpdatedAt,
+		})
+	}
+
+	return nil
+}
+
+// Import loads a backup into the user's account, giving everything a new ID.
+// Projects and habits with the same name are merged, and tasks, sessions,
+// events and notes that were already imported are skipped, so importing the
+// same file twice is harmless. The created projects and tasks can be undone as
+// one operation. Run it in a UnitOfWork
+func (s *BackupService) Import(ctx context.Context, userID int32, backup *Backup) (*ImportReport, error) {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+	report := &ImportReport{}
+
+	presetIDs, err := s.importPresets(ctx, userID, backup.PomodoroPresets, report)
+	if err != nil {
+		return nil, err
+	}
+
+	projectIDs := make(map[int32]int32, len(backup.Projects))
+	for _, project := range backup.Projects {
+		existing, err := s.queries.GetProjectByName(ctx, sqlc.GetProjectByNameParams{
+			UserID: user,
+			Name:   project.Name,
+		})
+		if err == nil {
+			projectIDs[project.ID] = existing.ID
+			report.ProjectsMerged++
+			report.conflict("project %q already exists, its tasks were added to it", project.Name)
+			continue
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("failed to look up project %q: %w", project.Name, err)
+		}
+
+		var presetID pgtype.Int4
+		if id, ok := presetIDs[project.Preset.String]; ok && project.Preset.Valid {
+			presetID = pgtype.Int4{
+				Int32: id,
+				Valid: true,
+			}
+		}
+
+		created, err := s.queries.ImportProject(ctx, sqlc.ImportProjectParams{
+			UserID:           user,
+			Name:             project.Name,
+			Description:      project.Description,
+			Deadline:         project.Deadline,
+			CreatedAt:        project.CreatedAt,
+			UpdatedAt:        project.UpdatedAt,
+			PomodoroPresetID: presetID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to import project %q: %w", project.Name, err)
+		}
+		if err := s.journal.recordProject(ctx, userID, "import backup", nil, &created); err != nil {
+			return nil, err
+		}
+		projectIDs[project.ID] = created.ID
+		report.ProjectsCreated++
+	}
+
+	taskIDs, created, err := s.importTasks(ctx, userID, backup.Tasks, projectIDs, report)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dependency := range backup.Dependencies {
+		taskID, ok1 := taskIDs[dependency.TaskID]
+		dependsOnID, ok2 := taskIDs[dependency.DependsOnID]
+		if !ok1 || !ok2 {
+			report.conflict("dependency of task %d on task %d refers to a task missing from the backup", dependency.TaskID, dependency.DependsOnID)
+			continue
+		}
+		// Dependencies between skipped tasks are already there
+		if !created[taskID] && !created[dependsOnID] {
+			continue
+		}
+		err := s.queries.AddTaskDependency(ctx, sqlc.AddTaskDependencyParams{
+			TaskID: pgtype.Int4{
+				Int32: taskID,
+				Valid: true,
+			},
+			DependsOnID: pgtype.Int4{
+				Int32: dependsOnID,
+				Valid: true,
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to import task dependency: %w", err)
+		}
+		report.Dependencies++
+	}
+
+	for _, session := range backup.PomodoroSessions {
+		count, err := s.queries.CountPomodoroSessionsStartingAt(ctx, sqlc.CountPomodoroSessionsStartingAtParams{
+			UserID:    user,
+			StartTime: session.StartTime,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to check pomodoro sessions: %w", err)
+		}
+		if count > 0 {
+			report.SessionsSkipped++
+			continue
+		}
+
+		var taskID pgtype.Int4
+		if id, ok := taskIDs[session.TaskID.Int32]; ok && session.TaskID.Valid {
+			taskID = pgtype.Int4{
+				Int32: id,
+				Valid: true,
+			}
+		}
+
+		_, err = s.queries.ImportPomodoroSession(ctx, sqlc.ImportPomodoroSessionParams{
+			UserID:             user,
+			TaskID:             taskID,
+			Status:             session.Status,
+			StartTime:          session.StartTime,
+			EndTime:            session.EndTime,
+			Duration:           session.Duration,
+			Completed:          session.Completed,
+			WorkDuration:       session.WorkDuration,
+			BreakDuration:      session.BreakDuration,
+			PauseTime:          session.PauseTime,
+			TotalPauseDuration: session.TotalPauseDuration,
+			ActualWorkDuration: session.ActualWorkDuration,
+			Note:               session.Note,
+			CreatedAt:          session.CreatedAt,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to import pomodoro session: %w", err)
+		}
+		report.SessionsCreated++
+	}
+
+	if err := s.importSettings(ctx, userID, backup, report); err != nil {
+		return nil, err
+	}
+	if err := s.importCalendar(ctx, user, backup, taskIDs, projectIDs, report); err != nil {
+		return nil, err
+	}
+	if err := s.importHabits(ctx, user, backup.Habits, report); err != nil {
+		return nil, err
+	}
+	if err := s.importNotes(ctx, user, backup.Notes, taskIDs, projectIDs, report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// importCalendar creates the events of a backup that aren't there yet, matched
+// on their UID, and links them to their tasks
+func (s *BackupService) importCalendar(ctx context.Context, user pgtype.Int4, backup *Backup, taskIDs, projectIDs map[int32]int32, report *ImportReport) error {
+	eventIDs := make(map[int32]int32, len(backup.CalendarEvents))
+	for _, event := range backup.CalendarEvents {
+		existing, err := s.queries.GetCalendarEventByUID(ctx, sqlc.GetCalendarEventByUIDParams{
+			UserID:       user,
+			Uid:          event.UID,
+			RecurrenceID: event.RecurrenceID,
+		})
+		if err == nil {
+			eventIDs[event.ID] = existing.ID
+			report.EventsSkipped++
+			continue
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to look up event %q: %w", event.Title, err)
+		}
+
+		var projectID pgtype.Int4
+		if event.ProjectID.Valid {
+			if id, ok := projectIDs[event.ProjectID.Int32]; ok {
+				projectID = pgtype.Int4{
+					Int32: id,
+					Valid: true,
+				}
+			} else {
+				report.conflict("project of event %q is missing from the backup, it was imported without a project", event.Title)
+			}
+		}
+
+		exdates := event.Exdates
+		if exdates == nil {
+			exdates = []time.Time{}
+		}
+		created, err := s.queries.ImportCalendarEvent(ctx, sqlc.ImportCalendarEventParams{
+			UserID:       user,
+			Title:        event.Title,
+			Description:  event.Description,
+			StartTime:    event.StartTime,
+			EndTime:      event.EndTime,
+			AllDay:       event.AllDay,
+			Location:     event.Location,
+			ProjectID:    projectID,
+			Uid:          event.UID,
+			Rrule:        event.Rrule,
+			Exdates:      exdates,
+			RecurrenceID: event.RecurrenceID,
+			Timezone:     event.Timezone,
+			Sequence:     event.Sequence,
+			CreatedAt:    event.CreatedAt,
+			UpdatedAt:    event.UpdatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to import event %q: %w", event.Title, err)
+		}
+		eventIDs[event.ID] = created.ID
+		report.EventsCreated++
+	}
+
+	for _, link := range backup.TaskEvents {
+		taskID, ok1 := taskIDs[link.TaskID]
+		eventID, ok2 := eventIDs[link.EventID]
+		if !ok1 || !ok2 {
+			report.conflict("link of task %d to event %d refers to an item missing from the backup", link.TaskID, link.EventID)
+			continue
+		}
+		err := s.queries.LinkTaskToEvent(ctx, sqlc.LinkTaskToEventParams{
+			TaskID: pgtype.Int4{
+				Int32: taskID,
+				Valid: true,
+			},
+			EventID: pgtype.Int4{
+				Int32: eventID,
+				Valid: true,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to link task to event: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// importHabits creates the habits of a backup, merging them into habits with
+// the same name, and adds the days they were completed
+func (s *BackupService) importHabits(ctx context.Context, user pgtype.Int4, habits []BackupHabit, report *ImportReport) error {
+	for _, habit := range habits {
+		existing, err := s.queries.GetHabitByName(ctx, sqlc.GetHabitByNameParams{
+			UserID: user,
+			Name:   habit.Name,
+		})
+		if err == nil {
+			report.HabitsMerged++
+			if existing.Frequency != habit.Frequency {
+				report.conflict("habit %q already exists with frequency %s, its completions were added to it", habit.Name, existing.Frequency)
+			}
+		} else if errors.Is(err, pgx.ErrNoRows) {
+			existing, err = s.queries.ImportHabit(ctx, sqlc.ImportHabitParams{
+				UserID:      user,
+				Name:        habit.Name,
+				Description: habit.Description,
+				Frequency:   habit.Frequency,
+				CreatedAt:   habit.CreatedAt,
+				UpdatedAt:   habit.UpdatedAt,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to import habit %q: %w", habit.Name, err)
+			}
+			report.HabitsCreated++
+		} else {
+			return fmt.Errorf("failed to look up habit %q: %w", habit.Name, err)
+		}
+
+		for _, day := range habit.Completions {
+			added, err := s.queries.AddHabitCompletion(ctx, sqlc.AddHabitCompletionParams{
+				HabitID: pgtype.Int4{
+					Int32: existing.ID,
+					Valid: true,
+				},
+				CompletedDate: day,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to import completion of habit %q: %w", habit.Name, err)
+			}
+			report.HabitsCompleted += int(added)
+		}
+	}
+
+	return nil
+}
+
+// importNotes creates the notes of a backup that weren't imported yet and
+// attaches them to their tasks
+func (s *BackupService) importNotes(ctx context.Context, user pgtype.Int4, notes []BackupNote, taskIDs, projectIDs map[int32]int32, report *ImportReport) error {
+	for _, note := range notes {
+		imported, err := s.queries.FindDuplicateNote(ctx, sqlc.FindDuplicateNoteParams{
+			UserID:    user,
+			Title:     note.Title,
+			CreatedAt: note.CreatedAt,
+		})
+		if err == nil {
+			report.NotesSkipped++
+		} else if errors.Is(err, pgx.ErrNoRows) {
+			var projectID pgtype.Int4
+			if note.ProjectID.Valid {
+				if id, ok := projectIDs[note.ProjectID.Int32]; ok {
+					projectID = pgtype.Int4{
+						Int32: id,
+						Valid: true,
+					}
+				} else {
+					report.conflict("project of note %q is missing from the backup, it was imported without a project", note.Title)
+				}
+			}
+
+			imported, err = s.queries.ImportNote(ctx, sqlc.ImportNoteParams{
+				UserID:    user,
+				Title:     note.Title,
+				Content:   note.Content,
+				ProjectID: projectID,
+				CreatedAt: note.CreatedAt,
+				UpdatedAt: note.UpdatedAt,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to import note %q: %w", note.Title, err)
+			}
+			report.NotesCreated++
+		} else {
+			return fmt.Errorf("failed to look up note %q: %w", note.Title, err)
+		}
+
+		for _, id := range note.TaskIDs {
+			taskID, ok := taskIDs[id]
+			if !ok {
+				report.conflict("task %d of note %q is missing from the backup", id, note.Title)
+				continue
+			}
+			err := s.queries.LinkTaskToNote(ctx, sqlc.LinkTaskToNoteParams{
+				TaskID: pgtype.Int4{
+					Int32: taskID,
+					Valid: true,
+				},
+				NoteID: pgtype.Int4{
+					Int32: imported.ID,
+					Valid: true,
+				},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to attach note %q to its task: %w", note.Title, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// importTasks creates the tasks of a backup parents first and returns the
+// mapping from backup IDs to new IDs, and which of the new IDs were created
+func (s *BackupService) importTasks(ctx context.Context, userID int32, tasks []BackupTask, projectIDs map[int32]int32, report *ImportReport) (map[int32]int32, map[int32]bool, error) {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+
+	taskIDs := make(map[int32]int32, len(tasks))
+	created := make(map[int32]bool, len(tasks))
+	for _, task := range parentsFirst(tasks, report) {
+		id, isNew, err := s.importTask(ctx, userID, user, task, taskIDs, projectIDs, report)
+		if err != nil {
+			return nil, nil, err
+		}
+		taskIDs[task.ID] = id
+		created[id] = isNew
+	}
+
+	return taskIDs, created, nil
+}
+
+// parentsFirst orders tasks so every parent in the backup comes before its
+// subtasks, keeping the backup order otherwise
+func parentsFirst(tasks []BackupTask, report *ImportReport) []BackupTask {
+	inBackup := make(map[int32]bool, len(tasks))
+	for _, task := range tasks {
+		inBackup[task.ID] = true
+	}
+
+	ordered := make([]BackupTask, 0, len(tasks))
+	placed := make(map[int32]bool, len(tasks))
+	pending := tasks
+	for len(pending) > 0 {
+		var waiting []BackupTask
+		for _, task := range pending {
+			if task.ParentID.Valid && inBackup[task.ParentID.Int32] && !placed[task.ParentID.Int32] {
+				waiting = append(waiting, task)
+				continue
+			}
+			ordered = append(ordered, task)
+			placed[task.ID] = true
+		}
+
+		// Only a cycle in the hierarchy keeps every remaining task waiting,
+		// break it by detaching one of them
+		if len(waiting) == len(pending) {
+			report.conflict("task %q is part of a parent cycle and was imported without a parent", waiting[0].Description)
+			waiting[0].ParentID = pgtype.Int4{}
+		}
+		pending = waiting
+	}
+
+	return ordered
+}
+
+func (s *BackupService) importTask(ctx context.Context, userID int32, user pgtype.Int4, task BackupTask, taskIDs, projectIDs map[int32]int32, report *ImportReport) (int32, bool, error) {
+	uuid := task.UUID
+	if uuid.Valid {
+		existing, err := s.queries.GetTaskByUUID(ctx, sqlc.GetTaskByUUIDParams{
+			UserID: user,
+			Uuid:   uuid,
+		})
+		if err == nil && !existing.DeletedAt.Valid {
+			report.TasksSkipped++
+			return existing.ID, false, nil
+		}
+		if err == nil {
+			report.conflict("task %q is in the trash, it was imported again as a new task", task.Description)
+			uuid = newUUID()
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, fmt.Errorf("failed to look up task %q: %w", task.Description, err)
+		}
+	} else {
+		// Backups without UUIDs are matched on description and creation time
+		existing, err := s.queries.FindDuplicateTask(ctx, sqlc.FindDuplicateTaskParams{
+			UserID:      user,
+			Description: task.Description,
+			CreatedAt:   task.CreatedAt,
+		})
+		if err == nil {
+			report.TasksSkipped++
+			return existing.ID, false, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, fmt.Errorf("failed to look up task %q: %w", task.Description, err)
+		}
+		uuid = newUUID()
+	}
+
+	var parentID pgtype.Int4
+	if task.ParentID.Valid {
+		if id, ok := taskIDs[task.ParentID.Int32]; ok {
+			parentID = pgtype.Int4{
+				Int32: id,
+				Valid: true,
+			}
+		} else {
+			report.conflict("parent of task %q is missing from the backup, it was imported as a top-level task", task.Description)
+		}
+	}
+
+	var projectID pgtype.Int4
+	if task.ProjectID.Valid {
+		if id, ok := projectIDs[task.ProjectID.Int32]; ok {
+			projectID = pgtype.Int4{
+				Int32: id,
+				Valid: true,
+			}
+		} else {
+			report.conflict("project of task %q is missing from the backup, it was imported without a project", task.Description)
+		}
+	}
+
+	imported, err := s.queries.ImportTask(ctx, sqlc.ImportTaskParams{
+		UserID:          user,
+		Description:     task.Description,
+		Status:          task.Status,
+		Priority:        task.Priority,
+		DueDate:         task.DueDate,
+		StartDate:       task.StartDate,
+		CompletedAt:     task.CompletedAt,
+		ProjectID:       projectID,
+		Recurrence:      task.Recurrence,
+		Tags:            task.Tags,
+		Notes:           task.Notes,
+		CreatedAt:       task.CreatedAt,
+		UpdatedAt:       task.UpdatedAt,
+		Dependent:       parentID,
+		EstimateMinutes: task.EstimateMinutes,
+		Uuid:            uuid,
+		WaitUntil:       task.WaitUntil,
+		Scheduled:       task.Scheduled,
+	})
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to import task %q: %w", task.Description, err)
+	}
+	if err := s.journal.recordTask(ctx, userID, "import backup", nil, &imported); err != nil {
+		return 0, false, err
+	}
+	report.TasksCreated++
+
+	return imported.ID, true, nil
+}
+
+// importPresets upserts the presets of a backup and returns their IDs by name
+func (s *BackupService) importPresets(ctx context.Context, userID int32, presets []BackupPomodoroPreset, report *ImportReport) (map[string]int32, error) {
+	ids := make(map[string]int32, len(presets))
+	for _, preset := range presets {
+		existing, err := s.queries.GetPomodoroPresetByName(ctx, sqlc.GetPomodoroPresetByNameParams{
+			UserID: userID,
+			Name:   preset.Name,
+		})
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("failed to look up preset %q: %w", preset.Name, err)
+		}
+		if err == nil && (existing.WorkDuration != preset.WorkDuration ||
+			existing.BreakDuration != preset.BreakDuration ||
+			existing.LongBreakDuration != preset.LongBreakDuration ||
+			existing.LongBreakInterval != preset.LongBreakInterval) {
+			report.conflict("preset %q already exists with other durations, it was overwritten", preset.Name)
+		}
+
+		upserted, err := s.queries.UpsertPomodoroPreset(ctx, sqlc.UpsertPomodoroPresetParams{
+			UserID:            userID,
+			Name:              preset.Name,
+			WorkDuration:      preset.WorkDuration,
+			BreakDuration:     preset.BreakDuration,
+			LongBreakDuration: preset.LongBreakDuration,
+			LongBreakInterval: preset.LongBreakInterval,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to import preset %q: %w", preset.Name, err)
+		}
+		ids[preset.Name] = upserted.ID
+		report.PresetsImported++
+	}
+
+	return ids, nil
+}
+
+// importSettings replaces the pomodoro config and goal with the ones of the backup
+func (s *BackupService) importSettings(ctx context.Context, userID int32, backup *Backup, report *ImportReport) error {
+	if config := backup.PomodoroConfig; config != nil {
+		if _, err := s.queries.GetPomodoroConfig(ctx, userID); err == nil {
+			report.conflict("pomodoro config was replaced by the one in the backup")
+		}
+		_, err := s.queries.UpsertPomodoroConfig(ctx, sqlc.UpsertPomodoroConfigParams{
+			UserID:             userID,
+			WorkDuration:       config.WorkDuration,
+			BreakDuration:      config.BreakDuration,
+			LongBreakDuration:  config.LongBreakDuration,
+			LongBreakInterval:  config.LongBreakInterval,
+			AutoStartBreaks:    config.AutoStartBreaks,
+			AutoStartPomodoros: config.AutoStartPomodoros,
+			LinkTasks:          config.LinkTasks,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to import pomodoro config: %w", err)
+		}
+	}
+
+	if goal := backup.PomodoroGoal; goal != nil {
+		if _, err := s.queries.GetPomodoroGoal(ctx, userID); err == nil {
+			report.conflict("pomodoro goal was replaced by the one in the backup")
+		}
+		_, err := s.queries.UpsertPomodoroGoal(ctx, sqlc.UpsertPomodoroGoalParams{
+			UserID:     userID,
+			DailyGoal:  goal.DailyGoal,
+			WeeklyGoal: goal.WeeklyGoal,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to import pomodoro goal: %w", err)
+		}
+	}
+
+	return nil
+}
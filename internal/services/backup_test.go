@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/dbtest"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParentsFirst(t *testing.T) {
+	parent := func(id int32) pgtype.Int4 {
+		return pgtype.Int4{
+			Int32: id,
+			Valid: true,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		tasks     []BackupTask
+		want      []int32
+		detached  []int32
+		conflicts int
+	}{
+		{
+			name:  "backup order is kept",
+			tasks: []BackupTask{{ID: 3}, {ID: 1, ParentID: parent(3)}, {ID: 2}},
+			want:  []int32{3, 1, 2},
+		},
+		{
+			name: "subtasks wait for their parent",
+			tasks: []BackupTask{
+				{ID: 1, ParentID: parent(2)},
+				{ID: 2, ParentID: parent(3)},
+				{ID: 3},
+				{ID: 4},
+			},
+			want: []int32{3, 4, 2, 1},
+		},
+		{
+			name:  "parents missing from the backup",
+			tasks: []BackupTask{{ID: 1, ParentID: parent(9)}, {ID: 2}},
+			want:  []int32{1, 2},
+		},
+		{
+			name: "a cycle is broken at its first task",
+			tasks: []BackupTask{
+				{ID: 1, ParentID: parent(2)},
+				{ID: 2, ParentID: parent(1)},
+				{ID: 3, ParentID: parent(1)},
+				{ID: 4},
+			},
+			want:      []int32{4, 1, 2, 3},
+			detached:  []int32{1},
+			conflicts: 1,
+		},
+		{
+			name:      "a task that is its own parent",
+			tasks:     []BackupTask{{ID: 1, ParentID: parent(1)}},
+			want:      []int32{1},
+			detached:  []int32{1},
+			conflicts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &ImportReport{}
+			ordered := parentsFirst(tt.tasks, report)
+
+			var ids, detached []int32
+			for _, task := range ordered {
+				ids = append(ids, task.ID)
+				for _, original := range tt.tasks {
+					if original.ID == task.ID && original.ParentID.Valid && !task.ParentID.Valid {
+						detached = append(detached, task.ID)
+					}
+				}
+			}
+			assert.Equal(t, tt.want, ids)
+			assert.Equal(t, tt.detached, detached)
+			assert.Len(t, report.Conflicts, tt.conflicts)
+		})
+	}
+}
+
+func TestBackupReimport(t *testing.T) {
+	db, queries := dbtest.Open(t)
+	user := dbtest.User(t, queries)
+	ctx := context.Background()
+
+	created := pgtype.Timestamptz{
+		Time:  time.Date(2025, 5, 1, 8, 0, 0, 0, time.UTC),
+		Valid: true,
+	}
+	task := func(id int32, description string, parentID int32) BackupTask {
+		return BackupTask{
+			ID:          id,
+			UUID:        newUUID(),
+			ParentID:    pgtype.Int4{Int32: parentID, Valid: parentID != 0},
+			Description: description,
+			Status:      "pending",
+			CreatedAt:   created,
+			UpdatedAt:   created,
+		}
+	}
+	// The subtask comes first and the IDs are taken in the account already
+	backup := &Backup{
+		Tasks: []BackupTask{
+			task(1, "Pack boxes", 2),
+			task(2, "Move house", 0),
+			task(3, "Water plants", 0),
+		},
+		Dependencies: []BackupDependency{{TaskID: 1, DependsOnID: 3}},
+	}
+	// Older backups have no UUIDs and are matched on description and creation
+	backup.Tasks[2].UUID = pgtype.UUID{}
+
+	importBackup := func() *ImportReport {
+		t.Helper()
+		var report *ImportReport
+		err := NewUnitOfWork(db, queries).Do(ctx, func(tx *TxServices) error {
+			var err error
+			report, err = tx.Backup.Import(ctx, user.ID, backup)
+			return err
+		})
+		require.NoError(t, err)
+		return report
+	}
+	byUUID := func(task BackupTask) sqlc.Task {
+		t.Helper()
+		found, err := queries.GetTaskByUUID(ctx, sqlc.GetTaskByUUIDParams{
+			UserID: pgtype.Int4{Int32: user.ID, Valid: true},
+			Uuid:   task.UUID,
+		})
+		require.NoError(t, err)
+		return found
+	}
+
+	report := importBackup()
+	assert.Equal(t, 3, report.TasksCreated)
+	assert.Equal(t, 1, report.Dependencies)
+	assert.Empty(t, report.Conflicts)
+	subtask, parent := byUUID(backup.Tasks[0]), byUUID(backup.Tasks[1])
+	assert.Equal(t, pgtype.Int4{Int32: parent.ID, Valid: true}, subtask.Dependent, "the parent is linked by its new ID")
+
+	// Importing again changes nothing
+	report = importBackup()
+	assert.Equal(t, 0, report.TasksCreated)
+	assert.Equal(t, 3, report.TasksSkipped)
+	assert.Equal(t, 0, report.Dependencies)
+
+	// A task in the trash is imported as a new task
+	_, err := NewTaskService(queries).DeleteTask(ctx, parent.ID, user.ID)
+	require.NoError(t, err)
+	report = importBackup()
+	assert.Equal(t, 1, report.TasksCreated)
+	assert.Equal(t, 2, report.TasksSkipped)
+	assert.Len(t, report.Conflicts, 1)
+}
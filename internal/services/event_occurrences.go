@@ -0,0 +1,366 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jskallebak/prod/internal/db/sqlc"
+)
+
+// EventOccurrence is a calendar event at one of the times it takes place
+type EventOccurrence struct {
+	Event sqlc.CalendarEvent
+	Start time.Time
+	End   time.Time
+}
+
+// maxRRulePeriods bounds the expansion of repeat rules that never match
+const maxRRulePeriods = 100000
+
+// ExpandEvents returns the occurrences of events that overlap from until to,
+// ordered by start. Repeating events are expanded with their RRULE, leaving
+// out excluded and changed occurrences, as the changed ones are events of
+// their own. Repeat rules that can't be read only give their first occurrence
+func ExpandEvents(events []sqlc.CalendarEvent, from, to time.Time) []EventOccurrence {
+	changed := make(map[string][]time.Time)
+	for _, event := range events {
+		if event.RecurrenceID.Valid {
+			changed[event.Uid] = append(changed[event.Uid], event.RecurrenceID.Time)
+		}
+	}
+
+	var occurrences []EventOccurrence
+	add := func(event sqlc.CalendarEvent, start, end time.Time) {
+		if start.Before(to) && (end.After(from) || !start.Before(from)) {
+			occurrences = append(occurrences, EventOccurrence{
+				Event: event,
+				Start: start,
+				End:   end,
+			})
+		}
+	}
+
+	for _, event := range events {
+		start, end := event.StartTime.Time, event.EndTime.Time
+		if !event.Rrule.Valid || event.RecurrenceID.Valid {
+			add(event, start, end)
+			continue
+		}
+
+		// Repeat in the zone of the event so occurrences keep their time of
+		// day across daylight saving changes
+		loc := time.Local
+		if event.Timezone.Valid && !event.AllDay.Bool {
+			if l, err := time.LoadLocation(event.Timezone.String); err == nil {
+				loc = l
+			}
+		}
+		start = start.In(loc)
+
+		rule, err := parseRRule(event.Rrule.String, loc)
+		if err != nil {
+			add(event, start, end)
+			continue
+		}
+
+		skipped := append(slices.Clone(event.Exdates), changed[event.Uid]...)
+		days := dayCount(start, end.In(loc))
+		duration := end.Sub(start)
+		rule.each(start, to, func(occurrence time.Time) {
+			if slices.ContainsFunc(skipped, occurrence.Equal) {
+				return
+			}
+			if event.AllDay.Bool {
+				// All-day events last calendar days, not 24 hours
+				add(event, occurrence, occurrence.AddDate(0, 0, days))
+			} else {
+				add(event, occurrence, occurrence.Add(duration))
+			}
+		})
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].Start.Before(occurrences[j].Start)
+	})
+	return occurrences
+}
+
+// dayCount counts the calendar days from start to end
+func dayCount(start, end time.Time) int {
+	days := 0
+	for day := dateOf(start); dateOf(end).After(day); day = day.AddDate(0, 0, 1) {
+		days++
+	}
+	return days
+}
+
+// rruleDay is a BYDAY entry, like MO or the ordinal -1FR for the last Friday
+type rruleDay struct {
+	ordinal int
+	weekday time.Weekday
+}
+
+// rrule is a parsed iCalendar repeat rule. Of the BY parts, only BYDAY,
+// BYMONTHDAY and BYMONTH are supported
+type rrule struct {
+	freq       string
+	interval   int
+	byDay      []rruleDay
+	byMonthDay []int
+	byMonth    []int
+	until      time.Time
+	count      int
+}
+
+func parseRRule(value string, loc *time.Location) (*rrule, error) {
+	rule := &rrule{interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid RRULE part %q", part)
+		}
+		val = strings.ToUpper(val)
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			switch val {
+			case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+				rule.freq = val
+			default:
+				return nil, fmt.Errorf("unsupported frequency %s", val)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid interval %q", val)
+			}
+			rule.interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid count %q", val)
+			}
+			rule.count = n
+		case "UNTIL":
+			until, err := parseRRuleUntil(val, loc)
+			if err != nil {
+				return nil, err
+			}
+			rule.until = until
+		case "BYDAY":
+			for _, code := range strings.Split(val, ",") {
+				day := slices.Index(icalWeekDays, code[max(len(code)-2, 0):])
+				if day < 0 {
+					return nil, fmt.Errorf("invalid weekday %q", code)
+				}
+				ordinal := 0
+				if n := code[:len(code)-2]; n != "" {
+					var err error
+					if ordinal, err = strconv.Atoi(n); err != nil || ordinal == 0 {
+						return nil, fmt.Errorf("invalid weekday %q", code)
+					}
+				}
+				// icalWeekDays starts on Monday
+				rule.byDay = append(rule.byDay, rruleDay{ordinal, time.Weekday((day + 1) % 7)})
+			}
+		case "BYMONTHDAY":
+			for _, n := range strings.Split(val, ",") {
+				day, err := strconv.Atoi(n)
+				if err != nil || day == 0 || day < -31 || day > 31 {
+					return nil, fmt.Errorf("invalid day of month %q", n)
+				}
+				rule.byMonthDay = append(rule.byMonthDay, day)
+			}
+		case "BYMONTH":
+			for _, n := range strings.Split(val, ",") {
+				month, err := strconv.Atoi(n)
+				if err != nil || month < 1 || month > 12 {
+					return nil, fmt.Errorf("invalid month %q", n)
+				}
+				rule.byMonth = append(rule.byMonth, month)
+			}
+		case "WKST":
+			// Weeks start on Monday, the default
+		default:
+			return nil, fmt.Errorf("unsupported RRULE part %s", key)
+		}
+	}
+	if rule.freq == "" {
+		return nil, fmt.Errorf("RRULE has no frequency")
+	}
+	return rule, nil
+}
+
+// parseRRuleUntil parses UNTIL, which includes the whole day for dates
+func parseRRuleUntil(value string, loc *time.Location) (time.Time, error) {
+	if len(value) == len(icalDate) {
+		t, err := time.ParseInLocation(icalDate, value, time.Local)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid UNTIL %q", value)
+		}
+		return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(icalDateTimeUTC, value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid UNTIL %q", value)
+		}
+		return t, nil
+	}
+	t, err := time.ParseInLocation(icalDateTime, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid UNTIL %q", value)
+	}
+	return t, nil
+}
+
+// each calls fn with the occurrences starting at start before to, in order
+func (r *rrule) each(start, to time.Time, fn func(time.Time)) {
+	loc := start.Location()
+	hour, minute, second := start.Clock()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, minute, second, 0, loc)
+	}
+
+	n := 0
+	for period := 0; period < maxRRulePeriods; period++ {
+		var base time.Time
+		var candidates []time.Time
+		switch r.freq {
+		case "DAILY":
+			day := at(start.Year(), start.Month(), start.Day()+period*r.interval)
+			base = day
+			if r.matchesDay(day) {
+				candidates = append(candidates, day)
+			}
+		case "WEEKLY":
+			// Weeks start on Monday
+			offset := (int(start.Weekday()) + 6) % 7
+			monday := at(start.Year(), start.Month(), start.Day()-offset+7*period*r.interval)
+			base = monday
+			for i := 0; i < 7; i++ {
+				day := at(monday.Year(), monday.Month(), monday.Day()+i)
+				if r.inMonth(day) && (len(r.byDay) > 0 && r.onWeekday(day) || len(r.byDay) == 0 && day.Weekday() == start.Weekday()) {
+					candidates = append(candidates, day)
+				}
+			}
+		case "MONTHLY":
+			first := at(start.Year(), start.Month()+time.Month(period*r.interval), 1)
+			base = first
+			if r.inMonth(first) {
+				candidates = r.monthDays(first, start, at)
+			}
+		case "YEARLY":
+			year := start.Year() + period*r.interval
+			base = at(year, time.January, 1)
+			months := r.byMonth
+			if len(months) == 0 {
+				months = []int{int(start.Month())}
+			}
+			for _, month := range months {
+				candidates = append(candidates, r.monthDays(at(year, time.Month(month), 1), start, at)...)
+			}
+		}
+
+		if !base.Before(to) {
+			return
+		}
+		for _, candidate := range candidates {
+			if candidate.Before(start) {
+				continue
+			}
+			if !r.until.IsZero() && candidate.After(r.until) {
+				return
+			}
+			n++
+			if r.count > 0 && n > r.count {
+				return
+			}
+			if !candidate.Before(to) {
+				return
+			}
+			fn(candidate)
+		}
+	}
+}
+
+func (r *rrule) inMonth(t time.Time) bool {
+	return len(r.byMonth) == 0 || slices.Contains(r.byMonth, int(t.Month()))
+}
+
+func (r *rrule) onWeekday(t time.Time) bool {
+	return slices.ContainsFunc(r.byDay, func(d rruleDay) bool {
+		return d.weekday == t.Weekday()
+	})
+}
+
+// matchesDay applies the BY parts that limit a daily rule
+func (r *rrule) matchesDay(t time.Time) bool {
+	if !r.inMonth(t) || (len(r.byDay) > 0 && !r.onWeekday(t)) {
+		return false
+	}
+	if len(r.byMonthDay) > 0 {
+		last := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+		return slices.ContainsFunc(r.byMonthDay, func(d int) bool {
+			return d == t.Day() || d < 0 && last+1+d == t.Day()
+		})
+	}
+	return true
+}
+
+// monthDays lists the days of the month of first the rule takes place on, in
+// order. Without BYMONTHDAY or BYDAY it is the day of the month of start
+func (r *rrule) monthDays(first, start time.Time, at func(int, time.Month, int) time.Time) []time.Time {
+	last := time.Date(first.Year(), first.Month()+1, 0, 0, 0, 0, 0, first.Location()).Day()
+
+	var days []int
+	switch {
+	case len(r.byMonthDay) > 0:
+		for _, d := range r.byMonthDay {
+			if d < 0 {
+				d = last + 1 + d
+			}
+			if d < 1 || d > last {
+				continue
+			}
+			if len(r.byDay) > 0 && !r.onWeekday(at(first.Year(), first.Month(), d)) {
+				continue
+			}
+			days = append(days, d)
+		}
+	case len(r.byDay) > 0:
+		for _, byDay := range r.byDay {
+			var matching []int
+			for d := 1; d <= last; d++ {
+				if at(first.Year(), first.Month(), d).Weekday() == byDay.weekday {
+					matching = append(matching, d)
+				}
+			}
+			switch {
+			case byDay.ordinal == 0:
+				days = append(days, matching...)
+			case byDay.ordinal > 0 && byDay.ordinal <= len(matching):
+				days = append(days, matching[byDay.ordinal-1])
+			case byDay.ordinal < 0 && -byDay.ordinal <= len(matching):
+				days = append(days, matching[len(matching)+byDay.ordinal])
+			}
+		}
+	default:
+		// Months without the day are skipped, like 31 in April
+		if start.Day() <= last {
+			days = append(days, start.Day())
+		}
+	}
+
+	slices.Sort(days)
+	days = slices.Compact(days)
+	times := make([]time.Time, len(days))
+	for i, d := range days {
+		times[i] = at(first.Year(), first.Month(), d)
+	}
+	return times
+}
@@ -0,0 +1,659 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// iCalendar date and date-time formats (RFC 5545)
+const (
+	icalDate        = "20060102"
+	icalDateTime    = "20060102T150405"
+	icalDateTimeUTC = "20060102T150405Z"
+)
+
+// ICalProductID identifies prod as the producer of a calendar
+const ICalProductID = "-//prod//prod//EN"
+
+// ICalProperty is a content line of an iCalendar component
+type ICalProperty struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// ICalComponent is an iCalendar component such as VCALENDAR, VTODO or VEVENT
+type ICalComponent struct {
+	Name       string
+	Properties []ICalProperty
+	Components []*ICalComponent
+}
+
+// NewICalendar returns an empty VCALENDAR with the properties every calendar
+// needs. name is shown by calendar apps when they subscribe to it
+func NewICalendar(name string) *ICalComponent {
+	cal := &ICalComponent{Name: "VCALENDAR"}
+	cal.Add("VERSION", "2.0")
+	cal.Add("PRODID", ICalProductID)
+	cal.Add("CALSCALE", "GREGORIAN")
+	if name != "" {
+		cal.AddText("NAME", name)
+		cal.AddText("X-WR-CALNAME", name)
+	}
+	return cal
+}
+
+// Add appends a property whose value is written as is
+func (c *ICalComponent) Add(name, value string) {
+	c.Properties = append(c.Properties, ICalProperty{Name: name, Value: value})
+}
+
+// AddText appends a TEXT property, escaping its value
+func (c *ICalComponent) AddText(name, value string) {
+	c.Add(name, escapeICalText(value))
+}
+
+// AddTime appends a DATE-TIME property in UTC
+func (c *ICalComponent) AddTime(name string, t time.Time) {
+	c.Add(name, t.UTC().Format(icalDateTimeUTC))
+}
+
+// AddDate appends a DATE property
+func (c *ICalComponent) AddDate(name string, t time.Time) {
+	c.Properties = append(c.Properties, ICalProperty{
+		Name:   name,
+		Params: map[string]string{"VALUE": "DATE"},
+		Value:  t.Format(icalDate),
+	})
+}
+
+// Property returns the first property with the given name
+func (c *ICalComponent) Property(name string) (ICalProperty, bool) {
+	for _, prop := range c.Properties {
+		if prop.Name == name {
+			return prop, true
+		}
+	}
+	return ICalProperty{}, false
+}
+
+// WriteICalendar writes a component and its subcomponents as iCalendar text
+func WriteICalendar(w io.Writer, cal *ICalComponent) error {
+	bw := bufio.NewWriter(w)
+	writeICalComponent(bw, cal)
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write calendar: %w", err)
+	}
+	return nil
+}
+
+func writeICalComponent(w *bufio.Writer, c *ICalComponent) {
+	writeICalLine(w, "BEGIN:"+c.Name)
+	for _, prop := range c.Properties {
+		var line strings.Builder
+		line.WriteString(prop.Name)
+		for _, key := range slices.Sorted(maps.Keys(prop.Params)) {
+			value := prop.Params[key]
+			if strings.ContainsAny(value, ":;,") {
+				value = `"` + value + `"`
+			}
+			fmt.Fprintf(&line, ";%s=%s", key, value)
+		}
+		line.WriteString(":")
+		line.WriteString(prop.Value)
+		writeICalLine(w, line.String())
+	}
+	for _, sub := range c.Components {
+		writeICalComponent(w, sub)
+	}
+	writeICalLine(w, "END:"+c.Name)
+}
+
+// writeICalLine writes a content line folded at 75 octets, without splitting
+// a UTF-8 character
+func writeICalLine(w *bufio.Writer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space of a continuation line counts towards its length
+		limit = 74
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+func escapeICalText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// icalRRule converts a prod recurrence pattern to an iCalendar RRULE value
+func icalRRule(recurrence string) (string, bool) {
+	pattern, err := ParseRecurrence(recurrence)
+	if err != nil {
+		return "", false
+	}
+
+	parts := []string{"FREQ=" + strings.ToUpper(string(pattern.Type))}
+	if pattern.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", pattern.Interval))
+	}
+
+	switch pattern.Type {
+	case RecurrenceWeekly:
+		if len(pattern.WeekDays) > 0 {
+			days := make([]string, len(pattern.WeekDays))
+			for i, day := range pattern.WeekDays {
+				days[i] = icalWeekDays[day-1]
+			}
+			parts = append(parts, "BYDAY="+strings.Join(days, ","))
+		}
+	case RecurrenceMonthly:
+		if pattern.MonthWeekDay != nil {
+			week := pattern.MonthWeekDay.Week
+			if week == 5 {
+				week = -1
+			}
+			parts = append(parts, fmt.Sprintf("BYDAY=%d%s", week, icalWeekDays[pattern.MonthWeekDay.WeekDay-1]))
+		} else if pattern.MonthDay != 0 {
+			parts = append(parts, fmt.Sprintf("BYMONTHDAY=%d", pattern.MonthDay))
+		}
+	case RecurrenceYearly:
+		if pattern.YearlyDate != nil {
+			parts = append(parts,
+				fmt.Sprintf("BYMONTH=%d", pattern.YearlyDate.Month),
+				fmt.Sprintf("BYMONTHDAY=%d", pattern.YearlyDate.Day))
+		}
+	}
+
+	if pattern.Until != nil {
+		// The whole until day is included
+		until := time.Date(pattern.Until.Year(), pattern.Until.Month(), pattern.Until.Day(), 23, 59, 59, 0, time.Local)
+		parts = append(parts, "UNTIL="+until.UTC().Format(icalDateTimeUTC))
+	} else if pattern.Count > 0 {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", pattern.Count))
+	}
+
+	return strings.Join(parts, ";"), true
+}
+
+// icalWeekDays are the iCalendar weekday codes, Monday first like prod
+var icalWeekDays = []string{"MO", "TU", "WE", "TH", "FR", "SA", "SU"}
+
+// ParseICalendar reads the components of an iCalendar stream, normally one
+// VCALENDAR. Folded lines are joined and property values are kept raw, use
+// ICalText to unescape TEXT values
+func ParseICalendar(r io.Reader) ([]*ICalComponent, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+
+	// Unfold lines continued with a leading space or tab
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, strings.TrimSuffix(line, "\r"))
+	}
+
+	var roots []*ICalComponent
+	var stack []*ICalComponent
+	for n, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		prop, err := parseICalLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+
+		switch prop.Name {
+		case "BEGIN":
+			component := &ICalComponent{Name: strings.ToUpper(prop.Value)}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Components = append(parent.Components, component)
+			} else {
+				roots = append(roots, component)
+			}
+			stack = append(stack, component)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(prop.Value) {
+				return nil, fmt.Errorf("line %d: unexpected END:%s", n+1, prop.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("line %d: property %s outside of a component", n+1, prop.Name)
+			}
+			current := stack[len(stack)-1]
+			current.Properties = append(current.Properties, prop)
+		}
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("missing END:%s", stack[len(stack)-1].Name)
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no calendar found")
+	}
+
+	return roots, nil
+}
+
+// parseICalLine splits a content line into its name, parameters and value.
+// Parameter values may be quoted, and quoted values may contain : and ;
+func parseICalLine(line string) (ICalProperty, error) {
+	prop := ICalProperty{}
+
+	end := strings.IndexAny(line, ";:")
+	if end <= 0 {
+		return prop, fmt.Errorf("invalid content line %q", line)
+	}
+	prop.Name = strings.ToUpper(line[:end])
+	rest := line[end:]
+
+	for strings.HasPrefix(rest, ";") {
+		rest = rest[1:]
+		eq := strings.Index(rest, "=")
+		if eq <= 0 {
+			return prop, fmt.Errorf("invalid parameter in %q", line)
+		}
+		key := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			closing := strings.Index(rest[1:], `"`)
+			if closing < 0 {
+				return prop, fmt.Errorf("unterminated quote in %q", line)
+			}
+			value = rest[1 : closing+1]
+			rest = rest[closing+2:]
+		} else {
+			stop := strings.IndexAny(rest, ";:")
+			if stop < 0 {
+				return prop, fmt.Errorf("missing value in %q", line)
+			}
+			value = rest[:stop]
+			rest = rest[stop:]
+		}
+
+		if prop.Params == nil {
+			prop.Params = make(map[string]string)
+		}
+		prop.Params[key] = value
+	}
+
+	if !strings.HasPrefix(rest, ":") {
+		return prop, fmt.Errorf("missing value in %q", line)
+	}
+	prop.Value = rest[1:]
+
+	return prop, nil
+}
+
+// ICalText unescapes a TEXT value
+func ICalText(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			i++
+			switch value[i] {
+			case 'n', 'N':
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(value[i])
+			}
+			continue
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
+
+// splitICalList splits a list value such as CATEGORIES on unescaped commas
+func splitICalList(value string) []string {
+	var items []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case ',':
+			items = append(items, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, value[start:])
+}
+
+// icalZones resolves the TZID parameters of a calendar to locations
+type icalZones struct {
+	calendar *ICalComponent
+	cache    map[string]*time.Location
+}
+
+func newICalZones(calendar *ICalComponent) *icalZones {
+	return &icalZones{
+		calendar: calendar,
+		cache:    make(map[string]*time.Location),
+	}
+}
+
+// location finds the location of a TZID. IANA names are loaded directly and
+// the Windows names used by Outlook are mapped to them. Other zones fall back
+// to the standard offset of their VTIMEZONE, or to local time
+func (z *icalZones) location(tzid string) *time.Location {
+	if loc, ok := z.cache[tzid]; ok {
+		return loc
+	}
+
+	loc := time.Local
+	name := strings.TrimPrefix(tzid, "/mozilla.org/20050126_1/")
+	if iana, ok := windowsTimeZones[name]; ok {
+		name = iana
+	}
+	if l, err := time.LoadLocation(name); err == nil {
+		loc = l
+	} else if offset, ok := z.standardOffset(tzid); ok {
+		loc = time.FixedZone(tzid, offset)
+	}
+
+	z.cache[tzid] = loc
+	return loc
+}
+
+// standardOffset returns the TZOFFSETTO of the STANDARD part of a VTIMEZONE
+func (z *icalZones) standardOffset(tzid string) (int, bool) {
+	if z.calendar == nil {
+		return 0, false
+	}
+	for _, vtimezone := range z.calendar.Components {
+		if vtimezone.Name != "VTIMEZONE" {
+			continue
+		}
+		if id, _ := vtimezone.Property("TZID"); id.Value != tzid {
+			continue
+		}
+		for _, part := range vtimezone.Components {
+			if part.Name != "STANDARD" {
+				continue
+			}
+			if prop, ok := part.Property("TZOFFSETTO"); ok {
+				return parseICalOffset(prop.Value)
+			}
+		}
+	}
+	return 0, false
+}
+
+// parseICalOffset parses a UTC offset like +0100 or -053000 to seconds
+func parseICalOffset(value string) (int, bool) {
+	if len(value) != 5 && len(value) != 7 {
+		return 0, false
+	}
+	sign := 1
+	switch value[0] {
+	case '-':
+		sign = -1
+	case '+':
+	default:
+		return 0, false
+	}
+	digits := value[1:] + "00"
+	hours, err1 := strconv.Atoi(digits[0:2])
+	minutes, err2 := strconv.Atoi(digits[2:4])
+	seconds, err3 := strconv.Atoi(digits[4:6])
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0, false
+	}
+	return sign * (hours*3600 + minutes*60 + seconds), true
+}
+
+// values parses the DATE or DATE-TIME values of a property. allDay is true
+// for dates, which are returned as midnight local time
+func (z *icalZones) values(prop ICalProperty) (times []time.Time, allDay bool, err error) {
+	for _, value := range strings.Split(prop.Value, ",") {
+		t, date, err := z.parse(prop, value)
+		if err != nil {
+			return nil, false, err
+		}
+		times = append(times, t)
+		allDay = date
+	}
+	return times, allDay, nil
+}
+
+// value parses the single DATE or DATE-TIME value of a property
+func (z *icalZones) value(prop ICalProperty) (time.Time, bool, error) {
+	return z.parse(prop, prop.Value)
+}
+
+func (z *icalZones) parse(prop ICalProperty, value string) (time.Time, bool, error) {
+	if prop.Params["VALUE"] == "DATE" || len(value) == len(icalDate) {
+		t, err := time.ParseInLocation(icalDate, value, time.Local)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date %q in %s", value, prop.Name)
+		}
+		return t, true, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(icalDateTimeUTC, value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date-time %q in %s", value, prop.Name)
+		}
+		return t, false, nil
+	}
+
+	// Times without a zone are floating and read as local time
+	loc := time.Local
+	if tzid, ok := prop.Params["TZID"]; ok {
+		loc = z.location(tzid)
+	}
+	t, err := time.ParseInLocation(icalDateTime, value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid date-time %q in %s", value, prop.Name)
+	}
+	return t, false, nil
+}
+
+var icalDurationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseICalDuration parses a DURATION value like PT1H30M or P1D
+func parseICalDuration(value string) (time.Duration, error) {
+	match := icalDurationPattern.FindStringSubmatch(value)
+	if match == nil || value == "P" || strings.HasSuffix(value, "T") {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if match[i+2] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(match[i+2])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		d += time.Duration(n) * unit
+	}
+	if match[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+// prodRecurrenceFromRRule converts an iCalendar RRULE to the prod recurrence
+// format. Rules prod can't express, like hourly ones or several days of the
+// month, are not converted
+func prodRecurrenceFromRRule(rrule string) (string, bool) {
+	rule := make(map[string]string)
+	for _, part := range strings.Split(rrule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return "", false
+		}
+		rule[strings.ToUpper(key)] = strings.ToUpper(value)
+	}
+
+	interval := 1
+	if value, ok := rule["INTERVAL"]; ok {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return "", false
+		}
+		interval = n
+	}
+
+	for key := range rule {
+		switch key {
+		case "FREQ", "INTERVAL", "BYDAY", "BYMONTHDAY", "BYMONTH", "UNTIL", "COUNT", "WKST":
+		default:
+			return "", false
+		}
+	}
+
+	var detail string
+	freq := strings.ToLower(rule["FREQ"])
+	switch freq {
+	case "daily":
+		if rule["BYDAY"] != "" || rule["BYMONTHDAY"] != "" || rule["BYMONTH"] != "" {
+			return "", false
+		}
+	case "weekly":
+		if rule["BYMONTHDAY"] != "" || rule["BYMONTH"] != "" {
+			return "", false
+		}
+		if byday := rule["BYDAY"]; byday != "" {
+			var days []string
+			for _, code := range strings.Split(byday, ",") {
+				day := slices.Index(icalWeekDays, code)
+				if day < 0 {
+					return "", false
+				}
+				days = append(days, strconv.Itoa(day+1))
+			}
+			detail = strings.Join(days, ",")
+		}
+	case "monthly":
+		if rule["BYMONTH"] != "" || (rule["BYDAY"] != "" && rule["BYMONTHDAY"] != "") {
+			return "", false
+		}
+		if byday := rule["BYDAY"]; byday != "" {
+			if len(byday) < 3 {
+				return "", false
+			}
+			week, err := strconv.Atoi(byday[:len(byday)-2])
+			day := slices.Index(icalWeekDays, byday[len(byday)-2:])
+			if err != nil || day < 0 || week == 0 || week < -1 || week > 5 {
+				return "", false
+			}
+			if week == -1 {
+				week = 5
+			}
+			detail = fmt.Sprintf("%dw%d", week, day+1)
+		} else if monthday := rule["BYMONTHDAY"]; monthday != "" {
+			if monthday == "-1" {
+				detail = "last"
+			} else if day, err := strconv.Atoi(monthday); err == nil && day >= 1 && day <= 31 {
+				detail = strconv.Itoa(day)
+			} else {
+				return "", false
+			}
+		}
+	case "yearly":
+		if rule["BYDAY"] != "" || (rule["BYMONTH"] == "") != (rule["BYMONTHDAY"] == "") {
+			return "", false
+		}
+		if rule["BYMONTH"] != "" {
+			month, err1 := strconv.Atoi(rule["BYMONTH"])
+			day, err2 := strconv.Atoi(rule["BYMONTHDAY"])
+			if err1 != nil || err2 != nil {
+				return "", false
+			}
+			detail = fmt.Sprintf("%02d%02d", month, day)
+		}
+	default:
+		return "", false
+	}
+
+	recurrence := fmt.Sprintf("%s:%d:%s", freq, interval, detail)
+	if until := rule["UNTIL"]; until != "" {
+		var t time.Time
+		var err error
+		if strings.HasSuffix(until, "Z") {
+			t, err = time.Parse(icalDateTimeUTC, until)
+			t = t.Local()
+		} else if len(until) == len(icalDate) {
+			t, err = time.ParseInLocation(icalDate, until, time.Local)
+		} else {
+			t, err = time.ParseInLocation(icalDateTime, until, time.Local)
+		}
+		if err != nil {
+			return "", false
+		}
+		recurrence += ":until:" + t.Format("2006-01-02")
+	} else if count := rule["COUNT"]; count != "" {
+		recurrence += ":count:" + count
+	}
+
+	if _, err := ParseRecurrence(recurrence); err != nil {
+		return "", false
+	}
+	return strings.TrimSuffix(recurrence, ":"), true
+}
+
+// windowsTimeZones maps the Windows time zone names found in invites sent
+// from Outlook and Exchange to IANA names
+var windowsTimeZones = map[string]string{
+	"UTC":                            "UTC",
+	"GMT Standard Time":              "Europe/London",
+	"Greenwich Standard Time":        "Atlantic/Reykjavik",
+	"W. Europe Standard Time":        "Europe/Berlin",
+	"Romance Standard Time":          "Europe/Paris",
+	"Central Europe Standard Time":   "Europe/Budapest",
+	"Central European Standard Time": "Europe/Warsaw",
+	"E. Europe Standard Time":        "Europe/Chisinau",
+	"FLE Standard Time":              "Europe/Kiev",
+	"GTB Standard Time":              "Europe/Bucharest",
+	"Russian Standard Time":          "Europe/Moscow",
+	"Eastern Standard Time":          "America/New_York",
+	"Central Standard Time":          "America/Chicago",
+	"Mountain Standard Time":         "America/Denver",
+	"US Mountain Standard Time":      "America/Phoenix",
+	"Pacific Standard Time":          "America/Los_Angeles",
+	"Alaskan Standard Time":          "America/Anchorage",
+	"Hawaiian Standard Time":         "Pacific/Honolulu",
+	"Atlantic Standard Time":         "America/Halifax",
+	"E. South America Standard Time": "America/Sao_Paulo",
+	"India Standard Time":            "Asia/Kolkata",
+	"China Standard Time":            "Asia/Shanghai",
+	"Tokyo Standard Time":            "Asia/Tokyo",
+	"Singapore Standard Time":        "Asia/Singapore",
+	"AUS Eastern Standard Time":      "Australia/Sydney",
+	"New Zealand Standard Time":      "Pacific/Auckland",
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+)
+
+// ICalFeedName is the calendar name of the deadlines feed
+const ICalFeedName = "prod deadlines"
+
+// ICalService converts tasks and calendar events to and from iCalendar
+type ICalService struct {
+	queries *sqlc.Queries
+	journal *journal
+}
+
+// NewICalService creates a new iCalendar service
+func NewICalService(queries *sqlc.Queries) *ICalService {
+	return &ICalService{
+		queries: queries,
+		journal: newJournal(queries),
+	}
+}
+
+// Export builds a calendar of the tasks due and the events between from and
+// to. Tasks become VTODOs and calendar events VEVENTs
+func (s *ICalService) Export(ctx context.Context, userID int32, from, to time.Time) (*ICalComponent, error) {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+	rangeStart := pgtype.Timestamptz{
+		Time:  from,
+		Valid: true,
+	}
+	rangeEnd := pgtype.Timestamptz{
+		Time:  to,
+		Valid: true,
+	}
+
+	tasks, err := s.queries.GetTasksWithinDateRange(ctx, sqlc.GetTasksWithinDateRangeParams{
+		UserID:      user,
+		StartDate:   rangeStart,
+		StartDate_2: rangeEnd,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks: %w", err)
+	}
+
+	events, err := s.queries.ListCalendarEventsInRange(ctx, sqlc.ListCalendarEventsInRangeParams{
+		UserID:     user,
+		RangeStart: rangeStart,
+		RangeEnd:   rangeEnd,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar events: %w", err)
+	}
+
+	// Parents are linked by UUID, so look up the ones outside the range too
+	uuids := make(map[int32]pgtype.UUID)
+	for _, task := range tasks {
+		uuids[task.ID] = task.Uuid
+	}
+
+	now := time.Now()
+	cal := NewICalendar(ICalFeedName)
+	for _, task := range tasks {
+		// The range also matches tasks on their start date only
+		if !task.DueDate.Valid {
+			continue
+		}
+
+		var parent pgtype.UUID
+		if task.Dependent.Valid {
+			var ok bool
+			if parent, ok = uuids[task.Dependent.Int32]; !ok {
+				p, err := s.queries.GetTask(ctx, sqlc.GetTaskParams{
+					ID:     task.Dependent.Int32,
+					UserID: user,
+				})
+				if err == nil {
+					parent = p.Uuid
+				}
+				uuids[task.Dependent.Int32] = parent
+			}
+		}
+
+		cal.Components = append(cal.Components, TaskToVTodo(task, parent, now))
+	}
+	for _, event := range events {
+		cal.Components = append(cal.Components, EventToVEvent(event, now))
+	}
+
+	return cal, nil
+}
+
+// TaskToVTodo converts a task to a VTODO. parent is the UUID of the parent
+// task, if any, and stamp the time the calendar is generated
+func TaskToVTodo(task sqlc.Task, parent pgtype.UUID, stamp time.Time) *ICalComponent {
+	todo := &ICalComponent{Name: "VTODO"}
+	todo.Add("UID", FormatUUID(task.Uuid))
+	todo.AddTime("DTSTAMP", stamp)
+	if task.CreatedAt.Valid {
+		todo.AddTime("CREATED", task.CreatedAt.Time)
+	}
+	if task.UpdatedAt.Valid {
+		todo.AddTime("LAST-MODIFIED", task.UpdatedAt.Time)
+	}
+	todo.AddText("SUMMARY", task.Description)
+	if task.Notes.Valid && task.Notes.String != "" {
+		todo.AddText("DESCRIPTION", task.Notes.String)
+	}
+
+	// DUE must be later than DTSTART, so a start date after it is left out
+	if task.StartDate.Valid && (!task.DueDate.Valid || task.StartDate.Time.Before(task.DueDate.Time)) {
+		todo.AddTime("DTSTART", task.StartDate.Time)
+	}
+	if task.DueDate.Valid {
+		todo.AddTime("DUE", task.DueDate.Time)
+	}
+
+	switch task.Status {
+	case "completed":
+		todo.Add("STATUS", "COMPLETED")
+		todo.Add("PERCENT-COMPLETE", "100")
+		if task.CompletedAt.Valid {
+			todo.AddTime("COMPLETED", task.CompletedAt.Time)
+		}
+	case "active":
+		todo.Add("STATUS", "IN-PROCESS")
+	default:
+		todo.Add("STATUS", "NEEDS-ACTION")
+	}
+
+	if priority := icalPriority(task.Priority); priority > 0 {
+		todo.Add("PRIORITY", fmt.Sprint(priority))
+	}
+
+	if len(task.Tags) > 0 {
+		categories := make([]string, len(task.Tags))
+		for i, tag := range task.Tags {
+			categories[i] = escapeICalText(tag)
+		}
+		todo.Add("CATEGORIES", strings.Join(categories, ","))
+	}
+
+	if task.Recurrence.Valid {
+		if rrule, ok := icalRRule(task.Recurrence.String); ok {
+			todo.Add("RRULE", rrule)
+		}
+	}
+
+	if parent.Valid {
+		todo.Add("RELATED-TO", FormatUUID(parent))
+	}
+
+	return todo
+}
+
+// EventToVEvent converts a calendar event to a VEVENT. stamp is the time the
+// calendar is generated
+func EventToVEvent(event sqlc.CalendarEvent, stamp time.Time) *ICalComponent {
+	vevent := &ICalComponent{Name: "VEVENT"}
+	vevent.Add("UID", event.Uid)
+	vevent.AddTime("DTSTAMP", stamp)
+	if event.CreatedAt.Valid {
+		vevent.AddTime("CREATED", event.CreatedAt.Time)
+	}
+	if event.UpdatedAt.Valid {
+		vevent.AddTime("LAST-MODIFIED", event.UpdatedAt.Time)
+	}
+	vevent.AddText("SUMMARY", event.Title)
+	if event.Description.Valid && event.Description.String != "" {
+		vevent.AddText("DESCRIPTION", event.Description.String)
+	}
+	if event.Location.Valid && event.Location.String != "" {
+		vevent.AddText("LOCATION", event.Location.String)
+	}
+
+	if event.AllDay.Bool {
+		// All-day events end at the start of the day after, like DTEND
+		start := dateOf(event.StartTime.Time.Local())
+		end := dateOf(event.EndTime.Time.Local())
+		if !end.After(start) {
+			end = start.AddDate(0, 0, 1)
+		}
+		vevent.AddDate("DTSTART", start)
+		vevent.AddDate("DTEND", end)
+	} else {
+		vevent.AddTime("DTSTART", event.StartTime.Time)
+		vevent.AddTime("DTEND", event.EndTime.Time)
+	}
+
+	if event.Sequence > 0 {
+		vevent.Add("SEQUENCE", fmt.Sprint(event.Sequence))
+	}
+	if event.Rrule.Valid {
+		vevent.Add("RRULE", event.Rrule.String)
+	}
+	// Excluded and changed occurrences use the value type of DTSTART
+	if len(event.Exdates) > 0 {
+		values := make([]string, len(event.Exdates))
+		for i, exdate := range event.Exdates {
+			values[i] = icalEventTime(exdate, event.AllDay.Bool)
+		}
+		exdate := ICalProperty{Name: "EXDATE", Value: strings.Join(values, ",")}
+		if event.AllDay.Bool {
+			exdate.Params = map[string]string{"VALUE": "DATE"}
+		}
+		vevent.Properties = append(vevent.Properties, exdate)
+	}
+	if event.RecurrenceID.Valid {
+		if event.AllDay.Bool {
+			vevent.AddDate("RECURRENCE-ID", event.RecurrenceID.Time.Local())
+		} else {
+			vevent.AddTime("RECURRENCE-ID", event.RecurrenceID.Time)
+		}
+	}
+
+	return vevent
+}
+
+func icalEventTime(t time.Time, allDay bool) string {
+	if allDay {
+		return t.Local().Format(icalDate)
+	}
+	return t.UTC().Format(icalDateTimeUTC)
+}
+
+// icalPriority maps prod priorities to the iCalendar scale where 1 is the
+// highest priority and 0 means undefined
+func icalPriority(priority pgtype.Text) int {
+	switch priority.String {
+	case "H":
+		return 1
+	case "M":
+		return 5
+	case "L":
+		return 9
+	}
+	return 0
+}
+
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,658 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+)
+
+// Import creates calendar events from the VEVENTs of calendars and tasks from
+// their VTODOs. Both are matched on their UID, so importing an updated invite
+// changes the event it created instead of adding another one, and importing a
+// cancellation deletes it. Tasks can be undone with 'prod undo', events can't.
+// Run it in a UnitOfWork
+func (s *ICalService) Import(ctx context.Context, userID int32, calendars []*ICalComponent) (*ImportReport, error) {
+	report := &ImportReport{}
+	var links []icalParentLink
+
+	for _, calendar := range calendars {
+		method := ""
+		if prop, ok := calendar.Property("METHOD"); ok {
+			method = strings.ToUpper(prop.Value)
+		}
+		zones := newICalZones(calendar)
+
+		for _, component := range calendar.Components {
+			switch component.Name {
+			case "VEVENT":
+				if err := s.importEvent(ctx, userID, component, zones, method, report); err != nil {
+					return nil, err
+				}
+			case "VTODO":
+				link, err := s.importTodo(ctx, userID, component, zones, report)
+				if err != nil {
+					return nil, err
+				}
+				if link != nil {
+					links = append(links, *link)
+				}
+			}
+		}
+	}
+
+	// Parents may come after their subtasks, so they are linked at the end
+	if err := s.linkParents(ctx, userID, links, report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// importEvent creates, updates or deletes the calendar event of a VEVENT
+func (s *ICalService) importEvent(ctx context.Context, userID int32, vevent *ICalComponent, zones *icalZones, method string, report *ImportReport) error {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+
+	uid := icalValue(vevent, "UID")
+	title := ICalText(icalValue(vevent, "SUMMARY"))
+	if title == "" {
+		title = "Untitled event"
+	}
+	if uid == "" {
+		report.conflict("event %q has no UID and was skipped", title)
+		report.EventsSkipped++
+		return nil
+	}
+
+	event, err := parseVEvent(vevent, zones)
+	if err != nil {
+		report.conflict("event %q was skipped: %v", title, err)
+		report.EventsSkipped++
+		return nil
+	}
+	event.UserID = user
+	event.Uid = uid
+	event.Title = title
+
+	existing, err := s.queries.GetCalendarEventByUID(ctx, sqlc.GetCalendarEventByUIDParams{
+		UserID:       user,
+		Uid:          uid,
+		RecurrenceID: event.RecurrenceID,
+	})
+	found := err == nil
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to look up event %q: %w", title, err)
+	}
+
+	if method == "CANCEL" || strings.EqualFold(icalValue(vevent, "STATUS"), "CANCELLED") {
+		return s.cancelEvent(ctx, userID, event, existing, found, report)
+	}
+
+	if !found {
+		_, err := s.queries.CreateCalendarEvent(ctx, sqlc.CreateCalendarEventParams{
+			UserID:       event.UserID,
+			Title:        event.Title,
+			Description:  event.Description,
+			StartTime:    event.StartTime,
+			EndTime:      event.EndTime,
+			AllDay:       event.AllDay,
+			Location:     event.Location,
+			ProjectID:    event.ProjectID,
+			Uid:          event.Uid,
+			Rrule:        event.Rrule,
+			Exdates:      event.Exdates,
+			RecurrenceID: event.RecurrenceID,
+			Timezone:     event.Timezone,
+			Sequence:     event.Sequence,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create event %q: %w", title, err)
+		}
+		report.EventsCreated++
+		return nil
+	}
+
+	// An invite older than the event, sent before an update, changes nothing
+	if event.Sequence < existing.Sequence {
+		report.conflict("event %q is older than the imported version and was skipped", title)
+		report.EventsSkipped++
+		return nil
+	}
+	if sameEvent(existing, event) {
+		report.EventsSkipped++
+		return nil
+	}
+
+	_, err = s.queries.UpdateCalendarEvent(ctx, sqlc.UpdateCalendarEventParams{
+		ID:          existing.ID,
+		UserID:      user,
+		Title:       event.Title,
+		Description: event.Description,
+		StartTime:   event.StartTime,
+		EndTime:     event.EndTime,
+		AllDay:      event.AllDay,
+		Location:    event.Location,
+		Rrule:       event.Rrule,
+		Exdates:     event.Exdates,
+		Timezone:    event.Timezone,
+		Sequence:    event.Sequence,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update event %q: %w", title, err)
+	}
+	report.EventsUpdated++
+	return nil
+}
+
+// cancelEvent deletes a cancelled event. A cancelled occurrence of a series
+// is excluded from the series instead
+func (s *ICalService) cancelEvent(ctx context.Context, userID int32, event sqlc.CalendarEvent, existing sqlc.CalendarEvent, found bool, report *ImportReport) error {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+
+	if !event.RecurrenceID.Valid {
+		deleted, err := s.queries.DeleteCalendarEventsByUID(ctx, sqlc.DeleteCalendarEventsByUIDParams{
+			UserID: user,
+			Uid:    event.Uid,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete event %q: %w", event.Title, err)
+		}
+		if deleted > 0 {
+			report.EventsDeleted++
+		} else {
+			report.EventsSkipped++
+		}
+		return nil
+	}
+
+	if found {
+		err := s.queries.DeleteCalendarEvent(ctx, sqlc.DeleteCalendarEventParams{
+			ID:     existing.ID,
+			UserID: user,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete event %q: %w", event.Title, err)
+		}
+	}
+
+	series, err := s.queries.GetCalendarEventByUID(ctx, sqlc.GetCalendarEventByUIDParams{
+		UserID: user,
+		Uid:    event.Uid,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		if found {
+			report.EventsDeleted++
+		} else {
+			report.EventsSkipped++
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up event %q: %w", event.Title, err)
+	}
+
+	occurrence := event.RecurrenceID.Time
+	if slices.ContainsFunc(series.Exdates, occurrence.Equal) {
+		if found {
+			report.EventsDeleted++
+		} else {
+			report.EventsSkipped++
+		}
+		return nil
+	}
+
+	_, err = s.queries.UpdateCalendarEvent(ctx, sqlc.UpdateCalendarEventParams{
+		ID:          series.ID,
+		UserID:      user,
+		Title:       series.Title,
+		Description: series.Description,
+		StartTime:   series.StartTime,
+		EndTime:     series.EndTime,
+		AllDay:      series.AllDay,
+		Location:    series.Location,
+		Rrule:       series.Rrule,
+		Exdates:     append(series.Exdates, occurrence),
+		Timezone:    series.Timezone,
+		Sequence:    series.Sequence,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update event %q: %w", series.Title, err)
+	}
+	report.EventsDeleted++
+	return nil
+}
+
+// parseVEvent reads the times, recurrence and details of a VEVENT
+func parseVEvent(vevent *ICalComponent, zones *icalZones) (sqlc.CalendarEvent, error) {
+	event := sqlc.CalendarEvent{}
+
+	dtstart, ok := vevent.Property("DTSTART")
+	if !ok {
+		return event, fmt.Errorf("it has no start")
+	}
+	start, allDay, err := zones.value(dtstart)
+	if err != nil {
+		return event, err
+	}
+
+	var end time.Time
+	if dtend, ok := vevent.Property("DTEND"); ok {
+		if end, _, err = zones.value(dtend); err != nil {
+			return event, err
+		}
+	} else if duration, ok := vevent.Property("DURATION"); ok {
+		d, err := parseICalDuration(duration.Value)
+		if err != nil {
+			return event, err
+		}
+		end = start.Add(d)
+		if allDay {
+			// Days of a duration are calendar days, not 24 hours
+			end = start.AddDate(0, 0, int(d/(24*time.Hour)))
+		}
+	} else if allDay {
+		end = start.AddDate(0, 0, 1)
+	} else {
+		end = start
+	}
+	if end.Before(start) {
+		end = start
+	}
+
+	event.StartTime = pgtype.Timestamptz{
+		Time:  start,
+		Valid: true,
+	}
+	event.EndTime = pgtype.Timestamptz{
+		Time:  end,
+		Valid: true,
+	}
+	event.AllDay = pgtype.Bool{
+		Bool:  allDay,
+		Valid: true,
+	}
+	if tzid, ok := dtstart.Params["TZID"]; ok && !allDay {
+		event.Timezone = pgtype.Text{
+			String: zones.location(tzid).String(),
+			Valid:  true,
+		}
+	}
+
+	if description := ICalText(icalValue(vevent, "DESCRIPTION")); description != "" {
+		event.Description = pgtype.Text{
+			String: description,
+			Valid:  true,
+		}
+	}
+	if location := ICalText(icalValue(vevent, "LOCATION")); location != "" {
+		event.Location = pgtype.Text{
+			String: location,
+			Valid:  true,
+		}
+	}
+
+	if rrule := icalValue(vevent, "RRULE"); rrule != "" {
+		event.Rrule = pgtype.Text{
+			String: rrule,
+			Valid:  true,
+		}
+	}
+	event.Exdates = []time.Time{}
+	for _, prop := range vevent.Properties {
+		if prop.Name != "EXDATE" {
+			continue
+		}
+		dates, _, err := zones.values(prop)
+		if err != nil {
+			return event, err
+		}
+		event.Exdates = append(event.Exdates, dates...)
+	}
+
+	if prop, ok := vevent.Property("RECURRENCE-ID"); ok {
+		occurrence, _, err := zones.value(prop)
+		if err != nil {
+			return event, err
+		}
+		event.RecurrenceID = pgtype.Timestamptz{
+			Time:  occurrence,
+			Valid: true,
+		}
+	}
+
+	if sequence, err := strconv.Atoi(icalValue(vevent, "SEQUENCE")); err == nil {
+		event.Sequence = int32(sequence)
+	}
+
+	return event, nil
+}
+
+// sameEvent reports whether importing event would leave existing unchanged
+func sameEvent(existing, event sqlc.CalendarEvent) bool {
+	return existing.Title == event.Title &&
+		existing.Description == event.Description &&
+		existing.StartTime.Time.Equal(event.StartTime.Time) &&
+		existing.EndTime.Time.Equal(event.EndTime.Time) &&
+		existing.AllDay.Bool == event.AllDay.Bool &&
+		existing.Location == event.Location &&
+		existing.Rrule == event.Rrule &&
+		slices.EqualFunc(existing.Exdates, event.Exdates, time.Time.Equal) &&
+		existing.Timezone == event.Timezone &&
+		existing.Sequence == event.Sequence
+}
+
+// icalParentLink is a task whose to-do names a parent with RELATED-TO
+type icalParentLink struct {
+	task   pgtype.UUID
+	parent pgtype.UUID
+}
+
+// importTodo creates or updates the task of a VTODO. The task is found by the
+// UUID derived from the UID, see uidToUUID. The parent of the task, if any,
+// is returned for linkParents
+func (s *ICalService) importTodo(ctx context.Context, userID int32, vtodo *ICalComponent, zones *icalZones, report *ImportReport) (*icalParentLink, error) {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+	const description = "import calendar"
+
+	summary := ICalText(icalValue(vtodo, "SUMMARY"))
+	uid := icalValue(vtodo, "UID")
+	if uid == "" || summary == "" {
+		report.conflict("to-do %q has no UID or summary and was skipped", summary)
+		report.TasksSkipped++
+		return nil, nil
+	}
+	uuid := uidToUUID(uid)
+
+	existing, err := s.queries.GetTaskByUUID(ctx, sqlc.GetTaskByUUIDParams{
+		UserID: user,
+		Uuid:   uuid,
+	})
+	found := err == nil
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to look up task %q: %w", summary, err)
+	}
+
+	cancelled := strings.EqualFold(icalValue(vtodo, "STATUS"), "CANCELLED")
+	switch {
+	case found && existing.DeletedAt.Valid:
+		if !cancelled {
+			report.conflict("task %q is in the trash and was not updated, restore it to import it again", summary)
+		}
+		report.TasksSkipped++
+		return nil, nil
+
+	case cancelled:
+		if !found {
+			report.TasksSkipped++
+			return nil, nil
+		}
+		trashed, err := s.queries.DeleteTask(ctx, sqlc.DeleteTaskParams{
+			ID:     existing.ID,
+			UserID: user,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete task %q: %w", summary, err)
+		}
+		if err := s.journal.recordTask(ctx, userID, description, &existing, &trashed); err != nil {
+			return nil, err
+		}
+		report.TasksTrashed++
+		return nil, nil
+	}
+
+	task := existing
+	if !found {
+		task = sqlc.Task{
+			UserID: user,
+			Uuid:   uuid,
+		}
+	}
+	if err := applyVTodo(&task, vtodo, zones, report); err != nil {
+		report.conflict("to-do %q was skipped: %v", summary, err)
+		report.TasksSkipped++
+		return nil, nil
+	}
+
+	var link *icalParentLink
+	if related, ok := vtodo.Property("RELATED-TO"); ok && related.Value != "" {
+		if reltype := related.Params["RELTYPE"]; reltype == "" || strings.EqualFold(reltype, "PARENT") {
+			link = &icalParentLink{
+				task:   uuid,
+				parent: uidToUUID(related.Value),
+			}
+		}
+	}
+
+	if found {
+		if len(diffTask(userID, &existing, &task)) == 0 {
+			report.TasksSkipped++
+			return link, nil
+		}
+		tasks := &TaskService{queries: s.queries, journal: s.journal}
+		if _, err := tasks.replaceTask(ctx, userID, description, &existing, task); err != nil {
+			return nil, err
+		}
+		report.TasksUpdated++
+		return link, nil
+	}
+
+	created, err := s.queries.ImportTask(ctx, sqlc.ImportTaskParams{
+		UserID:          user,
+		Description:     task.Description,
+		Status:          task.Status,
+		Priority:        task.Priority,
+		DueDate:         task.DueDate,
+		StartDate:       task.StartDate,
+		CompletedAt:     task.CompletedAt,
+		ProjectID:       task.ProjectID,
+		Recurrence:      task.Recurrence,
+		Tags:            task.Tags,
+		Notes:           task.Notes,
+		CreatedAt:       task.CreatedAt,
+		UpdatedAt:       task.UpdatedAt,
+		Dependent:       task.Dependent,
+		EstimateMinutes: task.EstimateMinutes,
+		Uuid:            task.Uuid,
+		WaitUntil:       task.WaitUntil,
+		Scheduled:       task.Scheduled,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to import task %q: %w", summary, err)
+	}
+	if err := s.journal.recordTask(ctx, userID, description, nil, &created); err != nil {
+		return nil, err
+	}
+	report.TasksCreated++
+	return link, nil
+}
+
+// linkParents sets the parents named by RELATED-TO on imported tasks
+func (s *ICalService) linkParents(ctx context.Context, userID int32, links []icalParentLink, report *ImportReport) error {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+	tasks := &TaskService{queries: s.queries, journal: s.journal}
+
+	for _, link := range links {
+		task, err := s.queries.GetTaskByUUID(ctx, sqlc.GetTaskByUUIDParams{
+			UserID: user,
+			Uuid:   link.task,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get task: %w", err)
+		}
+		parentTask, err := s.queries.GetTaskByUUID(ctx, sqlc.GetTaskByUUIDParams{
+			UserID: user,
+			Uuid:   link.parent,
+		})
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && parentTask.DeletedAt.Valid) || parentTask.ID == task.ID {
+			report.conflict("parent of task %q was not found, it was left without a parent", task.Description)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get parent of task %q: %w", task.Description, err)
+		}
+
+		if task.Dependent.Valid && task.Dependent.Int32 == parentTask.ID {
+			continue
+		}
+		updated := task
+		updated.Dependent = pgtype.Int4{
+			Int32: parentTask.ID,
+			Valid: true,
+		}
+		if _, err := tasks.replaceTask(ctx, userID, "import calendar", &task, updated); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyVTodo copies the fields of a VTODO onto a task. Fields iCalendar has
+// no place for, like the project and estimate, are kept
+func applyVTodo(task *sqlc.Task, vtodo *ICalComponent, zones *icalZones, report *ImportReport) error {
+	task.Description = ICalText(icalValue(vtodo, "SUMMARY"))
+	task.Notes = pgtype.Text{}
+	if notes := ICalText(icalValue(vtodo, "DESCRIPTION")); notes != "" {
+		task.Notes = pgtype.Text{
+			String: notes,
+			Valid:  true,
+		}
+	}
+
+	timeOf := func(name string) (pgtype.Timestamptz, error) {
+		prop, ok := vtodo.Property(name)
+		if !ok {
+			return pgtype.Timestamptz{}, nil
+		}
+		t, _, err := zones.value(prop)
+		if err != nil {
+			return pgtype.Timestamptz{}, err
+		}
+		return pgtype.Timestamptz{
+			Time:  t,
+			Valid: true,
+		}, nil
+	}
+
+	var err error
+	if task.DueDate, err = timeOf("DUE"); err != nil {
+		return err
+	}
+	if !task.DueDate.Valid {
+		// A to-do may have a duration from its start instead of a due date
+		if duration, ok := vtodo.Property("DURATION"); ok {
+			start, err := timeOf("DTSTART")
+			d, derr := parseICalDuration(duration.Value)
+			if err == nil && derr == nil && start.Valid {
+				task.DueDate = pgtype.Timestamptz{
+					Time:  start.Time.Add(d),
+					Valid: true,
+				}
+			}
+		}
+	}
+	if task.StartDate, err = timeOf("DTSTART"); err != nil {
+		return err
+	}
+
+	task.CompletedAt = pgtype.Timestamptz{}
+	switch strings.ToUpper(icalValue(vtodo, "STATUS")) {
+	case "COMPLETED":
+		task.Status = "completed"
+		if task.CompletedAt, err = timeOf("COMPLETED"); err != nil {
+			return err
+		}
+		if !task.CompletedAt.Valid {
+			task.CompletedAt = pgtype.Timestamptz{
+				Time:  time.Now(),
+				Valid: true,
+			}
+		}
+	case "IN-PROCESS":
+		task.Status = "active"
+	default:
+		task.Status = "pending"
+	}
+
+	task.Priority = pgtype.Text{}
+	if priority, err := strconv.Atoi(icalValue(vtodo, "PRIORITY")); err == nil && priority > 0 {
+		level := "L"
+		if priority < 5 {
+			level = "H"
+		} else if priority == 5 {
+			level = "M"
+		}
+		task.Priority = pgtype.Text{
+			String: level,
+			Valid:  true,
+		}
+	}
+
+	task.Tags = nil
+	for _, prop := range vtodo.Properties {
+		if prop.Name != "CATEGORIES" {
+			continue
+		}
+		for _, category := range splitICalList(prop.Value) {
+			if tag := strings.TrimSpace(ICalText(category)); tag != "" && !slices.Contains(task.Tags, tag) {
+				task.Tags = append(task.Tags, tag)
+			}
+		}
+	}
+
+	task.Recurrence = pgtype.Text{}
+	if rrule := icalValue(vtodo, "RRULE"); rrule != "" && task.Status != "completed" {
+		if recurrence, ok := prodRecurrenceFromRRule(rrule); ok {
+			task.Recurrence = pgtype.Text{
+				String: recurrence,
+				Valid:  true,
+			}
+		} else {
+			report.conflict("repeat rule %q of task %q is not supported and was dropped", rrule, task.Description)
+		}
+	}
+
+	if created, err := timeOf("CREATED"); err == nil && created.Valid {
+		task.CreatedAt = created
+	} else if !task.CreatedAt.Valid {
+		task.CreatedAt = pgtype.Timestamptz{
+			Time:  time.Now(),
+			Valid: true,
+		}
+	}
+	if modified, err := timeOf("LAST-MODIFIED"); err == nil && modified.Valid {
+		task.UpdatedAt = modified
+	} else {
+		task.UpdatedAt = pgtype.Timestamptz{
+			Time:  time.Now(),
+			Valid: true,
+		}
+	}
+
+	return nil
+}
+
+// icalValue returns the raw value of the first property with the given name
+func icalValue(c *ICalComponent, name string) string {
+	prop, _ := c.Property(name)
+	return prop.Value
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+)
+
+// ErrInvalidCalendar is returned for calendar data sent by a sync client that
+// can't be saved
+var ErrInvalidCalendar = errors.New("invalid calendar data")
+
+// TaskCalendar wraps the VTODO of a task in a calendar of its own, the way
+// CalDAV stores every task. DTSTAMP is the last change of the task, so the
+// data only changes when the task does
+func (s *ICalService) TaskCalendar(ctx context.Context, userID int32, task sqlc.Task) (*ICalComponent, error) {
+	var parent pgtype.UUID
+	if task.Dependent.Valid {
+		p, err := s.queries.GetTask(ctx, sqlc.GetTaskParams{
+			ID: task.Dependent.Int32,
+			UserID: pgtype.Int4{
+				Int32: userID,
+				Valid: true,
+			},
+		})
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("failed to get parent of task %q: %w", task.Description, err)
+		}
+		parent = p.Uuid
+	}
+
+	cal := NewICalendar("")
+	cal.Components = append(cal.Components, TaskToVTodo(task, parent, task.UpdatedAt.Time))
+	return cal, nil
+}
+
+// EventCalendar wraps a series of events with the same UID, the event and its
+// changed occurrences, in a calendar of its own
+func EventCalendar(series []sqlc.CalendarEvent) *ICalComponent {
+	cal := NewICalendar("")
+	for _, event := range series {
+		cal.Components = append(cal.Components, EventToVEvent(event, event.UpdatedAt.Time))
+	}
+	return cal
+}
+
+// PutTask saves the VTODO of a calendar sent by a sync client in project,
+// creating the task or updating the one with its UID. The change goes through
+// TaskService.SyncTask, so completing a recurring task creates the next
+// occurrence. Run it in a UnitOfWork
+func (s *ICalService) PutTask(ctx context.Context, userID int32, projectID pgtype.Int4, calendar *ICalComponent) (*sqlc.Task, error) {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+
+	var vtodo *ICalComponent
+	for _, component := range calendar.Components {
+		if component.Name == "VTODO" {
+			if vtodo != nil {
+				return nil, fmt.Errorf("%w: more than one to-do", ErrInvalidCalendar)
+			}
+			vtodo = component
+		}
+	}
+	if vtodo == nil {
+		return nil, fmt.Errorf("%w: no to-do found", ErrInvalidCalendar)
+	}
+	uid := icalValue(vtodo, "UID")
+	if uid == "" || icalValue(vtodo, "SUMMARY") == "" {
+		return nil, fmt.Errorf("%w: a to-do needs a UID and a summary", ErrInvalidCalendar)
+	}
+
+	uuid := uidToUUID(uid)
+	existing, err := s.queries.GetTaskByUUID(ctx, sqlc.GetTaskByUUIDParams{
+		UserID: user,
+		Uuid:   uuid,
+	})
+	found := err == nil
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to look up task: %w", err)
+	}
+	if found && existing.DeletedAt.Valid {
+		return nil, fmt.Errorf("%w: task %q is in the trash, restore it first", ErrInvalidCalendar, existing.Description)
+	}
+
+	task := existing
+	if !found {
+		task = sqlc.Task{
+			UserID: user,
+			Uuid:   uuid,
+		}
+	}
+	// Repeat rules prod can't express are dropped like on import
+	if err := applyVTodo(&task, vtodo, newICalZones(calendar), &ImportReport{}); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCalendar, err)
+	}
+	task.ProjectID = projectID
+	// A completed to-do drops its repeat rule, but completing a repeating task
+	// needs it to create the next occurrence
+	if found && task.Status == "completed" && existing.Status != "completed" && icalValue(vtodo, "RRULE") != "" {
+		task.Recurrence = existing.Recurrence
+	}
+
+	// Apps without subtasks leave RELATED-TO out, so the parent is only changed
+	// when one is given
+	if related, ok := vtodo.Property("RELATED-TO"); ok && related.Value != "" {
+		if reltype := related.Params["RELTYPE"]; reltype == "" || reltype == "PARENT" {
+			parent, err := s.queries.GetTaskByUUID(ctx, sqlc.GetTaskByUUIDParams{
+				UserID: user,
+				Uuid:   uidToUUID(related.Value),
+			})
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return nil, fmt.Errorf("failed to look up parent task: %w", err)
+			}
+			if err == nil && !parent.DeletedAt.Valid && parent.ID != task.ID {
+				task.Dependent = pgtype.Int4{
+					Int32: parent.ID,
+					Valid: true,
+				}
+			}
+		}
+	}
+
+	tasks := &TaskService{queries: s.queries, journal: s.journal}
+	if !found {
+		return tasks.SyncTask(ctx, userID, nil, task)
+	}
+	return tasks.SyncTask(ctx, userID, &existing, task)
+}
+
+// PutEvent saves the events of a calendar sent by a sync client: an event and
+// its changed occurrences, which all share one UID. Changed occurrences
+// missing from the calendar are deleted. The UID is returned
+func (s *ICalService) PutEvent(ctx context.Context, userID int32, calendar *ICalComponent) (string, error) {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+
+	zones := newICalZones(calendar)
+	var uid string
+	var events []sqlc.CalendarEvent
+	for _, component := range calendar.Components {
+		if component.Name != "VEVENT" {
+			continue
+		}
+		if uid == "" {
+			uid = icalValue(component, "UID")
+		}
+		if uid == "" || icalValue(component, "UID") != uid {
+			return "", fmt.Errorf("%w: every event needs the same UID", ErrInvalidCalendar)
+		}
+
+		event, err := parseVEvent(component, zones)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidCalendar, err)
+		}
+		event.UserID = user
+		event.Uid = uid
+		event.Title = ICalText(icalValue(component, "SUMMARY"))
+		if event.Title == "" {
+			event.Title = "Untitled event"
+		}
+		events = append(events, event)
+	}
+	if len(events) == 0 {
+		return "", fmt.Errorf("%w: no event found", ErrInvalidCalendar)
+	}
+
+	existing, err := s.queries.ListCalendarEventsByUID(ctx, sqlc.ListCalendarEventsByUIDParams{
+		UserID: user,
+		Uid:    uid,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list events: %w", err)
+	}
+
+	// Events are updated in place so links to tasks are kept
+	kept := make(map[int32]bool)
+	for _, event := range events {
+		var current *sqlc.CalendarEvent
+		for i := range existing {
+			if existing[i].RecurrenceID == event.RecurrenceID ||
+				(existing[i].RecurrenceID.Valid && event.RecurrenceID.Valid && existing[i].RecurrenceID.Time.Equal(event.RecurrenceID.Time)) {
+				current = &existing[i]
+				break
+			}
+		}
+
+		if current == nil {
+			_, err := s.queries.CreateCalendarEvent(ctx, sqlc.CreateCalendarEventParams{
+				UserID:       event.UserID,
+				Title:        event.Title,
+				Description:  event.Description,
+				StartTime:    event.StartTime,
+				EndTime:      event.EndTime,
+				AllDay:       event.AllDay,
+				Location:     event.Location,
+				ProjectID:    event.ProjectID,
+				Uid:          event.Uid,
+				Rrule:        event.Rrule,
+				Exdates:      event.Exdates,
+				RecurrenceID: event.RecurrenceID,
+				Timezone:     event.Timezone,
+				Sequence:     event.Sequence,
+			})
+			if err != nil {
+				return "", fmt.Errorf("failed to create event %q: %w", event.Title, err)
+			}
+			continue
+		}
+
+		kept[current.ID] = true
+		if sameEvent(*current, event) {
+			continue
+		}
+		_, err := s.queries.UpdateCalendarEvent(ctx, sqlc.UpdateCalendarEventParams{
+			ID:          current.ID,
+			UserID:      user,
+			Title:       event.Title,
+			Description: event.Description,
+			StartTime:   event.StartTime,
+			EndTime:     event.EndTime,
+			AllDay:      event.AllDay,
+			Location:    event.Location,
+			Rrule:       event.Rrule,
+			Exdates:     event.Exdates,
+			Timezone:    event.Timezone,
+			Sequence:    event.Sequence,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to update event %q: %w", event.Title, err)
+		}
+	}
+
+	for _, event := range existing {
+		if kept[event.ID] {
+			continue
+		}
+		err := s.queries.DeleteCalendarEvent(ctx, sqlc.DeleteCalendarEventParams{
+			ID:     event.ID,
+			UserID: user,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to delete event %q: %w", event.Title, err)
+		}
+	}
+
+	return uid, nil
+}
+
+// DeleteEvent deletes an event together with its changed occurrences
+func (s *ICalService) DeleteEvent(ctx context.Context, userID int32, uid string) error {
+	_, err := s.queries.DeleteCalendarEventsByUID(ctx, sqlc.DeleteCalendarEventsByUIDParams{
+		UserID: pgtype.Int4{
+			Int32: userID,
+			Valid: true,
+		},
+		Uid: uid,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete event: %w", err)
+	}
+	return nil
+}
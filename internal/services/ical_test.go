@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/dbtest"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCalendar parses a VCALENDAR holding lines
+func testCalendar(t *testing.T, lines ...string) *ICalComponent {
+	t.Helper()
+	data := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" + strings.Join(lines, "\r\n") + "\r\nEND:VCALENDAR\r\n"
+	calendars, err := ParseICalendar(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, calendars, 1)
+	return calendars[0]
+}
+
+// testEvent parses the first VEVENT of a calendar holding lines
+func testEvent(t *testing.T, lines ...string) sqlc.CalendarEvent {
+	t.Helper()
+	calendar := testCalendar(t, lines...)
+	for _, component := range calendar.Components {
+		if component.Name == "VEVENT" {
+			event, err := parseVEvent(component, newICalZones(calendar))
+			require.NoError(t, err)
+			return event
+		}
+	}
+	t.Fatal("no VEVENT found")
+	return sqlc.CalendarEvent{}
+}
+
+func TestParseICalendarUnfolding(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		prop  string
+		value string
+	}{
+		{"space", "SUMMARY:Bring the\r\n  slides", "SUMMARY", "Bring the slides"},
+		{"tab", "SUMMARY:Bring the\r\n\t slides", "SUMMARY", "Bring the slides"},
+		{"inside a word", "SUMMARY:Plan\r\n ning", "SUMMARY", "Planning"},
+		{"bare line feeds", "SUMMARY:Plan\n ning", "SUMMARY", "Planning"},
+		{"several lines", "DESCRIPTION:a\r\n b\r\n c", "DESCRIPTION", "abc"},
+		{"escaped text", `DESCRIPTION:one\, two\nthree\;`, "DESCRIPTION", "one, two\nthree;"},
+		{"quoted parameter", `ATTENDEE;CN="Doe: Jane";ROLE=CHAIR:mailto:jane@example.com`, "ATTENDEE", "mailto:jane@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar := testCalendar(t, "BEGIN:VEVENT", tt.data, "END:VEVENT")
+			require.Len(t, calendar.Components, 1)
+			prop, ok := calendar.Components[0].Property(tt.prop)
+			require.True(t, ok)
+			assert.Equal(t, tt.value, ICalText(prop.Value))
+		})
+	}
+
+	calendar := testCalendar(t, "BEGIN:VEVENT", `ATTENDEE;CN="Doe: Jane";ROLE=CHAIR:mailto:jane@example.com`, "END:VEVENT")
+	prop, _ := calendar.Components[0].Property("ATTENDEE")
+	assert.Equal(t, map[string]string{"CN": "Doe: Jane", "ROLE": "CHAIR"}, prop.Params)
+
+	_, err := ParseICalendar(strings.NewReader("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VCALENDAR\r\n"))
+	assert.Error(t, err, "components must be closed in order")
+}
+
+func TestICalZones(t *testing.T) {
+	custom := []string{
+		"BEGIN:VTIMEZONE", "TZID:Custom",
+		"BEGIN:DAYLIGHT", "TZOFFSETTO:+0630", "END:DAYLIGHT",
+		"BEGIN:STANDARD", "TZOFFSETTO:+0530", "END:STANDARD",
+		"END:VTIMEZONE",
+	}
+
+	tests := []struct {
+		name     string
+		tzid     string
+		want     time.Time
+		timezone string
+	}{
+		{"IANA name", "Europe/Copenhagen", time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC), "Europe/Copenhagen"},
+		{"Mozilla prefix", "/mozilla.org/20050126_1/Europe/Berlin", time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC), "Europe/Berlin"},
+		{"Windows name", "W. Europe Standard Time", time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC), "Europe/Berlin"},
+		{"VTIMEZONE offset", "Custom", time.Date(2025, 1, 15, 6, 30, 0, 0, time.UTC), "Custom"},
+		{"unknown zone", "Nowhere", time.Date(2025, 1, 15, 12, 0, 0, 0, time.Local), time.Local.String()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := append(custom, "BEGIN:VEVENT", "DTSTART;TZID=\""+tt.tzid+"\":20250115T120000", "END:VEVENT")
+			event := testEvent(t, lines...)
+			assert.True(t, tt.want.Equal(event.StartTime.Time), "got %s", event.StartTime.Time)
+			assert.Equal(t, tt.timezone, event.Timezone.String)
+		})
+	}
+
+	// UTC and floating times don't need a zone
+	event := testEvent(t, "BEGIN:VEVENT", "DTSTART:20250115T120000Z", "END:VEVENT")
+	assert.True(t, time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC).Equal(event.StartTime.Time))
+	assert.False(t, event.Timezone.Valid)
+	event = testEvent(t, "BEGIN:VEVENT", "DTSTART:20250115T120000", "END:VEVENT")
+	assert.True(t, time.Date(2025, 1, 15, 12, 0, 0, 0, time.Local).Equal(event.StartTime.Time))
+}
+
+func TestParseVEventEnd(t *testing.T) {
+	tests := []struct {
+		name   string
+		lines  []string
+		allDay bool
+		start  string
+		end    string
+	}{
+		{"all-day DTEND is exclusive", []string{"DTSTART;VALUE=DATE:20250601", "DTEND;VALUE=DATE:20250603"}, true, "2025-06-01 00:00", "2025-06-03 00:00"},
+		{"all-day without end lasts a day", []string{"DTSTART;VALUE=DATE:20250601"}, true, "2025-06-01 00:00", "2025-06-02 00:00"},
+		{"all-day duration counts days", []string{"DTSTART;VALUE=DATE:20250329", "DURATION:P2D"}, true, "2025-03-29 00:00", "2025-03-31 00:00"},
+		{"date without VALUE", []string{"DTSTART:20250601"}, true, "2025-06-01 00:00", "2025-06-02 00:00"},
+		{"timed duration", []string{"DTSTART:20250601T090000", "DURATION:PT1H30M"}, false, "2025-06-01 09:00", "2025-06-01 10:30"},
+		{"timed without end", []string{"DTSTART:20250601T090000"}, false, "2025-06-01 09:00", "2025-06-01 09:00"},
+		{"end before start", []string{"DTSTART:20250601T090000", "DTEND:20250601T080000"}, false, "2025-06-01 09:00", "2025-06-01 09:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := append([]string{"BEGIN:VEVENT"}, tt.lines...)
+			event := testEvent(t, append(lines, "END:VEVENT")...)
+			assert.Equal(t, tt.allDay, event.AllDay.Bool)
+			assert.Equal(t, tt.start, event.StartTime.Time.In(time.Local).Format("2006-01-02 15:04"))
+			assert.Equal(t, tt.end, event.EndTime.Time.In(time.Local).Format("2006-01-02 15:04"))
+		})
+	}
+}
+
+func TestExpandEventsExdate(t *testing.T) {
+	from := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 5)
+
+	tests := []struct {
+		name  string
+		lines []string
+		want  []string
+	}{
+		{
+			name:  "no exceptions",
+			lines: []string{"DTSTART;TZID=Europe/Copenhagen:20250602T090000", "DTEND;TZID=Europe/Copenhagen:20250602T100000"},
+			want:  []string{"06-02", "06-03", "06-04", "06-05", "06-06"},
+		},
+		{
+			name: "list in the zone of the event",
+			lines: []string{"DTSTART;TZID=Europe/Copenhagen:20250602T090000", "DTEND;TZID=Europe/Copenhagen:20250602T100000",
+				"EXDATE;TZID=Europe/Copenhagen:20250603T090000,20250605T090000"},
+			want: []string{"06-02", "06-04", "06-06"},
+		},
+		{
+			name: "several properties in UTC",
+			lines: []string{"DTSTART;TZID=Europe/Copenhagen:20250602T090000", "DTEND;TZID=Europe/Copenhagen:20250602T100000",
+				"EXDATE:20250603T070000Z", "EXDATE:20250604T070000Z"},
+			want: []string{"06-02", "06-05", "06-06"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := append([]string{"BEGIN:VEVENT", "RRULE:FREQ=DAILY"}, tt.lines...)
+			event := testEvent(t, append(lines, "END:VEVENT")...)
+			event.Uid = "daily"
+
+			var days []string
+			for _, occurrence := range ExpandEvents([]sqlc.CalendarEvent{event}, from, to) {
+				days = append(days, occurrence.Start.Format("01-02"))
+			}
+			assert.Equal(t, tt.want, days)
+		})
+	}
+
+	// All-day events repeat in local time
+	event := testEvent(t, "BEGIN:VEVENT", "RRULE:FREQ=DAILY", "DTSTART;VALUE=DATE:20250602", "EXDATE;VALUE=DATE:20250604", "END:VEVENT")
+	var days []string
+	for _, occurrence := range ExpandEvents([]sqlc.CalendarEvent{event}, time.Date(2025, 6, 2, 0, 0, 0, 0, time.Local), time.Date(2025, 6, 7, 0, 0, 0, 0, time.Local)) {
+		days = append(days, occurrence.Start.Format("01-02"))
+	}
+	assert.Equal(t, []string{"06-02", "06-03", "06-05", "06-06"}, days)
+
+	// A changed occurrence replaces the one it changes
+	event = testEvent(t, "BEGIN:VEVENT", "RRULE:FREQ=DAILY", "DTSTART:20250602T090000Z", "DTEND:20250602T100000Z", "END:VEVENT")
+	event.Uid = "daily"
+	changed := testEvent(t, "BEGIN:VEVENT", "RECURRENCE-ID:20250603T090000Z", "DTSTART:20250603T150000Z", "DTEND:20250603T160000Z", "END:VEVENT")
+	changed.Uid = "daily"
+	var starts []string
+	for _, occurrence := range ExpandEvents([]sqlc.CalendarEvent{event, changed}, from, from.AddDate(0, 0, 2)) {
+		starts = append(starts, occurrence.Start.UTC().Format("01-02 15:04"))
+	}
+	assert.Equal(t, []string{"06-02 09:00", "06-03 15:00"}, starts)
+}
+
+func TestICalReimport(t *testing.T) {
+	db, queries := dbtest.Open(t)
+	user := dbtest.User(t, queries)
+	ctx := context.Background()
+
+	invite := func(summary, sequence string) []*ICalComponent {
+		return []*ICalComponent{testCalendar(t,
+			"METHOD:REQUEST",
+			"BEGIN:VEVENT",
+			"UID:standup@example.com",
+			"SUMMARY:"+summary,
+			"SEQUENCE:"+sequence,
+			"DTSTART:20250602T090000Z",
+			"DTEND:20250602T091500Z",
+			"END:VEVENT",
+		)}
+	}
+	importCalendars := func(calendars []*ICalComponent) *ImportReport {
+		var report *ImportReport
+		err := NewUnitOfWork(db, queries).Do(ctx, func(tx *TxServices) error {
+			var err error
+			report, err = tx.ICal.Import(ctx, user.ID, calendars)
+			return err
+		})
+		require.NoError(t, err)
+		return report
+	}
+
+	tests := []struct {
+		name     string
+		calendar []*ICalComponent
+		created  int
+		updated  int
+		skipped  int
+		title    string
+	}{
+		{"first import creates", invite("Standup", "0"), 1, 0, 0, "Standup"},
+		{"same invite changes nothing", invite("Standup", "0"), 0, 0, 1, "Standup"},
+		{"update changes the event", invite("Daily standup", "1"), 0, 1, 0, "Daily standup"},
+		{"older invite is skipped", invite("Standup", "0"), 0, 0, 1, "Daily standup"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := importCalendars(tt.calendar)
+			assert.Equal(t, tt.created, report.EventsCreated)
+			assert.Equal(t, tt.updated, report.EventsUpdated)
+			assert.Equal(t, tt.skipped, report.EventsSkipped)
+
+			events, err := queries.ListCalendarEvents(ctx, pgtype.Int4{Int32: user.ID, Valid: true})
+			require.NoError(t, err)
+			require.Len(t, events, 1, "the UID matches the imported event")
+			assert.Equal(t, tt.title, events[0].Title)
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+)
+
+// Entity types recorded in the operation journal
+const (
+	JournalTask    = "task"
+	JournalProject = "project"
+)
+
+// journal records the changes made through services so they can be undone.
+// Services sharing a journal, like the ones of a UnitOfWork, record all their
+// changes as a single operation that is undone in one step
+type journal struct {
+	queries     *sqlc.Queries
+	operationID int32
+}
+
+func newJournal(queries *sqlc.Queries) *journal {
+	return &journal{
+		queries: queries,
+	}
+}
+
+// begin starts the operation unless one is already started. A new operation
+// discards everything that could still be redone
+func (j *journal) begin(ctx context.Context, userID int32, description string) error {
+	if j.operationID != 0 {
+		return nil
+	}
+
+	if err := j.queries.DeleteUndoneJournalOperations(ctx, userID); err != nil {
+		return fmt.Errorf("failed to clear redo history: %w", err)
+	}
+
+	operation, err := j.queries.CreateJournalOperation(ctx, sqlc.CreateJournalOperationParams{
+		UserID:      userID,
+		Description: description,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record operation: %w", err)
+	}
+
+	j.operationID = operation.ID
+	return nil
+}
+
+// recordTask saves the task before and after a change, nil meaning it didn't
+// exist, and appends the change to the task history
+func (j *journal) recordTask(ctx context.Context, userID int32, description string, before, after *sqlc.Task) error {
+	id := entityID(before, after, func(t *sqlc.Task) int32 { return t.ID })
+	if err := j.record(ctx, userID, description, JournalTask, id, before, after); err != nil {
+		return err
+	}
+	return recordTaskEvents(ctx, j.queries, userID, before, after)
+}
+
+// recordProject saves the project before and after a change, nil meaning it didn't exist
+func (j *journal) recordProject(ctx context.Context, userID int32, description string, before, after *sqlc.Project) error {
+	id := entityID(before, after, func(p *sqlc.Project) int32 { return p.ID })
+	return j.record(ctx, userID, description, JournalProject, id, before, after)
+}
+
+func (j *journal) record(ctx context.Context, userID int32, description, entityType string, id int32, before, after any) error {
+	if err := j.begin(ctx, userID, description); err != nil {
+		return err
+	}
+
+	beforeState, err := marshalState(before)
+	if err != nil {
+		return err
+	}
+	afterState, err := marshalState(after)
+	if err != nil {
+		return err
+	}
+
+	err = j.queries.CreateJournalChange(ctx, sqlc.CreateJournalChangeParams{
+		OperationID: j.operationID,
+		EntityType:  entityType,
+		EntityID:    id,
+		BeforeState: beforeState,
+		AfterState:  afterState,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record change: %w", err)
+	}
+
+	return nil
+}
+
+func entityID[T any](before, after *T, id func(*T) int32) int32 {
+	if after != nil {
+		return id(after)
+	}
+	return id(before)
+}
+
+// marshalState encodes a *sqlc.Task or *sqlc.Project, a nil pointer becoming SQL NULL
+func marshalState(state any) ([]byte, error) {
+	switch v := state.(type) {
+	case *sqlc.Task:
+		if v == nil {
+			return nil, nil
+		}
+	case *sqlc.Project:
+		if v == nil {
+			return nil, nil
+		}
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode journal state: %w", err)
+	}
+	return data, nil
+}
+
+// Describe names the operation of a unit of work, like "delete 3 tasks", when
+// called before its first change. Otherwise the first change names it
+func (t *TxServices) Describe(ctx context.Context, userID int32, description string) error {
+	return t.Tasks.journal.begin(ctx, userID, description)
+}
+
+// JournalService undoes and redoes the operations recorded in the journal
+type JournalService struct {
+	queries *sqlc.Queries
+}
+
+// NewJournalService creates a new JournalService
+func NewJournalService(queries *sqlc.Queries) *JournalService {
+	return &JournalService{
+		queries: queries,
+	}
+}
+
+// Undo reverts the last steps operations, newest first, and returns them.
+// Run it in a UnitOfWork so a failing step leaves everything as it was
+func (s *JournalService) Undo(ctx context.Context, userID int32, steps int) ([]sqlc.JournalOperation, error) {
+	operations, err := s.queries.ListUndoableJournalOperations(ctx, sqlc.ListUndoableJournalOperationsParams{
+		UserID: userID,
+		Limit:  int32(steps),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list operations: %w", err)
+	}
+
+	for _, operation := range operations {
+		changes, err := s.queries.ListJournalChanges(ctx, operation.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list changes: %w", err)
+		}
+
+		// Put back the earlier state in the reverse order of the changes
+		for i := len(changes) - 1; i >= 0; i-- {
+			if err := s.apply(ctx, userID, changes[i], changes[i].BeforeState); err != nil {
+				return nil, fmt.Errorf("failed to undo %q: %w", operation.Description, err)
+			}
+		}
+
+		err = s.queries.SetJournalOperationUndone(ctx, sqlc.SetJournalOperationUndoneParams{
+			UndoneAt: pgtype.Timestamptz{
+				Time:  time.Now(),
+				Valid: true,
+			},
+			ID:     operation.ID,
+			UserID: userID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to mark operation as undone: %w", err)
+		}
+	}
+
+	return operations, nil
+}
+
+// Redo applies the last steps undone operations again, oldest first, and returns them
+func (s *JournalService) Redo(ctx context.Context, userID int32, steps int) ([]sqlc.JournalOperation, error) {
+	operations, err := s.queries.ListRedoableJournalOperations(ctx, sqlc.ListRedoableJournalOperationsParams{
+		UserID: userID,
+		Limit:  int32(steps),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list operations: %w", err)
+	}
+
+	for _, operation := range operations {
+		changes, err := s.queries.ListJournalChanges(ctx, operation.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list changes: %w", err)
+		}
+
+		for _, change := range changes {
+			if err := s.apply(ctx, userID, change, change.AfterState); err != nil {
+				return nil, fmt.Errorf("failed to redo %q: %w", operation.Description, err)
+			}
+		}
+
+		err = s.queries.SetJournalOperationUndone(ctx, sqlc.SetJournalOperationUndoneParams{
+			ID:     operation.ID,
+			UserID: userID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to mark operation as redone: %w", err)
+		}
+	}
+
+	return operations, nil
+}
+
+// apply sets an entity to the recorded state, purging it when the state is empty
+func (s *JournalService) apply(ctx context.Context, userID int32, change sqlc.JournalChange, state []byte) error {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+
+	switch change.EntityType {
+	case JournalTask:
+		if state == nil {
+			deleted, err := s.queries.PurgeTask(ctx, sqlc.PurgeTaskParams{
+				ID:     change.EntityID,
+				UserID: user,
+			})
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to delete task %d: %w", change.EntityID, err)
+			}
+			return recordTaskEvents(ctx, s.queries, userID, &deleted, nil)
+		}
+
+		var task sqlc.Task
+		if err := json.Unmarshal(state, &task); err != nil {
+			return fmt.Errorf("failed to decode task %d: %w", change.EntityID, err)
+		}
+
+		// Keep the current state so the task history shows what was put back
+		var current *sqlc.Task
+		existing, err := s.queries.GetTaskIncludingDeleted(ctx, sqlc.GetTaskIncludingDeletedParams{
+			ID:     task.ID,
+			UserID: user,
+		})
+		if err == nil {
+			current = &existing
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to get task %d: %w", change.EntityID, err)
+		}
+
+		// States recorded before tasks had a UUID keep the current one
+		if !task.Uuid.Valid {
+			task.Uuid = newUUID()
+			if current != nil {
+				task.Uuid = current.Uuid
+			}
+		}
+
+		restored, err := s.queries.RestoreTask(ctx, sqlc.RestoreTaskParams{
+			ID:              task.ID,
+			UserID:          user,
+			Description:     task.Description,
+			Status:          task.Status,
+			Priority:        task.Priority,
+			DueDate:         task.DueDate,
+			StartDate:       task.StartDate,
+			CompletedAt:     task.CompletedAt,
+			ProjectID:       task.ProjectID,
+			Recurrence:      task.Recurrence,
+			Tags:            task.Tags,
+			Notes:           task.Notes,
+			CreatedAt:       task.CreatedAt,
+			UpdatedAt:       task.UpdatedAt,
+			Dependent:       task.Dependent,
+			EstimateMinutes: task.EstimateMinutes,
+			DeletedAt:       task.DeletedAt,
+			Uuid:            task.Uuid,
+			WaitUntil:       task.WaitUntil,
+			Scheduled:       task.Scheduled,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to restore task %d: %w", change.EntityID, err)
+		}
+		if err := recordTaskEvents(ctx, s.queries, userID, current, &restored); err != nil {
+			return err
+		}
+
+	case JournalProject:
+		if state == nil {
+			err := s.queries.PurgeProject(ctx, sqlc.PurgeProjectParams{
+				ID:     change.EntityID,
+				UserID: user,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to delete project %d: %w", change.EntityID, err)
+			}
+			return nil
+		}
+
+		var project sqlc.Project
+		if err := json.Unmarshal(state, &project); err != nil {
+			return fmt.Errorf("failed to decode project %d: %w", change.EntityID, err)
+		}
+		_, err := s.queries.RestoreProject(ctx, sqlc.RestoreProjectParams{
+			ID:               project.ID,
+			UserID:           user,
+			Name:             project.Name,
+			Description:      project.Description,
+			Deadline:         project.Deadline,
+			CreatedAt:        project.CreatedAt,
+			UpdatedAt:        project.UpdatedAt,
+			PomodoroPresetID: project.PomodoroPresetID,
+			DeletedAt:        project.DeletedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to restore project %d: %w", change.EntityID, err)
+		}
+
+	default:
+		return fmt.Errorf("unknown journal entity %q", change.EntityType)
+	}
+
+	return nil
+}
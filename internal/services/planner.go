@@ -0,0 +1,492 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+)
+
+// PlannerConfig holds the working hours and weekdays tasks are planned in
+type PlannerConfig struct {
+	Hours WorkingHours
+	Days  []time.Weekday
+}
+
+// DefaultWorkDays are Monday to Friday
+var DefaultWorkDays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+// Works tells whether day is a work day
+func (c PlannerConfig) Works(day time.Time) bool {
+	return slices.Contains(c.Days, day.Weekday())
+}
+
+// ParseWorkDays parses weekdays like "mon-fri" or "mon,wed,sat". Ranges may
+// wrap around the weekend, like "sun-thu"
+func ParseWorkDays(input string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, part := range strings.Split(input, ",") {
+		from, to, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		first, err := parseWeekday(from)
+		if err != nil {
+			return nil, err
+		}
+		last := first
+		if isRange {
+			if last, err = parseWeekday(to); err != nil {
+				return nil, err
+			}
+		}
+		for day := first; ; day = (day + 1) % 7 {
+			if !slices.Contains(days, day) {
+				days = append(days, day)
+			}
+			if day == last {
+				break
+			}
+		}
+	}
+	sortWeekdays(days)
+	return days, nil
+}
+
+func parseWeekday(input string) (time.Weekday, error) {
+	input = strings.ToLower(strings.TrimSpace(input))
+	if len(input) >= 3 {
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.HasPrefix(strings.ToLower(day.String()), input) {
+				return day, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid weekday %q (use e.g. mon-fri or mon,wed,fri)", input)
+}
+
+// FormatWorkDays formats weekdays like "Mon-Fri" or "Mon, Wed, Sat"
+func FormatWorkDays(days []time.Weekday) string {
+	days = slices.Clone(days)
+	sortWeekdays(days)
+	short := func(day time.Weekday) string {
+		return day.String()[:3]
+	}
+
+	var parts []string
+	for i := 0; i < len(days); {
+		j := i
+		for j+1 < len(days) && days[j+1] == (days[j]+1)%7 {
+			j++
+		}
+		switch {
+		case j-i >= 2:
+			parts = append(parts, short(days[i])+"-"+short(days[j]))
+		case j > i:
+			parts = append(parts, short(days[i]), short(days[j]))
+		default:
+			parts = append(parts, short(days[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sortWeekdays sorts days from Monday to Sunday
+func sortWeekdays(days []time.Weekday) {
+	sort.Slice(days, func(i, j int) bool {
+		return (days[i]+6)%7 < (days[j]+6)%7
+	})
+}
+
+// PlannedBlock is a stretch of time planned for working on a task
+type PlannedBlock struct {
+	Task  sqlc.Task
+	Start time.Time
+	End   time.Time
+}
+
+// UnplannedTask is an open task there was no room for in a plan
+type UnplannedTask struct {
+	Task      sqlc.Task
+	Remaining time.Duration
+}
+
+// Plan is a proposed schedule for a range of days
+type Plan struct {
+	From time.Time
+	To   time.Time
+	// Blocks are the new blocks, in order
+	Blocks []PlannedBlock
+	// Replaced are the events of planned blocks that haven't started yet, which
+	// the new blocks replace
+	Replaced []int32
+	// Events are the timed events the blocks were planned around
+	Events    []EventOccurrence
+	Unplanned []UnplannedTask
+}
+
+// PlannerService plans open tasks into the free time between calendar events
+type PlannerService struct {
+	queries *sqlc.Queries
+}
+
+// NewPlannerService creates a new planner service
+func NewPlannerService(queries *sqlc.Queries) *PlannerService {
+	return &PlannerService{
+		queries: queries,
+	}
+}
+
+// GetConfig returns the user's working hours and days. Users who haven't set
+// them work from 9 to 17, Monday to Friday
+func (s *PlannerService) GetConfig(ctx context.Context, userID int32) (*PlannerConfig, error) {
+	config, err := s.queries.GetPlannerConfig(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &PlannerConfig{
+			Hours: DefaultWorkingHours,
+			Days:  slices.Clone(DefaultWorkDays),
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get planner config: %w", err)
+	}
+	return plannerConfig(config), nil
+}
+
+// SetConfig stores the user's working hours and days
+func (s *PlannerService) SetConfig(ctx context.Context, userID int32, config PlannerConfig) (*PlannerConfig, error) {
+	if config.Hours.End <= config.Hours.Start {
+		return nil, fmt.Errorf("working hours end before they start")
+	}
+	if len(config.Days) == 0 {
+		return nil, fmt.Errorf("at least one work day is needed")
+	}
+
+	// Weekdays are stored in ISO order, where Monday is 1 and Sunday 7
+	var days []int32
+	for _, day := range config.Days {
+		days = append(days, int32((day+6)%7+1))
+	}
+	slices.Sort(days)
+
+	stored, err := s.queries.UpsertPlannerConfig(ctx, sqlc.UpsertPlannerConfigParams{
+		UserID:    userID,
+		WorkStart: int32(config.Hours.Start / time.Minute),
+		WorkEnd:   int32(config.Hours.End / time.Minute),
+		WorkDays:  slices.Compact(days),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set planner config: %w", err)
+	}
+	return plannerConfig(stored), nil
+}
+
+func plannerConfig(config sqlc.PlannerConfig) *PlannerConfig {
+	result := &PlannerConfig{
+		Hours: WorkingHours{
+			Start: time.Duration(config.WorkStart) * time.Minute,
+			End:   time.Duration(config.WorkEnd) * time.Minute,
+		},
+	}
+	for _, day := range config.WorkDays {
+		result.Days = append(result.Days, time.Weekday(day%7))
+	}
+	return result
+}
+
+// planStep is what block start times are rounded up to
+const planStep = 5 * time.Minute
+
+// Plan proposes blocks of time for the user's open tasks on the days starting
+// at the day of from, in the working hours of config from now on.
+//
+// Tasks are planned by due date, then priority, then estimate, shortest
+// first. A task needs its estimate less the Pomodoros spent on it, or a
+// single Pomodoro when it has no estimate or went over it. Tasks with open
+// subtasks are left to their subtasks, and tasks aren't planned before the
+// day they wait until or are scheduled for. Blocks planned earlier that
+// haven't started yet are planned again, the others count towards their task
+func (s *PlannerService) Plan(ctx context.Context, userID int32, from time.Time, days int, config PlannerConfig, now time.Time) (*Plan, error) {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+	start := dateOf(from)
+	end := start.AddDate(0, 0, days)
+	plan := &Plan{
+		From: start,
+		To:   end,
+	}
+
+	blocks, err := s.queries.ListTaskBlocks(ctx, sqlc.ListTaskBlocksParams{
+		UserID: user,
+		EndTime: pgtype.Timestamptz{
+			Time:  now,
+			Valid: true,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get planned blocks: %w", err)
+	}
+	replaced, planned := splitBlocks(blocks, start, end, now)
+	plan.Replaced = replaced
+
+	events, err := s.queries.ListCalendarEventsInRange(ctx, sqlc.ListCalendarEventsInRangeParams{
+		UserID: user,
+		RangeStart: pgtype.Timestamptz{
+			Time:  start,
+			Valid: true,
+		},
+		RangeEnd: pgtype.Timestamptz{
+			Time:  end.Add(-time.Nanosecond),
+			Valid: true,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar events: %w", err)
+	}
+	for _, occurrence := range ExpandEvents(events, start, end) {
+		// All-day events don't take up time, since they are often just reminders
+		if occurrence.Event.AllDay.Bool || slices.Contains(plan.Replaced, occurrence.Event.ID) {
+			continue
+		}
+		plan.Events = append(plan.Events, occurrence)
+	}
+
+	tasks, err := s.queries.ListTasks(ctx, sqlc.ListTasksParams{
+		UserID: user,
+		Status: []string{"pending", "active"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open tasks: %w", err)
+	}
+	pomodoros := NewPomodoroService(s.queries)
+	pomodoroLength := time.Duration(pomodoros.PomodoroMinutes(ctx, userID)) * time.Minute
+	spent, err := pomodoros.GetTaskPomodoroCounts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := remainingWork(tasks, spent, pomodoroLength, planned)
+	sortPlanTasks(queue)
+	plan.Blocks = placeBlocks(queue, plan.Events, start, end, config, now)
+
+	for _, task := range queue {
+		if task.Remaining > 0 {
+			plan.Unplanned = append(plan.Unplanned, task)
+		}
+	}
+
+	return plan, nil
+}
+
+// splitBlocks sorts out the blocks planned earlier. The events of the ones in
+// the planned days that haven't started yet are returned to be replaced, what
+// is left of the others counts towards their task
+func splitBlocks(blocks []sqlc.ListTaskBlocksRow, start, end, now time.Time) ([]int32, map[int32]time.Duration) {
+	var replaced []int32
+	planned := make(map[int32]time.Duration)
+	for _, block := range blocks {
+		blockStart := block.StartTime.Time
+		if !blockStart.Before(now) && !blockStart.Before(start) && blockStart.Before(end) {
+			if !slices.Contains(replaced, block.EventID) {
+				replaced = append(replaced, block.EventID)
+			}
+			continue
+		}
+		if blockStart.Before(now) {
+			blockStart = now
+		}
+		planned[block.TaskID.Int32] += block.EndTime.Time.Sub(blockStart)
+	}
+	return replaced, planned
+}
+
+// remainingWork returns the time each open task still needs, leaving out the
+// tasks with open subtasks and the ones planned in full already. spent is the
+// number of Pomodoros spent on each task
+func remainingWork(tasks []sqlc.Task, spent map[int32]int, pomodoroLength time.Duration, planned map[int32]time.Duration) []UnplannedTask {
+	parents := make(map[int32]bool)
+	for _, task := range tasks {
+		if task.Dependent.Valid {
+			parents[task.Dependent.Int32] = true
+		}
+	}
+
+	var queue []UnplannedTask
+	for _, task := range tasks {
+		if parents[task.ID] {
+			continue
+		}
+		remaining := pomodoroLength
+		if task.EstimateMinutes.Valid {
+			left := time.Duration(task.EstimateMinutes.Int32)*time.Minute - time.Duration(spent[task.ID])*pomodoroLength
+			if left > 0 {
+				remaining = left
+			}
+		}
+		remaining -= planned[task.ID]
+		if remaining > 0 {
+			queue = append(queue, UnplannedTask{
+				Task:      task,
+				Remaining: remaining,
+			})
+		}
+	}
+	return queue
+}
+
+// placeBlocks fills the free time between events in the working hours of the
+// days from start to end with the tasks of queue, in order and from now on.
+// The time placed is taken off the Remaining of each task
+func placeBlocks(queue []UnplannedTask, events []EventOccurrence, start, end time.Time, config PlannerConfig, now time.Time) []PlannedBlock {
+	var busy []TimeSpan
+	for _, occurrence := range events {
+		busy = append(busy, TimeSpan{Start: occurrence.Start, End: occurrence.End})
+	}
+
+	var blocks []PlannedBlock
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if !config.Works(day) {
+			continue
+		}
+		within := config.Hours.On(day)
+		if now.After(within.Start) {
+			within.Start = now.Add(planStep - 1).Truncate(planStep)
+		}
+		if !within.End.After(within.Start) {
+			continue
+		}
+
+		for _, gap := range FreeTime(within, busy, minimumFreeTime) {
+			cursor := gap.Start
+			for i := range queue {
+				task := &queue[i]
+				if task.Remaining <= 0 || deferredPast(task.Task, day) {
+					continue
+				}
+				// Pieces shorter than the minimum free time aren't worth it,
+				// unless that is all the task needs
+				length := min(task.Remaining, gap.End.Sub(cursor))
+				if length < min(task.Remaining, minimumFreeTime) {
+					continue
+				}
+				blocks = append(blocks, PlannedBlock{
+					Task:  task.Task,
+					Start: cursor,
+					End:   cursor.Add(length),
+				})
+				cursor = cursor.Add(length)
+				task.Remaining -= length
+			}
+		}
+	}
+	return blocks
+}
+
+// sortPlanTasks orders tasks by due date, priority and estimate, the ones
+// without coming last
+func sortPlanTasks(tasks []UnplannedTask) {
+	rank := map[string]int{"H": 0, "M": 1, "L": 2}
+	priority := func(task sqlc.Task) int {
+		if r, ok := rank[task.Priority.String]; ok && task.Priority.Valid {
+			return r
+		}
+		return len(rank)
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i].Task, tasks[j].Task
+		if a.DueDate.Valid != b.DueDate.Valid {
+			return a.DueDate.Valid
+		}
+		if dueA, dueB := dateOf(a.DueDate.Time.Local()), dateOf(b.DueDate.Time.Local()); a.DueDate.Valid && !dueA.Equal(dueB) {
+			return dueA.Before(dueB)
+		}
+		if priority(a) != priority(b) {
+			return priority(a) < priority(b)
+		}
+		if a.EstimateMinutes.Valid != b.EstimateMinutes.Valid {
+			return a.EstimateMinutes.Valid
+		}
+		if a.EstimateMinutes.Int32 != b.EstimateMinutes.Int32 {
+			return a.EstimateMinutes.Int32 < b.EstimateMinutes.Int32
+		}
+		return a.ID < b.ID
+	})
+}
+
+// Apply writes a plan to the calendar. The blocks it replaces are deleted and
+// each new block becomes an event linked to its task
+func (s *PlannerService) Apply(ctx context.Context, userID int32, plan *Plan) error {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+
+	for _, id := range plan.Replaced {
+		err := s.queries.DeleteCalendarEvent(ctx, sqlc.DeleteCalendarEventParams{
+			ID:     id,
+			UserID: user,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete planned block: %w", err)
+		}
+	}
+
+	for _, block := range plan.Blocks {
+		event, err := s.queries.CreateCalendarEvent(ctx, sqlc.CreateCalendarEventParams{
+			UserID: user,
+			Title:  block.Task.Description,
+			StartTime: pgtype.Timestamptz{
+				Time:  block.Start,
+				Valid: true,
+			},
+			EndTime: pgtype.Timestamptz{
+				Time:  block.End,
+				Valid: true,
+			},
+			AllDay: pgtype.Bool{
+				Bool:  false,
+				Valid: true,
+			},
+			ProjectID: block.Task.ProjectID,
+			Uid:       FormatUUID(newUUID()),
+			Exdates:   []time.Time{},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create block for task %q: %w", block.Task.Description, err)
+		}
+
+		err = s.queries.LinkTaskToEvent(ctx, sqlc.LinkTaskToEventParams{
+			TaskID: pgtype.Int4{
+				Int32: block.Task.ID,
+				Valid: true,
+			},
+			EventID: pgtype.Int4{
+				Int32: event.ID,
+				Valid: true,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to link block to task %q: %w", block.Task.Description, err)
+		}
+	}
+
+	return nil
+}
+
+// deferredPast reports whether a task waits until or is scheduled for a day
+// after day
+func deferredPast(task sqlc.Task, day time.Time) bool {
+	for _, deferred := range []pgtype.Timestamptz{task.WaitUntil, task.Scheduled} {
+		if deferred.Valid && dateOf(deferred.Time.Local()).After(day) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkDays(t *testing.T) {
+	days, err := ParseWorkDays("mon-fri")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultWorkDays, days)
+	assert.Equal(t, "Mon-Fri", FormatWorkDays(days))
+
+	days, err = ParseWorkDays("sat-mon,wed")
+	assert.NoError(t, err)
+	assert.Equal(t, "Mon, Wed, Sat, Sun", FormatWorkDays(days))
+
+	_, err = ParseWorkDays("someday")
+	assert.Error(t, err)
+}
+
+// planTime is a time on the Monday the planner tests start at, days later
+func planTime(days, hour, minute int) time.Time {
+	return time.Date(2025, 6, 2+days, hour, minute, 0, 0, time.Local)
+}
+
+func planTimestamp(t time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{
+		Time:  t,
+		Valid: true,
+	}
+}
+
+func TestSortPlanTasks(t *testing.T) {
+	high := pgtype.Text{String: "H", Valid: true}
+	medium := pgtype.Text{String: "M", Valid: true}
+	low := pgtype.Text{String: "L", Valid: true}
+	estimate := func(minutes int32) pgtype.Int4 {
+		return pgtype.Int4{Int32: minutes, Valid: true}
+	}
+
+	tests := []struct {
+		name  string
+		tasks []sqlc.Task
+		want  []int32
+	}{
+		{
+			name: "due date first, tasks without one last",
+			tasks: []sqlc.Task{
+				{ID: 1, Priority: high},
+				{ID: 2, DueDate: planTimestamp(planTime(3, 9, 0))},
+				{ID: 3, DueDate: planTimestamp(planTime(1, 9, 0))},
+			},
+			want: []int32{3, 2, 1},
+		},
+		{
+			name: "due the same day by priority",
+			tasks: []sqlc.Task{
+				{ID: 1, DueDate: planTimestamp(planTime(1, 8, 0))},
+				{ID: 2, DueDate: planTimestamp(planTime(1, 18, 0)), Priority: high},
+				{ID: 3, DueDate: planTimestamp(planTime(1, 9, 0)), Priority: low},
+			},
+			want: []int32{2, 3, 1},
+		},
+		{
+			name: "priority, then shortest estimate",
+			tasks: []sqlc.Task{
+				{ID: 1, Priority: medium, EstimateMinutes: estimate(10)},
+				{ID: 2, Priority: high},
+				{ID: 3, Priority: high, EstimateMinutes: estimate(60)},
+				{ID: 4, Priority: high, EstimateMinutes: estimate(30)},
+			},
+			want: []int32{4, 3, 2, 1},
+		},
+		{
+			name: "invalid priority counts as none",
+			tasks: []sqlc.Task{
+				{ID: 1, Priority: pgtype.Text{String: "X", Valid: true}},
+				{ID: 2, Priority: low},
+			},
+			want: []int32{2, 1},
+		},
+		{
+			name: "ID last",
+			tasks: []sqlc.Task{
+				{ID: 3},
+				{ID: 1},
+				{ID: 2},
+			},
+			want: []int32{1, 2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var queue []UnplannedTask
+			for _, task := range tt.tasks {
+				queue = append(queue, UnplannedTask{Task: task})
+			}
+			sortPlanTasks(queue)
+
+			var ids []int32
+			for _, task := range queue {
+				ids = append(ids, task.Task.ID)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestSplitBlocks(t *testing.T) {
+	block := func(taskID, eventID int32, start, end time.Time) sqlc.ListTaskBlocksRow {
+		return sqlc.ListTaskBlocksRow{
+			TaskID:    pgtype.Int4{Int32: taskID, Valid: true},
+			EventID:   eventID,
+			StartTime: planTimestamp(start),
+			EndTime:   planTimestamp(end),
+		}
+	}
+
+	replaced, planned := splitBlocks([]sqlc.ListTaskBlocksRow{
+		// Started, the rest of it counts
+		block(1, 1, planTime(0, 11, 0), planTime(0, 13, 0)),
+		// Not started yet, planned again
+		block(1, 2, planTime(0, 14, 0), planTime(0, 15, 0)),
+		block(2, 2, planTime(0, 14, 0), planTime(0, 15, 0)),
+		// After the planned days
+		block(2, 3, planTime(2, 9, 0), planTime(2, 10, 0)),
+	}, planTime(0, 0, 0), planTime(2, 0, 0), planTime(0, 12, 0))
+
+	assert.Equal(t, []int32{2}, replaced)
+	assert.Equal(t, map[int32]time.Duration{1: time.Hour, 2: time.Hour}, planned)
+}
+
+func TestRemainingWork(t *testing.T) {
+	estimate := func(minutes int32) pgtype.Int4 {
+		return pgtype.Int4{Int32: minutes, Valid: true}
+	}
+	tasks := []sqlc.Task{
+		{ID: 1},
+		{ID: 2, Dependent: pgtype.Int4{Int32: 1, Valid: true}, EstimateMinutes: estimate(100)},
+		{ID: 3, EstimateMinutes: estimate(50)},
+		{ID: 4, EstimateMinutes: estimate(60)},
+		{ID: 5, EstimateMinutes: estimate(60)},
+	}
+	spent := map[int32]int{2: 2, 3: 3}
+	planned := map[int32]time.Duration{4: time.Hour, 5: 20 * time.Minute}
+
+	remaining := make(map[int32]time.Duration)
+	for _, task := range remainingWork(tasks, spent, 25*time.Minute, planned) {
+		remaining[task.Task.ID] = task.Remaining
+	}
+	assert.Equal(t, map[int32]time.Duration{
+		// 1 is left to its subtask and 4 is planned in full
+		2: 50 * time.Minute,
+		// Over the estimate, one more Pomodoro
+		3: 25 * time.Minute,
+		5: 40 * time.Minute,
+	}, remaining)
+}
+
+func TestPlaceBlocks(t *testing.T) {
+	config := PlannerConfig{
+		Hours: WorkingHours{Start: 9 * time.Hour, End: 12 * time.Hour},
+		Days:  DefaultWorkDays,
+	}
+	event := func(days, startHour, startMinute, endHour, endMinute int) EventOccurrence {
+		return EventOccurrence{
+			Start: planTime(days, startHour, startMinute),
+			End:   planTime(days, endHour, endMinute),
+		}
+	}
+	task := func(id int32, remaining time.Duration) UnplannedTask {
+		return UnplannedTask{
+			Task:      sqlc.Task{ID: id},
+			Remaining: remaining,
+		}
+	}
+	scheduled := task(1, 30*time.Minute)
+	scheduled.Task.Scheduled = planTimestamp(planTime(1, 0, 0))
+	earlier := planTime(-1, 12, 0)
+
+	tests := []struct {
+		name   string
+		queue  []UnplannedTask
+		events []EventOccurrence
+		// from is the first day planned, days after the Monday
+		from int
+		days int
+		now  time.Time
+		want []string
+		left []time.Duration
+	}{
+		{
+			name:   "gaps are filled in order",
+			queue:  []UnplannedTask{task(1, time.Hour), task(2, 30*time.Minute)},
+			events: []EventOccurrence{event(0, 10, 0, 10, 30)},
+			days:   1,
+			now:    earlier,
+			want:   []string{"Mon 09:00-10:00 #1", "Mon 10:30-11:00 #2"},
+			left:   []time.Duration{0, 0},
+		},
+		{
+			name:   "tasks are split over gaps",
+			queue:  []UnplannedTask{task(1, 90*time.Minute)},
+			events: []EventOccurrence{event(0, 10, 0, 10, 30)},
+			days:   1,
+			now:    earlier,
+			want:   []string{"Mon 09:00-10:00 #1", "Mon 10:30-11:00 #1"},
+			left:   []time.Duration{0},
+		},
+		{
+			name:   "short leftovers are skipped",
+			queue:  []UnplannedTask{task(1, 10*time.Minute), task(2, time.Hour)},
+			events: []EventOccurrence{event(0, 9, 20, 11, 0)},
+			days:   1,
+			now:    earlier,
+			want:   []string{"Mon 09:00-09:10 #1", "Mon 11:00-12:00 #2"},
+			left:   []time.Duration{0, 0},
+		},
+		{
+			name:  "gaps shorter than the minimum are skipped",
+			queue: []UnplannedTask{task(1, 10*time.Minute)},
+			events: []EventOccurrence{
+				event(0, 9, 10, 11, 0),
+				event(0, 11, 10, 12, 0),
+			},
+			days: 2,
+			now:  earlier,
+			want: []string{"Tue 09:00-09:10 #1"},
+			left: []time.Duration{0},
+		},
+		{
+			name:  "planning starts now, rounded up",
+			queue: []UnplannedTask{task(1, 30*time.Minute)},
+			days:  1,
+			now:   planTime(0, 9, 42),
+			want:  []string{"Mon 09:45-10:15 #1"},
+			left:  []time.Duration{0},
+		},
+		{
+			name:  "days that have ended are skipped",
+			queue: []UnplannedTask{task(1, 30*time.Minute)},
+			days:  2,
+			now:   planTime(0, 11, 50),
+			want:  []string{"Tue 09:00-09:30 #1"},
+			left:  []time.Duration{0},
+		},
+		{
+			name:  "only work days",
+			queue: []UnplannedTask{task(1, 30*time.Minute)},
+			from:  -2,
+			days:  3,
+			now:   planTime(-3, 12, 0),
+			want:  []string{"Mon 09:00-09:30 #1"},
+			left:  []time.Duration{0},
+		},
+		{
+			name:  "deferred tasks wait for their day",
+			queue: []UnplannedTask{scheduled, task(2, 30*time.Minute)},
+			days:  2,
+			now:   earlier,
+			want:  []string{"Mon 09:00-09:30 #2", "Tue 09:00-09:30 #1"},
+			left:  []time.Duration{0, 0},
+		},
+		{
+			name:  "what doesn't fit is left",
+			queue: []UnplannedTask{task(1, 4*time.Hour)},
+			days:  1,
+			now:   earlier,
+			want:  []string{"Mon 09:00-12:00 #1"},
+			left:  []time.Duration{time.Hour},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := planTime(tt.from, 0, 0)
+			blocks := placeBlocks(tt.queue, tt.events, start, start.AddDate(0, 0, tt.days), config, tt.now)
+
+			var got []string
+			for _, block := range blocks {
+				got = append(got, fmt.Sprintf("%s %s-%s #%d", block.Start.Format("Mon"),
+					block.Start.Format("15:04"), block.End.Format("15:04"), block.Task.ID))
+			}
+			assert.Equal(t, tt.want, got)
+
+			var left []time.Duration
+			for _, task := range tt.queue {
+				left = append(left, task.Remaining)
+			}
+			assert.Equal(t, tt.left, left)
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// DefaultWorkMinutes is the length of a pomodoro when the user has no config
+const DefaultWorkMinutes = 25
+
+// NoProjectGroup is the accuracy group for tasks without a project
+const NoProjectGroup = "no project"
+
+// NoPriorityGroup is the accuracy group for tasks without a priority
+const NoPriorityGroup = "none"
+
+// AccuracyStat compares estimated and actual pomodoros for a group of tasks
+type AccuracyStat struct {
+	Key                string
+	Tasks              int
+	EstimatedPomodoros float64
+	ActualPomodoros    int
+	// Ratio is actual / estimated, above 1 means the tasks took longer than estimated
+	Ratio float64
+	Over  int
+	Under int
+}
+
+// EstimateAccuracy holds estimate accuracy overall, per project and per priority
+type EstimateAccuracy struct {
+	PomodoroMinutes int
+	Overall         AccuracyStat
+	ByProject       []AccuracyStat
+	ByPriority      []AccuracyStat
+}
+
+// EstimatePomodoros converts an estimate in minutes to pomodoros
+func EstimatePomodoros(minutes int32, pomodoroMinutes int) float64 {
+	if pomodoroMinutes <= 0 {
+		pomodoroMinutes = DefaultWorkMinutes
+	}
+	return float64(minutes) / float64(pomodoroMinutes)
+}
+
+// PomodoroMinutes returns the user's configured pomodoro length in minutes
+func (s *PomodoroService) PomodoroMinutes(ctx context.Context, userID int32) int {
+	config, err := s.GetUserConfig(ctx, userID)
+	if err != nil || config.WorkDuration <= 0 {
+		return DefaultWorkMinutes
+	}
+	return int(config.WorkDuration)
+}
+
+// GetTaskPomodoroCounts returns the number of completed sessions per task ID
+func (s *PomodoroService) GetTaskPomodoroCounts(ctx context.Context, userID int32) (map[int32]int, error) {
+	rows, err := s.queries.CountCompletedPomodorosByTask(ctx, pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count pomodoros per task: %w", err)
+	}
+
+	counts := make(map[int32]int, len(rows))
+	for _, row := range rows {
+		if row.TaskID.Valid {
+			counts[row.TaskID.Int32] = int(row.CompletedCount)
+		}
+	}
+
+	return counts, nil
+}
+
+// GetEstimateAccuracy compares estimated and actual pomodoros of completed tasks
+func (s *PomodoroService) GetEstimateAccuracy(ctx context.Context, userID int32) (*EstimateAccuracy, error) {
+	rows, err := s.queries.ListEstimateAccuracy(ctx, pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list estimated tasks: %w", err)
+	}
+
+	result := &EstimateAccuracy{
+		PomodoroMinutes: s.PomodoroMinutes(ctx, userID),
+		Overall:         AccuracyStat{Key: "all"},
+	}
+
+	projects := make(map[string]*AccuracyStat)
+	priorities := make(map[string]*AccuracyStat)
+
+	for _, row := range rows {
+		estimated := EstimatePomodoros(row.EstimateMinutes.Int32, result.PomodoroMinutes)
+		actual := int(row.ActualPomodoros)
+
+		project := NoProjectGroup
+		if row.ProjectName.Valid {
+			project = row.ProjectName.String
+		}
+		priority := NoPriorityGroup
+		if row.Priority.Valid && row.Priority.String != "" {
+			priority = row.Priority.String
+		}
+
+		for _, stat := range []*AccuracyStat{
+			&result.Overall,
+			accuracyGroup(projects, project),
+			accuracyGroup(priorities, priority),
+		} {
+			stat.Tasks++
+			stat.EstimatedPomodoros += estimated
+			stat.ActualPomodoros += actual
+			if float64(actual) > estimated {
+				stat.Over++
+			} else if float64(actual) < estimated {
+				stat.Under++
+			}
+		}
+	}
+
+	finishAccuracy(&result.Overall)
+	result.ByProject = sortedAccuracy(projects)
+	result.ByPriority = sortedAccuracy(priorities)
+
+	return result, nil
+}
+
+func accuracyGroup(groups map[string]*AccuracyStat, key string) *AccuracyStat {
+	stat, ok := groups[key]
+	if !ok {
+		stat = &AccuracyStat{Key: key}
+		groups[key] = stat
+	}
+	return stat
+}
+
+func finishAccuracy(stat *AccuracyStat) {
+	if stat.EstimatedPomodoros > 0 {
+		stat.Ratio = float64(stat.ActualPomodoros) / stat.EstimatedPomodoros
+	}
+}
+
+// sortedAccuracy returns the groups ordered by number of tasks, then key
+func sortedAccuracy(groups map[string]*AccuracyStat) []AccuracyStat {
+	stats := make([]AccuracyStat, 0, len(groups))
+	for _, stat := range groups {
+		finishAccuracy(stat)
+		stats = append(stats, *stat)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Tasks != stats[j].Tasks {
+			return stats[i].Tasks > stats[j].Tasks
+		}
+		return stats[i].Key < stats[j].Key
+	})
+
+	return stats
+}
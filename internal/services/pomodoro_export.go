@@ -0,0 +1,379 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jskallebak/prod/internal/util"
+)
+
+// Report export formats
+const (
+	ReportFormatText     = "text"
+	ReportFormatJSON     = "json"
+	ReportFormatCSV      = "csv"
+	ReportFormatMarkdown = "markdown"
+	ReportFormatHTML     = "html"
+)
+
+// ReportExport holds everything the report exporters write
+type ReportExport struct {
+	Title       string
+	Period      string
+	GeneratedAt time.Time
+	Report      *PomodoroReport
+	GroupBy     string
+	Groups      []GroupStat
+	Sessions    []ReportSession
+}
+
+// ReportFormatFromPath guesses the export format from a file extension
+func ReportFormatFromPath(path string) (string, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ReportFormatCSV, true
+	case ".md", ".markdown":
+		return ReportFormatMarkdown, true
+	case ".html", ".htm":
+		return ReportFormatHTML, true
+	case ".json":
+		return ReportFormatJSON, true
+	case ".txt":
+		return ReportFormatText, true
+	}
+	return "", false
+}
+
+// ReportFileExtension returns the file extension used for an export format
+func ReportFileExtension(format string) string {
+	switch format {
+	case ReportFormatCSV:
+		return ".csv"
+	case ReportFormatMarkdown:
+		return ".md"
+	case ReportFormatHTML:
+		return ".html"
+	case ReportFormatJSON:
+		return ".json"
+	}
+	return ".txt"
+}
+
+// WriteReportCSV writes one row per session
+func WriteReportCSV(w io.Writer, sessions []ReportSession) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{
+		"id", "status", "start_time", "end_time", "work_minutes", "break_minutes",
+		"focus_seconds", "task_id", "task", "project", "tags", "note",
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, session := range sessions {
+		endTime := ""
+		if session.EndTime != nil {
+			endTime = session.EndTime.Format(time.RFC3339)
+		}
+		taskID := ""
+		if session.TaskID != nil {
+			taskID = strconv.Itoa(int(*session.TaskID))
+		}
+
+		record := []string{
+			strconv.Itoa(int(session.ID)),
+			string(session.Status),
+			session.StartTime.Format(time.RFC3339),
+			endTime,
+			strconv.Itoa(int(session.WorkMinutes)),
+			strconv.Itoa(int(session.BreakMinutes)),
+			strconv.FormatInt(session.FocusSeconds, 10),
+			taskID,
+			session.Task,
+			session.Project,
+			strings.Join(session.Tags, ","),
+			session.Note,
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteReportJSON writes the report, breakdown and sessions as JSON
+func WriteReportJSON(w io.Writer, export *ReportExport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(export); err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	return nil
+}
+
+// WriteReportMarkdown writes a Markdown summary of the report
+func WriteReportMarkdown(w io.Writer, export *ReportExport) error {
+	var b strings.Builder
+	report := export.Report
+
+	fmt.Fprintf(&b, "# %s\n\n", export.Title)
+	fmt.Fprintf(&b, "Period: %s  \n", export.Period)
+	fmt.Fprintf(&b, "Generated: %s\n\n", export.GeneratedAt.Format("2006-01-02 15:04"))
+
+	b.WriteString("## Summary\n\n")
+	b.WriteString("| Metric | Value |\n|---|---|\n")
+	for _, row := range summaryRows(report) {
+		fmt.Fprintf(&b, "| %s | %s |\n", row[0], row[1])
+	}
+
+	if len(export.Groups) > 0 {
+		fmt.Fprintf(&b, "\n## Breakdown by %s\n\n", export.GroupBy)
+		b.WriteString("| Group | Sessions | Focus Time | Completion |\n|---|---:|---:|---:|\n")
+		for _, group := range export.Groups {
+			fmt.Fprintf(&b, "| %s | %d | %s | %.1f%% |\n",
+				markdownEscape(group.Key),
+				group.TotalSessions,
+				util.FormatDurationSeconds(group.FocusSeconds),
+				group.CompletionRate)
+		}
+	}
+
+	if len(report.DailyStats) > 0 {
+		b.WriteString("\n## Daily Breakdown\n\n")
+		b.WriteString("| Date | Sessions | Work Time | Completion |\n|---|---:|---:|---:|\n")
+		for _, day := range report.DailyStats {
+			fmt.Fprintf(&b, "| %s | %d | %s | %.1f%% |\n",
+				day.Date.Format("2006-01-02"),
+				day.TotalSessions,
+				util.FormatDurationSeconds(day.WorkTimeSeconds),
+				dayCompletionRate(day))
+		}
+	}
+
+	if len(report.TopTasks) > 0 {
+		b.WriteString("\n## Top Tasks\n\n")
+		b.WriteString("| ID | Task | Sessions | Total Time |\n|---:|---|---:|---:|\n")
+		for _, task := range report.TopTasks {
+			fmt.Fprintf(&b, "| %d | %s | %d | %s |\n",
+				task.ID,
+				markdownEscape(task.Description),
+				task.SessionCount,
+				util.FormatDurationSeconds(task.TotalTimeSeconds))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteReportHTML writes a self-contained HTML report with SVG bar charts
+func WriteReportHTML(w io.Writer, export *ReportExport) error {
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"duration": util.FormatDurationSeconds,
+	}).Parse(reportHTMLTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse HTML template: %w", err)
+	}
+
+	dailyBars := make([]chartValue, 0, len(export.Report.DailyStats))
+	for _, day := range export.Report.DailyStats {
+		dailyBars = append(dailyBars, chartValue{
+			Label: day.Date.Format("2006-01-02"),
+			Value: day.WorkTimeSeconds,
+		})
+	}
+
+	taskBars := make([]chartValue, 0, len(export.Report.TopTasks))
+	for _, task := range export.Report.TopTasks {
+		taskBars = append(taskBars, chartValue{
+			Label: fmt.Sprintf("#%d %s", task.ID, task.Description),
+			Value: task.TotalTimeSeconds,
+		})
+	}
+
+	groupBars := make([]chartValue, 0, len(export.Groups))
+	for _, group := range export.Groups {
+		groupBars = append(groupBars, chartValue{
+			Label: group.Key,
+			Value: group.FocusSeconds,
+		})
+	}
+
+	data := struct {
+		*ReportExport
+		Generated  string
+		Summary    [][2]string
+		DailyChart *barChart
+		TaskChart  *barChart
+		GroupChart *barChart
+	}{
+		ReportExport: export,
+		Generated:    export.GeneratedAt.Format("2006-01-02 15:04"),
+		Summary:      summaryRows(export.Report),
+		DailyChart:   newBarChart("Work time per day", dailyBars),
+		TaskChart:    newBarChart("Top tasks", taskBars),
+		GroupChart:   newBarChart("Focus time by "+export.GroupBy, groupBars),
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+	return nil
+}
+
+func summaryRows(report *PomodoroReport) [][2]string {
+	return [][2]string{
+		{"Total Sessions", strconv.Itoa(report.TotalSessions)},
+		{"Completed", fmt.Sprintf("%d (%.1f%%)", report.CompletedSessions, percentOf(int64(report.CompletedSessions), int64(report.TotalSessions)))},
+		{"Cancelled", fmt.Sprintf("%d (%.1f%%)", report.CancelledSessions, percentOf(int64(report.CancelledSessions), int64(report.TotalSessions)))},
+		{"Total Time", util.FormatDurationSeconds(report.TotalTimeSeconds)},
+		{"Work Time", util.FormatDurationSeconds(report.WorkTimeSeconds)},
+		{"Break Time", util.FormatDurationSeconds(report.BreakTimeSeconds)},
+		{"Pause Time", util.FormatDurationSeconds(report.PauseTimeSeconds)},
+		{"Average Work Session", util.FormatDurationSeconds(report.AvgWorkSessionSeconds)},
+	}
+}
+
+func percentOf(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
+
+func dayCompletionRate(day DailyStat) float64 {
+	return percentOf(int64(day.CompletedSessions), int64(day.TotalSessions))
+}
+
+func markdownEscape(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+// chartValue is one labelled value of a bar chart
+type chartValue struct {
+	Label string
+	Value int64
+}
+
+// barChart is a horizontal SVG bar chart with precomputed geometry
+type barChart struct {
+	Title  string
+	Width  int
+	Height int
+	Bars   []chartBar
+}
+
+type chartBar struct {
+	Label string
+	Text  string
+	X     int
+	Y     int
+	TextY int
+	Width int
+	EndX  int
+}
+
+const (
+	chartLabelWidth = 220
+	chartBarWidth   = 420
+	chartRowHeight  = 24
+)
+
+// newBarChart lays out the bars, scaled to the largest value. Nil if there is nothing to draw
+func newBarChart(title string, values []chartValue) *barChart {
+	if len(values) == 0 {
+		return nil
+	}
+
+	var peak int64
+	for _, v := range values {
+		if v.Value > peak {
+			peak = v.Value
+		}
+	}
+
+	chart := &barChart{
+		Title:  title,
+		Width:  chartLabelWidth + chartBarWidth + 100,
+		Height: len(values)*chartRowHeight + 10,
+	}
+
+	for i, v := range values {
+		width := 0
+		if peak > 0 {
+			width = int(float64(v.Value) / float64(peak) * chartBarWidth)
+		}
+
+		label := v.Label
+		if len([]rune(label)) > 32 {
+			label = string([]rune(label)[:29]) + "..."
+		}
+
+		y := i*chartRowHeight + 5
+		chart.Bars = append(chart.Bars, chartBar{
+			Label: label,
+			Text:  util.FormatDurationSeconds(v.Value),
+			X:     chartLabelWidth,
+			Y:     y,
+			TextY: y + 15,
+			Width: width,
+			EndX:  chartLabelWidth + width + 6,
+		})
+	}
+
+	return chart
+}
+
+const reportHTMLTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 800px; color: #222; }
+h1 { margin-bottom: 0.2em; }
+.meta { color: #666; margin-top: 0; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { padding: 4px 12px; border-bottom: 1px solid #ddd; text-align: left; }
+td.num, th.num { text-align: right; }
+svg text { font-size: 12px; fill: #333; }
+svg rect { fill: #e4572e; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">Period: {{.Period}} &middot; Generated {{.Generated}}</p>
+
+<h2>Summary</h2>
+<table>
+{{range .Summary}}<tr><th>{{index . 0}}</th><td class="num">{{index . 1}}</td></tr>
+{{end}}</table>
+{{define "chart"}}
+<h2>{{.Title}}</h2>
+<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="{{.Height}}" role="img" aria-label="{{.Title}}">
+{{range .Bars}}<text x="0" y="{{.TextY}}">{{.Label}}</text>
+<rect x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="18"></rect>
+<text x="{{.EndX}}" y="{{.TextY}}">{{.Text}}</text>
+{{end}}</svg>
+{{end}}
+{{if .GroupChart}}{{template "chart" .GroupChart}}
+<table>
+<tr><th>Group</th><th class="num">Sessions</th><th class="num">Focus Time</th><th class="num">Completion</th></tr>
+{{range .Groups}}<tr><td>{{.Key}}</td><td class="num">{{.TotalSessions}}</td><td class="num">{{duration .FocusSeconds}}</td><td class="num">{{printf "%.1f" .CompletionRate}}%</td></tr>
+{{end}}</table>
+{{end}}
+{{if .DailyChart}}{{template "chart" .DailyChart}}{{end}}
+{{if .TaskChart}}{{template "chart" .TaskChart}}{{end}}
+</body>
+</html>
+`
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testReportExport is a report grouped by project, with the sessions of one task
+func testReportExport() *ReportExport {
+	start := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+	end := start.Add(25 * time.Minute)
+	taskID := int32(7)
+
+	return &ReportExport{
+		Title:       "Pomodoro Report - All Tasks",
+		Period:      "This Week (from 2025-06-02)",
+		GeneratedAt: time.Date(2025, 6, 2, 18, 0, 0, 0, time.UTC),
+		GroupBy:     "project",
+		Report: &PomodoroReport{
+			TotalSessions:         2,
+			CompletedSessions:     1,
+			CancelledSessions:     1,
+			TotalTimeSeconds:      3000,
+			WorkTimeSeconds:       2100,
+			BreakTimeSeconds:      600,
+			PauseTimeSeconds:      300,
+			AvgWorkSessionSeconds: 1050,
+			DailyStats: []DailyStat{
+				{Date: start, TotalSessions: 2, CompletedSessions: 1, WorkTimeSeconds: 2100},
+				{Date: start.AddDate(0, 0, 1), TotalSessions: 1, WorkTimeSeconds: 1050},
+			},
+			TopTasks: []TaskStat{
+				{ID: taskID, Description: "Write <report> | draft", SessionCount: 2, TotalTimeSeconds: 2100},
+			},
+		},
+		Groups: []GroupStat{
+			{Key: "Work", TotalSessions: 2, CompletedSessions: 1, FocusSeconds: 2100, CompletionRate: 50},
+		},
+		Sessions: []ReportSession{
+			{
+				ID:           1,
+				Status:       PomodoroStatus("completed"),
+				StartTime:    start,
+				EndTime:      &end,
+				WorkMinutes:  25,
+				BreakMinutes: 5,
+				FocusSeconds: 1500,
+				TaskID:       &taskID,
+				Task:         "Write <report> | draft",
+				Project:      "Work",
+				Tags:         []string{"deep", "writing"},
+				Note:         "first, \"quoted\"",
+			},
+			{
+				ID:           2,
+				Status:       PomodoroStatus("active"),
+				StartTime:    start.Add(time.Hour),
+				WorkMinutes:  25,
+				BreakMinutes: 5,
+				FocusSeconds: 600,
+			},
+		},
+	}
+}
+
+// emptyReportExport is a report without sessions, which is still written
+func emptyReportExport() *ReportExport {
+	return &ReportExport{
+		Title:       "Pomodoro Report - All Tasks",
+		Period:      "All Time",
+		GeneratedAt: time.Date(2025, 6, 2, 18, 0, 0, 0, time.UTC),
+		Report:      &PomodoroReport{},
+	}
+}
+
+func TestWriteReportCSV(t *testing.T) {
+	tests := []struct {
+		name   string
+		export *ReportExport
+		want   string
+	}{
+		{"sessions", testReportExport(), `id,status,start_time,end_time,work_minutes,break_minutes,focus_seconds,task_id,task,project,tags,note
+1,completed,2025-06-02T09:00:00Z,2025-06-02T09:25:00Z,25,5,1500,7,Write <report> | draft,Work,"deep,writing","first, ""quoted"""
+2,active,2025-06-02T10:00:00Z,,25,5,600,,,,,
+`},
+		{"no sessions", emptyReportExport(), `id,status,start_time,end_time,work_minutes,break_minutes,focus_seconds,task_id,task,project,tags,note
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			require.NoError(t, WriteReportCSV(&b, tt.export.Sessions))
+			assert.Equal(t, tt.want, b.String())
+		})
+	}
+}
+
+func TestWriteReportMarkdown(t *testing.T) {
+	tests := []struct {
+		name   string
+		export *ReportExport
+		want   string
+	}{
+		{"sessions", testReportExport(), `# Pomodoro Report - All Tasks
+
+Period: This Week (from 2025-06-02)  
+Generated: 2025-06-02 18:00
+
+## Summary
+
+| Metric | Value |
+|---|---|
+| Total Sessions | 2 |
+| Completed | 1 (50.0%) |
+| Cancelled | 1 (50.0%) |
+| Total Time | 50m 0s |
+| Work Time | 35m 0s |
+| Break Time | 10m 0s |
+| Pause Time | 5m 0s |
+| Average Work Session | 17m 30s |
+
+## Breakdown by project
+
+| Group | Sessions | Focus Time | Completion |
+|---|---:|---:|---:|
+| Work | 2 | 35m 0s | 50.0% |
+
+## Daily Breakdown
+
+| Date | Sessions | Work Time | Completion |
+|---|---:|---:|---:|
+| 2025-06-02 | 2 | 35m 0s | 50.0% |
+| 2025-06-03 | 1 | 17m 30s | 0.0% |
+
+## Top Tasks
+
+| ID | Task | Sessions | Total Time |
+|---:|---|---:|---:|
+| 7 | Write <report> \| draft | 2 | 35m 0s |
+`},
+		{"no sessions", emptyReportExport(), `# Pomodoro Report - All Tasks
+
+Period: All Time  
+Generated: 2025-06-02 18:00
+
+## Summary
+
+| Metric | Value |
+|---|---|
+| Total Sessions | 0 |
+| Completed | 0 (0.0%) |
+| Cancelled | 0 (0.0%) |
+| Total Time | 0s |
+| Work Time | 0s |
+| Break Time | 0s |
+| Pause Time | 0s |
+| Average Work Session | 0s |
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			require.NoError(t, WriteReportMarkdown(&b, tt.export))
+			assert.Equal(t, tt.want, b.String())
+		})
+	}
+}
+
+func TestWriteReportHTML(t *testing.T) {
+	tests := []struct {
+		name   string
+		export *ReportExport
+		// want is the body, the head only holds the title and styles
+		want string
+	}{
+		{"sessions", testReportExport(), `<body>
+<h1>Pomodoro Report - All Tasks</h1>
+<p class="meta">Period: This Week (from 2025-06-02) &middot; Generated 2025-06-02 18:00</p>
+
+<h2>Summary</h2>
+<table>
+<tr><th>Total Sessions</th><td class="num">2</td></tr>
+<tr><th>Completed</th><td class="num">1 (50.0%)</td></tr>
+<tr><th>Cancelled</th><td class="num">1 (50.0%)</td></tr>
+<tr><th>Total Time</th><td class="num">50m 0s</td></tr>
+<tr><th>Work Time</th><td class="num">35m 0s</td></tr>
+<tr><th>Break Time</th><td class="num">10m 0s</td></tr>
+<tr><th>Pause Time</th><td class="num">5m 0s</td></tr>
+<tr><th>Average Work Session</th><td class="num">17m 30s</td></tr>
+</table>
+
+
+<h2>Focus time by project</h2>
+<svg xmlns="http://www.w3.org/2000/svg" width="740" height="34" role="img" aria-label="Focus time by project">
+<text x="0" y="20">Work</text>
+<rect x="220" y="5" width="420" height="18"></rect>
+<text x="646" y="20">35m 0s</text>
+</svg>
+
+<table>
+<tr><th>Group</th><th class="num">Sessions</th><th class="num">Focus Time</th><th class="num">Completion</th></tr>
+<tr><td>Work</td><td class="num">2</td><td class="num">35m 0s</td><td class="num">50.0%</td></tr>
+</table>
+
+
+<h2>Work time per day</h2>
+<svg xmlns="http://www.w3.org/2000/svg" width="740" height="58" role="img" aria-label="Work time per day">
+<text x="0" y="20">2025-06-02</text>
+<rect x="220" y="5" width="420" height="18"></rect>
+<text x="646" y="20">35m 0s</text>
+<text x="0" y="44">2025-06-03</text>
+<rect x="220" y="29" width="210" height="18"></rect>
+<text x="436" y="44">17m 30s</text>
+</svg>
+
+
+<h2>Top tasks</h2>
+<svg xmlns="http://www.w3.org/2000/svg" width="740" height="34" role="img" aria-label="Top tasks">
+<text x="0" y="20">#7 Write &lt;report&gt; | draft</text>
+<rect x="220" y="5" width="420" height="18"></rect>
+<text x="646" y="20">35m 0s</text>
+</svg>
+
+</body>
+</html>
+`},
+		{"no sessions", emptyReportExport(), `<body>
+<h1>Pomodoro Report - All Tasks</h1>
+<p class="meta">Period: All Time &middot; Generated 2025-06-02 18:00</p>
+
+<h2>Summary</h2>
+<table>
+<tr><th>Total Sessions</th><td class="num">0</td></tr>
+<tr><th>Completed</th><td class="num">0 (0.0%)</td></tr>
+<tr><th>Cancelled</th><td class="num">0 (0.0%)</td></tr>
+<tr><th>Total Time</th><td class="num">0s</td></tr>
+<tr><th>Work Time</th><td class="num">0s</td></tr>
+<tr><th>Break Time</th><td class="num">0s</td></tr>
+<tr><th>Pause Time</th><td class="num">0s</td></tr>
+<tr><th>Average Work Session</th><td class="num">0s</td></tr>
+</table>
+
+
+
+
+</body>
+</html>
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			require.NoError(t, WriteReportHTML(&b, tt.export))
+			head, body, ok := strings.Cut(b.String(), "</head>\n")
+			require.True(t, ok)
+			assert.Contains(t, head, "<title>Pomodoro Report - All Tasks</title>")
+			assert.Equal(t, tt.want, body)
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/jskallebak/prod/internal/util"
+)
+
+// PomodoroGoal holds a user's daily and weekly targets for completed pomodoros.
+// A goal of 0 means no goal is set
+type PomodoroGoal struct {
+	UserID     int32
+	DailyGoal  int32
+	WeeklyGoal int32
+}
+
+// GoalProgress shows how far the user is towards today's and this week's goals
+type GoalProgress struct {
+	Goal     PomodoroGoal
+	Today    int
+	ThisWeek int
+}
+
+// GoalStreaks summarises how often the daily goal was met
+type GoalStreaks struct {
+	DaysTracked   int
+	DaysMet       int
+	HitRate       float64
+	CurrentStreak int
+	LongestStreak int
+}
+
+// GetGoal returns the user's goals. Users without goals get an empty goal
+func (s *PomodoroService) GetGoal(ctx context.Context, userID int32) (*PomodoroGoal, error) {
+	goal, err := s.queries.GetPomodoroGoal(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &PomodoroGoal{UserID: userID}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Pomodoro goal: %w", err)
+	}
+
+	return &PomodoroGoal{
+		UserID:     goal.UserID,
+		DailyGoal:  goal.DailyGoal,
+		WeeklyGoal: goal.WeeklyGoal,
+	}, nil
+}
+
+// SetGoal stores the user's daily and weekly goals
+func (s *PomodoroService) SetGoal(ctx context.Context, userID int32, daily int32, weekly int32) (*PomodoroGoal, error) {
+	if daily < 0 || weekly < 0 {
+		return nil, fmt.Errorf("goals cannot be negative")
+	}
+
+	goal, err := s.queries.UpsertPomodoroGoal(ctx, sqlc.UpsertPomodoroGoalParams{
+		UserID:     userID,
+		DailyGoal:  daily,
+		WeeklyGoal: weekly,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set Pomodoro goal: %w", err)
+	}
+
+	return &PomodoroGoal{
+		UserID:     goal.UserID,
+		DailyGoal:  goal.DailyGoal,
+		WeeklyGoal: goal.WeeklyGoal,
+	}, nil
+}
+
+// GetGoalProgress counts today's and this week's completed sessions in local time
+func (s *PomodoroService) GetGoalProgress(ctx context.Context, userID int32) (*GoalProgress, error) {
+	goal, err := s.GetGoal(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	today := startOfDay(now)
+	weekStart := util.StartOfWeek(today)
+
+	counts, err := s.completedPerDay(ctx, userID, &weekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &GoalProgress{
+		Goal:  *goal,
+		Today: counts[today.Format("2006-01-02")],
+	}
+	for _, n := range counts {
+		progress.ThisWeek += n
+	}
+
+	return progress, nil
+}
+
+// GetGoalStreaks computes the daily goal hit rate between startDate (or the first
+// completed session) and today, plus the current and longest streaks of days the
+// goal was met. Days are taken in the local time zone
+func (s *PomodoroService) GetGoalStreaks(ctx context.Context, userID int32, startDate *time.Time) (*GoalStreaks, error) {
+	goal, err := s.GetGoal(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	streaks := &GoalStreaks{}
+	if goal.DailyGoal <= 0 {
+		return streaks, nil
+	}
+
+	counts, err := s.completedPerDay(ctx, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(counts) == 0 {
+		return streaks, nil
+	}
+
+	// Find the first day with a completed session
+	today := startOfDay(time.Now())
+	first := today
+	for key := range counts {
+		day, err := time.ParseInLocation("2006-01-02", key, time.Local)
+		if err == nil && day.Before(first) {
+			first = day
+		}
+	}
+
+	periodStart := first
+	if startDate != nil && startOfDay(*startDate).After(first) {
+		periodStart = startOfDay(*startDate)
+	}
+
+	run := 0
+	for day := first; !day.After(today); day = day.AddDate(0, 0, 1) {
+		met := counts[day.Format("2006-01-02")] >= int(goal.DailyGoal)
+
+		if !day.Before(periodStart) {
+			streaks.DaysTracked++
+			if met {
+				streaks.DaysMet++
+			}
+		}
+
+		if met {
+			run++
+			if run > streaks.LongestStreak {
+				streaks.LongestStreak = run
+			}
+		} else if !day.Equal(today) {
+			// Today is still in progress, so it doesn't break the streak yet
+			run = 0
+		}
+	}
+	streaks.CurrentStreak = run
+
+	if streaks.DaysTracked > 0 {
+		streaks.HitRate = float64(streaks.DaysMet) / float64(streaks.DaysTracked) * 100
+	}
+
+	return streaks, nil
+}
+
+// CompletedToday counts the sessions completed today in local time
+func (s *PomodoroService) CompletedToday(ctx context.Context, userID int32) (int, error) {
+	today := startOfDay(time.Now())
+	counts, err := s.completedPerDay(ctx, userID, &today)
+	if err != nil {
+		return 0, err
+	}
+	return counts[today.Format("2006-01-02")], nil
+}
+
+// completedPerDay counts completed sessions per local day since the given time
+func (s *PomodoroService) completedPerDay(ctx context.Context, userID int32, since *time.Time) (map[string]int, error) {
+	params := sqlc.ListCompletedPomodoroStartTimesParams{
+		UserID: pgtype.Int4{
+			Int32: userID,
+			Valid: true,
+		},
+	}
+
+	if since != nil {
+		params.Since = pgtype.Timestamptz{
+			Time:  *since,
+			Valid: true,
+		}
+	}
+
+	starts, err := s.queries.ListCompletedPomodoroStartTimes(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list completed sessions: %w", err)
+	}
+
+	counts := make(map[string]int)
+	for _, start := range starts {
+		counts[start.Time.In(time.Local).Format("2006-01-02")]++
+	}
+
+	return counts, nil
+}
+
+// startOfDay returns midnight of the given time's day in local time
+func startOfDay(t time.Time) time.Time {
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+)
+
+// PomodoroPreset is a named work/break cadence
+type PomodoroPreset struct {
+	ID                int32
+	Name              string
+	WorkDuration      int32
+	BreakDuration     int32
+	LongBreakDuration int32
+	LongBreakInterval int32
+}
+
+// PresetChanges are the durations given when saving a preset. Nil fields keep
+// the value of an existing preset, or take the default for a new one
+type PresetChanges struct {
+	WorkDuration      *int32
+	BreakDuration     *int32
+	LongBreakDuration *int32
+	LongBreakInterval *int32
+}
+
+// defaultPreset has the durations of a new preset, the classic 25/5 cadence
+var defaultPreset = PomodoroPreset{
+	WorkDuration:      25,
+	BreakDuration:     5,
+	LongBreakDuration: 15,
+	LongBreakInterval: 4,
+}
+
+// mergePreset applies changes to an existing preset, or to the defaults when
+// existing is nil
+func mergePreset(existing *PomodoroPreset, changes PresetChanges) PomodoroPreset {
+	preset := defaultPreset
+	if existing != nil {
+		preset = *existing
+	}
+	if changes.WorkDuration != nil {
+		preset.WorkDuration = *changes.WorkDuration
+	}
+	if changes.BreakDuration != nil {
+		preset.BreakDuration = *changes.BreakDuration
+	}
+	if changes.LongBreakDuration != nil {
+		preset.LongBreakDuration = *changes.LongBreakDuration
+	}
+	if changes.LongBreakInterval != nil {
+		preset.LongBreakInterval = *changes.LongBreakInterval
+	}
+	return preset
+}
+
+// Cadence returns the durations a new session follows: those of preset when
+// given, else those of the user's config, else the defaults
+func (s *PomodoroService) Cadence(ctx context.Context, userID int32, preset *PomodoroPreset) PomodoroPreset {
+	if preset != nil {
+		return *preset
+	}
+	config, err := s.GetUserConfig(ctx, userID)
+	if err != nil {
+		return defaultPreset
+	}
+	return PomodoroPreset{
+		WorkDuration:      config.WorkDuration,
+		BreakDuration:     config.BreakDuration,
+		LongBreakDuration: config.LongBreakDuration,
+		LongBreakInterval: config.LongBreakInterval,
+	}
+}
+
+// NextBreak returns the break after the next pomodoro of the day, given how
+// many were completed today, and whether it is the long break that comes
+// after every LongBreakInterval pomodoros
+func (p PomodoroPreset) NextBreak(completedToday int) (int32, bool) {
+	if p.LongBreakInterval > 0 && (completedToday+1)%int(p.LongBreakInterval) == 0 {
+		return p.LongBreakDuration, true
+	}
+	return p.BreakDuration, false
+}
+
+// SavePreset creates a preset or changes the preset with the same name. Only
+// the given durations of an existing preset change
+func (s *PomodoroService) SavePreset(ctx context.Context, userID int32, name string, changes PresetChanges) (*PomodoroPreset, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("preset name cannot be empty")
+	}
+
+	var existing *PomodoroPreset
+	current, err := s.queries.GetPomodoroPresetByName(ctx, sqlc.GetPomodoroPresetByNameParams{
+		UserID: userID,
+		Name:   name,
+	})
+	if err == nil {
+		existing = toPomodoroPreset(current)
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get Pomodoro preset: %w", err)
+	}
+
+	merged := mergePreset(existing, changes)
+	if merged.WorkDuration <= 0 {
+		return nil, fmt.Errorf("work duration must be positive")
+	}
+	if merged.BreakDuration < 0 || merged.LongBreakDuration < 0 {
+		return nil, fmt.Errorf("break durations cannot be negative")
+	}
+	if merged.LongBreakInterval <= 0 {
+		return nil, fmt.Errorf("long break interval must be positive")
+	}
+
+	// Call data layer
+	preset, err := s.queries.UpsertPomodoroPreset(ctx, sqlc.UpsertPomodoroPresetParams{
+		UserID:            userID,
+		Name:              name,
+		WorkDuration:      merged.WorkDuration,
+		BreakDuration:     merged.BreakDuration,
+		LongBreakDuration: merged.LongBreakDuration,
+		LongBreakInterval: merged.LongBreakInterval,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save Pomodoro preset: %w", err)
+	}
+
+	return toPomodoroPreset(preset), nil
+}
+
+// GetPreset retrieves a preset by name
+func (s *PomodoroService) GetPreset(ctx context.Context, userID int32, name string) (*PomodoroPreset, error) {
+	preset, err := s.queries.GetPomodoroPresetByName(ctx, sqlc.GetPomodoroPresetByNameParams{
+		UserID: userID,
+		Name:   strings.TrimSpace(name),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("no preset named %q", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Pomodoro preset: %w", err)
+	}
+
+	return toPomodoroPreset(preset), nil
+}
+
+// ListPresets returns all of the user's presets ordered by name
+func (s *PomodoroService) ListPresets(ctx context.Context, userID int32) ([]PomodoroPreset, error) {
+	presets, err := s.queries.ListPomodoroPresets(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Pomodoro presets: %w", err)
+	}
+
+	result := make([]PomodoroPreset, 0, len(presets))
+	for _, preset := range presets {
+		result = append(result, *toPomodoroPreset(preset))
+	}
+
+	return result, nil
+}
+
+// DeletePreset removes a preset, projects using it fall back to no default
+func (s *PomodoroService) DeletePreset(ctx context.Context, userID int32, name string) error {
+	if _, err := s.GetPreset(ctx, userID, name); err != nil {
+		return err
+	}
+
+	err := s.queries.DeletePomodoroPreset(ctx, sqlc.DeletePomodoroPresetParams{
+		UserID: userID,
+		Name:   strings.TrimSpace(name),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete Pomodoro preset: %w", err)
+	}
+
+	return nil
+}
+
+// SetProjectPreset sets the default preset of a project, an empty name clears it
+func (s *PomodoroService) SetProjectPreset(ctx context.Context, userID int32, projectID int32, name string) (*sqlc.Project, error) {
+	params := sqlc.SetProjectPomodoroPresetParams{
+		ID: projectID,
+		UserID: pgtype.Int4{
+			Int32: userID,
+			Valid: true,
+		},
+	}
+
+	if name != "" {
+		preset, err := s.GetPreset(ctx, userID, name)
+		if err != nil {
+			return nil, err
+		}
+		params.PomodoroPresetID = pgtype.Int4{
+			Int32: preset.ID,
+			Valid: true,
+		}
+	}
+
+	project, err := s.queries.SetProjectPomodoroPreset(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set project preset: %w", err)
+	}
+
+	return &project, nil
+}
+
+// GetTaskPreset returns the default preset of the task's project, or nil if there is none
+func (s *PomodoroService) GetTaskPreset(ctx context.Context, userID int32, taskID int32) (*PomodoroPreset, error) {
+	userParam := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+
+	task, err := s.queries.GetTask(ctx, sqlc.GetTaskParams{
+		ID:     taskID,
+		UserID: userParam,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	if !task.ProjectID.Valid {
+		return nil, nil
+	}
+
+	project, err := s.queries.GetProject(ctx, sqlc.GetProjectParams{
+		ID:     task.ProjectID.Int32,
+		UserID: userParam,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	if !project.PomodoroPresetID.Valid {
+		return nil, nil
+	}
+
+	preset, err := s.queries.GetPomodoroPreset(ctx, sqlc.GetPomodoroPresetParams{
+		ID:     project.PomodoroPresetID.Int32,
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Pomodoro preset: %w", err)
+	}
+
+	return toPomodoroPreset(preset), nil
+}
+
+func toPomodoroPreset(preset sqlc.PomodoroPreset) *PomodoroPreset {
+	return &PomodoroPreset{
+		ID:                preset.ID,
+		Name:              preset.Name,
+		WorkDuration:      preset.WorkDuration,
+		BreakDuration:     preset.BreakDuration,
+		LongBreakDuration: preset.LongBreakDuration,
+		LongBreakInterval: preset.LongBreakInterval,
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergePreset(t *testing.T) {
+	fifty := int32(50)
+
+	// A new preset starts from the defaults
+	assert.Equal(t, PomodoroPreset{
+		WorkDuration:      50,
+		BreakDuration:     5,
+		LongBreakDuration: 15,
+		LongBreakInterval: 4,
+	}, mergePreset(nil, PresetChanges{WorkDuration: &fifty}))
+
+	// An existing preset keeps what wasn't given
+	existing := &PomodoroPreset{
+		ID:                3,
+		Name:              "deep",
+		WorkDuration:      45,
+		BreakDuration:     10,
+		LongBreakDuration: 20,
+		LongBreakInterval: 3,
+	}
+	three := int32(3)
+	assert.Equal(t, PomodoroPreset{
+		ID:                3,
+		Name:              "deep",
+		WorkDuration:      45,
+		BreakDuration:     3,
+		LongBreakDuration: 20,
+		LongBreakInterval: 3,
+	}, mergePreset(existing, PresetChanges{BreakDuration: &three}))
+	assert.Equal(t, int32(10), existing.BreakDuration, "the existing preset is left as it is")
+}
+
+func TestNextBreak(t *testing.T) {
+	deep := PomodoroPreset{
+		WorkDuration:      50,
+		BreakDuration:     0,
+		LongBreakDuration: 20,
+		LongBreakInterval: 3,
+	}
+
+	tests := []struct {
+		name      string
+		preset    PomodoroPreset
+		completed int
+		want      int32
+		long      bool
+	}{
+		{"first of the day", deep, 0, 0, false},
+		{"a break of 0 is kept", deep, 1, 0, false},
+		{"every interval pomodoros", deep, 2, 20, true},
+		{"after the long break", deep, 3, 0, false},
+		{"the next long break", deep, 5, 20, true},
+		{"defaults", defaultPreset, 3, 15, true},
+		{"without an interval", PomodoroPreset{BreakDuration: 5, LongBreakDuration: 15}, 3, 5, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minutes, long := tt.preset.NextBreak(tt.completed)
+			assert.Equal(t, tt.want, minutes)
+			assert.Equal(t, tt.long, long)
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+)
+
+// Groupings supported by GenerateGroupedReport
+const (
+	GroupByProject = "project"
+	GroupByTag     = "tag"
+	GroupByTask    = "task"
+	GroupByDay     = "day"
+	GroupByWeekday = "weekday"
+	GroupByHour    = "hour"
+)
+
+// UnassignedGroup is the group for sessions without a task
+const UnassignedGroup = "unassigned"
+
+// GroupStat holds aggregated Pomodoro statistics for one group of sessions
+type GroupStat struct {
+	Key               string
+	TotalSessions     int
+	CompletedSessions int
+	FocusSeconds      int64
+	CompletionRate    float64
+}
+
+// GenerateGroupedReport aggregates focused time, session counts and completion
+// rate of the user's sessions by project, tag, task, day, weekday or hour
+func (s *PomodoroService) GenerateGroupedReport(
+	ctx context.Context,
+	userID int32,
+	taskID *int32,
+	startDate *time.Time,
+	endDate *time.Time,
+	groupBy string,
+) ([]GroupStat, error) {
+	switch groupBy {
+	case GroupByProject, GroupByTag, GroupByTask, GroupByDay, GroupByWeekday, GroupByHour:
+	default:
+		return nil, fmt.Errorf("invalid grouping %q: must be one of project, tag, task, day, weekday, hour", groupBy)
+	}
+
+	sessions, err := s.listSessionsWithTask(ctx, userID, taskID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string]*GroupStat)
+	// order keeps the natural sort position of time based groups
+	order := make(map[string]int)
+
+	for _, session := range sessions {
+		focus := focusSeconds(session)
+
+		for _, key := range groupKeys(session, groupBy, order) {
+			group, exists := groups[key]
+			if !exists {
+				group = &GroupStat{Key: key}
+				groups[key] = group
+			}
+
+			group.TotalSessions++
+			if session.Status == string(StatusCompleted) {
+				group.CompletedSessions++
+			}
+			group.FocusSeconds += focus
+		}
+	}
+
+	stats := make([]GroupStat, 0, len(groups))
+	for _, group := range groups {
+		group.CompletionRate = float64(group.CompletedSessions) / float64(group.TotalSessions) * 100
+		stats = append(stats, *group)
+	}
+
+	switch groupBy {
+	case GroupByDay, GroupByWeekday, GroupByHour:
+		sort.Slice(stats, func(i, j int) bool {
+			return order[stats[i].Key] < order[stats[j].Key]
+		})
+	default:
+		sort.Slice(stats, func(i, j int) bool {
+			if stats[i].FocusSeconds != stats[j].FocusSeconds {
+				return stats[i].FocusSeconds > stats[j].FocusSeconds
+			}
+			return stats[i].Key < stats[j].Key
+		})
+	}
+
+	return stats, nil
+}
+
+// groupKeys returns the groups a session belongs to. Tagged tasks belong to one
+// group per tag. Time based keys record their sort position in order
+func groupKeys(session sqlc.ListPomodoroSessionsWithTaskRow, groupBy string, order map[string]int) []string {
+	start := session.StartTime.Time.In(time.Local)
+
+	switch groupBy {
+	case GroupByDay:
+		key := start.Format("2006-01-02")
+		order[key] = int(startOfDay(start).Unix())
+		return []string{key}
+	case GroupByWeekday:
+		// Monday first, like the weeks of the other reports
+		key := start.Weekday().String()
+		order[key] = (int(start.Weekday()) + 6) % 7
+		return []string{key}
+	case GroupByHour:
+		key := fmt.Sprintf("%02d:00", start.Hour())
+		order[key] = start.Hour()
+		return []string{key}
+	}
+
+	// The remaining groupings need the task
+	if !session.TaskID.Valid {
+		return []string{UnassignedGroup}
+	}
+
+	switch groupBy {
+	case GroupByProject:
+		if !session.ProjectName.Valid {
+			return []string{"no project"}
+		}
+		return []string{session.ProjectName.String}
+	case GroupByTag:
+		if len(session.TaskTags) == 0 {
+			return []string{"untagged"}
+		}
+		return session.TaskTags
+	default:
+		return []string{fmt.Sprintf("#%d %s", session.TaskID.Int32, session.TaskDescription.String)}
+	}
+}
+
+// ReportSession is a single session with its task details, as exported in reports
+type ReportSession struct {
+	ID           int32
+	Status       PomodoroStatus
+	StartTime    time.Time
+	EndTime      *time.Time
+	WorkMinutes  int32
+	BreakMinutes int32
+	FocusSeconds int64
+	TaskID       *int32
+	Task         string
+	Project      string
+	Tags         []string
+	Note         string
+}
+
+// ListReportSessions returns the raw sessions covered by a report
+func (s *PomodoroService) ListReportSessions(
+	ctx context.Context,
+	userID int32,
+	taskID *int32,
+	startDate *time.Time,
+	endDate *time.Time,
+) ([]ReportSession, error) {
+	sessions, err := s.listSessionsWithTask(ctx, userID, taskID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ReportSession, 0, len(sessions))
+	for _, session := range sessions {
+		rs := ReportSession{
+			ID:           session.ID,
+			Status:       PomodoroStatus(session.Status),
+			StartTime:    session.StartTime.Time,
+			WorkMinutes:  session.WorkDuration,
+			BreakMinutes: session.BreakDuration,
+			FocusSeconds: focusSeconds(session),
+			Task:         session.TaskDescription.String,
+			Project:      session.ProjectName.String,
+			Tags:         session.TaskTags,
+			Note:         session.Note.String,
+		}
+
+		if session.EndTime.Valid {
+			endTime := session.EndTime.Time
+			rs.EndTime = &endTime
+		}
+
+		if session.TaskID.Valid {
+			id := session.TaskID.Int32
+			rs.TaskID = &id
+		}
+
+		result = append(result, rs)
+	}
+
+	return result, nil
+}
+
+// focusSeconds returns the recorded focus time of a session. Sessions without
+// one count from their start to their end less their pauses, so an
+// interrupted session isn't counted at its full planned duration, and running
+// sessions don't count yet
+func focusSeconds(session sqlc.ListPomodoroSessionsWithTaskRow) int64 {
+	if session.ActualWorkDuration.Valid {
+		return int64(session.ActualWorkDuration.Int32)
+	}
+	if !session.StartTime.Valid || !session.EndTime.Valid || session.EndTime.Time.Before(session.StartTime.Time) {
+		return 0
+	}
+	seconds := int64(session.EndTime.Time.Sub(session.StartTime.Time).Seconds())
+	if session.TotalPauseDuration.Valid {
+		seconds -= int64(session.TotalPauseDuration.Int32)
+	}
+	return max(seconds, 0)
+}
+
+func (s *PomodoroService) listSessionsWithTask(
+	ctx context.Context,
+	userID int32,
+	taskID *int32,
+	startDate *time.Time,
+	endDate *time.Time,
+) ([]sqlc.ListPomodoroSessionsWithTaskRow, error) {
+	params := sqlc.ListPomodoroSessionsWithTaskParams{
+		UserID: pgtype.Int4{
+			Int32: userID,
+			Valid: true,
+		},
+	}
+
+	if taskID != nil {
+		params.TaskID = pgtype.Int4{
+			Int32: *taskID,
+			Valid: true,
+		}
+	}
+
+	if startDate != nil {
+		params.StartDate = pgtype.Timestamptz{
+			Time:  *startDate,
+			Valid: true,
+		}
+	}
+
+	if endDate != nil {
+		params.EndDate = pgtype.Timestamptz{
+			Time:  *endDate,
+			Valid: true,
+		}
+	}
+
+	sessions, err := s.queries.ListPomodoroSessionsWithTask(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Pomodoro sessions: %w", err)
+	}
+
+	return sessions, nil
+}
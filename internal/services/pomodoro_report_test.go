@@ -0,0 +1,55 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFocusSeconds(t *testing.T) {
+	start := time.Date(2025, 5, 14, 9, 0, 0, 0, time.UTC)
+	timestamp := func(t time.Time) pgtype.Timestamptz {
+		return pgtype.Timestamptz{
+			Time:  t,
+			Valid: true,
+		}
+	}
+
+	// The recorded time wins
+	assert.Equal(t, int64(1200), focusSeconds(sqlc.ListPomodoroSessionsWithTaskRow{
+		StartTime:    timestamp(start),
+		EndTime:      timestamp(start.Add(25 * time.Minute)),
+		WorkDuration: 25,
+		ActualWorkDuration: pgtype.Int4{
+			Int32: 1200,
+			Valid: true,
+		},
+	}))
+
+	// An interrupted session counts until it ended, not its planned 25 minutes
+	assert.Equal(t, int64(600), focusSeconds(sqlc.ListPomodoroSessionsWithTaskRow{
+		StartTime:    timestamp(start),
+		EndTime:      timestamp(start.Add(10 * time.Minute)),
+		WorkDuration: 25,
+	}))
+
+	// Pauses don't count as focus time
+	assert.Equal(t, int64(420), focusSeconds(sqlc.ListPomodoroSessionsWithTaskRow{
+		StartTime:    timestamp(start),
+		EndTime:      timestamp(start.Add(10 * time.Minute)),
+		WorkDuration: 25,
+		TotalPauseDuration: pgtype.Int4{
+			Int32: 180,
+			Valid: true,
+		},
+	}))
+
+	// A running session doesn't count yet
+	assert.Equal(t, int64(0), focusSeconds(sqlc.ListPomodoroSessionsWithTaskRow{
+		StartTime:    timestamp(start),
+		WorkDuration: 25,
+	}))
+}
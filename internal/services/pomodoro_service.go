@@ -26,12 +26,14 @@ const (
 // PomodoroService handles business logic for Pomodoro sessions
 type PomodoroService struct {
 	queries *sqlc.Queries
+	journal *journal
 }
 
 // NewPomodoroService creates a new PomodoroService
 func NewPomodoroService(queries *sqlc.Queries) *PomodoroService {
 	return &PomodoroService{
 		queries: queries,
+		journal: newJournal(queries),
 	}
 }
 
@@ -62,8 +64,10 @@ type PomodoroConfig struct {
 	LongBreakInterval  int32
 	AutoStartBreaks    bool
 	AutoStartPomodoros bool
-	CreatedAt          pgtype.Timestamptz
-	UpdatedAt          pgtype.Timestamptz
+	// LinkTasks keeps the state of a session's task in step with the session
+	LinkTasks bool
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
 }
 
 // PomodoroReport represents statistics and data for a Pomodoro report
@@ -204,6 +208,10 @@ func (s *PomodoroService) GetActiveSession(ctx context.Context, userID int32) (*
 		pomodoroSession.PauseTime = session.PauseTime
 	}
 
+	if session.TotalPauseDuration.Valid {
+		pomodoroSession.TotalPauseDuration = time.Duration(session.TotalPauseDuration.Int32) * time.Second
+	}
+
 	return pomodoroSession, nil
 }
 
@@ -297,15 +305,16 @@ func (s *PomodoroService) PauseSession(ctx context.Context, userID int32) (*Pomo
 
 	// Convert to service model
 	pomodoroSession := &PomodoroSession{
-		ID:            session.ID,
-		UserID:        session.UserID.Int32,
-		Status:        PomodoroStatus(session.Status),
-		WorkDuration:  time.Duration(session.WorkDuration) * time.Minute,
-		BreakDuration: time.Duration(session.BreakDuration) * time.Minute,
-		StartTime:     session.StartTime,
-		PauseTime:     session.PauseTime,
-		CreatedAt:     session.CreatedAt,
-		Note:          session.Note.String,
+		ID:                 session.ID,
+		UserID:             session.UserID.Int32,
+		Status:             PomodoroStatus(session.Status),
+		WorkDuration:       time.Duration(session.WorkDuration) * time.Minute,
+		BreakDuration:      time.Duration(session.BreakDuration) * time.Minute,
+		StartTime:          session.StartTime,
+		PauseTime:          session.PauseTime,
+		TotalPauseDuration: time.Duration(session.TotalPauseDuration.Int32) * time.Second,
+		CreatedAt:          session.CreatedAt,
+		Note:               session.Note.String,
 	}
 
 	if session.TaskID.Valid {
@@ -496,6 +505,7 @@ func (s *PomodoroService) GetUserConfig(ctx context.Context, userID int32) (*Pom
 		LongBreakInterval:  config.LongBreakInterval,
 		AutoStartBreaks:    config.AutoStartBreaks,
 		AutoStartPomodoros: config.AutoStartPomodoros,
+		LinkTasks:          config.LinkTasks,
 		CreatedAt:          pgtype.Timestamptz{Time: config.CreatedAt, Valid: true},
 		UpdatedAt:          pgtype.Timestamptz{Time: config.UpdatedAt, Valid: true},
 	}
@@ -513,6 +523,7 @@ func (s *PomodoroService) UpdateUserConfig(
 	longBreakInterval int32,
 	autoStartBreaks bool,
 	autoStartPomodoros bool,
+	linkTasks bool,
 ) (*PomodoroConfig, error) {
 	params := sqlc.UpsertPomodoroConfigParams{
 		UserID:             userID,
@@ -522,6 +533,7 @@ func (s *PomodoroService) UpdateUserConfig(
 		LongBreakInterval:  longBreakInterval,
 		AutoStartBreaks:    autoStartBreaks,
 		AutoStartPomodoros: autoStartPomodoros,
+		LinkTasks:          linkTasks,
 	}
 
 	config, err := s.queries.UpsertPomodoroConfig(ctx, params)
@@ -538,6 +550,7 @@ func (s *PomodoroService) UpdateUserConfig(
 		LongBreakInterval:  config.LongBreakInterval,
 		AutoStartBreaks:    config.AutoStartBreaks,
 		AutoStartPomodoros: config.AutoStartPomodoros,
+		LinkTasks:          config.LinkTasks,
 		CreatedAt:          pgtype.Timestamptz{Time: config.CreatedAt, Valid: true},
 		UpdatedAt:          pgtype.Timestamptz{Time: config.UpdatedAt, Valid: true},
 	}
@@ -828,3 +841,288 @@ func (s *PomodoroService) GenerateReport(ctx context.Context, userID int32, task
 
 	return report, nil
 }
+
+// SessionParams holds the fields that can be changed on an existing session.
+// Nil fields are left unchanged
+type SessionParams struct {
+	TaskID    *int32
+	ClearTask bool
+	Note      *string
+	StartTime *time.Time
+	EndTime   *time.Time
+	Status    *PomodoroStatus
+}
+
+// GetSession retrieves a single Pomodoro session by ID
+func (s *PomodoroService) GetSession(ctx context.Context, userID int32, sessionID int32) (*PomodoroSession, error) {
+	session, err := s.queries.GetPomodoroSession(ctx, sqlc.GetPomodoroSessionParams{
+		ID: sessionID,
+		UserID: pgtype.Int4{
+			Int32: userID,
+			Valid: true,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Pomodoro session %d: %w", sessionID, err)
+	}
+
+	return toPomodoroSession(session), nil
+}
+
+// LogSession records a completed Pomodoro session that happened in the past.
+// In a UnitOfWork other sessions can't be logged or moved onto the same time
+// between the overlap check and the insert
+func (s *PomodoroService) LogSession(
+	ctx context.Context,
+	userID int32,
+	taskID *int32,
+	startTime time.Time,
+	workDuration time.Duration,
+	breakDuration time.Duration,
+	note string,
+) (*PomodoroSession, error) {
+	if workDuration <= 0 {
+		return nil, fmt.Errorf("work duration must be positive")
+	}
+
+	endTime := startTime.Add(workDuration)
+	if endTime.After(time.Now()) {
+		return nil, fmt.Errorf("session would end in the future (%s)", endTime.Format("2006-01-02 15:04"))
+	}
+
+	if err := s.lockedCheckOverlap(ctx, userID, 0, startTime, endTime); err != nil {
+		return nil, err
+	}
+
+	params := sqlc.LogPomodoroSessionParams{
+		UserID: pgtype.Int4{
+			Int32: userID,
+			Valid: true,
+		},
+		Status:        string(StatusCompleted),
+		WorkDuration:  int32(workDuration.Minutes()),
+		BreakDuration: int32(breakDuration.Minutes()),
+		StartTime: pgtype.Timestamptz{
+			Time:  startTime,
+			Valid: true,
+		},
+		EndTime: pgtype.Timestamptz{
+			Time:  endTime,
+			Valid: true,
+		},
+		ActualWorkDuration: pgtype.Int4{
+			Int32: int32(workDuration.Seconds()),
+			Valid: true,
+		},
+	}
+
+	if taskID != nil {
+		params.TaskID = pgtype.Int4{
+			Int32: *taskID,
+			Valid: true,
+		}
+	}
+
+	if note != "" {
+		params.Note = pgtype.Text{
+			String: note,
+			Valid:  true,
+		}
+	}
+
+	// Call data layer
+	session, err := s.queries.LogPomodoroSession(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to log Pomodoro session: %w", err)
+	}
+
+	return toPomodoroSession(session), nil
+}
+
+// UpdateSession changes the task, note, times or status of an existing
+// session. Its planned work duration is kept, the time worked follows the new
+// times. Like LogSession, in a UnitOfWork the overlap check holds until commit
+func (s *PomodoroService) UpdateSession(ctx context.Context, userID int32, sessionID int32, p SessionParams) (*PomodoroSession, error) {
+	current, err := s.queries.GetPomodoroSession(ctx, sqlc.GetPomodoroSessionParams{
+		ID: sessionID,
+		UserID: pgtype.Int4{
+			Int32: userID,
+			Valid: true,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Pomodoro session %d: %w", sessionID, err)
+	}
+
+	running := current.Status == string(StatusActive) || current.Status == string(StatusPaused)
+	if running && (p.StartTime != nil || p.EndTime != nil || p.Status != nil) {
+		return nil, fmt.Errorf("session %d is still running; stop it before changing its times or status", sessionID)
+	}
+
+	params := sqlc.UpdatePomodoroSessionParams{
+		ID:                 current.ID,
+		UserID:             current.UserID,
+		TaskID:             current.TaskID,
+		Status:             current.Status,
+		StartTime:          current.StartTime,
+		EndTime:            current.EndTime,
+		WorkDuration:       current.WorkDuration,
+		ActualWorkDuration: current.ActualWorkDuration,
+		Note:               current.Note,
+	}
+
+	if p.ClearTask {
+		params.TaskID = pgtype.Int4{}
+	} else if p.TaskID != nil {
+		params.TaskID = pgtype.Int4{
+			Int32: *p.TaskID,
+			Valid: true,
+		}
+	}
+
+	if p.Note != nil {
+		params.Note = pgtype.Text{
+			String: *p.Note,
+			Valid:  *p.Note != "",
+		}
+	}
+
+	if p.Status != nil {
+		if *p.Status != StatusCompleted && *p.Status != StatusCancelled {
+			return nil, fmt.Errorf("invalid status %q: must be completed or cancelled", *p.Status)
+		}
+		params.Status = string(*p.Status)
+	}
+
+	if p.StartTime != nil || p.EndTime != nil {
+		start := current.StartTime.Time
+		if p.StartTime != nil {
+			start = *p.StartTime
+		}
+
+		end := current.EndTime.Time
+		if p.EndTime != nil {
+			end = *p.EndTime
+		} else if !current.EndTime.Valid {
+			end = start.Add(time.Duration(current.WorkDuration) * time.Minute)
+		}
+
+		if !end.After(start) {
+			return nil, fmt.Errorf("end time must be after start time")
+		}
+
+		if err := s.lockedCheckOverlap(ctx, userID, sessionID, start, end); err != nil {
+			return nil, err
+		}
+
+		worked := end.Sub(start) - time.Duration(current.TotalPauseDuration.Int32)*time.Second
+		if worked < 0 {
+			worked = 0
+		}
+
+		params.StartTime = pgtype.Timestamptz{
+			Time:  start,
+			Valid: true,
+		}
+		params.EndTime = pgtype.Timestamptz{
+			Time:  end,
+			Valid: true,
+		}
+		params.ActualWorkDuration = pgtype.Int4{
+			Int32: int32(worked.Seconds()),
+			Valid: true,
+		}
+	}
+
+	// Call data layer
+	session, err := s.queries.UpdatePomodoroSession(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update Pomodoro session: %w", err)
+	}
+
+	return toPomodoroSession(session), nil
+}
+
+// DeleteSession removes a Pomodoro session
+func (s *PomodoroService) DeleteSession(ctx context.Context, userID int32, sessionID int32) error {
+	// Make sure the session exists and belongs to the user
+	if _, err := s.GetSession(ctx, userID, sessionID); err != nil {
+		return err
+	}
+
+	err := s.queries.DeletePomodoroSession(ctx, sqlc.DeletePomodoroSessionParams{
+		ID: sessionID,
+		UserID: pgtype.Int4{
+			Int32: userID,
+			Valid: true,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete Pomodoro session: %w", err)
+	}
+
+	return nil
+}
+
+// lockedCheckOverlap locks the sessions of the user before checking for an
+// overlap, so two writers can't both find the time free
+func (s *PomodoroService) lockedCheckOverlap(ctx context.Context, userID int32, excludeID int32, start, end time.Time) error {
+	if err := s.queries.LockPomodoroSessions(ctx, userID); err != nil {
+		return fmt.Errorf("failed to lock sessions: %w", err)
+	}
+	return s.checkOverlap(ctx, userID, excludeID, start, end)
+}
+
+// checkOverlap returns an error if any other session of the user overlaps the given time range
+func (s *PomodoroService) checkOverlap(ctx context.Context, userID int32, excludeID int32, start, end time.Time) error {
+	count, err := s.queries.CountOverlappingPomodoroSessions(ctx, sqlc.CountOverlappingPomodoroSessionsParams{
+		UserID: pgtype.Int4{
+			Int32: userID,
+			Valid: true,
+		},
+		ExcludeID: excludeID,
+		RangeEnd: pgtype.Timestamptz{
+			Time:  end,
+			Valid: true,
+		},
+		RangeStart: pgtype.Timestamptz{
+			Time:  start,
+			Valid: true,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check for overlapping sessions: %w", err)
+	}
+
+	if count > 0 {
+		return fmt.Errorf("session from %s to %s overlaps %d existing session(s)",
+			start.Format("2006-01-02 15:04"), end.Format("15:04"), count)
+	}
+
+	return nil
+}
+
+// toPomodoroSession converts a database row to the service model
+func toPomodoroSession(session sqlc.PomodoroSession) *PomodoroSession {
+	pomodoroSession := &PomodoroSession{
+		ID:                 session.ID,
+		UserID:             session.UserID.Int32,
+		Status:             PomodoroStatus(session.Status),
+		WorkDuration:       time.Duration(session.WorkDuration) * time.Minute,
+		BreakDuration:      time.Duration(session.BreakDuration) * time.Minute,
+		StartTime:          session.StartTime,
+		EndTime:            session.EndTime,
+		PauseTime:          session.PauseTime,
+		TotalPauseDuration: time.Duration(session.TotalPauseDuration.Int32) * time.Second,
+		ActualWorkDuration: time.Duration(session.ActualWorkDuration.Int32) * time.Second,
+		CreatedAt:          session.CreatedAt,
+		Note:               session.Note.String,
+	}
+
+	if session.TaskID.Valid {
+		taskID := session.TaskID.Int32
+		pomodoroSession.TaskID = &taskID
+	}
+
+	return pomodoroSession
+}
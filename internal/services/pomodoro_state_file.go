@@ -0,0 +1,158 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// PomodoroState is a snapshot of the running Pomodoro session kept in
+// ~/.prod/pomodoro.json so status can be shown without a database round-trip.
+// UserID tells whose session it is
+type PomodoroState struct {
+	SessionID          int32          `json:"session_id"`
+	UserID             int32          `json:"user_id"`
+	Status             PomodoroStatus `json:"status"`
+	StartTime          time.Time      `json:"start_time"`
+	PauseTime          *time.Time     `json:"pause_time,omitempty"`
+	WorkDuration       time.Duration  `json:"work_duration"`
+	TotalPauseDuration time.Duration  `json:"total_pause_duration"`
+	TaskID             *int32         `json:"task_id,omitempty"`
+	Task               string         `json:"task,omitempty"`
+	Note               string         `json:"note,omitempty"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+}
+
+// NewPomodoroState builds the state snapshot for a session
+func NewPomodoroState(session *PomodoroSession, taskDescription string) *PomodoroState {
+	state := &PomodoroState{
+		SessionID:          session.ID,
+		UserID:             session.UserID,
+		Status:             session.Status,
+		StartTime:          session.StartTime.Time,
+		WorkDuration:       session.WorkDuration,
+		TotalPauseDuration: session.TotalPauseDuration,
+		TaskID:             session.TaskID,
+		Task:               taskDescription,
+		Note:               session.Note,
+		UpdatedAt:          time.Now(),
+	}
+
+	if session.Status == StatusPaused && session.PauseTime.Valid {
+		pauseTime := session.PauseTime.Time
+		state.PauseTime = &pauseTime
+	}
+
+	return state
+}
+
+// Elapsed returns the focused time of the session at the given time
+func (s *PomodoroState) Elapsed(now time.Time) time.Duration {
+	end := now
+	if s.Status == StatusPaused && s.PauseTime != nil {
+		end = *s.PauseTime
+	}
+
+	elapsed := end.Sub(s.StartTime) - s.TotalPauseDuration
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return elapsed
+}
+
+// Remaining returns the work time left at the given time
+func (s *PomodoroState) Remaining(now time.Time) time.Duration {
+	remaining := s.WorkDuration - s.Elapsed(now)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining
+}
+
+func pomodoroStatePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("Error getting home directory: %w", err)
+	}
+
+	return filepath.Join(homeDir, ".prod", "pomodoro.json"), nil
+}
+
+// SavePomodoroState writes the state file, replacing any previous state
+func SavePomodoroState(state *PomodoroState) error {
+	filePath, err := pomodoroStatePath()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(filePath), 0755)
+	if err != nil {
+		return fmt.Errorf("Error creating directory: %w", err)
+	}
+
+	jsonData, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Error marshaling to JSON: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial state
+	tmpPath := filePath + ".tmp"
+	err = os.WriteFile(tmpPath, jsonData, 0644)
+	if err != nil {
+		return fmt.Errorf("Error writing to file: %w", err)
+	}
+
+	err = os.Rename(tmpPath, filePath)
+	if err != nil {
+		return fmt.Errorf("Error replacing state file: %w", err)
+	}
+
+	return nil
+}
+
+// LoadPomodoroState reads the state file of a user, returning nil if the user
+// has no running session. The file is shared by everyone logging in on this
+// computer, so a state left by another user is ignored
+func LoadPomodoroState(userID int32) (*PomodoroState, error) {
+	filePath, err := pomodoroStatePath()
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error reading the state file: %w", err)
+	}
+
+	state := &PomodoroState{}
+	err = json.Unmarshal(bytes, state)
+	if err != nil {
+		return nil, fmt.Errorf("Error unmarshal the state file: %w", err)
+	}
+	if state.UserID != userID {
+		return nil, nil
+	}
+
+	return state, nil
+}
+
+// ClearPomodoroState removes the state file once no session is running
+func ClearPomodoroState() error {
+	filePath, err := pomodoroStatePath()
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(filePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("Error removing the state file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPomodoroState(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	state, err := LoadPomodoroState(1)
+	require.NoError(t, err)
+	assert.Nil(t, state, "no file, no session")
+
+	saved := &PomodoroState{
+		SessionID:    4,
+		UserID:       1,
+		Status:       StatusActive,
+		StartTime:    time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC),
+		WorkDuration: 25 * time.Minute,
+		UpdatedAt:    time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, SavePomodoroState(saved))
+
+	state, err = LoadPomodoroState(1)
+	require.NoError(t, err)
+	assert.Equal(t, saved, state)
+
+	// Another user logged in on the same computer doesn't see the session
+	state, err = LoadPomodoroState(2)
+	require.NoError(t, err)
+	assert.Nil(t, state)
+
+	require.NoError(t, ClearPomodoroState())
+	state, err = LoadPomodoroState(1)
+	require.NoError(t, err)
+	assert.Nil(t, state)
+}
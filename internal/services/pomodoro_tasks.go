@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jskallebak/prod/internal/db/sqlc"
+)
+
+// LinkedStop is the outcome of stopping a session together with its task
+type LinkedStop struct {
+	Session *PomodoroSession
+	// Task is the paused or completed task, nil if nothing was attached or changed
+	Task *sqlc.Task
+	// NextTask is the next instance of a completed recurring task
+	NextTask *sqlc.Task
+	// Completed is true when the task was completed rather than paused
+	Completed bool
+}
+
+// LinkTasksEnabled reports whether the user opted in to linking sessions and tasks
+func (s *PomodoroService) LinkTasksEnabled(ctx context.Context, userID int32) bool {
+	config, err := s.GetUserConfig(ctx, userID)
+	return err == nil && config.LinkTasks
+}
+
+// SessionMeetsTarget reports whether enough of the work duration was focused on
+// for the session to count as completed
+func SessionMeetsTarget(session *PomodoroSession, now time.Time) bool {
+	elapsed := now.Sub(session.StartTime.Time)
+
+	// Time spent in the current pause does not count
+	if session.Status == StatusPaused && session.PauseTime.Valid {
+		elapsed -= now.Sub(session.PauseTime.Time)
+	}
+	elapsed -= session.TotalPauseDuration
+
+	// At least 80% of the work duration has to be done
+	return elapsed >= time.Duration(float64(session.WorkDuration)*0.8)
+}
+
+// StartSessionWithTask starts a session and marks its task active
+func (s *PomodoroService) StartSessionWithTask(
+	ctx context.Context,
+	userID int32,
+	taskID int32,
+	workDuration time.Duration,
+	breakDuration time.Duration,
+	note string,
+) (*PomodoroSession, *sqlc.Task, error) {
+	session, err := s.StartSession(ctx, userID, &taskID, workDuration, breakDuration, note)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	task, err := s.taskService().StartTask(ctx, taskID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return session, task, nil
+}
+
+// StopSessionWithTask stops the active session and updates its task. With done
+// the task is completed, generating the next instance of a recurring task,
+// otherwise an active task is paused. Run it in a transaction so the session
+// and the task change together.
+func (s *PomodoroService) StopSessionWithTask(ctx context.Context, userID int32, complete bool, done bool) (*LinkedStop, error) {
+	session, err := s.StopSession(ctx, userID, complete)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LinkedStop{Session: session}
+	if session.TaskID == nil {
+		return result, nil
+	}
+
+	taskService := s.taskService()
+	if done {
+		completedTask, nextTask, err := taskService.CompleteRecurringTask(ctx, *session.TaskID, userID)
+		if err != nil {
+			return nil, err
+		}
+		result.Task = completedTask
+		result.NextTask = nextTask
+		result.Completed = true
+		return result, nil
+	}
+
+	task, err := taskService.GetTask(ctx, *session.TaskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != "active" {
+		return result, nil
+	}
+
+	result.Task, err = taskService.PauseTask(ctx, task.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CompleteTaskAndStopSession completes a task and, when stopSession is set,
+// stops the active session in the same unit of work
+func (s *PomodoroService) CompleteTaskAndStopSession(ctx context.Context, userID int32, taskID int32, stopSession bool) (*LinkedStop, error) {
+	taskService := s.taskService()
+	completedTask, nextTask, err := taskService.CompleteRecurringTask(ctx, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LinkedStop{
+		Task:      completedTask,
+		NextTask:  nextTask,
+		Completed: true,
+	}
+	if !stopSession {
+		return result, nil
+	}
+
+	active, err := s.GetActiveSession(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("no active Pomodoro session found: %w", err)
+	}
+
+	result.Session, err = s.StopSession(ctx, userID, SessionMeetsTarget(active, time.Now()))
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// taskService returns a TaskService recording its changes in the same journal
+func (s *PomodoroService) taskService() *TaskService {
+	return &TaskService{
+		queries: s.queries,
+		journal: s.journal,
+	}
+}
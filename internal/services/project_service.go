@@ -2,9 +2,12 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jskallebak/prod/internal/db/sqlc"
 )
@@ -12,12 +15,14 @@ import (
 // ProjectService handles business logic for projects
 type ProjectService struct {
 	queries *sqlc.Queries
+	journal *journal
 }
 
 // NewProjectService creates a new ProjectService
 func NewProjectService(queries *sqlc.Queries) *ProjectService {
 	return &ProjectService{
 		queries: queries,
+		journal: newJournal(queries),
 	}
 }
 
@@ -65,9 +70,53 @@ func (s *ProjectService) CreateProject(ctx context.Context, userID int32, params
 		return nil, fmt.Errorf("failed to create project: %w", err)
 	}
 
+	if err := s.journal.recordProject(ctx, userID, fmt.Sprintf("create project %q", project.Name), nil, &project); err != nil {
+		return nil, err
+	}
+
 	return &project, nil
 }
 
+// findOrCreateProject returns the project with the given name, creating it
+// when the user has none. The bool reports whether it was created, and
+// description names the journal operation the creation is part of
+func (s *ProjectService) findOrCreateProject(ctx context.Context, userID int32, name, description string) (*sqlc.Project, bool, error) {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+
+	project, err := s.queries.GetProjectByName(ctx, sqlc.GetProjectByNameParams{
+		UserID: user,
+		Name:   name,
+	})
+	if err == nil {
+		return &project, false, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, fmt.Errorf("failed to look up project %q: %w", name, err)
+	}
+
+	now := pgtype.Timestamptz{
+		Time:  time.Now(),
+		Valid: true,
+	}
+	project, err = s.queries.ImportProject(ctx, sqlc.ImportProjectParams{
+		UserID:    user,
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create project %q: %w", name, err)
+	}
+	if err := s.journal.recordProject(ctx, userID, description, nil, &project); err != nil {
+		return nil, false, err
+	}
+
+	return &project, true, nil
+}
+
 // GetProject retrieves a project by ID
 func (s *ProjectService) GetProject(ctx context.Context, projectID int32, userID int32) (*sqlc.Project, error) {
 	project, err := s.queries.GetProject(ctx, sqlc.GetProjectParams{
@@ -84,6 +133,29 @@ func (s *ProjectService) GetProject(ctx context.Context, projectID int32, userID
 	return &project, nil
 }
 
+// FindProject returns the project with the given ID or name
+func (s *ProjectService) FindProject(ctx context.Context, userID int32, nameOrID string) (*sqlc.Project, error) {
+	if id, err := strconv.Atoi(nameOrID); err == nil {
+		return s.GetProject(ctx, int32(id), userID)
+	}
+
+	project, err := s.queries.GetProjectByName(ctx, sqlc.GetProjectByNameParams{
+		UserID: pgtype.Int4{
+			Int32: userID,
+			Valid: true,
+		},
+		Name: nameOrID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("no project named %q", nameOrID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up project %q: %w", nameOrID, err)
+	}
+
+	return &project, nil
+}
+
 // ListProjects retrieves all projects for a user
 func (s *ProjectService) ListProjects(ctx context.Context, userID int32) ([]sqlc.Project, error) {
 	projects, err := s.queries.ListProjects(ctx, pgtype.Int4{
@@ -98,6 +170,11 @@ func (s *ProjectService) ListProjects(ctx context.Context, userID int32) ([]sqlc
 
 // UpdateProject updates a project
 func (s *ProjectService) UpdateProject(ctx context.Context, projectID int32, userID int32, params ProjectParams) (*sqlc.Project, error) {
+	before, err := s.GetProject(ctx, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	updateParams := sqlc.UpdateProjectParams{
 		ID: projectID,
 		UserID: pgtype.Int4{
@@ -131,28 +208,75 @@ func (s *ProjectService) UpdateProject(ctx context.Context, projectID int32, use
 		return nil, fmt.Errorf("failed to update project: %w", err)
 	}
 
+	if err := s.journal.recordProject(ctx, userID, fmt.Sprintf("edit project %q", project.Name), before, &project); err != nil {
+		return nil, err
+	}
+
 	return &project, nil
 }
 
-// DeleteProject deletes a project
+// DeleteProject moves a project to the trash. Its tasks are kept without a
+// project and re-attached when the project is restored from the trash
 func (s *ProjectService) DeleteProject(ctx context.Context, projectID int32, userID int32) error {
-	err := s.queries.DeleteProject(ctx, sqlc.DeleteProjectParams{
-		ID: projectID,
-		UserID: pgtype.Int4{
-			Int32: userID,
-			Valid: true,
-		},
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+	project := pgtype.Int4{
+		Int32: projectID,
+		Valid: true,
+	}
+
+	deleted, err := s.queries.DeleteProject(ctx, sqlc.DeleteProjectParams{
+		ID:     projectID,
+		UserID: user,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to delete project: %w", err)
 	}
 
+	// Remember the tasks before detaching them, forgetting those of an earlier trashing
+	if err := s.queries.ForgetProjectTasks(ctx, projectID); err != nil {
+		return fmt.Errorf("failed to forget project tasks: %w", err)
+	}
+	err = s.queries.RememberProjectTasks(ctx, sqlc.RememberProjectTasksParams{
+		ProjectID: project,
+		UserID:    user,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remember project tasks: %w", err)
+	}
+	tasks, err := s.queries.DetachProjectTasks(ctx, sqlc.DetachProjectTasksParams{
+		ProjectID: project,
+		UserID:    user,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to detach project tasks: %w", err)
+	}
+
+	if err := s.journal.begin(ctx, userID, fmt.Sprintf("delete project %q", deleted.Name)); err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		before := task
+		before.ProjectID = project
+		if err := s.journal.recordTask(ctx, userID, "", &before, &task); err != nil {
+			return err
+		}
+	}
+	before := deleted
+	before.DeletedAt = pgtype.Timestamptz{}
+	if err := s.journal.recordProject(ctx, userID, "", &before, &deleted); err != nil {
+		return err
+	}
+
 	return nil
 }
 
-// GetProjectTasks retrieves all tasks for a project
-func (s *ProjectService) GetProjectTasks(ctx context.Context, projectID int32, userID int32) ([]sqlc.Task, error) {
-	tasks, err := s.queries.GetProjectTasks(ctx, sqlc.GetProjectTasksParams{
+// GetProjectTasks retrieves the tasks of a project. Tasks hidden with 'prod
+// task wait' are left out unless includeWaiting is set
+func (s *ProjectService) GetProjectTasks(ctx context.Context, projectID int32, userID int32, includeWaiting bool) ([]sqlc.Task, error) {
+	params := sqlc.GetProjectTasksParams{
 		ProjectID: pgtype.Int4{
 			Int32: projectID,
 			Valid: true,
@@ -161,7 +285,15 @@ func (s *ProjectService) GetProjectTasks(ctx context.Context, projectID int32, u
 			Int32: userID,
 			Valid: true,
 		},
-	})
+	}
+	if !includeWaiting {
+		params.Waiting = pgtype.Bool{
+			Bool:  false,
+			Valid: true,
+		}
+	}
+
+	tasks, err := s.queries.GetProjectTasks(ctx, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get project tasks: %w", err)
 	}
@@ -170,16 +302,30 @@ func (s *ProjectService) GetProjectTasks(ctx context.Context, projectID int32, u
 
 // RemoveTaskFromProject removes a task from a project
 func (s *ProjectService) RemoveTaskFromProject(ctx context.Context, taskID int32, userID int32) (*sqlc.Task, error) {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+
+	before, err := s.queries.GetTask(ctx, sqlc.GetTaskParams{
+		ID:     taskID,
+		UserID: user,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
 	task, err := s.queries.RemoveTaskFromProject(ctx, sqlc.RemoveTaskFromProjectParams{
-		ID: taskID,
-		UserID: pgtype.Int4{
-			Int32: userID,
-			Valid: true,
-		},
+		ID:     taskID,
+		UserID: user,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to remove task from project: %w", err)
 	}
 
+	if err := s.journal.recordTask(ctx, userID, fmt.Sprintf("remove task %q from project", task.Description), &before, &task); err != nil {
+		return nil, err
+	}
+
 	return &task, nil
 }
@@ -7,19 +7,18 @@ import (
 	"github.com/jskallebak/prod/internal/db/sqlc"
 )
 
-func RecursiveSubtasks(
+// ConfirmSubtasks asks to confirm the action for each subtask and returns them, changing nothing
+func ConfirmSubtasks(
 	ctx context.Context,
 	userID int32,
 	taskID int32,
 	ts *TaskService,
 	action string,
-	input int,
 	confirmFunc func(context.Context, int32, int32, string, *TaskService) error,
-	executeFunc func(context.Context, int32, int32) (*sqlc.Task, error),
-) error {
+) ([]sqlc.Task, error) {
 	subtasks, err := ts.GetDependent(ctx, userID, taskID)
 	if err != nil {
-		return fmt.Errorf("RecursiveSubtasks: Error with GetDependent: %v", err)
+		return nil, fmt.Errorf("ConfirmSubtasks: Error with GetDependent: %v", err)
 	}
 
 	if len(subtasks) != 0 {
@@ -27,15 +26,27 @@ func RecursiveSubtasks(
 		for _, st := range subtasks {
 			err = confirmFunc(ctx, st.ID, userID, action, ts)
 			if err != nil {
-				return fmt.Errorf("RecursiveSubtasks: Error with ConfirmCmd: %v", err)
+				return nil, fmt.Errorf("ConfirmSubtasks: Error with ConfirmCmd: %v", err)
 			}
+		}
+	}
 
-			_, err = executeFunc(ctx, st.ID, userID)
-			if err != nil {
-				return fmt.Errorf("RecursiveSubtasks: Error with DeleteTask: %v", err)
-			}
-			fmt.Printf("Task %d deleted successfully\n", input)
+	return subtasks, nil
+}
+
+// ApplyToSubtasks applies executeFunc to each of the confirmed subtasks
+func ApplyToSubtasks(
+	ctx context.Context,
+	userID int32,
+	subtasks []sqlc.Task,
+	done string,
+	executeFunc func(context.Context, int32, int32) (*sqlc.Task, error),
+) error {
+	for _, st := range subtasks {
+		if _, err := executeFunc(ctx, st.ID, userID); err != nil {
+			return fmt.Errorf("ApplyToSubtasks: Error changing subtask %q: %v", st.Description, err)
 		}
+		fmt.Printf("Subtask %q %s successfully\n", st.Description, done)
 	}
 
 	return nil
@@ -0,0 +1,393 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+)
+
+// BulkLine is one task line of a bulk edit file, like
+// `  x #3 (H) due:2025-05-20 +errand project:Home Buy milk`. The number refers
+// to a task written to the file and lines without one are new tasks. Each two
+// spaces or tab of indentation makes a line a subtask of the line above it
+type BulkLine struct {
+	Depth       int
+	Completed   bool
+	Number      int
+	Priority    string
+	Due         time.Time
+	Tags        []string
+	Project     string
+	Description string
+}
+
+var (
+	bulkNumber   = regexp.MustCompile(`^#[0-9]+$`)
+	bulkPriority = regexp.MustCompile(`^\([HML]\)$`)
+)
+
+// ParseBulkLine parses a bulk edit line. It returns false for blank lines and
+// comments starting with "# "
+func ParseBulkLine(line string) (BulkLine, bool, error) {
+	var parsed BulkLine
+	fields := strings.Fields(line)
+	if len(fields) == 0 || fields[0] == "#" || strings.HasPrefix(strings.TrimSpace(line), "# ") {
+		return parsed, false, nil
+	}
+
+	indent := 0
+	for _, r := range line {
+		if r == ' ' {
+			indent++
+		} else if r == '\t' {
+			indent += 2
+		} else {
+			break
+		}
+	}
+	parsed.Depth = indent / 2
+
+	if fields[0] == "x" {
+		parsed.Completed = true
+		fields = fields[1:]
+	}
+	if len(fields) > 0 && bulkNumber.MatchString(fields[0]) {
+		parsed.Number, _ = strconv.Atoi(fields[0][1:])
+		fields = fields[1:]
+	}
+	if len(fields) > 0 && bulkPriority.MatchString(fields[0]) {
+		parsed.Priority = fields[0][1:2]
+		fields = fields[1:]
+	}
+
+	var words []string
+	for _, field := range fields {
+		if len(field) > 1 && field[0] == '+' {
+			parsed.Tags = append(parsed.Tags, field[1:])
+			continue
+		}
+		key, value, found := strings.Cut(field, ":")
+		if found && value != "" {
+			switch key {
+			case "due":
+				date, err := time.ParseInLocation(todoDateFormat, value, time.Local)
+				if err != nil {
+					return parsed, true, fmt.Errorf("invalid due date %q (use YYYY-MM-DD)", value)
+				}
+				parsed.Due = date
+				continue
+			case "project":
+				parsed.Project = value
+				continue
+			}
+		}
+		words = append(words, field)
+	}
+	parsed.Description = strings.Join(words, " ")
+	if parsed.Description == "" {
+		return parsed, true, fmt.Errorf("the task has no description")
+	}
+
+	return parsed, true, nil
+}
+
+// String formats the line, indented by its depth
+func (l BulkLine) String() string {
+	parts := []string{strings.Repeat("  ", l.Depth)}
+	if l.Completed {
+		parts = append(parts, "x ")
+	}
+	if l.Number > 0 {
+		parts = append(parts, fmt.Sprintf("#%d ", l.Number))
+	}
+	if l.Priority != "" {
+		parts = append(parts, "("+l.Priority+") ")
+	}
+	if !l.Due.IsZero() {
+		parts = append(parts, "due:"+l.Due.Format(todoDateFormat)+" ")
+	}
+	for _, tag := range l.Tags {
+		parts = append(parts, "+"+todoWord(tag)+" ")
+	}
+	if l.Project != "" {
+		parts = append(parts, "project:"+todoWord(l.Project)+" ")
+	}
+	parts = append(parts, l.Description)
+
+	return strings.Join(parts, "")
+}
+
+// FormatBulkEdit writes tasks as bulk edit lines, each subtask below its
+// parent. The tasks are returned in the order of the lines, line n being for
+// task #n
+func FormatBulkEdit(tasks []sqlc.Task, projectNames map[int32]string) ([]sqlc.Task, []string) {
+	ordered := SortTaskList(tasks)
+
+	byID := make(map[int32]sqlc.Task, len(ordered))
+	for _, task := range ordered {
+		byID[task.ID] = task
+	}
+	depths := make(map[int32]int)
+
+	lines := make([]string, 0, len(ordered))
+	for i, task := range ordered {
+		line := BulkLine{
+			Depth:       calculateDepth(task.ID, byID, depths),
+			Completed:   task.Status == "completed",
+			Number:      i + 1,
+			Priority:    task.Priority.String,
+			Due:         todoDate(task.DueDate),
+			Tags:        task.Tags,
+			Description: task.Description,
+		}
+		if task.ProjectID.Valid {
+			line.Project = projectNames[task.ProjectID.Int32]
+		}
+		lines = append(lines, line.String())
+	}
+
+	return ordered, lines
+}
+
+// BulkEdit is what changed in a bulk edit file
+type BulkEdit struct {
+	Lines   []BulkEditLine
+	Deleted []sqlc.Task
+}
+
+// BulkEditLine is a task as edited in the file
+type BulkEditLine struct {
+	// Before is the task written to the file, nil for a new task
+	Before *sqlc.Task
+	// Task has the edited fields. With a new parent task, Dependent is set
+	// when the parent is created
+	Task sqlc.Task
+	// Parent is the index of the line of the parent task, or -1
+	Parent int
+}
+
+// Changed tells whether the line changes its task or makes a new one
+func (l BulkEditLine) Changed(userID int32) bool {
+	// A subtask of a new task gets its parent once that is created
+	if l.Before == nil || l.Parent >= 0 && !l.Task.Dependent.Valid {
+		return true
+	}
+	return len(diffTask(userID, l.Before, &l.Task)) > 0
+}
+
+// PlanBulkEdit compares the lines of an edited file to the tasks written to
+// it, line n being for tasks[n-1]. Tasks whose line was removed are deleted.
+// A top level line keeps a parent that wasn't in the file, so filtering out
+// parents doesn't move their subtasks
+func PlanBulkEdit(userID int32, tasks []sqlc.Task, lines []string, projectNames map[int32]string) (*BulkEdit, error) {
+	projectIDs := make(map[string]int32, len(projectNames))
+	for id, name := range projectNames {
+		projectIDs[todoWord(name)] = id
+	}
+	inFile := make(map[int32]bool, len(tasks))
+	for _, task := range tasks {
+		inFile[task.ID] = true
+	}
+
+	edit := &BulkEdit{}
+	seen := make(map[int]bool)
+	type level struct {
+		depth int
+		index int
+	}
+	var parents []level
+
+	for i, text := range lines {
+		line, ok, err := ParseBulkLine(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		if !ok {
+			continue
+		}
+
+		var before *sqlc.Task
+		task := sqlc.Task{
+			UserID: pgtype.Int4{
+				Int32: userID,
+				Valid: true,
+			},
+			Status: "pending",
+		}
+		if line.Number > 0 {
+			if line.Number > len(tasks) {
+				return nil, fmt.Errorf("line %d: there is no task #%d", i+1, line.Number)
+			}
+			if seen[line.Number] {
+				return nil, fmt.Errorf("line %d: task #%d is on more than one line", i+1, line.Number)
+			}
+			seen[line.Number] = true
+			before = &tasks[line.Number-1]
+			task = *before
+		}
+
+		task.Description = line.Description
+		task.Priority = pgtype.Text{}
+		if line.Priority != "" {
+			task.Priority = pgtype.Text{
+				String: line.Priority,
+				Valid:  true,
+			}
+		}
+		task.DueDate = todoTimestamp(task.DueDate, line.Due)
+		task.Tags = line.Tags
+		if task.Tags == nil {
+			task.Tags = []string{}
+		}
+		task.ProjectID = pgtype.Int4{}
+		if line.Project != "" {
+			id, ok := projectIDs[todoWord(line.Project)]
+			if !ok {
+				return nil, fmt.Errorf("line %d: no project named %q", i+1, line.Project)
+			}
+			task.ProjectID = pgtype.Int4{
+				Int32: id,
+				Valid: true,
+			}
+		}
+		switch {
+		case line.Completed:
+			task.Status = "completed"
+		case task.Status == "completed":
+			task.Status = "pending"
+		}
+
+		for len(parents) > 0 && parents[len(parents)-1].depth >= line.Depth {
+			parents = parents[:len(parents)-1]
+		}
+		parent := -1
+		if len(parents) > 0 {
+			parent = parents[len(parents)-1].index
+			task.Dependent = pgtype.Int4{}
+			if other := edit.Lines[parent].Before; other != nil {
+				task.Dependent = pgtype.Int4{
+					Int32: other.ID,
+					Valid: true,
+				}
+			}
+		} else if !task.Dependent.Valid || inFile[task.Dependent.Int32] {
+			task.Dependent = pgtype.Int4{}
+		}
+		parents = append(parents, level{
+			depth: line.Depth,
+			index: len(edit.Lines),
+		})
+
+		edit.Lines = append(edit.Lines, BulkEditLine{
+			Before: before,
+			Task:   task,
+			Parent: parent,
+		})
+	}
+
+	for i := range tasks {
+		if !seen[i+1] {
+			edit.Deleted = append(edit.Deleted, tasks[i])
+		}
+	}
+
+	return edit, nil
+}
+
+// ErrEditConflict is returned by ApplyBulkEdit when a task was changed or
+// deleted elsewhere after the file was written
+var ErrEditConflict = errors.New("changed since the file was written")
+
+// ApplyBulkEdit creates, changes and deletes the tasks of a bulk edit, in the
+// order of the file so new parents exist before their subtasks. Tasks that
+// changed since the file was written fail with ErrEditConflict instead of
+// being overwritten. Run it in a UnitOfWork so the whole edit is saved, and
+// undone, at once
+func (s *TaskService) ApplyBulkEdit(ctx context.Context, userID int32, edit *BulkEdit) error {
+	var touched []sqlc.Task
+	for _, line := range edit.Lines {
+		if line.Before != nil && line.Changed(userID) {
+			touched = append(touched, *line.Before)
+		}
+	}
+	touched = append(touched, edit.Deleted...)
+	if err := s.lockUnchanged(ctx, userID, touched); err != nil {
+		return err
+	}
+
+	if err := s.journal.begin(ctx, userID, "bulk edit tasks"); err != nil {
+		return err
+	}
+
+	ids := make([]int32, len(edit.Lines))
+	for i, line := range edit.Lines {
+		task := line.Task
+		if line.Parent >= 0 {
+			task.Dependent = pgtype.Int4{
+				Int32: ids[line.Parent],
+				Valid: true,
+			}
+		}
+		if line.Before != nil {
+			ids[i] = line.Before.ID
+			if len(diffTask(userID, line.Before, &task)) == 0 {
+				continue
+			}
+			// A kept parent outside the file can be below a task in it
+			if task.Dependent.Valid && task.Dependent != line.Before.Dependent {
+				if err := s.checkParent(ctx, userID, task.Dependent.Int32, []sqlc.Task{*line.Before}); err != nil {
+					return fmt.Errorf("can't move %q: %w", task.Description, err)
+				}
+			}
+		}
+
+		saved, err := s.SyncTask(ctx, userID, line.Before, task)
+		if err != nil {
+			return err
+		}
+		ids[i] = saved.ID
+	}
+
+	for _, task := range edit.Deleted {
+		if _, err := s.DeleteTask(ctx, task.ID, userID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lockUnchanged locks the tasks until the transaction ends and makes sure they
+// are still as they were read, naming every task that isn't
+func (s *TaskService) lockUnchanged(ctx context.Context, userID int32, tasks []sqlc.Task) error {
+	var conflicts []string
+	for _, task := range tasks {
+		current, err := s.queries.GetTaskForUpdate(ctx, sqlc.GetTaskForUpdateParams{
+			ID: task.ID,
+			UserID: pgtype.Int4{
+				Int32: userID,
+				Valid: true,
+			},
+		})
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to get task %q: %w", task.Description, err)
+		}
+		// Tasks in the trash aren't found either
+		if err != nil || current.UpdatedAt.Valid != task.UpdatedAt.Valid ||
+			!current.UpdatedAt.Time.Equal(task.UpdatedAt.Time) || current.DeletedAt.Valid != task.DeletedAt.Valid {
+			conflicts = append(conflicts, fmt.Sprintf("%q", task.Description))
+		}
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%s %w", strings.Join(conflicts, ", "), ErrEditConflict)
+	}
+	return nil
+}
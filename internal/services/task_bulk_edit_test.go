@@ -0,0 +1,135 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/stretchr/testify/assert"
+)
+
+func bulkTask(id int32, description string, parent int32) sqlc.Task {
+	task := sqlc.Task{
+		ID: id,
+		UserID: pgtype.Int4{
+			Int32: 1,
+			Valid: true,
+		},
+		Description: description,
+		Status:      "pending",
+		Tags:        []string{},
+	}
+	if parent != 0 {
+		task.Dependent = pgtype.Int4{
+			Int32: parent,
+			Valid: true,
+		}
+	}
+	return task
+}
+
+func TestParseBulkLine(t *testing.T) {
+	line, ok, err := ParseBulkLine("    x #3 (H) due:2025-05-20 +errand project:Home_Office Buy milk")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 2, line.Depth)
+	assert.True(t, line.Completed)
+	assert.Equal(t, 3, line.Number)
+	assert.Equal(t, "H", line.Priority)
+	assert.Equal(t, time.Date(2025, 5, 20, 0, 0, 0, 0, time.Local), line.Due)
+	assert.Equal(t, []string{"errand"}, line.Tags)
+	assert.Equal(t, "Home_Office", line.Project)
+	assert.Equal(t, "Buy milk", line.Description)
+	assert.Equal(t, "    x #3 (H) due:2025-05-20 +errand project:Home_Office Buy milk", line.String())
+
+	// Comments and blank lines are skipped
+	for _, text := range []string{"", "   ", "# a comment", "#"} {
+		_, ok, err := ParseBulkLine(text)
+		assert.NoError(t, err)
+		assert.False(t, ok, text)
+	}
+
+	_, _, err = ParseBulkLine("#3 (H) +errand")
+	assert.Error(t, err, "a task needs a description")
+	_, _, err = ParseBulkLine("Call mom due:friday")
+	assert.Error(t, err)
+}
+
+func TestFormatBulkEdit(t *testing.T) {
+	tasks := []sqlc.Task{
+		bulkTask(12, "Paint the fence", 10),
+		bulkTask(10, "Garden", 0),
+		bulkTask(11, "Groceries", 0),
+	}
+	tasks[2].ProjectID = pgtype.Int4{
+		Int32: 5,
+		Valid: true,
+	}
+
+	ordered, lines := FormatBulkEdit(tasks, map[int32]string{5: "Home Office"})
+	assert.Equal(t, []int32{10, 12, 11}, []int32{ordered[0].ID, ordered[1].ID, ordered[2].ID})
+	assert.Equal(t, []string{
+		"#1 Garden",
+		"  #2 Paint the fence",
+		"#3 project:Home_Office Groceries",
+	}, lines)
+}
+
+func TestPlanBulkEdit(t *testing.T) {
+	tasks := []sqlc.Task{
+		bulkTask(10, "Garden", 0),
+		bulkTask(12, "Paint the fence", 10),
+		bulkTask(11, "Groceries", 0),
+		bulkTask(13, "Old idea", 0),
+		// A subtask whose parent wasn't in the file
+		bulkTask(14, "Water plants", 99),
+	}
+
+	edit, err := PlanBulkEdit(1, tasks, []string{
+		"# Edit the tasks",
+		"#1 Garden",
+		"x #3 (H) +shop Groceries",
+		"  #2 Paint the fence",
+		"  Buy brushes",
+		"    Compare prices",
+		"#5 Water plants",
+	}, map[int32]string{})
+	assert.NoError(t, err)
+	assert.Len(t, edit.Lines, 6)
+
+	assert.False(t, edit.Lines[0].Changed(1), "the garden is unchanged")
+
+	groceries := edit.Lines[1]
+	assert.True(t, groceries.Changed(1))
+	assert.Equal(t, "completed", groceries.Task.Status)
+	assert.Equal(t, "H", groceries.Task.Priority.String)
+	assert.Equal(t, []string{"shop"}, groceries.Task.Tags)
+
+	// Moved under the groceries
+	fence := edit.Lines[2]
+	assert.Equal(t, 1, fence.Parent)
+	assert.Equal(t, int32(11), fence.Task.Dependent.Int32)
+
+	// New tasks, the second one below the first
+	assert.Nil(t, edit.Lines[3].Before)
+	assert.Equal(t, 1, edit.Lines[3].Parent)
+	assert.Equal(t, 3, edit.Lines[4].Parent)
+	assert.False(t, edit.Lines[4].Task.Dependent.Valid, "set once its parent is created")
+	assert.True(t, edit.Lines[4].Changed(1))
+
+	// The parent outside the file is kept
+	assert.False(t, edit.Lines[5].Changed(1))
+	assert.Equal(t, int32(99), edit.Lines[5].Task.Dependent.Int32)
+
+	// Removed lines are deleted
+	assert.Len(t, edit.Deleted, 1)
+	assert.Equal(t, int32(13), edit.Deleted[0].ID)
+
+	_, err = PlanBulkEdit(1, tasks, []string{"#9 Nothing"}, nil)
+	assert.Error(t, err)
+	_, err = PlanBulkEdit(1, tasks, []string{"#1 Garden", "#1 Garden again"}, nil)
+	assert.Error(t, err)
+	_, err = PlanBulkEdit(1, tasks, []string{"#1 project:Nowhere Garden"}, nil)
+	assert.Error(t, err)
+}
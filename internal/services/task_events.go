@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+)
+
+// Task event types stored in task_events
+const (
+	TaskEventCreated     = "created"
+	TaskEventDeleted     = "deleted"
+	TaskEventRestored    = "restored"
+	TaskEventDescription = "description"
+	TaskEventStatus      = "status"
+	TaskEventPriority    = "priority"
+	TaskEventDueDate     = "due_date"
+	TaskEventStartDate   = "start_date"
+	TaskEventWaitUntil   = "wait_until"
+	TaskEventScheduled   = "scheduled"
+	TaskEventProject     = "project"
+	TaskEventParent      = "parent"
+	TaskEventTags        = "tags"
+	TaskEventRecurrence  = "recurrence"
+	TaskEventEstimate    = "estimate"
+	TaskEventNotes       = "notes"
+)
+
+// recordTaskEvents appends one task event per field that differs between
+// before and after, nil meaning the task didn't exist
+func recordTaskEvents(ctx context.Context, queries *sqlc.Queries, userID int32, before, after *sqlc.Task) error {
+	var events []sqlc.CreateTaskEventParams
+
+	switch {
+	case before == nil && after == nil:
+		return nil
+	case before == nil:
+		events = append(events, taskEvent(after.ID, userID, TaskEventCreated, "", after.Description))
+	case after == nil:
+		events = append(events, taskEvent(before.ID, userID, TaskEventDeleted, before.Description, ""))
+	default:
+		// Moving to and from the trash
+		if !before.DeletedAt.Valid && after.DeletedAt.Valid {
+			events = append(events, taskEvent(after.ID, userID, TaskEventDeleted, after.Description, ""))
+		} else if before.DeletedAt.Valid && !after.DeletedAt.Valid {
+			events = append(events, taskEvent(after.ID, userID, TaskEventRestored, "", after.Description))
+		}
+		events = append(events, diffTask(userID, before, after)...)
+	}
+
+	for _, event := range events {
+		if err := queries.CreateTaskEvent(ctx, event); err != nil {
+			return fmt.Errorf("failed to record task event: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func diffTask(userID int32, before, after *sqlc.Task) []sqlc.CreateTaskEventParams {
+	fields := []struct {
+		eventType string
+		old, new  string
+	}{
+		{TaskEventDescription, before.Description, after.Description},
+		{TaskEventStatus, before.Status, after.Status},
+		{TaskEventPriority, textValue(before.Priority), textValue(after.Priority)},
+		{TaskEventDueDate, timeValue(before.DueDate), timeValue(after.DueDate)},
+		{TaskEventStartDate, timeValue(before.StartDate), timeValue(after.StartDate)},
+		{TaskEventWaitUntil, timeValue(before.WaitUntil), timeValue(after.WaitUntil)},
+		{TaskEventScheduled, timeValue(before.Scheduled), timeValue(after.Scheduled)},
+		{TaskEventProject, int4Value(before.ProjectID), int4Value(after.ProjectID)},
+		{TaskEventParent, int4Value(before.Dependent), int4Value(after.Dependent)},
+		{TaskEventTags, strings.Join(before.Tags, ","), strings.Join(after.Tags, ",")},
+		{TaskEventRecurrence, textValue(before.Recurrence), textValue(after.Recurrence)},
+		{TaskEventEstimate, int4Value(before.EstimateMinutes), int4Value(after.EstimateMinutes)},
+		{TaskEventNotes, textValue(before.Notes), textValue(after.Notes)},
+	}
+
+	var events []sqlc.CreateTaskEventParams
+	for _, field := range fields {
+		if field.old != field.new {
+			events = append(events, taskEvent(after.ID, userID, field.eventType, field.old, field.new))
+		}
+	}
+	return events
+}
+
+// taskEvent builds an event, empty values being stored as NULL
+func taskEvent(taskID, userID int32, eventType, oldValue, newValue string) sqlc.CreateTaskEventParams {
+	return sqlc.CreateTaskEventParams{
+		TaskID:    taskID,
+		UserID:    userID,
+		EventType: eventType,
+		OldValue: pgtype.Text{
+			String: oldValue,
+			Valid:  oldValue != "",
+		},
+		NewValue: pgtype.Text{
+			String: newValue,
+			Valid:  newValue != "",
+		},
+	}
+}
+
+func textValue(t pgtype.Text) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.String
+}
+
+// timeValue formats a timestamp as RFC 3339 in UTC so it can be cast back to timestamptz
+func timeValue(t pgtype.Timestamptz) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.UTC().Format(time.RFC3339)
+}
+
+func int4Value(i pgtype.Int4) string {
+	if !i.Valid {
+		return ""
+	}
+	return strconv.Itoa(int(i.Int32))
+}
+
+// GetTaskHistory returns the events of a task, oldest first. It works for
+// deleted tasks too, as the history is kept after deletion
+func (s *TaskService) GetTaskHistory(ctx context.Context, userID, taskID int32) ([]sqlc.TaskEvent, error) {
+	events, err := s.queries.ListTaskEvents(ctx, sqlc.ListTaskEventsParams{
+		TaskID: taskID,
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task history: %w", err)
+	}
+
+	return events, nil
+}
+
+// CountDueDatePushbacks returns how many times the due date of a task was moved later
+func (s *TaskService) CountDueDatePushbacks(ctx context.Context, userID, taskID int32) (int64, error) {
+	count, err := s.queries.CountDueDatePushbacks(ctx, sqlc.CountDueDatePushbacksParams{
+		TaskID: taskID,
+		UserID: userID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count due date pushbacks: %w", err)
+	}
+
+	return count, nil
+}
+
+// ListPostponedTasks returns the tasks whose due date was pushed back most often since the given time
+func (s *TaskService) ListPostponedTasks(ctx context.Context, userID int32, since time.Time, limit int32) ([]sqlc.ListPostponedTasksRow, error) {
+	rows, err := s.queries.ListPostponedTasks(ctx, sqlc.ListPostponedTasksParams{
+		UserID:    userID,
+		CreatedAt: since,
+		Limit:     limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list postponed tasks: %w", err)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+)
+
+// TaskFilter selects tasks by their fields. Like 'prod task list' it matches
+// pending and active tasks that aren't waiting unless Status or Waiting say
+// otherwise
+type TaskFilter struct {
+	// Project is the name or ID of a project
+	Project  string
+	Priority string
+	// Tags must all be on a task
+	Tags   []string
+	Status []string
+	// Waiting matches the tasks waiting until later instead
+	Waiting bool
+	// Words must all appear in the description, in any case
+	Words []string
+}
+
+// ParseTaskFilter parses a filter like "project:Home +errand priority:H
+// status:completed milk". Words without a prefix are looked for in the
+// description, and status:waiting matches tasks hidden until later
+func ParseTaskFilter(args []string) (TaskFilter, error) {
+	var filter TaskFilter
+	for _, arg := range args {
+		for _, token := range strings.Fields(arg) {
+			key, value, _ := strings.Cut(token, ":")
+			switch {
+			case strings.HasPrefix(token, "+") && len(token) > 1:
+				filter.Tags = append(filter.Tags, token[1:])
+			case key == "project" && value != "":
+				filter.Project = value
+			case key == "priority":
+				value = strings.ToUpper(value)
+				if value != "H" && value != "M" && value != "L" {
+					return TaskFilter{}, fmt.Errorf("invalid priority: %q (use H, M or L)", value)
+				}
+				filter.Priority = value
+			case key == "status":
+				switch value {
+				case "pending", "active", "completed":
+					filter.Status = append(filter.Status, value)
+				case "waiting":
+					filter.Waiting = true
+				default:
+					return TaskFilter{}, fmt.Errorf("invalid status: %q (use pending, active, completed or waiting)", value)
+				}
+			default:
+				filter.Words = append(filter.Words, token)
+			}
+		}
+	}
+	return filter, nil
+}
+
+// FilterTasks lists the tasks matching a filter
+func (s *TaskService) FilterTasks(ctx context.Context, userID int32, filter TaskFilter) ([]sqlc.Task, error) {
+	var priority, project *string
+	if filter.Priority != "" {
+		priority = &filter.Priority
+	}
+	if filter.Project != "" {
+		found, err := NewProjectService(s.queries).FindProject(ctx, userID, filter.Project)
+		if err != nil {
+			return nil, err
+		}
+		id := fmt.Sprint(found.ID)
+		project = &id
+	}
+	status := filter.Status
+	if len(status) == 0 {
+		status = []string{"pending", "active"}
+	}
+
+	tasks, err := s.ListTasks(ctx, userID, priority, project, nil, status, false, filter.Waiting)
+	if err != nil {
+		return nil, err
+	}
+
+	matching := []sqlc.Task{}
+	for _, task := range tasks {
+		if matchesFilter(task, filter) {
+			matching = append(matching, task)
+		}
+	}
+	return matching, nil
+}
+
+func matchesFilter(task sqlc.Task, filter TaskFilter) bool {
+	for _, tag := range filter.Tags {
+		if !slices.Contains(task.Tags, tag) {
+			return false
+		}
+	}
+	description := strings.ToLower(task.Description)
+	for _, word := range filter.Words {
+		if !strings.Contains(description, strings.ToLower(word)) {
+			return false
+		}
+	}
+	return true
+}
+
+// TaskChanges are changes made to many tasks at once. Nil fields are left
+// as they are, and fields set to a NULL value are cleared
+type TaskChanges struct {
+	Priority   *pgtype.Text
+	DueDate    *pgtype.Timestamptz
+	ProjectID  *pgtype.Int4
+	ParentID   *pgtype.Int4
+	AddTags    []string
+	RemoveTags []string
+}
+
+// Apply returns the task with the changes made to it
+func (c TaskChanges) Apply(task sqlc.Task) sqlc.Task {
+	if c.Priority != nil {
+		task.Priority = *c.Priority
+	}
+	if c.DueDate != nil {
+		task.DueDate = *c.DueDate
+	}
+	if c.ProjectID != nil {
+		task.ProjectID = *c.ProjectID
+	}
+	if c.ParentID != nil {
+		task.Dependent = *c.ParentID
+	}
+
+	if len(c.AddTags) > 0 || len(c.RemoveTags) > 0 {
+		tags := []string{}
+		for _, tag := range task.Tags {
+			if !slices.Contains(c.RemoveTags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		for _, tag := range c.AddTags {
+			if !slices.Contains(tags, tag) && !slices.Contains(c.RemoveTags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		task.Tags = tags
+	}
+
+	return task
+}
+
+// ModifyTasks makes the changes to each of the tasks that they change and
+// returns the tasks as saved. The tasks are read again under a lock, so edits
+// made since they were listed are kept. A parent can't be one of the tasks or
+// below one of them. Run it in a UnitOfWork so all tasks are changed or none
+func (s *TaskService) ModifyTasks(ctx context.Context, userID int32, tasks []sqlc.Task, changes TaskChanges) ([]sqlc.Task, error) {
+	if changes.ParentID != nil && changes.ParentID.Valid {
+		if err := s.checkParent(ctx, userID, changes.ParentID.Int32, tasks); err != nil {
+			return nil, err
+		}
+	}
+
+	description := fmt.Sprintf("modify %d tasks", len(tasks))
+	if len(tasks) == 1 {
+		description = fmt.Sprintf("modify task %q", tasks[0].Description)
+	}
+
+	modified := []sqlc.Task{}
+	for _, selected := range tasks {
+		// Change the task as it is now, not as it was when it was selected
+		before, err := s.lockTask(ctx, userID, selected)
+		if err != nil {
+			return nil, err
+		}
+		task := changes.Apply(*before)
+		if len(diffTask(userID, before, &task)) == 0 {
+			continue
+		}
+		task.UpdatedAt = pgtype.Timestamptz{
+			Time:  time.Now(),
+			Valid: true,
+		}
+
+		saved, err := s.replaceTask(ctx, userID, description, before, task)
+		if err != nil {
+			return nil, err
+		}
+		modified = append(modified, *saved)
+	}
+	return modified, nil
+}
+
+// lockTask reads a task again and locks it until the transaction ends. A task
+// moved to the trash or purged since it was read is an error
+func (s *TaskService) lockTask(ctx context.Context, userID int32, task sqlc.Task) (*sqlc.Task, error) {
+	locked, err := s.queries.GetTaskForUpdate(ctx, sqlc.GetTaskForUpdateParams{
+		ID: task.ID,
+		UserID: pgtype.Int4{
+			Int32: userID,
+			Valid: true,
+		},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("task %q was deleted in the meantime", task.Description)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task %q: %w", task.Description, err)
+	}
+	return &locked, nil
+}
+
+// checkParent makes sure that a task exists and that it and the tasks above it
+// aren't among the tasks, which would make a loop of parents
+func (s *TaskService) checkParent(ctx context.Context, userID, parentID int32, tasks []sqlc.Task) error {
+	ids := make(map[int32]bool, len(tasks))
+	for _, task := range tasks {
+		ids[task.ID] = true
+	}
+
+	seen := make(map[int32]bool)
+	for id := parentID; !seen[id]; {
+		seen[id] = true
+		if ids[id] {
+			if id == parentID {
+				return fmt.Errorf("a task can't be its own parent")
+			}
+			return fmt.Errorf("the parent is a subtask of one of the tasks")
+		}
+		task, err := s.GetTask(ctx, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to find parent task: %w", err)
+		}
+		if !task.Dependent.Valid {
+			return nil
+		}
+		id = task.Dependent.Int32
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTaskFilter(t *testing.T) {
+	filter, err := ParseTaskFilter([]string{"project:Home", "+errand", "priority:h", "buy milk"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Home", filter.Project)
+	assert.Equal(t, "H", filter.Priority)
+	assert.Equal(t, []string{"errand"}, filter.Tags)
+	assert.Equal(t, []string{"buy", "milk"}, filter.Words)
+	assert.False(t, filter.Waiting)
+
+	filter, err = ParseTaskFilter([]string{"status:completed", "status:waiting"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"completed"}, filter.Status)
+	assert.True(t, filter.Waiting)
+
+	_, err = ParseTaskFilter([]string{"priority:X"})
+	assert.Error(t, err)
+	_, err = ParseTaskFilter([]string{"status:someday"})
+	assert.Error(t, err)
+}
+
+func TestTaskChangesApply(t *testing.T) {
+	task := sqlc.Task{
+		Description: "Buy milk",
+		Priority: pgtype.Text{
+			String: "M",
+			Valid:  true,
+		},
+		ProjectID: pgtype.Int4{
+			Int32: 1,
+			Valid: true,
+		},
+		Tags: []string{"errand", "shop"},
+	}
+
+	// Nothing changes without changes
+	assert.Equal(t, task, TaskChanges{}.Apply(task))
+
+	high := pgtype.Text{
+		String: "H",
+		Valid:  true,
+	}
+	changed := TaskChanges{
+		Priority:   &high,
+		ProjectID:  &pgtype.Int4{},
+		AddTags:    []string{"weekend", "shop"},
+		RemoveTags: []string{"errand"},
+	}.Apply(task)
+	assert.Equal(t, "H", changed.Priority.String)
+	assert.False(t, changed.ProjectID.Valid)
+	assert.Equal(t, []string{"shop", "weekend"}, changed.Tags)
+	assert.Equal(t, []string{"errand", "shop"}, task.Tags, "the task itself is left as it is")
+}
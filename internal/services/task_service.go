@@ -3,7 +3,6 @@ package services
 import (
 	"context"
 	"fmt"
-	"slices"
 	"strings"
 	"time"
 
@@ -14,12 +13,16 @@ import (
 // TaskService handles business logic for tasks
 type TaskService struct {
 	queries *sqlc.Queries
+	journal *journal
 }
 
-// NewTaskService creates a new TaskService
+// NewTaskService creates a new TaskService. The service has a journal of its
+// own, so all changes made through one instance are undone as one operation.
+// Use a new instance, or a UnitOfWork, for each command
 func NewTaskService(queries *sqlc.Queries) *TaskService {
 	return &TaskService{
 		queries: queries,
+		journal: newJournal(queries),
 	}
 }
 
@@ -34,10 +37,34 @@ type TaskParams struct {
 	Notes       *string
 	Recurrence  *string
 	Dependent   int32
+	// WaitUntil hides the task from the default lists until then
+	WaitUntil *time.Time
+	// Scheduled is the day work on the task is planned to begin
+	Scheduled *time.Time
+	// EstimateMinutes is the estimated effort in minutes
+	EstimateMinutes *int32
+	// UUID identifies the task in other apps. A random one is used when unset
+	UUID pgtype.UUID
 }
 
-// CompleteRecurringTask completes a task and generates the next instance if it's recurring
+// CompleteRecurringTask completes a task and generates the next instance if it's recurring.
+// Run it in a UnitOfWork so the completion and the next instance are saved together
 func (s *TaskService) CompleteRecurringTask(ctx context.Context, taskID, userID int32) (*sqlc.Task, *sqlc.Task, error) {
+	// Lock the task so concurrent completions can't create two next instances
+	task, err := s.queries.GetTaskForUpdate(ctx, sqlc.GetTaskForUpdateParams{
+		ID: taskID,
+		UserID: pgtype.Int4{
+			Int32: userID,
+			Valid: true,
+		},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	if task.Status == "completed" && task.Recurrence.Valid && task.Recurrence.String != "" {
+		return nil, nil, fmt.Errorf("task %d is already completed", taskID)
+	}
+
 	// Complete the current task
 	completedTask, err := s.CompleteTask(ctx, taskID, userID)
 	if err != nil {
@@ -55,23 +82,29 @@ func (s *TaskService) CompleteRecurringTask(ctx context.Context, taskID, userID
 
 		// Create the next task instance in the database
 		createParams := sqlc.CreateTaskParams{
-			UserID:      nextTask.UserID,
-			Description: nextTask.Description,
-			Status:      nextTask.Status,
-			Priority:    nextTask.Priority,
-			DueDate:     nextTask.DueDate,
-			StartDate:   nextTask.StartDate,
-			ProjectID:   nextTask.ProjectID,
-			Recurrence:  nextTask.Recurrence,
-			Tags:        nextTask.Tags,
-			Notes:       nextTask.Notes,
-			Dependent:   nextTask.Dependent,
+			UserID:          nextTask.UserID,
+			Description:     nextTask.Description,
+			Status:          nextTask.Status,
+			Priority:        nextTask.Priority,
+			DueDate:         nextTask.DueDate,
+			StartDate:       nextTask.StartDate,
+			ProjectID:       nextTask.ProjectID,
+			Recurrence:      nextTask.Recurrence,
+			Tags:            nextTask.Tags,
+			Notes:           nextTask.Notes,
+			Dependent:       nextTask.Dependent,
+			EstimateMinutes: nextTask.EstimateMinutes,
+			Uuid:            newUUID(),
 		}
 
 		createdTask, err := s.queries.CreateTask(ctx, createParams)
 		if err != nil {
 			return completedTask, nil, fmt.Errorf("failed to create next task instance: %w", err)
 		}
+		err = s.journal.recordTask(ctx, userID, fmt.Sprintf("create next occurrence of %q", createdTask.Description), nil, &createdTask)
+		if err != nil {
+			return completedTask, nil, err
+		}
 
 		return completedTask, &createdTask, nil
 	}
@@ -99,6 +132,10 @@ func (s *TaskService) CreateTask(ctx context.Context, userID int32, params TaskP
 		Description: params.Description,
 		Status:      status,
 		Tags:        params.Tags,
+		Uuid:        params.UUID,
+	}
+	if !createParams.Uuid.Valid {
+		createParams.Uuid = newUUID()
 	}
 
 	// Only set optional parameters if provided
@@ -123,6 +160,20 @@ func (s *TaskService) CreateTask(ctx context.Context, userID int32, params TaskP
 		}
 	}
 
+	if params.WaitUntil != nil {
+		createParams.WaitUntil = pgtype.Timestamptz{
+			Time:  *params.WaitUntil,
+			Valid: true,
+		}
+	}
+
+	if params.Scheduled != nil {
+		createParams.Scheduled = pgtype.Timestamptz{
+			Time:  *params.Scheduled,
+			Valid: true,
+		}
+	}
+
 	if params.ProjectID != nil {
 		createParams.ProjectID = pgtype.Int4{
 			Int32: *params.ProjectID,
@@ -151,12 +202,23 @@ func (s *TaskService) CreateTask(ctx context.Context, userID int32, params TaskP
 		}
 	}
 
+	if params.EstimateMinutes != nil {
+		createParams.EstimateMinutes = pgtype.Int4{
+			Int32: *params.EstimateMinutes,
+			Valid: true,
+		}
+	}
+
 	// Call data layer
 	task, err := s.queries.CreateTask(ctx, createParams)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create task: %w", err)
 	}
 
+	if err := s.journal.recordTask(ctx, userID, fmt.Sprintf("add task %q", task.Description), nil, &task); err != nil {
+		return nil, err
+	}
+
 	return &task, nil
 }
 
@@ -177,6 +239,11 @@ func (s *TaskService) GetTask(ctx context.Context, taskID int32, userID int32) (
 }
 
 func (s *TaskService) PauseTask(ctx context.Context, taskID, userID int32) (*sqlc.Task, error) {
+	before, err := s.GetTask(ctx, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	task, err := s.queries.PauseTask(ctx, sqlc.PauseTaskParams{
 		ID: taskID,
 		UserID: pgtype.Int4{
@@ -188,10 +255,20 @@ func (s *TaskService) PauseTask(ctx context.Context, taskID, userID int32) (*sql
 	if err != nil {
 		return nil, fmt.Errorf("failed to update the task to active: %w", err)
 	}
+
+	if err := s.journal.recordTask(ctx, userID, fmt.Sprintf("pause task %q", task.Description), before, &task); err != nil {
+		return nil, err
+	}
+
 	return &task, nil
 }
 
 func (s *TaskService) StartTask(ctx context.Context, taskID, userID int32) (*sqlc.Task, error) {
+	before, err := s.GetTask(ctx, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	task, err := s.queries.StartTask(ctx, sqlc.StartTaskParams{
 		ID: taskID,
 		UserID: pgtype.Int4{
@@ -203,11 +280,21 @@ func (s *TaskService) StartTask(ctx context.Context, taskID, userID int32) (*sql
 	if err != nil {
 		return nil, fmt.Errorf("failed to update task to active: %w", err)
 	}
+
+	if err := s.journal.recordTask(ctx, userID, fmt.Sprintf("start task %q", task.Description), before, &task); err != nil {
+		return nil, err
+	}
+
 	return &task, nil
 }
 
 // CompleteTask marks a task as completed
 func (s *TaskService) CompleteTask(ctx context.Context, taskID int32, userID int32) (*sqlc.Task, error) {
+	before, err := s.GetTask(ctx, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	task, err := s.queries.CompleteTask(ctx, sqlc.CompleteTaskParams{
 		ID: taskID,
 		UserID: pgtype.Int4{
@@ -219,10 +306,16 @@ func (s *TaskService) CompleteTask(ctx context.Context, taskID int32, userID int
 		return nil, fmt.Errorf("failed to update task to completed: %w", err)
 	}
 
+	if err := s.journal.recordTask(ctx, userID, fmt.Sprintf("complete task %q", task.Description), before, &task); err != nil {
+		return nil, err
+	}
+
 	return &task, nil
 }
 
-func (s *TaskService) ListTasks(ctx context.Context, userID int32, priority *string, project *string, tags []string, status []string, today bool) ([]sqlc.Task, error) {
+// ListTasks lists the tasks matching the filters. Tasks waiting until a later
+// time are listed when waiting is true and left out otherwise
+func (s *TaskService) ListTasks(ctx context.Context, userID int32, priority *string, project *string, tags []string, status []string, today bool, waiting bool) ([]sqlc.Task, error) {
 	// Create params object with userID being mandatory
 	params := sqlc.ListTasksParams{
 		UserID: pgtype.Int4{
@@ -263,6 +356,11 @@ func (s *TaskService) ListTasks(ctx context.Context, userID int32, priority *str
 		}
 	}
 
+	params.Waiting = pgtype.Bool{
+		Bool:  waiting,
+		Valid: true,
+	}
+
 	tasks, err := s.queries.ListTasks(ctx, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed ListTasks query: %w", err)
@@ -271,7 +369,7 @@ func (s *TaskService) ListTasks(ctx context.Context, userID int32, priority *str
 	return tasks, nil
 }
 
-// DeleteTask removes a task
+// DeleteTask moves a task to the trash, see TrashService to restore or purge it
 func (s *TaskService) DeleteTask(ctx context.Context, taskID int32, userID int32) (*sqlc.Task, error) {
 	task, err := s.queries.DeleteTask(ctx, sqlc.DeleteTaskParams{
 		ID: taskID,
@@ -284,10 +382,21 @@ func (s *TaskService) DeleteTask(ctx context.Context, taskID int32, userID int32
 		return nil, fmt.Errorf("failed to delete task: %w", err)
 	}
 
+	before := task
+	before.DeletedAt = pgtype.Timestamptz{}
+	if err := s.journal.recordTask(ctx, userID, fmt.Sprintf("delete task %q", task.Description), &before, &task); err != nil {
+		return nil, err
+	}
+
 	return &task, nil
 }
 
 func (s TaskService) SetDue(ctx context.Context, userID int32, taskID int32, date *time.Time) (*sqlc.Task, error) {
+	before, err := s.GetTask(ctx, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	var params sqlc.SetTaskDueParams
 	params.ID = taskID
 	params.UserID = pgtype.Int4{
@@ -308,41 +417,155 @@ func (s TaskService) SetDue(ctx context.Context, userID int32, taskID int32, dat
 		return nil, fmt.Errorf("DueToday: failed to set due_date: %v", err)
 	}
 
+	if err := s.journal.recordTask(ctx, userID, fmt.Sprintf("set due date of %q", task.Description), before, &task); err != nil {
+		return nil, err
+	}
+
 	return &task, nil
 
 }
 
-func (s *TaskService) AddTag(ctx context.Context, userID, taskID int32, tags []string) error {
-	oldTags, err := s.GetTags(ctx, userID, taskID)
+// SetEstimate sets the estimated effort of a task in minutes, nil clears it
+func (s *TaskService) SetEstimate(ctx context.Context, userID, taskID int32, minutes *int32) (*sqlc.Task, error) {
+	before, err := s.GetTask(ctx, taskID, userID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	params := sqlc.SetTaskEstimateParams{
+		ID: taskID,
+		UserID: pgtype.Int4{
+			Int32: userID,
+			Valid: true,
+		},
+	}
+
+	if minutes != nil {
+		params.EstimateMinutes = pgtype.Int4{
+			Int32: *minutes,
+			Valid: true,
+		}
 	}
 
+	task, err := s.queries.SetTaskEstimate(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set task estimate: %w", err)
+	}
+
+	if err := s.journal.recordTask(ctx, userID, fmt.Sprintf("set estimate of %q", task.Description), before, &task); err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+// SnoozeUntil adds a duration to the time a task waits until, or to now when
+// it isn't waiting anymore
+func SnoozeUntil(waitUntil pgtype.Timestamptz, duration time.Duration, now time.Time) time.Time {
+	if waitUntil.Valid && waitUntil.Time.After(now) {
+		return waitUntil.Time.Add(duration)
+	}
+	return now.Add(duration)
+}
+
+// SetWait hides a task from the default lists until a time, nil shows it again
+func (s *TaskService) SetWait(ctx context.Context, userID, taskID int32, until *time.Time) (*sqlc.Task, error) {
+	before, err := s.GetTask(ctx, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := sqlc.SetTaskWaitParams{
+		ID: taskID,
+		UserID: pgtype.Int4{
+			Int32: userID,
+			Valid: true,
+		},
+	}
+
+	if until != nil {
+		params.WaitUntil = pgtype.Timestamptz{
+			Time:  *until,
+			Valid: true,
+		}
+	}
+
+	task, err := s.queries.SetTaskWait(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set task wait: %w", err)
+	}
+
+	if err := s.journal.recordTask(ctx, userID, fmt.Sprintf("set wait of %q", task.Description), before, &task); err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+// SetScheduled sets the day work on a task is planned to begin, nil clears it
+func (s *TaskService) SetScheduled(ctx context.Context, userID, taskID int32, scheduled *time.Time) (*sqlc.Task, error) {
+	before, err := s.GetTask(ctx, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := sqlc.SetTaskScheduledParams{
+		ID: taskID,
+		UserID: pgtype.Int4{
+			Int32: userID,
+			Valid: true,
+		},
+	}
+
+	if scheduled != nil {
+		params.Scheduled = pgtype.Timestamptz{
+			Time:  *scheduled,
+			Valid: true,
+		}
+	}
+
+	task, err := s.queries.SetTaskScheduled(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set task schedule: %w", err)
+	}
+
+	if err := s.journal.recordTask(ctx, userID, fmt.Sprintf("schedule %q", task.Description), before, &task); err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+// AddTag appends the tags the task doesn't have yet in a single statement
+func (s *TaskService) AddTag(ctx context.Context, userID, taskID int32, tags []string) error {
 	cleanedTags := []string{}
 	for _, tag := range tags {
 		cleaned := strings.TrimSpace(tag)
 		if cleaned == "" {
 			continue
 		}
-		cleanedTags = append(cleanedTags, tag)
-
+		cleanedTags = append(cleanedTags, cleaned)
 	}
 
-	tags = append(oldTags, cleanedTags...)
-	params := sqlc.SetTagsParams{
-		ID: taskID,
+	params := sqlc.AddTaskTagsParams{
+		Tags: cleanedTags,
+		ID:   taskID,
 		UserID: pgtype.Int4{
 			Int32: userID,
 			Valid: true,
 		},
-		Tags: tags,
 	}
-	err = s.queries.SetTags(ctx, params)
+	before, err := s.GetTask(ctx, taskID, userID)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	err = s.queries.AddTaskTags(ctx, params)
+	if err != nil {
+		return fmt.Errorf("Failed to add tags: %w", err)
+	}
+
+	return s.recordTags(ctx, userID, "tag task %q", before)
 }
 
 func (s *TaskService) GetTags(ctx context.Context, userID, taskID int32) ([]string, error) {
@@ -370,44 +593,51 @@ func (s *TaskService) ClearTags(ctx context.Context, userID, taskID int32) error
 			Valid: true,
 		},
 	}
-	err := s.queries.ClearTags(ctx, params)
+	before, err := s.GetTask(ctx, taskID, userID)
+	if err != nil {
+		return err
+	}
+
+	err = s.queries.ClearTags(ctx, params)
 	if err != nil {
 		return fmt.Errorf("Failed to clear tags: %w", err)
 	}
-	return nil
+
+	return s.recordTags(ctx, userID, "clear tags of %q", before)
 }
 
+// RemoveTags removes the tags from the task in a single statement
 func (s *TaskService) RemoveTags(ctx context.Context, userID, taskID int32, tags []string) error {
-	removeMap := make(map[string]struct{})
-	for _, s := range tags {
-		removeMap[s] = struct{}{}
+	params := sqlc.RemoveTaskTagsParams{
+		Tags: tags,
+		ID:   taskID,
+		UserID: pgtype.Int4{
+			Int32: userID,
+			Valid: true,
+		},
 	}
 
-	oldTags, err := s.GetTags(ctx, userID, taskID)
+	before, err := s.GetTask(ctx, taskID, userID)
 	if err != nil {
 		return err
 	}
 
-	result := slices.DeleteFunc(oldTags, func(s string) bool {
-		_, found := removeMap[s]
-		return found
-	})
-
-	params := sqlc.SetTagsParams{
-		ID: taskID,
-		UserID: pgtype.Int4{
-			Int32: userID,
-			Valid: true,
-		},
-		Tags: result,
+	err = s.queries.RemoveTaskTags(ctx, params)
+	if err != nil {
+		return fmt.Errorf("Failed to remove tags: %w", err)
 	}
 
-	err = s.queries.SetTags(ctx, params)
+	return s.recordTags(ctx, userID, "untag task %q", before)
+}
+
+// recordTags journals a tag edit, the tag queries don't return the updated task
+func (s *TaskService) recordTags(ctx context.Context, userID int32, description string, before *sqlc.Task) error {
+	after, err := s.GetTask(ctx, before.ID, userID)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return s.journal.recordTask(ctx, userID, fmt.Sprintf(description, before.Description), before, after)
 }
 
 func (s *TaskService) GetToday(ctx context.Context, userID int32) ([]sqlc.Task, error) {
@@ -423,28 +653,35 @@ func (s *TaskService) GetToday(ctx context.Context, userID int32) ([]sqlc.Task,
 }
 
 func (s TaskService) GetDependent(ctx context.Context, userID int32, taskID int32) ([]sqlc.Task, error) {
-	tasks, err := s.ListTasks(ctx, userID, nil, nil, nil, nil, false)
-	if err != nil {
-		return nil, err
-	}
-
 	task, err := s.GetTask(ctx, taskID, userID)
 	if err != nil {
 		return nil, fmt.Errorf("GetDependent: error getting the task: %w", err)
 	}
 
-	result := []sqlc.Task{}
-	for _, t := range tasks {
-		if t.Dependent.Int32 == task.ID {
-			result = append(result, t)
-		}
+	tasks, err := s.queries.ListSubtasks(ctx, sqlc.ListSubtasksParams{
+		UserID: pgtype.Int4{
+			Int32: userID,
+			Valid: true,
+		},
+		Dependent: pgtype.Int4{
+			Int32: task.ID,
+			Valid: true,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed ListSubtasks query: %w", err)
 	}
 
-	return result, nil
+	return tasks, nil
 }
 
 // UpdateTaskRecurrence updates the recurrence pattern for a task
 func (s *TaskService) UpdateTaskRecurrence(ctx context.Context, taskID, userID int32, recurrence string) (*sqlc.Task, error) {
+	before, err := s.GetTask(ctx, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Special case for clearing recurrence (empty string)
 	if recurrence == "" {
 		// Use the dedicated ClearRecurrence query to set recurrence=NULL
@@ -461,11 +698,15 @@ func (s *TaskService) UpdateTaskRecurrence(ctx context.Context, taskID, userID i
 			return nil, fmt.Errorf("failed to clear recurrence: %w", err)
 		}
 
+		if err := s.journal.recordTask(ctx, userID, fmt.Sprintf("clear recurrence of %q", task.Description), before, &task); err != nil {
+			return nil, err
+		}
+
 		return &task, nil
 	}
 
 	// For non-empty recurrence, validate the pattern
-	_, err := ParseRecurrence(recurrence)
+	_, err = ParseRecurrence(recurrence)
 	if err != nil {
 		return nil, fmt.Errorf("invalid recurrence pattern: %w", err)
 	}
@@ -497,5 +738,159 @@ func (s *TaskService) UpdateTaskRecurrence(ctx context.Context, taskID, userID i
 		return nil, fmt.Errorf("failed to update task recurrence: %w", err)
 	}
 
+	if err := s.journal.recordTask(ctx, userID, fmt.Sprintf("set recurrence of %q", task.Description), before, &task); err != nil {
+		return nil, err
+	}
+
 	return &task, nil
 }
+
+// UpdateTask overwrites the editable fields of a task
+func (s *TaskService) UpdateTask(ctx context.Context, userID int32, params sqlc.UpdateTaskParams) (*sqlc.Task, error) {
+	before, err := s.GetTask(ctx, params.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	params.UserID = pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+	task, err := s.queries.UpdateTask(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update task: %w", err)
+	}
+
+	if err := s.journal.recordTask(ctx, userID, fmt.Sprintf("edit task %q", task.Description), before, &task); err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+// SyncTask saves a task edited in another app, like a CalDAV client, creating
+// it when before is nil. Every field is written, so cleared fields are cleared
+// here too. Completing a recurring task creates its next occurrence, like
+// 'prod task done'. Run it in a UnitOfWork
+func (s *TaskService) SyncTask(ctx context.Context, userID int32, before *sqlc.Task, task sqlc.Task) (*sqlc.Task, error) {
+	if before == nil {
+		params := TaskParams{
+			Description: task.Description,
+			Tags:        task.Tags,
+			Dependent:   task.Dependent.Int32,
+			UUID:        task.Uuid,
+		}
+		if task.Priority.Valid {
+			params.Priority = &task.Priority.String
+		}
+		if task.DueDate.Valid {
+			params.DueDate = &task.DueDate.Time
+		}
+		if task.StartDate.Valid {
+			params.StartDate = &task.StartDate.Time
+		}
+		if task.WaitUntil.Valid {
+			params.WaitUntil = &task.WaitUntil.Time
+		}
+		if task.Scheduled.Valid {
+			params.Scheduled = &task.Scheduled.Time
+		}
+		if task.ProjectID.Valid {
+			params.ProjectID = &task.ProjectID.Int32
+		}
+		if task.Notes.Valid {
+			params.Notes = &task.Notes.String
+		}
+		if task.Recurrence.Valid {
+			params.Recurrence = &task.Recurrence.String
+		}
+		if task.EstimateMinutes.Valid {
+			params.EstimateMinutes = &task.EstimateMinutes.Int32
+		}
+
+		created, err := s.CreateTask(ctx, userID, params)
+		if err != nil {
+			return nil, err
+		}
+		if task.Status == created.Status {
+			return created, nil
+		}
+		before = created
+	}
+
+	task.ID = before.ID
+	task.UserID = before.UserID
+	task.Uuid = before.Uuid
+	task.DeletedAt = before.DeletedAt
+	if !task.CreatedAt.Valid {
+		task.CreatedAt = before.CreatedAt
+	}
+	task.UpdatedAt = pgtype.Timestamptz{
+		Time:  time.Now(),
+		Valid: true,
+	}
+
+	// A completion goes through CompleteRecurringTask after the other changes
+	completing := task.Status == "completed" && before.Status != "completed"
+	if completing {
+		task.Status = before.Status
+		task.CompletedAt = before.CompletedAt
+	} else if task.Status != "completed" {
+		task.CompletedAt = pgtype.Timestamptz{}
+	} else if !task.CompletedAt.Valid {
+		task.CompletedAt = before.CompletedAt
+	}
+
+	saved := before
+	if len(diffTask(userID, before, &task)) > 0 {
+		var err error
+		saved, err = s.replaceTask(ctx, userID, fmt.Sprintf("edit task %q", task.Description), before, task)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if completing {
+		completed, _, err := s.CompleteRecurringTask(ctx, saved.ID, userID)
+		if err != nil {
+			return nil, err
+		}
+		saved = completed
+	}
+
+	return saved, nil
+}
+
+// replaceTask writes every field of a task and records the change
+func (s *TaskService) replaceTask(ctx context.Context, userID int32, description string, before *sqlc.Task, task sqlc.Task) (*sqlc.Task, error) {
+	updated, err := s.queries.RestoreTask(ctx, sqlc.RestoreTaskParams{
+		ID:              task.ID,
+		UserID:          task.UserID,
+		Description:     task.Description,
+		Status:          task.Status,
+		Priority:        task.Priority,
+		DueDate:         task.DueDate,
+		StartDate:       task.StartDate,
+		CompletedAt:     task.CompletedAt,
+		ProjectID:       task.ProjectID,
+		Recurrence:      task.Recurrence,
+		Tags:            task.Tags,
+		Notes:           task.Notes,
+		CreatedAt:       task.CreatedAt,
+		UpdatedAt:       task.UpdatedAt,
+		Dependent:       task.Dependent,
+		EstimateMinutes: task.EstimateMinutes,
+		DeletedAt:       task.DeletedAt,
+		Uuid:            task.Uuid,
+		WaitUntil:       task.WaitUntil,
+		Scheduled:       task.Scheduled,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update task %q: %w", task.Description, err)
+	}
+	if err := s.journal.recordTask(ctx, userID, description, before, &updated); err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnoozeUntil(t *testing.T) {
+	now := time.Date(2025, 5, 14, 15, 30, 0, 0, time.UTC)
+
+	// Not waiting, or no longer
+	assert.Equal(t, now.Add(48*time.Hour), SnoozeUntil(pgtype.Timestamptz{}, 48*time.Hour, now))
+	assert.Equal(t, now.Add(time.Hour), SnoozeUntil(pgtype.Timestamptz{
+		Time:  now.Add(-time.Hour),
+		Valid: true,
+	}, time.Hour, now))
+
+	// Still waiting
+	assert.Equal(t, now.Add(26*time.Hour), SnoozeUntil(pgtype.Timestamptz{
+		Time:  now.Add(2 * time.Hour),
+		Valid: true,
+	}, 24*time.Hour, now))
+}
+
+func TestGetDependent(t *testing.T) {
+	_, queries := dbtest.Open(t)
+	user := dbtest.User(t, queries)
+	ctx := context.Background()
+	tasks := NewTaskService(queries)
+
+	parent, err := tasks.CreateTask(ctx, user.ID, TaskParams{Description: "Move house"})
+	require.NoError(t, err)
+	nextWeek := time.Now().AddDate(0, 0, 7)
+	waiting, err := tasks.CreateTask(ctx, user.ID, TaskParams{
+		Description: "Return the keys",
+		Dependent:   parent.ID,
+		WaitUntil:   &nextWeek,
+	})
+	require.NoError(t, err)
+	packing, err := tasks.CreateTask(ctx, user.ID, TaskParams{Description: "Pack boxes", Dependent: parent.ID})
+	require.NoError(t, err)
+	trashed, err := tasks.CreateTask(ctx, user.ID, TaskParams{Description: "Rent a van", Dependent: parent.ID})
+	require.NoError(t, err)
+	_, err = tasks.DeleteTask(ctx, trashed.ID, user.ID)
+	require.NoError(t, err)
+	_, err = tasks.CreateTask(ctx, user.ID, TaskParams{Description: "Water plants"})
+	require.NoError(t, err)
+
+	// Waiting subtasks are changed along with their parent too
+	subtasks, err := tasks.GetDependent(ctx, user.ID, parent.ID)
+	require.NoError(t, err)
+	var ids []int32
+	for _, subtask := range subtasks {
+		ids = append(ids, subtask.ID)
+	}
+	assert.Equal(t, []int32{waiting.ID, packing.ID}, ids)
+}
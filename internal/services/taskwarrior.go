@@ -0,0 +1,661 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+)
+
+// taskwarriorTimeFormat is the compact UTC format Taskwarrior uses for dates
+const taskwarriorTimeFormat = "20060102T150405Z"
+
+// TaskwarriorTask is a task as written by `task export` and read by `task import`.
+// ProdParent is a user defined attribute carrying the subtask hierarchy, which
+// Taskwarrior keeps without knowing about it
+type TaskwarriorTask struct {
+	UUID        string                  `json:"uuid"`
+	Description string                  `json:"description"`
+	Status      string                  `json:"status"`
+	Entry       string                  `json:"entry,omitempty"`
+	Modified    string                  `json:"modified,omitempty"`
+	Start       string                  `json:"start,omitempty"`
+	End         string                  `json:"end,omitempty"`
+	Due         string                  `json:"due,omitempty"`
+	Wait        string                  `json:"wait,omitempty"`
+	Scheduled   string                  `json:"scheduled,omitempty"`
+	Project     string                  `json:"project,omitempty"`
+	Priority    string                  `json:"priority,omitempty"`
+	Tags        []string                `json:"tags,omitempty"`
+	Annotations []TaskwarriorAnnotation `json:"annotations,omitempty"`
+	Depends     taskwarriorDepends      `json:"depends,omitempty"`
+	Recur       string                  `json:"recur,omitempty"`
+	Parent      string                  `json:"parent,omitempty"`
+	ProdParent  string                  `json:"prod_parent,omitempty"`
+}
+
+// TaskwarriorAnnotation is a timestamped note on a Taskwarrior task
+type TaskwarriorAnnotation struct {
+	Entry       string `json:"entry"`
+	Description string `json:"description"`
+}
+
+// taskwarriorDepends reads depends both as the array of Taskwarrior 2.6 and
+// later and as the comma separated string of older versions
+type taskwarriorDepends []string
+
+func (d *taskwarriorDepends) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*d = list
+		return nil
+	}
+
+	var joined string
+	if err := json.Unmarshal(data, &joined); err != nil {
+		return fmt.Errorf("depends must be a list or a comma separated string")
+	}
+	*d = nil
+	for _, uuid := range strings.Split(joined, ",") {
+		if uuid = strings.TrimSpace(uuid); uuid != "" {
+			*d = append(*d, uuid)
+		}
+	}
+	return nil
+}
+
+// ReadTaskwarrior decodes the output of `task export`, either a JSON array or
+// one JSON object per line
+func ReadTaskwarrior(r io.Reader) ([]TaskwarriorTask, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read taskwarrior export: %w", err)
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var tasks []TaskwarriorTask
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &tasks); err != nil {
+			return nil, fmt.Errorf("failed to decode taskwarrior export: %w", err)
+		}
+		return tasks, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for decoder.More() {
+		var task TaskwarriorTask
+		if err := decoder.Decode(&task); err != nil {
+			return nil, fmt.Errorf("failed to decode taskwarrior export: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// WriteTaskwarrior encodes tasks as a JSON array accepted by `task import`
+func WriteTaskwarrior(w io.Writer, tasks []TaskwarriorTask) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(tasks); err != nil {
+		return fmt.Errorf("failed to write taskwarrior export: %w", err)
+	}
+
+	return nil
+}
+
+// TaskwarriorService converts tasks between prod and Taskwarrior. Tasks are
+// matched on their UUID so both tools can be used side by side
+type TaskwarriorService struct {
+	queries *sqlc.Queries
+	journal *journal
+}
+
+// NewTaskwarriorService creates a new TaskwarriorService
+func NewTaskwarriorService(queries *sqlc.Queries) *TaskwarriorService {
+	return &TaskwarriorService{
+		queries: queries,
+		journal: newJournal(queries),
+	}
+}
+
+// Export converts the tasks of a user, except those in the trash, to Taskwarrior tasks
+func (s *TaskwarriorService) Export(ctx context.Context, userID int32) ([]TaskwarriorTask, error) {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+
+	tasks, err := s.queries.ListTasks(ctx, sqlc.ListTasksParams{
+		UserID: user,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	projects, err := s.queries.ListProjects(ctx, user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	projectNames := make(map[int32]string, len(projects))
+	for _, project := range projects {
+		projectNames[project.ID] = project.Name
+	}
+
+	uuids := make(map[int32]string, len(tasks))
+	for _, task := range tasks {
+		uuids[task.ID] = FormatUUID(task.Uuid)
+	}
+
+	dependencies, err := s.queries.ListUserTaskDependencies(ctx, user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task dependencies: %w", err)
+	}
+	depends := make(map[int32][]string)
+	for _, dependency := range dependencies {
+		if uuid, ok := uuids[dependency.DependsOnID.Int32]; ok {
+			depends[dependency.TaskID.Int32] = append(depends[dependency.TaskID.Int32], uuid)
+		}
+	}
+
+	exported := make([]TaskwarriorTask, 0, len(tasks))
+	for _, task := range tasks {
+		exported = append(exported, exportTaskwarrior(task, projectNames[task.ProjectID.Int32], uuids[task.Dependent.Int32], depends[task.ID]))
+	}
+
+	return exported, nil
+}
+
+// exportTaskwarrior converts a prod task to a Taskwarrior task. parent is the
+// UUID of its parent task and depends the UUIDs of the tasks it depends on
+func exportTaskwarrior(task sqlc.Task, project, parent string, depends []string) TaskwarriorTask {
+	tw := TaskwarriorTask{
+		UUID:        FormatUUID(task.Uuid),
+		Description: task.Description,
+		Status:      "pending",
+		Entry:       taskwarriorTime(task.CreatedAt),
+		Modified:    taskwarriorTime(task.UpdatedAt),
+		Due:         taskwarriorTime(task.DueDate),
+		Project:     project,
+		Priority:    task.Priority.String,
+		Tags:        task.Tags,
+		Depends:     depends,
+		ProdParent:  parent,
+	}
+
+	switch task.Status {
+	case "completed":
+		tw.Status = "completed"
+		tw.End = taskwarriorTime(task.CompletedAt)
+		if tw.End == "" {
+			tw.End = tw.Modified
+		}
+		tw.Start = taskwarriorTime(task.StartDate)
+	case "active":
+		tw.Start = taskwarriorTime(task.StartDate)
+	}
+	tw.Wait = taskwarriorTime(task.WaitUntil)
+	tw.Scheduled = taskwarriorTime(task.Scheduled)
+
+	// Taskwarrior only recurs tasks with a due date, from a template
+	if task.Recurrence.Valid && task.DueDate.Valid && tw.Status == "pending" {
+		if recur, ok := taskwarriorRecur(task.Recurrence.String); ok {
+			tw.Status = "recurring"
+			tw.Recur = recur
+		}
+	}
+
+	if task.Notes.Valid {
+		for _, line := range strings.Split(task.Notes.String, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				tw.Annotations = append(tw.Annotations, TaskwarriorAnnotation{
+					Entry:       tw.Modified,
+					Description: line,
+				})
+			}
+		}
+	}
+
+	return tw
+}
+
+// Import creates or updates prod tasks from Taskwarrior tasks. Tasks already
+// imported are updated in place, tasks deleted in Taskwarrior are moved to the
+// trash, and projects are created by name. Pending instances of recurring
+// tasks are skipped since prod creates the next instance itself from the
+// imported template. The import can be undone as one operation. Run it in a
+// UnitOfWork
+func (s *TaskwarriorService) Import(ctx context.Context, userID int32, tasks []TaskwarriorTask) (*ImportReport, error) {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+	report := &ImportReport{}
+	const description = "import taskwarrior tasks"
+
+	projectIDs := map[string]int32{}
+	taskIDs := map[string]int32{}
+	instances := 0
+
+	for _, tw := range tasks {
+		if tw.Parent != "" && tw.Status != "completed" && tw.Status != "deleted" {
+			instances++
+			report.TasksSkipped++
+			continue
+		}
+
+		uuid, err := ParseUUID(tw.UUID)
+		if err != nil {
+			report.conflict("task %q has an invalid uuid and was skipped", tw.Description)
+			report.TasksSkipped++
+			continue
+		}
+
+		existing, err := s.queries.GetTaskByUUID(ctx, sqlc.GetTaskByUUIDParams{
+			UserID: user,
+			Uuid:   uuid,
+		})
+		found := err == nil
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("failed to look up task %q: %w", tw.Description, err)
+		}
+
+		switch {
+		case found && existing.DeletedAt.Valid:
+			if tw.Status != "deleted" {
+				report.conflict("task %q is in the trash and was not updated, restore it to sync it again", tw.Description)
+			}
+			report.TasksSkipped++
+			continue
+
+		case tw.Status == "deleted":
+			if found {
+				trashed, err := s.queries.DeleteTask(ctx, sqlc.DeleteTaskParams{
+					ID:     existing.ID,
+					UserID: user,
+				})
+				if err != nil {
+					return nil, fmt.Errorf("failed to delete task %q: %w", tw.Description, err)
+				}
+				if err := s.journal.recordTask(ctx, userID, description, &existing, &trashed); err != nil {
+					return nil, err
+				}
+				report.TasksTrashed++
+			} else {
+				report.TasksSkipped++
+			}
+			continue
+		}
+
+		task := existing
+		if !found {
+			task = sqlc.Task{
+				UserID: user,
+				Uuid:   uuid,
+			}
+		}
+		projectID, err := s.projectID(ctx, userID, tw.Project, projectIDs, report)
+		if err != nil {
+			return nil, err
+		}
+		applyTaskwarrior(&task, tw, projectID, report)
+
+		if found {
+			if len(diffTask(userID, &existing, &task)) == 0 {
+				report.TasksSkipped++
+				taskIDs[tw.UUID] = existing.ID
+				continue
+			}
+			tasks := &TaskService{queries: s.queries, journal: s.journal}
+			updated, err := tasks.replaceTask(ctx, userID, description, &existing, task)
+			if err != nil {
+				return nil, err
+			}
+			taskIDs[tw.UUID] = updated.ID
+			report.TasksUpdated++
+			continue
+		}
+
+		created, err := s.queries.ImportTask(ctx, sqlc.ImportTaskParams{
+			UserID:          user,
+			Description:     task.Description,
+			Status:          task.Status,
+			Priority:        task.Priority,
+			DueDate:         task.DueDate,
+			StartDate:       task.StartDate,
+			CompletedAt:     task.CompletedAt,
+			ProjectID:       task.ProjectID,
+			Recurrence:      task.Recurrence,
+			Tags:            task.Tags,
+			Notes:           task.Notes,
+			CreatedAt:       task.CreatedAt,
+			UpdatedAt:       task.UpdatedAt,
+			Dependent:       task.Dependent,
+			EstimateMinutes: task.EstimateMinutes,
+			Uuid:            task.Uuid,
+			WaitUntil:       task.WaitUntil,
+			Scheduled:       task.Scheduled,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to import task %q: %w", tw.Description, err)
+		}
+		if err := s.journal.recordTask(ctx, userID, description, nil, &created); err != nil {
+			return nil, err
+		}
+		taskIDs[tw.UUID] = created.ID
+		report.TasksCreated++
+	}
+
+	if instances > 0 {
+		report.conflict("%d pending instance(s) of recurring tasks were skipped, prod creates them from the template", instances)
+	}
+
+	// Parents and dependencies may point at any task of the export, so they
+	// are linked once every task exists
+	if err := s.linkTasks(ctx, userID, description, tasks, taskIDs, report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// linkTasks sets the parents and adds the dependencies of imported tasks
+func (s *TaskwarriorService) linkTasks(ctx context.Context, userID int32, description string, tasks []TaskwarriorTask, taskIDs map[string]int32, report *ImportReport) error {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+
+	dependencies, err := s.queries.ListUserTaskDependencies(ctx, user)
+	if err != nil {
+		return fmt.Errorf("failed to list task dependencies: %w", err)
+	}
+	existing := make(map[[2]int32]bool, len(dependencies))
+	for _, dependency := range dependencies {
+		existing[[2]int32{dependency.TaskID.Int32, dependency.DependsOnID.Int32}] = true
+	}
+
+	for _, tw := range tasks {
+		id, ok := taskIDs[tw.UUID]
+		if !ok {
+			continue
+		}
+
+		var parentID pgtype.Int4
+		if tw.ProdParent != "" {
+			if parent, ok := taskIDs[tw.ProdParent]; ok && parent != id {
+				parentID = pgtype.Int4{
+					Int32: parent,
+					Valid: true,
+				}
+			} else {
+				report.conflict("parent of task %q is not in the export, it was left without a parent", tw.Description)
+			}
+		}
+
+		task, err := s.queries.GetTask(ctx, sqlc.GetTaskParams{
+			ID:     id,
+			UserID: user,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get task %q: %w", tw.Description, err)
+		}
+		if task.Dependent != parentID {
+			updated := task
+			updated.Dependent = parentID
+			tasks := &TaskService{queries: s.queries, journal: s.journal}
+			if _, err := tasks.replaceTask(ctx, userID, description, &task, updated); err != nil {
+				return err
+			}
+		}
+
+		for _, uuid := range tw.Depends {
+			dependsOnID, ok := taskIDs[uuid]
+			if !ok {
+				report.conflict("task %q depends on %s, which is not in the export", tw.Description, uuid)
+				continue
+			}
+			if existing[[2]int32{id, dependsOnID}] || dependsOnID == id {
+				continue
+			}
+
+			err := s.queries.AddTaskDependency(ctx, sqlc.AddTaskDependencyParams{
+				TaskID: pgtype.Int4{
+					Int32: id,
+					Valid: true,
+				},
+				DependsOnID: pgtype.Int4{
+					Int32: dependsOnID,
+					Valid: true,
+				},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to add dependency of task %q: %w", tw.Description, err)
+			}
+			existing[[2]int32{id, dependsOnID}] = true
+			report.Dependencies++
+		}
+	}
+
+	return nil
+}
+
+// projectID finds the project with the given name, creating it when needed
+func (s *TaskwarriorService) projectID(ctx context.Context, userID int32, name string, projectIDs map[string]int32, report *ImportReport) (pgtype.Int4, error) {
+	if name == "" {
+		return pgtype.Int4{}, nil
+	}
+
+	id, ok := projectIDs[name]
+	if !ok {
+		projects := &ProjectService{queries: s.queries, journal: s.journal}
+		project, created, err := projects.findOrCreateProject(ctx, userID, name, "import taskwarrior tasks")
+		if err != nil {
+			return pgtype.Int4{}, err
+		}
+		if created {
+			report.ProjectsCreated++
+		}
+		id = project.ID
+		projectIDs[name] = id
+	}
+
+	return pgtype.Int4{
+		Int32: id,
+		Valid: true,
+	}, nil
+}
+
+// applyTaskwarrior copies the fields of a Taskwarrior task onto a prod task
+func applyTaskwarrior(task *sqlc.Task, tw TaskwarriorTask, projectID pgtype.Int4, report *ImportReport) {
+	task.Description = tw.Description
+	task.ProjectID = projectID
+	task.Tags = tw.Tags
+	task.DueDate = parseTaskwarriorTime(tw.Due)
+	task.WaitUntil = parseTaskwarriorTime(tw.Wait)
+	task.Scheduled = parseTaskwarriorTime(tw.Scheduled)
+	task.CompletedAt = pgtype.Timestamptz{}
+
+	switch {
+	case tw.Status == "completed":
+		task.Status = "completed"
+		task.CompletedAt = parseTaskwarriorTime(tw.End)
+		task.StartDate = parseTaskwarriorTime(tw.Start)
+	case tw.Start != "":
+		task.Status = "active"
+		task.StartDate = parseTaskwarriorTime(tw.Start)
+	default:
+		task.Status = "pending"
+		task.StartDate = pgtype.Timestamptz{}
+	}
+
+	task.Priority = pgtype.Text{}
+	if priority := strings.ToUpper(tw.Priority); priority == "H" || priority == "M" || priority == "L" {
+		task.Priority = pgtype.Text{
+			String: priority,
+			Valid:  true,
+		}
+	}
+
+	task.Recurrence = pgtype.Text{}
+	if tw.Recur != "" && tw.Status != "completed" {
+		if recurrence, ok := prodRecurrence(tw.Recur); ok {
+			task.Recurrence = pgtype.Text{
+				String: recurrence,
+				Valid:  true,
+			}
+		} else {
+			report.conflict("recurrence %q of task %q is not supported and was dropped", tw.Recur, tw.Description)
+		}
+	}
+
+	var notes []string
+	for _, annotation := range tw.Annotations {
+		notes = append(notes, annotation.Description)
+	}
+	task.Notes = pgtype.Text{
+		String: strings.Join(notes, "\n"),
+		Valid:  len(notes) > 0,
+	}
+
+	if entry := parseTaskwarriorTime(tw.Entry); entry.Valid {
+		task.CreatedAt = entry
+	} else if !task.CreatedAt.Valid {
+		task.CreatedAt = pgtype.Timestamptz{
+			Time:  time.Now(),
+			Valid: true,
+		}
+	}
+	task.UpdatedAt = parseTaskwarriorTime(tw.Modified)
+	if !task.UpdatedAt.Valid {
+		task.UpdatedAt = task.CreatedAt
+	}
+}
+
+func taskwarriorTime(t pgtype.Timestamptz) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.UTC().Format(taskwarriorTimeFormat)
+}
+
+func parseTaskwarriorTime(s string) pgtype.Timestamptz {
+	if s == "" {
+		return pgtype.Timestamptz{}
+	}
+	for _, layout := range []string{taskwarriorTimeFormat, time.RFC3339} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return pgtype.Timestamptz{
+				Time:  t,
+				Valid: true,
+			}
+		}
+	}
+	return pgtype.Timestamptz{}
+}
+
+var taskwarriorDuration = regexp.MustCompile(`^(\d+)\s*([a-z]+)$`)
+
+// prodRecurrence converts a Taskwarrior recurrence like "weekly" or "2w" to
+// the prod recurrence format
+func prodRecurrence(recur string) (string, bool) {
+	recur = strings.ToLower(strings.TrimSpace(recur))
+
+	switch recur {
+	case "daily", "day":
+		return "daily", true
+	case "weekly", "week":
+		return "weekly", true
+	case "weekdays":
+		return "weekly:1:1,2,3,4,5", true
+	case "biweekly", "fortnight":
+		return "weekly:2", true
+	case "monthly", "month":
+		return "monthly", true
+	case "bimonthly":
+		return "monthly:2", true
+	case "quarterly":
+		return "monthly:3", true
+	case "semiannual":
+		return "monthly:6", true
+	case "yearly", "annual", "annually", "year":
+		return "yearly", true
+	case "biannual", "biyearly":
+		return "yearly:2", true
+	}
+
+	match := taskwarriorDuration.FindStringSubmatch(recur)
+	if match == nil {
+		return "", false
+	}
+	n, err := strconv.Atoi(match[1])
+	if err != nil || n < 1 {
+		return "", false
+	}
+
+	switch match[2] {
+	case "d", "day", "days":
+		return fmt.Sprintf("daily:%d", n), true
+	case "w", "wk", "wks", "week", "weeks":
+		return fmt.Sprintf("weekly:%d", n), true
+	case "mo", "mos", "mth", "mths", "month", "months":
+		return fmt.Sprintf("monthly:%d", n), true
+	case "q", "qtr", "qtrs", "quarter", "quarters":
+		return fmt.Sprintf("monthly:%d", 3*n), true
+	case "y", "yr", "yrs", "year", "years":
+		return fmt.Sprintf("yearly:%d", n), true
+	}
+	return "", false
+}
+
+// taskwarriorRecur converts a prod recurrence to Taskwarrior. Taskwarrior
+// can't express specific weekdays or days of the month, so only the
+// frequency and interval are kept
+func taskwarriorRecur(recurrence string) (string, bool) {
+	pattern, err := ParseRecurrence(recurrence)
+	if err != nil {
+		return "", false
+	}
+
+	n := pattern.Interval
+	switch pattern.Type {
+	case RecurrenceDaily:
+		if n == 1 {
+			return "daily", true
+		}
+		return fmt.Sprintf("%ddays", n), true
+	case RecurrenceWeekly:
+		if n == 1 && slices.Equal(pattern.WeekDays, []int{1, 2, 3, 4, 5}) {
+			return "weekdays", true
+		}
+		if n == 1 {
+			return "weekly", true
+		}
+		return fmt.Sprintf("%dweeks", n), true
+	case RecurrenceMonthly:
+		if n == 1 {
+			return "monthly", true
+		}
+		return fmt.Sprintf("%dmonths", n), true
+	case RecurrenceYearly:
+		if n == 1 {
+			return "yearly", true
+		}
+		return fmt.Sprintf("%dyears", n), true
+	}
+	return "", false
+}
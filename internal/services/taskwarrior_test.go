@@ -0,0 +1,356 @@
+package services
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadTaskwarrior(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []TaskwarriorTask
+		err   bool
+	}{
+		{
+			name:  "empty export",
+			input: " \n",
+		},
+		{
+			name:  "array",
+			input: `[{"uuid":"a","description":"One","status":"pending"},{"uuid":"b","description":"Two","status":"completed"}]`,
+			want: []TaskwarriorTask{
+				{UUID: "a", Description: "One", Status: "pending"},
+				{UUID: "b", Description: "Two", Status: "completed"},
+			},
+		},
+		{
+			name:  "one object per line",
+			input: "{\"uuid\":\"a\",\"description\":\"One\",\"status\":\"pending\"}\n{\"uuid\":\"b\",\"description\":\"Two\",\"status\":\"completed\"}\n",
+			want: []TaskwarriorTask{
+				{UUID: "a", Description: "One", Status: "pending"},
+				{UUID: "b", Description: "Two", Status: "completed"},
+			},
+		},
+		{
+			name:  "depends as an array",
+			input: `[{"uuid":"a","depends":["b","c"]}]`,
+			want:  []TaskwarriorTask{{UUID: "a", Depends: taskwarriorDepends{"b", "c"}}},
+		},
+		{
+			name:  "depends as a comma separated string",
+			input: `{"uuid":"a","depends":"b, c,"}`,
+			want:  []TaskwarriorTask{{UUID: "a", Depends: taskwarriorDepends{"b", "c"}}},
+		},
+		{
+			name:  "depends of another type",
+			input: `[{"uuid":"a","depends":1}]`,
+			err:   true,
+		},
+		{
+			name:  "invalid line",
+			input: "{\"uuid\":\"a\"}\n{\"uuid\":",
+			err:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := ReadTaskwarrior(strings.NewReader(tt.input))
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, tasks)
+		})
+	}
+}
+
+func TestProdRecurrence(t *testing.T) {
+	tests := []struct {
+		recur string
+		want  string
+		ok    bool
+	}{
+		{"daily", "daily", true},
+		{"Weekly", "weekly", true},
+		{"weekdays", "weekly:1:1,2,3,4,5", true},
+		{"biweekly", "weekly:2", true},
+		{"quarterly", "monthly:3", true},
+		{"annual", "yearly", true},
+		{"3d", "daily:3", true},
+		{"2 weeks", "weekly:2", true},
+		{"6mo", "monthly:6", true},
+		{"2q", "monthly:6", true},
+		{"1y", "yearly:1", true},
+		{"0d", "", false},
+		{"2h", "", false},
+		{"P1W", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.recur, func(t *testing.T) {
+			recurrence, ok := prodRecurrence(tt.recur)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, recurrence)
+		})
+	}
+}
+
+func TestTaskwarriorRecur(t *testing.T) {
+	tests := []struct {
+		recurrence string
+		want       string
+		ok         bool
+	}{
+		{"daily", "daily", true},
+		{"daily:3", "3days", true},
+		{"weekly", "weekly", true},
+		{"weekly:1:1,2,3,4,5", "weekdays", true},
+		// Specific weekdays are dropped
+		{"weekly:1:1,3", "weekly", true},
+		{"weekly:2", "2weeks", true},
+		{"monthly:1:last", "monthly", true},
+		{"monthly:3", "3months", true},
+		{"yearly:1:0101", "yearly", true},
+		{"yearly:2", "2years", true},
+		{"whenever", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.recurrence, func(t *testing.T) {
+			recur, ok := taskwarriorRecur(tt.recurrence)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, recur)
+
+			if ok {
+				back, ok := prodRecurrence(recur)
+				assert.True(t, ok, "prod reads the recurrence it writes")
+				again, _ := taskwarriorRecur(back)
+				assert.Equal(t, recur, again)
+			}
+		})
+	}
+}
+
+func TestParseTaskwarriorTime(t *testing.T) {
+	tests := []struct {
+		input string
+		want  pgtype.Timestamptz
+	}{
+		{"20250601T083000Z", pgtype.Timestamptz{Time: time.Date(2025, 6, 1, 8, 30, 0, 0, time.UTC), Valid: true}},
+		{"2025-06-01T10:30:00+02:00", pgtype.Timestamptz{Time: time.Date(2025, 6, 1, 8, 30, 0, 0, time.UTC), Valid: true}},
+		{"", pgtype.Timestamptz{}},
+		{"2025-06-01", pgtype.Timestamptz{}},
+		{"tomorrow", pgtype.Timestamptz{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			parsed := parseTaskwarriorTime(tt.input)
+			assert.Equal(t, tt.want.Valid, parsed.Valid)
+			assert.True(t, tt.want.Time.Equal(parsed.Time), "got %v", parsed.Time)
+		})
+	}
+}
+
+func TestApplyTaskwarrior(t *testing.T) {
+	created := pgtype.Timestamptz{
+		Time:  time.Date(2025, 5, 1, 8, 0, 0, 0, time.UTC),
+		Valid: true,
+	}
+
+	tests := []struct {
+		name      string
+		tw        TaskwarriorTask
+		status    string
+		priority  pgtype.Text
+		started   bool
+		completed bool
+		recurs    string
+		conflicts int
+	}{
+		{
+			name:   "pending",
+			tw:     TaskwarriorTask{Status: "pending", Priority: "H"},
+			status: "pending",
+			priority: pgtype.Text{
+				String: "H",
+				Valid:  true,
+			},
+		},
+		{
+			name:    "started",
+			tw:      TaskwarriorTask{Status: "pending", Start: "20250601T083000Z", Priority: "m"},
+			status:  "active",
+			started: true,
+			priority: pgtype.Text{
+				String: "M",
+				Valid:  true,
+			},
+		},
+		{
+			name:   "waiting",
+			tw:     TaskwarriorTask{Status: "waiting", Wait: "20250610T000000Z"},
+			status: "pending",
+		},
+		{
+			name:      "completed",
+			tw:        TaskwarriorTask{Status: "completed", Start: "20250601T083000Z", End: "20250601T093000Z", Recur: "weekly"},
+			status:    "completed",
+			started:   true,
+			completed: true,
+		},
+		{
+			name:   "recurring template",
+			tw:     TaskwarriorTask{Status: "recurring", Due: "20250601T083000Z", Recur: "2w"},
+			status: "pending",
+			recurs: "weekly:2",
+		},
+		{
+			name:      "unsupported recurrence",
+			tw:        TaskwarriorTask{Status: "recurring", Due: "20250601T083000Z", Recur: "P1W"},
+			status:    "pending",
+			conflicts: 1,
+		},
+		{
+			name:   "unknown priority",
+			tw:     TaskwarriorTask{Status: "pending", Priority: "X"},
+			status: "pending",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The task was active, completed and recurring before
+			task := sqlc.Task{
+				Status:      "active",
+				Priority:    pgtype.Text{String: "L", Valid: true},
+				StartDate:   created,
+				CompletedAt: created,
+				Recurrence:  pgtype.Text{String: "daily", Valid: true},
+				CreatedAt:   created,
+			}
+			report := &ImportReport{}
+			applyTaskwarrior(&task, tt.tw, pgtype.Int4{}, report)
+
+			assert.Equal(t, tt.status, task.Status)
+			assert.Equal(t, tt.priority, task.Priority)
+			assert.Equal(t, tt.started, task.StartDate.Valid, "start date")
+			assert.Equal(t, tt.completed, task.CompletedAt.Valid, "completed at")
+			assert.Equal(t, tt.recurs, task.Recurrence.String)
+			assert.Equal(t, tt.recurs != "", task.Recurrence.Valid)
+			assert.Len(t, report.Conflicts, tt.conflicts)
+
+			// Without entry and modified the creation is kept
+			assert.Equal(t, created, task.CreatedAt)
+			assert.Equal(t, created, task.UpdatedAt)
+		})
+	}
+}
+
+func TestTaskwarriorRoundTrip(t *testing.T) {
+	at := func(day, hour int) pgtype.Timestamptz {
+		return pgtype.Timestamptz{
+			Time:  time.Date(2025, 6, day, hour, 0, 0, 0, time.UTC),
+			Valid: true,
+		}
+	}
+	uuid := func(s string) pgtype.UUID {
+		id, err := ParseUUID(s)
+		require.NoError(t, err)
+		return id
+	}
+
+	tests := []struct {
+		name string
+		task sqlc.Task
+	}{
+		{
+			name: "pending",
+			task: sqlc.Task{
+				Uuid:        uuid("0195f3a2-7c1e-7d4b-9a7e-3c2f1b0a9d8e"),
+				Description: "Call mom",
+				Status:      "pending",
+				Priority:    pgtype.Text{String: "H", Valid: true},
+				DueDate:     at(5, 12),
+				WaitUntil:   at(3, 0),
+				Scheduled:   at(4, 9),
+				Tags:        []string{"family", "phone"},
+				Notes:       pgtype.Text{String: "Ask about the trip\nBring up the garden", Valid: true},
+				CreatedAt:   at(1, 8),
+				UpdatedAt:   at(2, 8),
+			},
+		},
+		{
+			name: "active",
+			task: sqlc.Task{
+				Uuid:        uuid("0195f3a2-7c1e-7d4b-9a7e-3c2f1b0a9d8f"),
+				Description: "Write report",
+				Status:      "active",
+				StartDate:   at(2, 9),
+				CreatedAt:   at(1, 8),
+				UpdatedAt:   at(2, 9),
+			},
+		},
+		{
+			name: "completed",
+			task: sqlc.Task{
+				Uuid:        uuid("0195f3a2-7c1e-7d4b-9a7e-3c2f1b0a9d90"),
+				Description: "Buy milk",
+				Status:      "completed",
+				Priority:    pgtype.Text{String: "L", Valid: true},
+				StartDate:   at(2, 9),
+				CompletedAt: at(2, 10),
+				CreatedAt:   at(1, 8),
+				UpdatedAt:   at(2, 10),
+			},
+		},
+		{
+			name: "recurring",
+			task: sqlc.Task{
+				Uuid:        uuid("0195f3a2-7c1e-7d4b-9a7e-3c2f1b0a9d91"),
+				Description: "Water plants",
+				Status:      "pending",
+				DueDate:     at(6, 18),
+				Recurrence:  pgtype.Text{String: "weekly:2", Valid: true},
+				CreatedAt:   at(1, 8),
+				UpdatedAt:   at(1, 8),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, WriteTaskwarrior(&buf, []TaskwarriorTask{exportTaskwarrior(tt.task, "", "", nil)}))
+			tasks, err := ReadTaskwarrior(&buf)
+			require.NoError(t, err)
+			require.Len(t, tasks, 1)
+			assert.Equal(t, FormatUUID(tt.task.Uuid), tasks[0].UUID)
+
+			imported := sqlc.Task{Uuid: tt.task.Uuid}
+			report := &ImportReport{}
+			applyTaskwarrior(&imported, tasks[0], pgtype.Int4{}, report)
+			assert.Empty(t, report.Conflicts)
+			assert.Empty(t, diffTask(1, &tt.task, &imported))
+			for _, times := range [][2]pgtype.Timestamptz{
+				{tt.task.CompletedAt, imported.CompletedAt},
+				{tt.task.CreatedAt, imported.CreatedAt},
+				{tt.task.UpdatedAt, imported.UpdatedAt},
+			} {
+				assert.Equal(t, times[0].Valid, times[1].Valid)
+				assert.True(t, times[0].Time.Equal(times[1].Time), "%v is %v after the round trip", times[0].Time, times[1].Time)
+			}
+		})
+	}
+}
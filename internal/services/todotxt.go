@@ -0,0 +1,856 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+)
+
+const todoDateFormat = "2006-01-02"
+
+// TodoItem is one task line of a todo.txt file, like
+// `(A) 2025-04-01 Call mom +Family @phone due:2025-04-05`. The prod: tag holds
+// the UUID of the matching prod task so a file can be synced repeatedly
+type TodoItem struct {
+	Completed     bool
+	Priority      string
+	CompletedDate time.Time
+	CreatedDate   time.Time
+	Text          string
+	Projects      []string
+	Contexts      []string
+	Due           time.Time
+	Threshold     time.Time
+	Recurrence    string
+	ID            string
+}
+
+var todoPriority = regexp.MustCompile(`^\([A-Z]\)$`)
+
+// ParseTodoItem parses a todo.txt line. It returns false for blank lines
+func ParseTodoItem(line string) (TodoItem, bool) {
+	var item TodoItem
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return item, false
+	}
+
+	if fields[0] == "x" {
+		item.Completed = true
+		fields = fields[1:]
+		if date, ok := parseTodoDate(fields); ok {
+			item.CompletedDate = date
+			fields = fields[1:]
+		}
+	} else if todoPriority.MatchString(fields[0]) {
+		item.Priority = fields[0][1:2]
+		fields = fields[1:]
+	}
+	if date, ok := parseTodoDate(fields); ok {
+		item.CreatedDate = date
+		fields = fields[1:]
+	}
+
+	var words []string
+	for _, field := range fields {
+		switch {
+		case len(field) > 1 && field[0] == '+':
+			item.Projects = append(item.Projects, field[1:])
+			continue
+		case len(field) > 1 && field[0] == '@':
+			item.Contexts = append(item.Contexts, field[1:])
+			continue
+		}
+
+		key, value, found := strings.Cut(field, ":")
+		if found && value != "" {
+			switch key {
+			case "due":
+				if date, err := time.ParseInLocation(todoDateFormat, value, time.Local); err == nil {
+					item.Due = date
+					continue
+				}
+			case "t":
+				if date, err := time.ParseInLocation(todoDateFormat, value, time.Local); err == nil {
+					item.Threshold = date
+					continue
+				}
+			case "rec":
+				item.Recurrence = value
+				continue
+			case "pri":
+				if len(value) == 1 && value[0] >= 'A' && value[0] <= 'Z' {
+					item.Priority = value
+					continue
+				}
+			case "prod":
+				item.ID = value
+				continue
+			}
+		}
+		words = append(words, field)
+	}
+	item.Text = strings.Join(words, " ")
+
+	return item, true
+}
+
+func parseTodoDate(fields []string) (time.Time, bool) {
+	if len(fields) == 0 {
+		return time.Time{}, false
+	}
+	date, err := time.ParseInLocation(todoDateFormat, fields[0], time.Local)
+	return date, err == nil
+}
+
+// String formats the item as a todo.txt line. Completed items keep their
+// priority in a pri: tag as the format recommends
+func (item TodoItem) String() string {
+	var parts []string
+	if item.Completed {
+		parts = append(parts, "x")
+		if !item.CompletedDate.IsZero() {
+			parts = append(parts, item.CompletedDate.Format(todoDateFormat))
+		}
+	} else if item.Priority != "" {
+		parts = append(parts, "("+item.Priority+")")
+	}
+	// A creation date on a completed item needs the completion date before it
+	if !item.CreatedDate.IsZero() && (!item.Completed || !item.CompletedDate.IsZero()) {
+		parts = append(parts, item.CreatedDate.Format(todoDateFormat))
+	}
+
+	if item.Text != "" {
+		parts = append(parts, item.Text)
+	}
+	for _, project := range item.Projects {
+		parts = append(parts, "+"+todoWord(project))
+	}
+	for _, context := range item.Contexts {
+		parts = append(parts, "@"+todoWord(context))
+	}
+	if !item.Due.IsZero() {
+		parts = append(parts, "due:"+item.Due.Format(todoDateFormat))
+	}
+	if !item.Threshold.IsZero() {
+		parts = append(parts, "t:"+item.Threshold.Format(todoDateFormat))
+	}
+	if item.Recurrence != "" {
+		parts = append(parts, "rec:"+item.Recurrence)
+	}
+	if item.Completed && item.Priority != "" {
+		parts = append(parts, "pri:"+item.Priority)
+	}
+	if item.ID != "" {
+		parts = append(parts, "prod:"+item.ID)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// todoWord replaces the spaces a todo.txt project or context can't contain
+func todoWord(s string) string {
+	return strings.ReplaceAll(strings.TrimSpace(s), " ", "_")
+}
+
+// TodoSyncState remembers the lines written by the last sync of a file, so
+// the next sync can tell which side changed a task
+type TodoSyncState struct {
+	SyncedAt time.Time         `json:"synced_at"`
+	Lines    map[string]string `json:"lines"`
+}
+
+// TodoSyncReport sums up the changes made by a sync on both sides
+type TodoSyncReport struct {
+	TasksCreated int
+	TasksUpdated int
+	TasksTrashed int
+	LinesAdded   int
+	LinesUpdated int
+	LinesRemoved int
+	Conflicts    []string
+}
+
+func todoSyncStatePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	return filepath.Join(homeDir, ".prod", "todotxt_sync.json"), nil
+}
+
+func loadTodoSyncStates() (map[string]*TodoSyncState, error) {
+	filePath, err := todoSyncStatePath()
+	if err != nil {
+		return nil, err
+	}
+
+	states := map[string]*TodoSyncState{}
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return states, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read todo.txt sync state: %w", err)
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("failed to decode todo.txt sync state: %w", err)
+	}
+
+	return states, nil
+}
+
+// LoadTodoSyncState returns the state of the last sync of a todo.txt file,
+// or an empty state if it was never synced
+func LoadTodoSyncState(todoPath string) (*TodoSyncState, error) {
+	states, err := loadTodoSyncStates()
+	if err != nil {
+		return nil, err
+	}
+
+	if state, ok := states[todoPath]; ok {
+		return state, nil
+	}
+	return &TodoSyncState{Lines: map[string]string{}}, nil
+}
+
+// SaveTodoSyncState stores the state of a todo.txt file after a sync
+func SaveTodoSyncState(todoPath string, state *TodoSyncState) error {
+	states, err := loadTodoSyncStates()
+	if err != nil {
+		return err
+	}
+	states[todoPath] = state
+
+	filePath, err := todoSyncStatePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode todo.txt sync state: %w", err)
+	}
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write todo.txt sync state: %w", err)
+	}
+
+	return nil
+}
+
+// TodoTxtService converts tasks between prod and the todo.txt format
+type TodoTxtService struct {
+	queries *sqlc.Queries
+	journal *journal
+}
+
+// NewTodoTxtService creates a new TodoTxtService
+func NewTodoTxtService(queries *sqlc.Queries) *TodoTxtService {
+	return &TodoTxtService{
+		queries: queries,
+		journal: newJournal(queries),
+	}
+}
+
+// todoTasks holds the tasks and projects of a user while converting them
+type todoTasks struct {
+	tasks        map[string]sqlc.Task
+	order        []string
+	projectNames map[int32]string
+	projectIDs   map[string]int32
+}
+
+func (s *TodoTxtService) load(ctx context.Context, userID int32) (*todoTasks, error) {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+
+	tasks, err := s.queries.ListTasks(ctx, sqlc.ListTasksParams{
+		UserID: user,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	projects, err := s.queries.ListProjects(ctx, user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	loaded := &todoTasks{
+		tasks:        make(map[string]sqlc.Task, len(tasks)),
+		projectNames: make(map[int32]string, len(projects)),
+		projectIDs:   make(map[string]int32, len(projects)),
+	}
+	for _, task := range tasks {
+		uuid := FormatUUID(task.Uuid)
+		loaded.tasks[uuid] = task
+		loaded.order = append(loaded.order, uuid)
+	}
+	for _, project := range projects {
+		loaded.projectNames[project.ID] = project.Name
+		loaded.projectIDs[todoWord(project.Name)] = project.ID
+	}
+
+	return loaded, nil
+}
+
+// item converts a task to a todo.txt item
+func (t *todoTasks) item(task sqlc.Task) TodoItem {
+	item := TodoItem{
+		Completed:   task.Status == "completed",
+		Priority:    todoPriorityLetter(task.Priority),
+		CreatedDate: todoDate(task.CreatedAt),
+		Text:        task.Description,
+		Contexts:    task.Tags,
+		Due:         todoDate(task.DueDate),
+		ID:          FormatUUID(task.Uuid),
+	}
+	if item.Completed {
+		item.CompletedDate = todoDate(task.CompletedAt)
+	}
+	if name, ok := t.projectNames[task.ProjectID.Int32]; ok && task.ProjectID.Valid {
+		item.Projects = []string{name}
+	}
+	item.Threshold = todoDate(task.WaitUntil)
+	if task.Recurrence.Valid {
+		item.Recurrence = todoRecurrence(task.Recurrence.String)
+	}
+
+	return item
+}
+
+// line formats a task as a todo.txt line
+func (t *todoTasks) line(task sqlc.Task) string {
+	return t.item(task).String()
+}
+
+// Export formats the tasks of a user, except those in the trash, as todo.txt lines
+func (s *TodoTxtService) Export(ctx context.Context, userID int32) ([]string, error) {
+	loaded, err := s.load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0, len(loaded.order))
+	for _, uuid := range loaded.order {
+		lines = append(lines, loaded.line(loaded.tasks[uuid]))
+	}
+	return lines, nil
+}
+
+// Import creates tasks from todo.txt items. Items with a prod: tag update the
+// task they were exported from instead. Run it in a UnitOfWork
+func (s *TodoTxtService) Import(ctx context.Context, userID int32, items []TodoItem) (*ImportReport, error) {
+	loaded, err := s.load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	report := &ImportReport{}
+	const description = "import todo.txt"
+
+	for _, item := range items {
+		if task, ok := loaded.tasks[item.ID]; ok && item.ID != "" {
+			if loaded.line(task) == item.String() {
+				report.TasksSkipped++
+				continue
+			}
+			if _, err := s.updateTask(ctx, userID, description, loaded, task, item, &report.Conflicts, &report.ProjectsCreated); err != nil {
+				return nil, err
+			}
+			report.TasksUpdated++
+			continue
+		}
+
+		created, err := s.createTask(ctx, userID, description, loaded, item, &report.Conflicts, &report.ProjectsCreated)
+		if err != nil {
+			return nil, err
+		}
+		if created == nil {
+			report.TasksSkipped++
+			continue
+		}
+		report.TasksCreated++
+	}
+
+	return report, nil
+}
+
+// Sync merges a todo.txt file with prod and returns the new content of the
+// file. Lines changed in the file update their task, tasks changed in prod
+// update their line, and when both changed since the last sync the most
+// recent change wins. New lines become tasks and new pending tasks are
+// appended to the file. A line deleted from the file moves its task to the
+// trash, except completed lines that were archived, and a task deleted in
+// prod removes its line. Run it in a UnitOfWork and save the returned state
+// once the file is written
+func (s *TodoTxtService) Sync(ctx context.Context, userID int32, lines []string, state *TodoSyncState, modified time.Time) ([]string, *TodoSyncState, *TodoSyncReport, error) {
+	loaded, err := s.load(ctx, userID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	report := &TodoSyncReport{}
+	var projectsCreated int
+	const description = "sync todo.txt"
+
+	var out []string
+	inFile := map[string]bool{}
+	for _, line := range lines {
+		item, ok := ParseTodoItem(line)
+		if !ok {
+			out = append(out, line)
+			continue
+		}
+
+		task, live := loaded.tasks[item.ID]
+		base, synced := state.Lines[item.ID]
+		if item.ID == "" || !live {
+			if item.ID != "" && synced {
+				// Deleted in prod since the last sync
+				report.LinesRemoved++
+				continue
+			}
+			created, err := s.createTask(ctx, userID, description, loaded, item, &report.Conflicts, &projectsCreated)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if created == nil {
+				out = append(out, line)
+				continue
+			}
+			out = append(out, loaded.line(*created))
+			inFile[FormatUUID(created.Uuid)] = true
+			report.TasksCreated++
+			continue
+		}
+		inFile[item.ID] = true
+
+		current := loaded.line(task)
+		fileChanged := strings.TrimSpace(line) != base
+		prodChanged := current != base
+		if !synced {
+			// Never synced, the file and prod only differ if the line was edited
+			fileChanged = strings.TrimSpace(line) != current
+			prodChanged = fileChanged
+		}
+
+		switch {
+		case !fileChanged:
+			if prodChanged {
+				report.LinesUpdated++
+			}
+			out = append(out, current)
+		case !prodChanged || modified.After(task.UpdatedAt.Time):
+			if prodChanged {
+				report.Conflicts = append(report.Conflicts, fmt.Sprintf("%q changed in both, the file is newer and was kept", task.Description))
+			}
+			updated, err := s.updateTask(ctx, userID, description, loaded, task, item, &report.Conflicts, &projectsCreated)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			out = append(out, loaded.line(*updated))
+			report.TasksUpdated++
+		default:
+			report.Conflicts = append(report.Conflicts, fmt.Sprintf("%q changed in both, prod is newer and was kept", task.Description))
+			out = append(out, current)
+			report.LinesUpdated++
+		}
+	}
+
+	for _, uuid := range loaded.order {
+		task := loaded.tasks[uuid]
+		if inFile[uuid] {
+			continue
+		}
+
+		base, synced := state.Lines[uuid]
+		if !synced {
+			if task.Status != "completed" {
+				out = append(out, loaded.line(task))
+				report.LinesAdded++
+			}
+			continue
+		}
+
+		// Completed lines leave the file when archived to done.txt
+		if task.Status == "completed" || strings.HasPrefix(base, "x ") {
+			continue
+		}
+
+		trashed, err := s.queries.DeleteTask(ctx, sqlc.DeleteTaskParams{
+			ID:     task.ID,
+			UserID: task.UserID,
+		})
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to delete task %q: %w", task.Description, err)
+		}
+		if err := s.journal.recordTask(ctx, userID, description, &task, &trashed); err != nil {
+			return nil, nil, nil, err
+		}
+		report.TasksTrashed++
+	}
+
+	synced := &TodoSyncState{
+		SyncedAt: time.Now(),
+		Lines:    map[string]string{},
+	}
+	for _, line := range out {
+		if item, ok := ParseTodoItem(line); ok && item.ID != "" {
+			synced.Lines[item.ID] = line
+		}
+	}
+
+	return out, synced, report, nil
+}
+
+// createTask creates a task from a todo.txt item, keeping the UUID of its
+// prod: tag when it is free. It returns nil when the task is in the trash or
+// the line has no description
+func (s *TodoTxtService) createTask(ctx context.Context, userID int32, description string, loaded *todoTasks, item TodoItem, conflicts *[]string, projectsCreated *int) (*sqlc.Task, error) {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+
+	if item.Text == "" {
+		*conflicts = append(*conflicts, "a line without a description was skipped")
+		return nil, nil
+	}
+
+	uuid := newUUID()
+	if item.ID != "" {
+		if parsed, err := ParseUUID(item.ID); err == nil {
+			existing, err := s.queries.GetTaskByUUID(ctx, sqlc.GetTaskByUUIDParams{
+				UserID: user,
+				Uuid:   parsed,
+			})
+			if err == nil && existing.DeletedAt.Valid {
+				*conflicts = append(*conflicts, fmt.Sprintf("%q is in the trash and was not imported, restore it to sync it again", existing.Description))
+				return nil, nil
+			}
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return nil, fmt.Errorf("failed to look up task %q: %w", item.Text, err)
+			}
+			if err != nil {
+				uuid = parsed
+			}
+		}
+	}
+
+	now := time.Now()
+	task := sqlc.Task{
+		UserID: user,
+		Status: "pending",
+		Uuid:   uuid,
+		CreatedAt: pgtype.Timestamptz{
+			Time:  now,
+			Valid: true,
+		},
+	}
+	if !item.CreatedDate.IsZero() {
+		task.CreatedAt.Time = item.CreatedDate
+	}
+	if err := s.applyItem(ctx, userID, description, loaded, &task, item, conflicts, projectsCreated); err != nil {
+		return nil, err
+	}
+
+	created, err := s.queries.ImportTask(ctx, sqlc.ImportTaskParams{
+		UserID:          task.UserID,
+		Description:     task.Description,
+		Status:          task.Status,
+		Priority:        task.Priority,
+		DueDate:         task.DueDate,
+		StartDate:       task.StartDate,
+		CompletedAt:     task.CompletedAt,
+		ProjectID:       task.ProjectID,
+		Recurrence:      task.Recurrence,
+		Tags:            task.Tags,
+		Notes:           task.Notes,
+		CreatedAt:       task.CreatedAt,
+		UpdatedAt:       task.UpdatedAt,
+		Dependent:       task.Dependent,
+		EstimateMinutes: task.EstimateMinutes,
+		Uuid:            task.Uuid,
+		WaitUntil:       task.WaitUntil,
+		Scheduled:       task.Scheduled,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task %q: %w", task.Description, err)
+	}
+	if err := s.journal.recordTask(ctx, userID, description, nil, &created); err != nil {
+		return nil, err
+	}
+
+	uuidString := FormatUUID(created.Uuid)
+	loaded.tasks[uuidString] = created
+	loaded.order = append(loaded.order, uuidString)
+	return &created, nil
+}
+
+// updateTask sets the fields of a task to the ones of a todo.txt item
+func (s *TodoTxtService) updateTask(ctx context.Context, userID int32, description string, loaded *todoTasks, before sqlc.Task, item TodoItem, conflicts *[]string, projectsCreated *int) (*sqlc.Task, error) {
+	task := before
+	if err := s.applyItem(ctx, userID, description, loaded, &task, item, conflicts, projectsCreated); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.queries.RestoreTask(ctx, sqlc.RestoreTaskParams{
+		ID:              task.ID,
+		UserID:          task.UserID,
+		Description:     task.Description,
+		Status:          task.Status,
+		Priority:        task.Priority,
+		DueDate:         task.DueDate,
+		StartDate:       task.StartDate,
+		CompletedAt:     task.CompletedAt,
+		ProjectID:       task.ProjectID,
+		Recurrence:      task.Recurrence,
+		Tags:            task.Tags,
+		Notes:           task.Notes,
+		CreatedAt:       task.CreatedAt,
+		UpdatedAt:       task.UpdatedAt,
+		Dependent:       task.Dependent,
+		EstimateMinutes: task.EstimateMinutes,
+		DeletedAt:       task.DeletedAt,
+		Uuid:            task.Uuid,
+		WaitUntil:       task.WaitUntil,
+		Scheduled:       task.Scheduled,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update task %q: %w", task.Description, err)
+	}
+	if err := s.journal.recordTask(ctx, userID, description, &before, &updated); err != nil {
+		return nil, err
+	}
+
+	loaded.tasks[FormatUUID(updated.Uuid)] = updated
+
+	// Completing a recurring task in the file creates its next occurrence
+	if updated.Status == "completed" && before.Status != "completed" && updated.Recurrence.Valid {
+		next, err := GenerateNextTaskInstance(updated, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate next recurring task: %w", err)
+		}
+		created, err := s.queries.CreateTask(ctx, sqlc.CreateTaskParams{
+			UserID:          next.UserID,
+			Description:     next.Description,
+			Status:          next.Status,
+			Priority:        next.Priority,
+			DueDate:         next.DueDate,
+			StartDate:       next.StartDate,
+			ProjectID:       next.ProjectID,
+			Recurrence:      next.Recurrence,
+			Tags:            next.Tags,
+			Notes:           next.Notes,
+			Dependent:       next.Dependent,
+			EstimateMinutes: next.EstimateMinutes,
+			Uuid:            newUUID(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create next task instance: %w", err)
+		}
+		if err := s.journal.recordTask(ctx, userID, description, nil, &created); err != nil {
+			return nil, err
+		}
+		uuid := FormatUUID(created.Uuid)
+		loaded.tasks[uuid] = created
+		loaded.order = append(loaded.order, uuid)
+	}
+
+	return &updated, nil
+}
+
+// applyItem copies the fields of a todo.txt item onto a task. Dates keep the
+// time of day of the task when the day didn't change
+func (s *TodoTxtService) applyItem(ctx context.Context, userID int32, description string, loaded *todoTasks, task *sqlc.Task, item TodoItem, conflicts *[]string, projectsCreated *int) error {
+	task.Description = item.Text
+	task.Tags = item.Contexts
+	task.Priority = prodPriority(item.Priority)
+	task.DueDate = todoTimestamp(task.DueDate, item.Due)
+	task.UpdatedAt = pgtype.Timestamptz{
+		Time:  time.Now(),
+		Valid: true,
+	}
+
+	switch {
+	case item.Completed:
+		if task.Status != "completed" || !task.CompletedAt.Valid {
+			task.CompletedAt = pgtype.Timestamptz{
+				Time:  time.Now(),
+				Valid: true,
+			}
+		}
+		task.CompletedAt = todoTimestamp(task.CompletedAt, item.CompletedDate)
+		task.Status = "completed"
+	case task.Status == "completed":
+		task.Status = "pending"
+		task.CompletedAt = pgtype.Timestamptz{}
+	}
+	task.WaitUntil = todoTimestamp(task.WaitUntil, item.Threshold)
+
+	task.Recurrence = pgtype.Text{}
+	if item.Recurrence != "" {
+		if recurrence, ok := prodTodoRecurrence(item.Recurrence); ok {
+			task.Recurrence = pgtype.Text{
+				String: recurrence,
+				Valid:  true,
+			}
+		} else {
+			*conflicts = append(*conflicts, fmt.Sprintf("recurrence %q of %q is not supported and was dropped", item.Recurrence, item.Text))
+		}
+	}
+
+	task.ProjectID = pgtype.Int4{}
+	if len(item.Projects) > 1 {
+		*conflicts = append(*conflicts, fmt.Sprintf("%q has several projects, only +%s was kept", item.Text, item.Projects[0]))
+	}
+	if len(item.Projects) > 0 {
+		name := item.Projects[0]
+		id, ok := loaded.projectIDs[todoWord(name)]
+		if !ok {
+			projects := &ProjectService{queries: s.queries, journal: s.journal}
+			project, created, err := projects.findOrCreateProject(ctx, userID, name, description)
+			if err != nil {
+				return err
+			}
+			id = project.ID
+			loaded.projectIDs[todoWord(name)] = id
+			loaded.projectNames[id] = project.Name
+			if created {
+				*projectsCreated++
+			}
+		}
+		task.ProjectID = pgtype.Int4{
+			Int32: id,
+			Valid: true,
+		}
+	}
+
+	return nil
+}
+
+// todoTimestamp returns date as a timestamp, or current when it is on that
+// day already. A zero date clears the timestamp
+func todoTimestamp(current pgtype.Timestamptz, date time.Time) pgtype.Timestamptz {
+	if date.IsZero() {
+		return pgtype.Timestamptz{}
+	}
+	if current.Valid && current.Time.Local().Format(todoDateFormat) == date.Format(todoDateFormat) {
+		return current
+	}
+	return pgtype.Timestamptz{
+		Time:  date,
+		Valid: true,
+	}
+}
+
+func todoDate(t pgtype.Timestamptz) time.Time {
+	if !t.Valid {
+		return time.Time{}
+	}
+	local := t.Time.Local()
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// todoPriorityLetter maps the H, M and L priorities to A, B and C
+func todoPriorityLetter(priority pgtype.Text) string {
+	switch priority.String {
+	case "H":
+		return "A"
+	case "M":
+		return "B"
+	case "L":
+		return "C"
+	}
+	return ""
+}
+
+// prodPriority maps A to H, B to M and any lower letter to L
+func prodPriority(letter string) pgtype.Text {
+	var priority string
+	switch {
+	case letter == "":
+		return pgtype.Text{}
+	case letter == "A":
+		priority = "H"
+	case letter == "B":
+		priority = "M"
+	default:
+		priority = "L"
+	}
+	return pgtype.Text{
+		String: priority,
+		Valid:  true,
+	}
+}
+
+var todoRecurrencePattern = regexp.MustCompile(`^\+?(\d+)([dwmyb])$`)
+
+// prodTodoRecurrence converts the rec: extension of todo.txt apps, like 1w
+// or +2d, to the prod recurrence format
+func prodTodoRecurrence(rec string) (string, bool) {
+	match := todoRecurrencePattern.FindStringSubmatch(strings.ToLower(rec))
+	if match == nil {
+		return "", false
+	}
+	n, err := strconv.Atoi(match[1])
+	if err != nil || n < 1 {
+		return "", false
+	}
+
+	switch match[2] {
+	case "d":
+		return fmt.Sprintf("daily:%d", n), true
+	case "w":
+		return fmt.Sprintf("weekly:%d", n), true
+	case "m":
+		return fmt.Sprintf("monthly:%d", n), true
+	case "y":
+		return fmt.Sprintf("yearly:%d", n), true
+	case "b":
+		if n == 1 {
+			return "weekly:1:1,2,3,4,5", true
+		}
+	}
+	return "", false
+}
+
+// todoRecurrence converts a prod recurrence to the rec: extension, keeping
+// only the frequency and interval
+func todoRecurrence(recurrence string) string {
+	pattern, err := ParseRecurrence(recurrence)
+	if err != nil {
+		return ""
+	}
+
+	switch pattern.Type {
+	case RecurrenceDaily:
+		return fmt.Sprintf("%dd", pattern.Interval)
+	case RecurrenceWeekly:
+		if pattern.Interval == 1 && len(pattern.WeekDays) == 5 && pattern.WeekDays[0] == 1 && pattern.WeekDays[4] == 5 {
+			return "1b"
+		}
+		return fmt.Sprintf("%dw", pattern.Interval)
+	case RecurrenceMonthly:
+		return fmt.Sprintf("%dm", pattern.Interval)
+	case RecurrenceYearly:
+		return fmt.Sprintf("%dy", pattern.Interval)
+	}
+	return ""
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jskallebak/prod/internal/db/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTodoItem(t *testing.T) {
+	item, ok := ParseTodoItem("(A) 2025-04-01 Call mom +Family @phone due:2025-04-05")
+	require.True(t, ok)
+	assert.Equal(t, "A", item.Priority)
+	assert.Equal(t, "2025-04-01", item.CreatedDate.Format(todoDateFormat))
+	assert.Equal(t, "Call mom", item.Text)
+	assert.Equal(t, []string{"Family"}, item.Projects)
+	assert.Equal(t, []string{"phone"}, item.Contexts)
+	assert.Equal(t, "2025-04-05", item.Due.Format(todoDateFormat))
+	assert.False(t, item.Completed)
+
+	_, ok = ParseTodoItem("   ")
+	assert.False(t, ok, "blank lines are no items")
+
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"pending", "(A) 2025-04-01 Call mom +Family @phone due:2025-04-05", ""},
+		{"completed with priority", "x 2025-04-06 2025-04-01 Call mom +Family @phone due:2025-04-05 pri:A", ""},
+		{"completed without dates", "x Call mom", ""},
+		{"threshold, recurrence and ID", "Water plants t:2025-04-03 rec:1w prod:0195f3a2-7c1e-7d4b-9a7e-3c2f1b0a9d8e", ""},
+		{"tags are moved to the end", "Call +Family mom due:2025-04-05 @phone", "Call mom +Family @phone due:2025-04-05"},
+		{"invalid dates stay in the text", "Call mom due:tomorrow", ""},
+		{"lowercase priority is text", "(a) Call mom", ""},
+		{"first date of a completed item is its completion", "x 2025-04-06 Call mom", ""},
+		{"extra spaces are dropped", "  (B)   Call   mom  ", "(B) Call mom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.want
+			if want == "" {
+				want = tt.line
+			}
+			item, ok := ParseTodoItem(tt.line)
+			require.True(t, ok)
+			assert.Equal(t, want, item.String())
+
+			again, _ := ParseTodoItem(item.String())
+			assert.Equal(t, item, again, "a formatted item parses to itself")
+		})
+	}
+}
+
+func TestTodoTxtSync(t *testing.T) {
+	db, queries := dbtest.Open(t)
+	user := dbtest.User(t, queries)
+	ctx := context.Background()
+
+	state := &TodoSyncState{Lines: map[string]string{}}
+	sync := func(lines []string, modified time.Time) ([]string, *TodoSyncReport) {
+		t.Helper()
+		var out []string
+		var report *TodoSyncReport
+		err := NewUnitOfWork(db, queries).Do(ctx, func(tx *TxServices) error {
+			var err error
+			out, state, report, err = tx.TodoTxt.Sync(ctx, user.ID, lines, state, modified)
+			return err
+		})
+		require.NoError(t, err)
+		return out, report
+	}
+	// setDescription changes a task in prod as if it was edited at updated
+	setDescription := func(from, to string, updated time.Time) {
+		t.Helper()
+		_, err := db.Exec(ctx, "UPDATE tasks SET description = $1, updated_at = $2 WHERE user_id = $3 AND description = $4",
+			to, updated, user.ID, from)
+		require.NoError(t, err)
+	}
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+
+	// New lines become tasks and get the UUID of their task
+	out, report := sync([]string{"(A) Call mom @phone due:2025-04-05", "Water plants"}, now)
+	assert.Equal(t, 2, report.TasksCreated)
+	require.Len(t, out, 2)
+	assert.Contains(t, out[0], "Call mom @phone due:2025-04-05 prod:")
+	assert.Contains(t, out[1], "Water plants prod:")
+
+	// Nothing changed
+	again, report := sync(out, now)
+	assert.Equal(t, out, again)
+	assert.Equal(t, &TodoSyncReport{}, report)
+
+	// Changed in prod only
+	setDescription("Call mom", "Call dad", now)
+	out, report = sync(out, earlier)
+	assert.Equal(t, 1, report.LinesUpdated)
+	assert.Contains(t, out[0], "Call dad")
+
+	// Changed in the file only, however old the file is
+	out[1] = strings.Replace(out[1], "Water plants", "Water the plants", 1)
+	out, report = sync(out, earlier)
+	assert.Equal(t, 1, report.TasksUpdated)
+	assert.Contains(t, out[1], "Water the plants")
+
+	// Changed in both, the file is newer
+	setDescription("Call dad", "Call grandma", earlier)
+	out[0] = strings.Replace(out[0], "Call dad", "Call grandpa", 1)
+	out, report = sync(out, now)
+	assert.Equal(t, 1, report.TasksUpdated)
+	assert.Contains(t, out[0], "Call grandpa")
+	require.Len(t, report.Conflicts, 1)
+	assert.Contains(t, report.Conflicts[0], "the file is newer")
+
+	// Changed in both, prod is newer
+	setDescription("Call grandpa", "Call aunt", now)
+	out[0] = strings.Replace(out[0], "Call grandpa", "Call uncle", 1)
+	out, report = sync(out, earlier)
+	assert.Equal(t, 0, report.TasksUpdated)
+	assert.Contains(t, out[0], "Call aunt")
+	require.Len(t, report.Conflicts, 1)
+	assert.Contains(t, report.Conflicts[0], "prod is newer")
+
+	// A line deleted from the file moves its task to the trash
+	out, report = sync(out[:1], now)
+	assert.Equal(t, 1, report.TasksTrashed)
+	require.Len(t, out, 1)
+
+	// A task deleted in prod removes its line
+	_, err := db.Exec(ctx, "UPDATE tasks SET deleted_at = NOW() WHERE user_id = $1 AND description = 'Call aunt'", user.ID)
+	require.NoError(t, err)
+	out, report = sync(out, now)
+	assert.Equal(t, 1, report.LinesRemoved)
+	assert.Empty(t, out)
+
+	// New pending tasks are appended
+	err = NewUnitOfWork(db, queries).Do(ctx, func(tx *TxServices) error {
+		_, err := tx.Tasks.CreateTask(ctx, user.ID, TaskParams{Description: "Buy milk"})
+		return err
+	})
+	require.NoError(t, err)
+	out, report = sync(out, now)
+	assert.Equal(t, 1, report.LinesAdded)
+	require.Len(t, out, 1)
+	assert.Contains(t, out[0], "Buy milk")
+
+	// Completed lines archived to done.txt keep their task
+	out, report = sync([]string{"x " + out[0]}, now)
+	assert.Equal(t, 1, report.TasksUpdated)
+	out, report = sync(nil, now)
+	assert.Equal(t, 0, report.TasksTrashed)
+	assert.Empty(t, out)
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+)
+
+// TrashService lists, restores and purges deleted tasks and projects
+type TrashService struct {
+	queries *sqlc.Queries
+	journal *journal
+}
+
+// NewTrashService creates a new TrashService
+func NewTrashService(queries *sqlc.Queries) *TrashService {
+	return &TrashService{
+		queries: queries,
+		journal: newJournal(queries),
+	}
+}
+
+// List returns the projects and tasks in the trash, most recently deleted first
+func (s *TrashService) List(ctx context.Context, userID int32) ([]sqlc.Project, []sqlc.Task, error) {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+
+	projects, err := s.queries.ListTrashedProjects(ctx, user)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list trashed projects: %w", err)
+	}
+
+	tasks, err := s.queries.ListTrashedTasks(ctx, user)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list trashed tasks: %w", err)
+	}
+
+	return projects, tasks, nil
+}
+
+// RestoreTask takes a task out of the trash together with the subtasks that
+// were deleted along with it. A parent or project still in the trash is
+// detached from the restored task. Run it in a UnitOfWork
+func (s *TrashService) RestoreTask(ctx context.Context, userID, taskID int32) ([]sqlc.Task, error) {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+
+	tree, err := s.queries.ListTrashedTaskTree(ctx, sqlc.ListTrashedTaskTreeParams{
+		ID:     taskID,
+		UserID: user,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trashed task: %w", err)
+	}
+	if len(tree) == 0 {
+		return nil, fmt.Errorf("task %d is not in the trash", taskID)
+	}
+
+	ids := make([]int32, len(tree))
+	before := make(map[int32]sqlc.Task, len(tree))
+	for i, task := range tree {
+		ids[i] = task.ID
+		before[task.ID] = task
+	}
+
+	restored, err := s.queries.UntrashTasks(ctx, sqlc.UntrashTasksParams{
+		Ids:    ids,
+		UserID: user,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore task: %w", err)
+	}
+
+	if err := s.journal.begin(ctx, userID, fmt.Sprintf("restore task %q", tree[0].Description)); err != nil {
+		return nil, err
+	}
+	for _, task := range restored {
+		old := before[task.ID]
+		if err := s.journal.recordTask(ctx, userID, "", &old, &task); err != nil {
+			return nil, err
+		}
+	}
+
+	return restored, nil
+}
+
+// RestoreProject takes a project out of the trash and re-attaches the tasks it
+// had when it was deleted. Run it in a UnitOfWork
+func (s *TrashService) RestoreProject(ctx context.Context, userID, projectID int32) (*sqlc.Project, []sqlc.Task, error) {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+
+	before, err := s.queries.GetTrashedProject(ctx, sqlc.GetTrashedProjectParams{
+		ID:     projectID,
+		UserID: user,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, fmt.Errorf("project %d is not in the trash", projectID)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get trashed project: %w", err)
+	}
+
+	project, err := s.queries.UntrashProject(ctx, sqlc.UntrashProjectParams{
+		ID:     projectID,
+		UserID: user,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to restore project: %w", err)
+	}
+
+	tasks, err := s.queries.ReattachProjectTasks(ctx, sqlc.ReattachProjectTasksParams{
+		ProjectID: pgtype.Int4{
+			Int32: projectID,
+			Valid: true,
+		},
+		UserID: user,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to re-attach project tasks: %w", err)
+	}
+	if err := s.queries.ForgetProjectTasks(ctx, projectID); err != nil {
+		return nil, nil, fmt.Errorf("failed to forget project tasks: %w", err)
+	}
+
+	if err := s.journal.recordProject(ctx, userID, fmt.Sprintf("restore project %q", project.Name), &before, &project); err != nil {
+		return nil, nil, err
+	}
+	for _, task := range tasks {
+		old := task
+		old.ProjectID = pgtype.Int4{}
+		if err := s.journal.recordTask(ctx, userID, "", &old, &task); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return &project, tasks, nil
+}
+
+// Empty permanently deletes what has been in the trash for longer than
+// olderThan and returns how many tasks and projects were purged. This can't
+// be undone. Run it in a UnitOfWork
+func (s *TrashService) Empty(ctx context.Context, userID int32, olderThan time.Duration) (int64, int64, error) {
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+	cutoff := pgtype.Timestamptz{
+		Time:  time.Now().Add(-olderThan),
+		Valid: true,
+	}
+
+	// Subtasks restored on their own still point at their purged parent
+	err := s.queries.DetachPurgedSubtasks(ctx, sqlc.DetachPurgedSubtasksParams{
+		UserID:    user,
+		DeletedAt: cutoff,
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to detach subtasks: %w", err)
+	}
+
+	tasks, err := s.queries.PurgeTrashedTasks(ctx, sqlc.PurgeTrashedTasksParams{
+		UserID:    user,
+		DeletedAt: cutoff,
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to purge tasks: %w", err)
+	}
+
+	projects, err := s.queries.PurgeTrashedProjects(ctx, sqlc.PurgeTrashedProjectsParams{
+		UserID:    user,
+		DeletedAt: cutoff,
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to purge projects: %w", err)
+	}
+
+	return tasks, projects, nil
+}
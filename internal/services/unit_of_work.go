@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+)
+
+// TxBeginner starts transactions. It is implemented by *pgxpool.Pool, and by
+// pgx.Tx where Begin creates a savepoint so units of work can be nested
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// UnitOfWork runs the statements of a multi-step operation in one transaction
+type UnitOfWork struct {
+	db      TxBeginner
+	queries *sqlc.Queries
+}
+
+// NewUnitOfWork creates a UnitOfWork starting its transactions on db
+func NewUnitOfWork(db TxBeginner, queries *sqlc.Queries) *UnitOfWork {
+	return &UnitOfWork{
+		db:      db,
+		queries: queries,
+	}
+}
+
+// TxServices are the services bound to the transaction of a unit of work
+type TxServices struct {
+	Queries     *sqlc.Queries
+	Tasks       *TaskService
+	Projects    *ProjectService
+	Pomodoros   *PomodoroService
+	Trash       *TrashService
+	Backup      *BackupService
+	Taskwarrior *TaskwarriorService
+	TodoTxt     *TodoTxtService
+	ICal        *ICalService
+	Planner     *PlannerService
+	Journal     *JournalService
+}
+
+// Do runs fn in a transaction, committing when fn returns nil and rolling back
+// every statement it made otherwise
+func (u *UnitOfWork) Do(ctx context.Context, fn func(tx *TxServices) error) error {
+	tx, err := u.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// The services share a journal so the whole unit is undone in one step
+	queries := u.queries.WithTx(tx)
+	journal := newJournal(queries)
+	err = fn(&TxServices{
+		Queries:     queries,
+		Tasks:       &TaskService{queries: queries, journal: journal},
+		Projects:    &ProjectService{queries: queries, journal: journal},
+		Pomodoros:   &PomodoroService{queries: queries, journal: journal},
+		Trash:       &TrashService{queries: queries, journal: journal},
+		Backup:      &BackupService{queries: queries, journal: journal},
+		Taskwarrior: &TaskwarriorService{queries: queries, journal: journal},
+		TodoTxt:     &TodoTxtService{queries: queries, journal: journal},
+		ICal:        &ICalService{queries: queries, journal: journal},
+		Planner:     &PlannerService{queries: queries},
+		Journal:     NewJournalService(queries),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
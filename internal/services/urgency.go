@@ -0,0 +1,414 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+)
+
+// Names of the urgency coefficients. Tags are weighed by a coefficient per
+// tag, named UrgencyTagPrefix followed by the tag
+const (
+	UrgencyPriorityHigh   = "priority.H"
+	UrgencyPriorityMedium = "priority.M"
+	UrgencyPriorityLow    = "priority.L"
+	UrgencyDue            = "due"
+	UrgencyAge            = "age"
+	UrgencyBlocking       = "blocking"
+	UrgencyActive         = "active"
+	UrgencyProject        = "project"
+	UrgencyTagPrefix      = "tag."
+)
+
+// UrgencyCoefficients weigh the factors that make up the urgency of a task,
+// by coefficient name
+type UrgencyCoefficients map[string]float64
+
+// DefaultUrgencyCoefficients are the coefficients of users who haven't changed
+// them, the same as Taskwarrior's
+var DefaultUrgencyCoefficients = UrgencyCoefficients{
+	UrgencyPriorityHigh:          6.0,
+	UrgencyPriorityMedium:        3.9,
+	UrgencyPriorityLow:           1.8,
+	UrgencyDue:                   12.0,
+	UrgencyAge:                   2.0,
+	UrgencyBlocking:              8.0,
+	UrgencyActive:                4.0,
+	UrgencyProject:               1.0,
+	UrgencyTagPrefix + "next":    15.0,
+	UrgencyTagPrefix + "someday": -5.0,
+}
+
+// Names returns the coefficient names in order, the tags last
+func (c UrgencyCoefficients) Names() []string {
+	names := make([]string, 0, len(c))
+	for name := range c {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		tagI, tagJ := strings.HasPrefix(names[i], UrgencyTagPrefix), strings.HasPrefix(names[j], UrgencyTagPrefix)
+		if tagI != tagJ {
+			return tagJ
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// ValidateUrgencyCoefficient checks that name is a known coefficient
+func ValidateUrgencyCoefficient(name string) error {
+	if tag, ok := strings.CutPrefix(name, UrgencyTagPrefix); ok {
+		if tag == "" {
+			return fmt.Errorf("coefficient %q needs a tag, like tag.next", name)
+		}
+		return nil
+	}
+	if _, ok := DefaultUrgencyCoefficients[name]; !ok {
+		return fmt.Errorf("unknown urgency coefficient %q", name)
+	}
+	return nil
+}
+
+// UrgencyTerm is the part of a task's urgency one factor adds. Factor is how
+// much the factor applies, mostly from 0 to 1, and Value is Factor times the
+// coefficient
+type UrgencyTerm struct {
+	Name        string
+	Detail      string
+	Factor      float64
+	Coefficient float64
+	Value       float64
+}
+
+// Urgency is the urgency score of a task with the terms it is the sum of
+type Urgency struct {
+	Score float64
+	Terms []UrgencyTerm
+}
+
+// urgencyAgeMax is the age at which a task's age counts fully
+const urgencyAgeMax = 365 * 24 * time.Hour
+
+// UrgencyModel scores tasks with a user's coefficients
+type UrgencyModel struct {
+	Coefficients UrgencyCoefficients
+	Now          time.Time
+	// blocking counts the open tasks waiting for each task, through a
+	// dependency or because they are its parent
+	blocking map[int32]int
+	// blocked are the tasks waiting for an open dependency or subtask
+	blocked map[int32]bool
+}
+
+// Blocked tells whether a task waits for an open dependency or subtask
+func (m *UrgencyModel) Blocked(taskID int32) bool {
+	return m.blocked[taskID]
+}
+
+// Urgency computes the urgency of a task
+func (m *UrgencyModel) Urgency(task sqlc.Task) Urgency {
+	var urgency Urgency
+	add := func(name, detail string, factor float64) {
+		coefficient := m.Coefficients[name]
+		if factor == 0 || coefficient == 0 {
+			return
+		}
+		urgency.Terms = append(urgency.Terms, UrgencyTerm{
+			Name:        name,
+			Detail:      detail,
+			Factor:      factor,
+			Coefficient: coefficient,
+			Value:       factor * coefficient,
+		})
+		urgency.Score += factor * coefficient
+	}
+
+	if task.Priority.Valid {
+		switch task.Priority.String {
+		case "H":
+			add(UrgencyPriorityHigh, "high priority", 1)
+		case "M":
+			add(UrgencyPriorityMedium, "medium priority", 1)
+		case "L":
+			add(UrgencyPriorityLow, "low priority", 1)
+		}
+	}
+
+	if task.DueDate.Valid {
+		// Due dates are days, so they are compared by day
+		days := int(math.Round(dateOf(task.DueDate.Time.Local()).Sub(dateOf(m.Now)).Hours() / 24))
+		add(UrgencyDue, describeDue(days), dueFactor(days))
+	}
+
+	if task.Status == "active" {
+		add(UrgencyActive, "started", 1)
+	}
+
+	if n := m.blocking[task.ID]; n > 0 {
+		add(UrgencyBlocking, fmt.Sprintf("blocks %d %s", n, plural(n, "task", "tasks")), 1)
+	}
+
+	if task.ProjectID.Valid {
+		add(UrgencyProject, "in a project", 1)
+	}
+
+	if task.CreatedAt.Valid {
+		age := m.Now.Sub(task.CreatedAt.Time)
+		days := int(age.Hours() / 24)
+		add(UrgencyAge, fmt.Sprintf("created %d %s ago", days, plural(days, "day", "days")),
+			math.Min(math.Max(float64(age)/float64(urgencyAgeMax), 0), 1))
+	}
+
+	for _, tag := range task.Tags {
+		add(UrgencyTagPrefix+tag, "tagged "+tag, 1)
+	}
+
+	return urgency
+}
+
+// Sort orders tasks by urgency, the most urgent first. Completed tasks come
+// after the others in the order they were in
+func (m *UrgencyModel) Sort(tasks []sqlc.Task) {
+	scores := make(map[int32]float64, len(tasks))
+	for _, task := range tasks {
+		scores[task.ID] = m.Urgency(task).Score
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		doneI, doneJ := tasks[i].Status == "completed", tasks[j].Status == "completed"
+		if doneI || doneJ {
+			return !doneI && doneJ
+		}
+		return scores[tasks[i].ID] > scores[tasks[j].ID]
+	})
+}
+
+// dueFactor rises from 0.2 for tasks due in two weeks or more to 1 for tasks
+// a week or more overdue
+func dueFactor(daysUntilDue int) float64 {
+	overdue := float64(-daysUntilDue)
+	switch {
+	case overdue >= 7:
+		return 1
+	case overdue >= -14:
+		return (overdue+14)*0.8/21 + 0.2
+	default:
+		return 0.2
+	}
+}
+
+func describeDue(days int) string {
+	switch {
+	case days < -1:
+		return fmt.Sprintf("overdue by %d days", -days)
+	case days == -1:
+		return "due yesterday"
+	case days == 0:
+		return "due today"
+	case days == 1:
+		return "due tomorrow"
+	default:
+		return fmt.Sprintf("due in %d days", days)
+	}
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// UrgencyService stores urgency coefficients and builds urgency models
+type UrgencyService struct {
+	queries *sqlc.Queries
+}
+
+// NewUrgencyService creates a new urgency service
+func NewUrgencyService(queries *sqlc.Queries) *UrgencyService {
+	return &UrgencyService{
+		queries: queries,
+	}
+}
+
+// Coefficients returns the user's urgency coefficients: the defaults with the
+// ones the user set instead
+func (s *UrgencyService) Coefficients(ctx context.Context, userID int32) (UrgencyCoefficients, error) {
+	stored, err := s.queries.ListUrgencyCoefficients(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get urgency coefficients: %w", err)
+	}
+
+	coefficients := make(UrgencyCoefficients, len(DefaultUrgencyCoefficients)+len(stored))
+	for name, value := range DefaultUrgencyCoefficients {
+		coefficients[name] = value
+	}
+	for _, coefficient := range stored {
+		coefficients[coefficient.Name] = coefficient.Value
+	}
+	return coefficients, nil
+}
+
+// SetCoefficient sets one of the user's urgency coefficients
+func (s *UrgencyService) SetCoefficient(ctx context.Context, userID int32, name string, value float64) error {
+	if err := ValidateUrgencyCoefficient(name); err != nil {
+		return err
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Errorf("invalid value for %s", name)
+	}
+
+	err := s.queries.SetUrgencyCoefficient(ctx, sqlc.SetUrgencyCoefficientParams{
+		UserID: userID,
+		Name:   name,
+		Value:  value,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set urgency coefficient %s: %w", name, err)
+	}
+	return nil
+}
+
+// ResetCoefficient puts one of the user's urgency coefficients back to its
+// default. It returns false when the coefficient wasn't set
+func (s *UrgencyService) ResetCoefficient(ctx context.Context, userID int32, name string) (bool, error) {
+	n, err := s.queries.DeleteUrgencyCoefficient(ctx, sqlc.DeleteUrgencyCoefficientParams{
+		UserID: userID,
+		Name:   name,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to reset urgency coefficient %s: %w", name, err)
+	}
+	return n > 0, nil
+}
+
+// Model builds the urgency model of the user at now
+func (s *UrgencyService) Model(ctx context.Context, userID int32, now time.Time) (*UrgencyModel, error) {
+	model, _, err := s.model(ctx, userID, now)
+	return model, err
+}
+
+// model builds the urgency model of the user, returning the open tasks it
+// was built from
+func (s *UrgencyService) model(ctx context.Context, userID int32, now time.Time) (*UrgencyModel, []sqlc.Task, error) {
+	coefficients, err := s.Coefficients(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user := pgtype.Int4{
+		Int32: userID,
+		Valid: true,
+	}
+	tasks, err := s.queries.ListTasks(ctx, sqlc.ListTasksParams{
+		UserID: user,
+		Status: []string{"pending", "active"},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get open tasks: %w", err)
+	}
+	dependencies, err := s.queries.ListUserTaskDependencies(ctx, user)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get task dependencies: %w", err)
+	}
+
+	open := make(map[int32]bool, len(tasks))
+	for _, task := range tasks {
+		open[task.ID] = true
+	}
+	blocking := make(map[int32]int)
+	blocked := make(map[int32]bool)
+	for _, dependency := range dependencies {
+		if open[dependency.TaskID.Int32] && open[dependency.DependsOnID.Int32] {
+			blocking[dependency.DependsOnID.Int32]++
+			blocked[dependency.TaskID.Int32] = true
+		}
+	}
+	// Parents wait for their subtasks
+	for _, task := range tasks {
+		if task.Dependent.Valid && open[task.Dependent.Int32] {
+			blocking[task.ID]++
+			blocked[task.Dependent.Int32] = true
+		}
+	}
+
+	return &UrgencyModel{
+		Coefficients: coefficients,
+		Now:          now,
+		blocking:     blocking,
+		blocked:      blocked,
+	}, tasks, nil
+}
+
+// NextTask is an actionable task with why it is worth doing
+type NextTask struct {
+	Task    sqlc.Task
+	Urgency Urgency
+	// Reason sums up the largest terms of the urgency, like "due tomorrow,
+	// high priority"
+	Reason string
+}
+
+// Next returns up to n of the most urgent tasks that can be worked on now:
+// open tasks that don't wait for an open dependency or subtask, aren't
+// waiting until later and aren't scheduled after today. With projectID set
+// only the tasks of that project are considered
+func (s *UrgencyService) Next(ctx context.Context, userID int32, projectID *int32, n int, now time.Time) ([]NextTask, error) {
+	model, tasks, err := s.model(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var actionable []sqlc.Task
+	for _, task := range tasks {
+		if model.Blocked(task.ID) {
+			continue
+		}
+		if task.WaitUntil.Valid && task.WaitUntil.Time.After(now) || deferredPast(task, dateOf(now)) {
+			continue
+		}
+		if projectID != nil && task.ProjectID.Int32 != *projectID {
+			continue
+		}
+		actionable = append(actionable, task)
+	}
+	model.Sort(actionable)
+
+	next := []NextTask{}
+	for _, task := range actionable[:min(n, len(actionable))] {
+		urgency := model.Urgency(task)
+		next = append(next, NextTask{
+			Task:    task,
+			Urgency: urgency,
+			Reason:  urgencyReason(urgency),
+		})
+	}
+	return next, nil
+}
+
+// urgencyReason joins the details of the two largest terms that add to an
+// urgency
+func urgencyReason(urgency Urgency) string {
+	terms := slices.Clone(urgency.Terms)
+	sort.SliceStable(terms, func(i, j int) bool {
+		return terms[i].Value > terms[j].Value
+	})
+
+	var details []string
+	for _, term := range terms {
+		if term.Value <= 0 || len(details) == 2 {
+			break
+		}
+		details = append(details, term.Detail)
+	}
+	if len(details) == 0 {
+		return "nothing more urgent"
+	}
+	return strings.Join(details, ", ")
+}
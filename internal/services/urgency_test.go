@@ -0,0 +1,41 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jskallebak/prod/internal/db/sqlc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUrgencySort(t *testing.T) {
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.Local)
+	model := &UrgencyModel{
+		Coefficients: DefaultUrgencyCoefficients,
+		Now:          now,
+	}
+	due := func(days int) pgtype.Timestamptz {
+		return pgtype.Timestamptz{Time: now.AddDate(0, 0, days), Valid: true}
+	}
+
+	tasks := []sqlc.Task{
+		{ID: 1, Status: "pending", Priority: pgtype.Text{String: "L", Valid: true}},
+		{ID: 2, Status: "completed", Priority: pgtype.Text{String: "H", Valid: true}},
+		{ID: 3, Status: "pending", DueDate: due(0)},
+		{ID: 4, Status: "pending", Priority: pgtype.Text{String: "H", Valid: true}, DueDate: due(30)},
+		{ID: 5, Status: "pending", Tags: []string{"next"}},
+	}
+	model.Sort(tasks)
+
+	var ids []int32
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	assert.Equal(t, []int32{5, 3, 4, 1, 2}, ids, "completed tasks come last")
+
+	urgency := model.Urgency(tasks[1])
+	assert.Len(t, urgency.Terms, 1)
+	assert.Equal(t, "due today", urgency.Terms[0].Detail)
+	assert.InDelta(t, 12*(14*0.8/21+0.2), urgency.Score, 0.001)
+}
@@ -449,6 +449,9 @@ func GenerateNextTaskInstance(task sqlc.Task, state *RecurrenceState) (*sqlc.Tas
 		Notes:       task.Notes,
 		Dependent:   task.Dependent,
 
+		// Each instance gets the same estimate
+		EstimateMinutes: task.EstimateMinutes,
+
 		// Set the new due date
 		DueDate: pgtype.Timestamptz{
 			Time:  nextDue,
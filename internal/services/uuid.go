@@ -0,0 +1,62 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// newUUID returns a random version 4 UUID
+func newUUID() pgtype.UUID {
+	var id pgtype.UUID
+	if _, err := rand.Read(id.Bytes[:]); err != nil {
+		panic(fmt.Sprintf("failed to generate uuid: %v", err))
+	}
+	id.Bytes[6] = id.Bytes[6]&0x0f | 0x40
+	id.Bytes[8] = id.Bytes[8]&0x3f | 0x80
+	id.Valid = true
+	return id
+}
+
+// FormatUUID formats a UUID in its canonical form, or returns "" when it is NULL
+func FormatUUID(id pgtype.UUID) string {
+	if !id.Valid {
+		return ""
+	}
+	b := id.Bytes
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// ParseUUID parses a UUID in canonical form
+func ParseUUID(s string) (pgtype.UUID, error) {
+	var id pgtype.UUID
+	if err := id.Scan(s); err != nil {
+		return pgtype.UUID{}, fmt.Errorf("invalid uuid %q: %w", s, err)
+	}
+	return id, nil
+}
+
+// icalNamespace is the namespace of the UUIDs derived from iCalendar UIDs,
+// the URL namespace of RFC 4122
+var icalNamespace = [16]byte{0x6b, 0xa7, 0xb8, 0x11, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+
+// uidToUUID returns the UUID of a task imported with an iCalendar UID. UIDs
+// that are UUIDs are used as is, others map to the same name based version 5
+// UUID every time, so importing them again finds the task
+func uidToUUID(uid string) pgtype.UUID {
+	if id, err := ParseUUID(uid); err == nil {
+		return id
+	}
+
+	hash := sha1.New()
+	hash.Write(icalNamespace[:])
+	hash.Write([]byte(uid))
+	var id pgtype.UUID
+	copy(id.Bytes[:], hash.Sum(nil))
+	id.Bytes[6] = id.Bytes[6]&0x0f | 0x50
+	id.Bytes[8] = id.Bytes[8]&0x3f | 0x80
+	id.Valid = true
+	return id
+}
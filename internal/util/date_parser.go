@@ -2,6 +2,8 @@ package util
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -13,3 +15,91 @@ func ParseDate(dateStr string) (time.Time, error) {
 	}
 	return date, nil
 }
+
+// ParseDateTime parses a date and time in local time. Accepted formats are
+// "YYYY-MM-DD HH:MM", "today HH:MM", "yesterday HH:MM" and "HH:MM" (today)
+func ParseDateTime(input string) (time.Time, error) {
+	now := time.Now()
+	fields := strings.Fields(strings.ToLower(strings.TrimSpace(input)))
+
+	var day time.Time
+	var clock string
+	switch len(fields) {
+	case 1:
+		day = now
+		clock = fields[0]
+	case 2:
+		switch fields[0] {
+		case "today":
+			day = now
+		case "yesterday":
+			day = now.AddDate(0, 0, -1)
+		default:
+			d, err := time.ParseInLocation("2006-01-02", fields[0], time.Local)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid date format: %s", fields[0])
+			}
+			day = d
+		}
+		clock = fields[1]
+	default:
+		return time.Time{}, fmt.Errorf("invalid date/time: %q", input)
+	}
+
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time format: %s (use HH:MM)", clock)
+	}
+
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, time.Local), nil
+}
+
+// StartOfWeek returns midnight of the Monday of the week of t, in the
+// location of t. Weeks start on Monday everywhere in prod, like the ISO
+// weekdays used by recurrences
+func StartOfWeek(t time.Time) time.Time {
+	monday := t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+	return time.Date(monday.Year(), monday.Month(), monday.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// ParseDay parses a day relative to now and returns its start in local time.
+// Accepted are "YYYY-MM-DD", "today", "tomorrow", a weekday like "mon" or
+// "friday" (the next one after today) and a number of days or weeks from
+// today like "2d", "+3d" or "1w"
+func ParseDay(input string, now time.Time) (time.Time, error) {
+	input = strings.ToLower(strings.TrimSpace(input))
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	switch input {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		if input == name || input == name[:3] {
+			days := (int(day)-int(today.Weekday())+6)%7 + 1
+			return today.AddDate(0, 0, days), nil
+		}
+	}
+
+	if number := strings.TrimPrefix(input, "+"); len(number) > 1 {
+		count, err := strconv.Atoi(number[:len(number)-1])
+		if err == nil && count >= 0 {
+			switch number[len(number)-1] {
+			case 'd':
+				return today.AddDate(0, 0, count), nil
+			case 'w':
+				return today.AddDate(0, 0, 7*count), nil
+			}
+		}
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", input, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date: %s (use YYYY-MM-DD, today, tomorrow, a weekday or e.g. +2d)", input)
+	}
+	return date, nil
+}
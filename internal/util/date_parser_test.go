@@ -0,0 +1,54 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDay(t *testing.T) {
+	// A Wednesday
+	now := time.Date(2025, 5, 14, 15, 30, 0, 0, time.Local)
+	day := func(d int) time.Time {
+		return time.Date(2025, 5, d, 0, 0, 0, 0, time.Local)
+	}
+
+	for input, want := range map[string]time.Time{
+		"today":      day(14),
+		"tomorrow":   day(15),
+		"friday":     day(16),
+		"mon":        day(19),
+		"wed":        day(21),
+		"+2d":        day(16),
+		"1w":         day(21),
+		"2025-05-30": day(30),
+	} {
+		got, err := ParseDay(input, now)
+		assert.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	_, err := ParseDay("someday", now)
+	assert.Error(t, err)
+}
+
+func TestParseDuration(t *testing.T) {
+	for input, want := range map[string]time.Duration{
+		"30d":   30 * 24 * time.Hour,
+		"2w":    14 * 24 * time.Hour,
+		" 1W ":  7 * 24 * time.Hour,
+		"1h30m": 90 * time.Minute,
+		"12h":   12 * time.Hour,
+		"0d":    0,
+	} {
+		got, err := ParseDuration(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	for _, input := range []string{"", "  ", "-1d", "-1h", "d", "1.5d", "30 days", "soon"} {
+		_, err := ParseDuration(input)
+		assert.Error(t, err, input)
+	}
+}
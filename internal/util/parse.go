@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // ParseArgs parses arguments in formats like "1", "1-3", "1,2,4", "1 2 4" or combinations
@@ -74,3 +75,62 @@ func Input2Int(input string) (int, error) {
 	}
 	return i, nil
 }
+
+// ParseEstimate parses a task estimate like "4p" (pomodoros) or "90m", "1h30m" (durations)
+// Returns the estimate in minutes, using pomodoroMinutes as the length of one pomodoro.
+// Estimates are stored in minutes, so pomodoros are converted once, when entered
+func ParseEstimate(input string, pomodoroMinutes int) (int, error) {
+	input = strings.ToLower(strings.TrimSpace(input))
+	if input == "" {
+		return 0, fmt.Errorf("empty estimate")
+	}
+
+	var minutes int
+	if strings.HasSuffix(input, "p") {
+		count, err := strconv.Atoi(strings.TrimSuffix(input, "p"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid pomodoro count: %s", input)
+		}
+		minutes = count * pomodoroMinutes
+	} else {
+		d, err := time.ParseDuration(input)
+		if err != nil {
+			return 0, fmt.Errorf("invalid estimate: %s (use e.g. 4p or 90m)", input)
+		}
+		minutes = int(d.Minutes())
+	}
+
+	if minutes <= 0 {
+		return 0, fmt.Errorf("estimate must be positive: %s", input)
+	}
+
+	return minutes, nil
+}
+
+// ParseDuration parses a duration like "30d", "2w" or "1h30m". A day is 24
+// hours and a week 7 days
+func ParseDuration(input string) (time.Duration, error) {
+	input = strings.ToLower(strings.TrimSpace(input))
+	if input == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+
+	for suffix, unit := range map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	} {
+		if strings.HasSuffix(input, suffix) {
+			count, err := strconv.Atoi(strings.TrimSuffix(input, suffix))
+			if err != nil || count < 0 {
+				return 0, fmt.Errorf("invalid duration: %s (use e.g. 30d, 2w or 12h)", input)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+
+	d, err := time.ParseDuration(input)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration: %s (use e.g. 30d, 2w or 12h)", input)
+	}
+	return d, nil
+}